@@ -48,6 +48,14 @@ function download_pkgs(){
     pkg_list=(
         "github.com/BurntSushi/toml"
         "gopkg.in/mgo.v2"
+        "go.etcd.io/bbolt"
+        "golang.org/x/net/dns/dnsmessage"
+        "github.com/fxamacker/cbor/v2"
+        "google.golang.org/grpc"
+        "google.golang.org/protobuf/proto"
+        "github.com/hashicorp/raft"
+        "github.com/hashicorp/raft-boltdb/v2"
+        "github.com/hashicorp/go-hclog"
     )
 
     idx=1
@@ -15,5 +15,18 @@
 #
 ###############################################################################
 #!/bin/bash
-mongod --repair
-mongod --smallfiles & ./tns-server
+
+# Local MongoDB is not required when the embedded "bolt" database,
+# a remote MongoDB (url) or the cluster with its own replicas is configured
+cluster_enabled() {
+    awk '/^[[:space:]]*\[/ { section = $0 }
+         section ~ /^[[:space:]]*\[cluster\]/ && /^[[:space:]]*enabled[[:space:]]*=[[:space:]]*true/ { found = 1 }
+         END { exit !found }' ./config/config.toml
+}
+
+if grep -Eq '^[[:space:]]*(type[[:space:]]*=[[:space:]]*"(bolt|memory)"|url[[:space:]]*=)' ./config/config.toml || cluster_enabled; then
+    ./tns-server
+else
+    mongod --repair
+    mongod --smallfiles & ./tns-server
+fi
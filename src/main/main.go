@@ -18,9 +18,14 @@
 package main
 
 import (
+	"flag"
 	"tns/api"
 )
 
 func main() {
-	api.RunServer("./config/config.toml")
+	devMode := flag.Bool("dev", false, "keep topics in memory instead of the configured database")
+	relayUpstream := flag.String("relay", "", "run as a relay of the upstream server at the given address")
+	flag.Parse()
+
+	api.RunServer("./config/config.toml", *devMode, *relayUpstream)
 }
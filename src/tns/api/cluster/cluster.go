@@ -0,0 +1,127 @@
+/*******************************************************************************
+ * Copyright 2018 Samsung Electronics All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ *******************************************************************************/
+package cluster
+
+import (
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"strings"
+	"tns/api/common"
+	"tns/commons/errors"
+	"tns/commons/logger"
+	topicDB "tns/db/topic"
+)
+
+// Only the leader of the cluster writes topics and keeps publishers alive, so
+// followers pass requests to the leader. They are forwarded through a reverse
+// proxy, or redirected with 307 (Temporary Redirect) if redirect is set, which
+// keeps the method and body of the request.
+// The state of each node is served on /api/v1/tns/cluster without forwarding.
+
+type Command interface {
+	Handle(w http.ResponseWriter, req *http.Request)
+	Forward(w http.ResponseWriter, req *http.Request, redirect bool) bool
+}
+
+type RequestHandler struct{}
+
+// FORWARDED_HEADER is set to the ID of the leader on forwarded requests, which
+// are not forwarded again if the leader has changed in the meantime.
+const FORWARDED_HEADER = "X-Tns-Forwarded-To"
+
+var clusterExecutor topicDB.Cluster
+
+func init() {
+	clusterExecutor = topicDB.RaftExecutor{}
+}
+
+// Handle returns the state of this node and the members of the cluster.
+func (RequestHandler) Handle(w http.ResponseWriter, req *http.Request) {
+	logger.Logging(logger.DEBUG, "IN")
+	defer logger.Logging(logger.DEBUG, "OUT")
+
+	// Check URL
+	url := strings.TrimPrefix(req.URL.Path, "/api/v1"+"/tns/cluster")
+	if len(url) != 0 {
+		common.WriteError(w, errors.NotFoundURL{url})
+		return
+	}
+
+	if req.Method != http.MethodGet {
+		logger.Logging(logger.DEBUG, "Invalid Method")
+		common.WriteError(w, errors.InvalidMethod{req.Method})
+		return
+	}
+
+	resp, err := clusterExecutor.ReadCluster()
+	if err != nil {
+		common.WriteError(w, err)
+		return
+	}
+
+	common.WriteResponse(w, http.StatusOK, common.MapToJsonByte(resp))
+}
+
+// Forward passes the request to the leader and returns true, or returns false
+// if this node is the leader and should handle the request.
+// 503 (Service Unavailable) is returned if the leader is not reachable.
+func (RequestHandler) Forward(w http.ResponseWriter, req *http.Request, redirect bool) bool {
+	if clusterExecutor.IsLeader() {
+		return false
+	}
+
+	leader, exists := clusterExecutor.Leader()
+	switch {
+	case !exists:
+		logger.Logging(logger.DEBUG, "No leader")
+		common.WriteError(w, errors.Unavailable{"no leader is elected"})
+		return true
+	case req.Header.Get(FORWARDED_HEADER) != "":
+		logger.Logging(logger.DEBUG, "Leader has changed: "+req.Header.Get(FORWARDED_HEADER))
+		common.WriteError(w, errors.Unavailable{"leader has changed to " + leader.Id})
+		return true
+	case leader.ApiAddress == "":
+		logger.Logging(logger.ERROR, "Unknown API address of the leader: "+leader.Id)
+		common.WriteError(w, errors.Unavailable{"unknown address of the leader " + leader.Id})
+		return true
+	}
+
+	if redirect {
+		location := url.URL{Scheme: "http", Host: leader.ApiAddress, Path: req.URL.Path, RawQuery: req.URL.RawQuery}
+		logger.Logging(logger.DEBUG, "Redirect to "+location.String())
+		http.Redirect(w, req, location.String(), http.StatusTemporaryRedirect)
+		return true
+	}
+
+	logger.Logging(logger.DEBUG, "Forward to "+leader.Id+" "+leader.ApiAddress)
+	proxy := &httputil.ReverseProxy{
+		Director: func(out *http.Request) {
+			out.URL.Scheme = "http"
+			out.URL.Host = leader.ApiAddress
+			out.Header.Set(FORWARDED_HEADER, leader.Id)
+		},
+		FlushInterval: -1, // Events of watch are streamed
+		ErrorHandler: func(w http.ResponseWriter, req *http.Request, err error) {
+			logger.Logging(logger.ERROR, "Forward failed: "+err.Error())
+			common.WriteError(w, errors.Unavailable{"failed to forward to the leader " + leader.Id})
+		},
+	}
+	proxy.ServeHTTP(w, req)
+
+	return true
+}
@@ -0,0 +1,268 @@
+/*******************************************************************************
+ * Copyright 2018 Samsung Electronics All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ *******************************************************************************/
+package cluster
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/golang/mock/gomock"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"tns/commons/errors"
+	topicDB "tns/db/topic"
+	topicDBMock "tns/db/topic/mocks"
+)
+
+const clusterUrl = "/api/v1/tns/cluster"
+const topicUrl = "/api/v1/tns/topic"
+
+var Handler Command
+
+func init() {
+	Handler = RequestHandler{}
+}
+
+func TestCallHandleWithInvalidRequest(t *testing.T) {
+	// Mock is not necessary for this test
+
+	testCases := []struct {
+		name         string
+		method       string
+		url          string
+		expectedCode int
+	}{
+		{"InvalidUrl", "GET", clusterUrl + "/invalid", http.StatusNotFound},
+		{"InvalidMethod_Post", "POST", clusterUrl, http.StatusBadRequest},
+		{"InvalidMethod_Delete", "DELETE", clusterUrl, http.StatusBadRequest},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(tc.method, tc.url, nil)
+			w := httptest.NewRecorder()
+
+			Handler.Handle(w, req)
+
+			if w.Code != tc.expectedCode {
+				t.Errorf("Expected Code: %s, Actual: %s", http.StatusText(tc.expectedCode), http.StatusText(w.Code))
+			}
+		})
+	}
+}
+
+func TestCallHandleGet(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	clusterMockObj := topicDBMock.NewMockCluster(ctrl)
+
+	// pass mockObj to a real object.
+	clusterExecutor = clusterMockObj
+
+	expectedResp := map[string]interface{}{"node_id": "node1", "state": "leader", "leader_id": "node1"}
+	expectedRespByte, _ := json.Marshal(expectedResp)
+
+	testCases := []struct {
+		name         string
+		mockRetResp  map[string]interface{}
+		mockRetError error
+		expectedCode int
+	}{
+		{"Success", expectedResp, nil, http.StatusOK},
+		{"InternalError", nil, errors.InternalServerError{}, http.StatusInternalServerError},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			gomock.InOrder(
+				clusterMockObj.EXPECT().ReadCluster().Return(tc.mockRetResp, tc.mockRetError),
+			)
+
+			req := httptest.NewRequest("GET", clusterUrl, nil)
+			w := httptest.NewRecorder()
+
+			Handler.Handle(w, req)
+
+			if w.Code != tc.expectedCode {
+				t.Errorf("Expected Code: %s, Actual: %s", http.StatusText(tc.expectedCode), http.StatusText(w.Code))
+			}
+			if tc.mockRetError == nil && 0 != bytes.Compare(w.Body.Bytes(), expectedRespByte) {
+				t.Errorf("Expected body: %s, Actual: %s", expectedRespByte, w.Body.Bytes())
+			}
+		})
+	}
+}
+
+func TestCallForwardOnLeader(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	clusterMockObj := topicDBMock.NewMockCluster(ctrl)
+
+	// pass mockObj to a real object.
+	clusterExecutor = clusterMockObj
+
+	gomock.InOrder(
+		clusterMockObj.EXPECT().IsLeader().Return(true),
+	)
+
+	req := httptest.NewRequest("GET", topicUrl+"?name=/a", nil)
+	w := httptest.NewRecorder()
+
+	if Handler.Forward(w, req, false) {
+		t.Error("Request is forwarded on the leader")
+	}
+}
+
+func TestCallForwardWithoutLeader(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	clusterMockObj := topicDBMock.NewMockCluster(ctrl)
+
+	// pass mockObj to a real object.
+	clusterExecutor = clusterMockObj
+
+	testCases := []struct {
+		name          string
+		mockRetLeader topicDB.ClusterNode
+		mockRetExists bool
+		forwardedTo   string
+	}{
+		{"NoLeader", topicDB.ClusterNode{}, false, ""},
+		{"LeaderChanged", topicDB.ClusterNode{Id: "node2", ApiAddress: "127.0.0.1:48324"}, true, "node1"},
+		{"UnknownApiAddress", topicDB.ClusterNode{Id: "node2"}, true, ""},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			gomock.InOrder(
+				clusterMockObj.EXPECT().IsLeader().Return(false),
+				clusterMockObj.EXPECT().Leader().Return(tc.mockRetLeader, tc.mockRetExists),
+			)
+
+			req := httptest.NewRequest("GET", topicUrl+"?name=/a", nil)
+			if tc.forwardedTo != "" {
+				req.Header.Set(FORWARDED_HEADER, tc.forwardedTo)
+			}
+			w := httptest.NewRecorder()
+
+			if !Handler.Forward(w, req, false) {
+				t.Error("Request is not handled")
+			}
+			if w.Code != http.StatusServiceUnavailable {
+				t.Errorf("Expected Code: %s, Actual: %s", http.StatusText(http.StatusServiceUnavailable), http.StatusText(w.Code))
+			}
+		})
+	}
+}
+
+func TestCallForwardWithRedirect(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	clusterMockObj := topicDBMock.NewMockCluster(ctrl)
+
+	// pass mockObj to a real object.
+	clusterExecutor = clusterMockObj
+
+	gomock.InOrder(
+		clusterMockObj.EXPECT().IsLeader().Return(false),
+		clusterMockObj.EXPECT().Leader().Return(topicDB.ClusterNode{Id: "node2", ApiAddress: "127.0.0.1:48324"}, true),
+	)
+
+	req := httptest.NewRequest("DELETE", topicUrl+"?name=/a", nil)
+	w := httptest.NewRecorder()
+
+	if !Handler.Forward(w, req, true) {
+		t.Error("Request is not redirected")
+	}
+	if w.Code != http.StatusTemporaryRedirect {
+		t.Errorf("Expected Code: %s, Actual: %s", http.StatusText(http.StatusTemporaryRedirect), http.StatusText(w.Code))
+	}
+	expectedLocation := "http://127.0.0.1:48324" + topicUrl + "?name=/a"
+	if location := w.Header().Get("Location"); location != expectedLocation {
+		t.Errorf("Expected Location: %s, Actual: %s", expectedLocation, location)
+	}
+}
+
+func TestCallForwardWithProxy(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	clusterMockObj := topicDBMock.NewMockCluster(ctrl)
+
+	// pass mockObj to a real object.
+	clusterExecutor = clusterMockObj
+
+	testBodyString := `{"topic":{"name":"/a","datamodel":"test_0.0.1","endpoint":"localhost:1883","protocol":"MQTT"}}`
+
+	leader := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		body, _ := ioutil.ReadAll(req.Body)
+		switch {
+		case req.Header.Get(FORWARDED_HEADER) != "node2":
+			w.WriteHeader(http.StatusBadRequest)
+		case req.Method != "POST" || req.URL.Path != topicUrl || string(body) != testBodyString:
+			w.WriteHeader(http.StatusBadRequest)
+		default:
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte(`{"ttl":60}`))
+		}
+	}))
+	defer leader.Close()
+
+	// Closed port for the unreachable leader
+	listener, _ := net.Listen("tcp", "127.0.0.1:0")
+	closedAddress := listener.Addr().String()
+	listener.Close()
+
+	testCases := []struct {
+		name         string
+		apiAddress   string
+		expectedCode int
+		expectedBody string
+	}{
+		{"Success", strings.TrimPrefix(leader.URL, "http://"), http.StatusCreated, `{"ttl":60}`},
+		{"UnreachableLeader", closedAddress, http.StatusServiceUnavailable, ""},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			gomock.InOrder(
+				clusterMockObj.EXPECT().IsLeader().Return(false),
+				clusterMockObj.EXPECT().Leader().Return(topicDB.ClusterNode{Id: "node2", ApiAddress: tc.apiAddress}, true),
+			)
+
+			req := httptest.NewRequest("POST", topicUrl, strings.NewReader(testBodyString))
+			w := httptest.NewRecorder()
+
+			if !Handler.Forward(w, req, false) {
+				t.Error("Request is not forwarded")
+			}
+			if w.Code != tc.expectedCode {
+				t.Errorf("Expected Code: %s, Actual: %s", http.StatusText(tc.expectedCode), http.StatusText(w.Code))
+			}
+			if tc.expectedBody != "" && w.Body.String() != tc.expectedBody {
+				t.Errorf("Expected body: %s, Actual: %s", tc.expectedBody, w.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,216 @@
+/*******************************************************************************
+ * Copyright 2018 Samsung Electronics All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ *******************************************************************************/
+package coap
+
+import (
+	"net"
+	"strconv"
+	"time"
+	"tns/commons/logger"
+	keepaliveController "tns/controller/keepalive"
+	topicController "tns/controller/topic"
+)
+
+// Constrained publishers can register, look up, remove and keep alive topics
+// with CoAP over UDP (RFC 7252) in the same way as the REST APIs:
+//  - POST, GET and DELETE on /tns/topic, and
+//  - POST on /tns/keepalive,
+// where queries are given as Uri-Query options, e.g., "name=/a". Payloads are
+// JSON or CBOR, as given in Content-Format, and responses are in the format of
+// Accept, or of the request if not given.
+// Confirmable requests are answered with piggybacked responses, and
+// non-confirmable requests with non-confirmable responses unless suppressed
+// with the No-Response option (RFC 7967), e.g., 2 for successful keep-alives.
+// Block-wise transfer is not supported, so large lookups should use REST APIs.
+
+type Config struct {
+	Enabled bool
+	Ip      string
+	Port    uint
+}
+
+type Command interface {
+	Serve(config Config) error
+}
+
+type RequestHandler struct{}
+
+const (
+	EXCHANGE_LIFETIME = 247 * time.Second // Confirmable requests may be retransmitted within this
+	NON_LIFETIME      = 145 * time.Second // Non-confirmable requests may be duplicated within this
+	MAX_EXCHANGES     = 4096              // Recent requests kept for deduplication
+	MAX_MESSAGE_SIZE  = 65507             // Maximum payload of UDP over IPv4
+)
+
+var topicExecutor topicController.Command
+var keepaliveExecutor keepaliveController.Command
+
+func init() {
+	topicExecutor = topicController.Executor{}
+	keepaliveExecutor = keepaliveController.Executor{}
+}
+
+// exchange is a request received recently, whose response is sent again
+// for its duplicates instead of handling them again.
+type exchange struct {
+	resp      []byte // nil if no response has been sent
+	expiresAt time.Time
+}
+
+// endpoint receives the messages on a socket one by one.
+type endpoint struct {
+	messageID uint16              // ID of the last non-confirmable response
+	exchanges map[string]exchange // "address/message ID":exchange
+}
+
+// Serve starts to handle CoAP requests over UDP in background.
+func (RequestHandler) Serve(config Config) error {
+	logger.Logging(logger.DEBUG, "IN")
+	defer logger.Logging(logger.DEBUG, "OUT")
+
+	address := net.JoinHostPort(config.Ip, strconv.FormatUint(uint64(config.Port), 10))
+
+	conn, err := net.ListenPacket("udp", address)
+	if err != nil {
+		logger.Logging(logger.ERROR, "ListenPacket failed: "+err.Error())
+		return err
+	}
+
+	logger.Logging(logger.DEBUG, "Serve CoAP on "+address)
+
+	go serve(conn)
+
+	return nil
+}
+
+func newEndpoint() *endpoint {
+	return &endpoint{
+		messageID: uint16(time.Now().UnixNano()),
+		exchanges: make(map[string]exchange),
+	}
+}
+
+func serve(conn net.PacketConn) {
+	e := newEndpoint()
+	buf := make([]byte, MAX_MESSAGE_SIZE)
+	for {
+		n, addr, err := conn.ReadFrom(buf)
+		if err != nil {
+			logger.Logging(logger.ERROR, "ReadFrom failed: "+err.Error())
+			return
+		}
+
+		resp := e.receive(buf[:n], addr.String(), time.Now())
+		if resp != nil {
+			conn.WriteTo(resp, addr)
+		}
+	}
+}
+
+// receive returns the response to the message from address, or nil if nothing
+// should be sent.
+func (e *endpoint) receive(data []byte, address string, now time.Time) []byte {
+	req, err := parseMessage(data)
+	if err != nil {
+		logger.Logging(logger.DEBUG, "parseMessage failed: "+err.Error())
+		// Malformed confirmable messages are rejected, the others are ignored
+		if len(data) >= 4 && req.messageType == CONFIRMABLE {
+			return message{messageType: RESET, id: req.id}.marshal()
+		}
+		return nil
+	}
+
+	switch {
+	case req.messageType == ACKNOWLEDGEMENT || req.messageType == RESET:
+		// No confirmable message is sent by the server
+		return nil
+	case req.code == CODE_EMPTY || req.code>>5 != 0:
+		// Ping with an empty message, or an unexpected response
+		if req.messageType == CONFIRMABLE {
+			return message{messageType: RESET, id: req.id}.marshal()
+		}
+		return nil
+	}
+
+	key := address + "/" + strconv.Itoa(int(req.id))
+	if ex, exists := e.exchanges[key]; exists && now.Before(ex.expiresAt) {
+		logger.Logging(logger.DEBUG, "Duplicate message: "+key)
+		return ex.resp
+	}
+
+	resp := handle(req)
+	resp.token = req.token
+	logger.Logging(logger.DEBUG, "Response "+codeString(resp.code)+" to "+address)
+
+	var sent []byte
+	lifetime := NON_LIFETIME
+	switch {
+	case req.messageType == CONFIRMABLE && isSuppressed(req, resp.code):
+		lifetime = EXCHANGE_LIFETIME
+		sent = message{messageType: ACKNOWLEDGEMENT, id: req.id}.marshal()
+	case req.messageType == CONFIRMABLE:
+		lifetime = EXCHANGE_LIFETIME
+		resp.messageType = ACKNOWLEDGEMENT
+		resp.id = req.id
+		sent = resp.marshal()
+	case !isSuppressed(req, resp.code):
+		e.messageID++
+		resp.messageType = NON_CONFIRMABLE
+		resp.id = e.messageID
+		sent = resp.marshal()
+	}
+
+	e.remember(key, exchange{resp: sent, expiresAt: now.Add(lifetime)}, now)
+
+	return sent
+}
+
+// remember keeps the exchange, removing the expired ones if too many are kept.
+func (e *endpoint) remember(key string, ex exchange, now time.Time) {
+	if len(e.exchanges) >= MAX_EXCHANGES {
+		for k, old := range e.exchanges {
+			if !now.Before(old.expiresAt) {
+				delete(e.exchanges, k)
+			}
+		}
+	}
+	if len(e.exchanges) >= MAX_EXCHANGES {
+		// Duplicates of the forgotten requests are handled again
+		logger.Logging(logger.ERROR, "Too many exchanges, deduplication is reset")
+		e.exchanges = make(map[string]exchange)
+	}
+	e.exchanges[key] = ex
+}
+
+// isSuppressed returns true if the client is not interested in the response
+// of the code, as given in the No-Response option.
+func isSuppressed(req message, code uint8) bool {
+	value, exists := req.uintOption(OPTION_NO_RESPONSE)
+	if !exists {
+		return false
+	}
+
+	switch code >> 5 {
+	case 2:
+		return value&0x02 != 0
+	case 4:
+		return value&0x08 != 0
+	case 5:
+		return value&0x10 != 0
+	}
+	return false
+}
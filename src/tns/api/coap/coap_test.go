@@ -0,0 +1,497 @@
+/*******************************************************************************
+ * Copyright 2018 Samsung Electronics All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ *******************************************************************************/
+package coap
+
+import (
+	"bytes"
+	"github.com/fxamacker/cbor/v2"
+	"github.com/golang/mock/gomock"
+	"net"
+	"reflect"
+	"testing"
+	"time"
+	"tns/commons/errors"
+	keepaliveControllerMock "tns/controller/keepalive/mocks"
+	topicControllerMock "tns/controller/topic/mocks"
+)
+
+const (
+	testTopicBody = `{"topic":{"name":"/a","endpoint":"0.0.0.0:1234","datamodel":"test_0.0.1"}}`
+	testPingBody  = `{"topic_names":["/a"]}`
+)
+
+var testTopics = map[string]interface{}{
+	"topics": []map[string]interface{}{{"name": "/a", "endpoints": []string{"0.0.0.0:1234"}}},
+}
+
+var Handler Command
+
+func init() {
+	Handler = RequestHandler{}
+}
+
+// newRequest returns a request on the path with the options,
+// each of which is followed by its value.
+func newRequest(code uint8, path string, payload string, options ...interface{}) message {
+	req := message{messageType: CONFIRMABLE, code: code, id: 1, token: []byte{0xab}, payload: []byte(payload)}
+	for _, segment := range bytes.Split([]byte(path[1:]), []byte("/")) {
+		req.options = append(req.options, option{OPTION_URI_PATH, segment})
+	}
+	for i := 0; i < len(options); i += 2 {
+		number := options[i].(int)
+		switch value := options[i+1].(type) {
+		case string:
+			req.options = append(req.options, option{uint16(number), []byte(value)})
+		case int:
+			req.addUintOption(uint16(number), uint(value))
+		}
+	}
+	return req
+}
+
+func TestCallServeWithInvalidAddress(t *testing.T) {
+	err := Handler.Serve(Config{Enabled: true, Ip: "invalid ip", Port: 0})
+	if err == nil {
+		t.Error("Serve did not return an error")
+	}
+}
+
+func TestHandleTopic(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	topicCtrlrMockObj := topicControllerMock.NewMockCommand(ctrl)
+
+	// pass mockObj to a real object.
+	topicExecutor = topicCtrlrMockObj
+
+	cborBody, _ := cbor.Marshal(map[string]interface{}{
+		"topic": map[string]interface{}{"name": "/a", "endpoint": "0.0.0.0:1234", "datamodel": "test_0.0.1", "ka_interval": 60},
+	})
+	createdResp := map[string]interface{}{"ka_interval": uint(200)}
+
+	testCases := []struct {
+		name            string
+		req             message
+		expectedCall    func() *gomock.Call
+		expectedCode    uint8
+		expectedFormat  int // -1 for a diagnostic payload
+		expectedPayload string
+	}{
+		{
+			"Post_Created",
+			newRequest(CODE_POST, TOPIC_PATH, testTopicBody, OPTION_CONTENT_FORMAT, FORMAT_JSON),
+			func() *gomock.Call {
+				return topicCtrlrMockObj.EXPECT().CreateTopic(testTopicBody).Return(createdResp, true, nil)
+			},
+			CODE_CREATED, FORMAT_JSON, `{"ka_interval":200}`,
+		},
+		{
+			"Post_Changed",
+			newRequest(CODE_POST, TOPIC_PATH, testTopicBody),
+			func() *gomock.Call {
+				return topicCtrlrMockObj.EXPECT().CreateTopic(testTopicBody).Return(createdResp, false, nil)
+			},
+			CODE_CHANGED, FORMAT_JSON, `{"ka_interval":200}`,
+		},
+		{
+			"Post_CBOR",
+			newRequest(CODE_POST, TOPIC_PATH, string(cborBody), OPTION_CONTENT_FORMAT, FORMAT_CBOR),
+			func() *gomock.Call {
+				return topicCtrlrMockObj.EXPECT().CreateTopic(
+					`{"topic":{"datamodel":"test_0.0.1","endpoint":"0.0.0.0:1234","ka_interval":60,"name":"/a"}}`).Return(createdResp, true, nil)
+			},
+			CODE_CREATED, FORMAT_CBOR, "\xa1\x6bka_interval\x18\xc8",
+		},
+		{
+			"Post_InvalidParam",
+			newRequest(CODE_POST, TOPIC_PATH, `{}`),
+			func() *gomock.Call {
+				return topicCtrlrMockObj.EXPECT().CreateTopic(`{}`).Return(nil, false, errors.InvalidParam{"'topic' field is required"})
+			},
+			CODE_BAD_REQUEST, -1, "invalid parameter: 'topic' field is required",
+		},
+		{
+			"Post_EmptyBody",
+			newRequest(CODE_POST, TOPIC_PATH, ""),
+			nil,
+			CODE_BAD_REQUEST, -1, "invalid parameter: body is empty",
+		},
+		{
+			"Post_InvalidCBOR",
+			newRequest(CODE_POST, TOPIC_PATH, "\xa1", OPTION_CONTENT_FORMAT, FORMAT_CBOR),
+			nil,
+			CODE_BAD_REQUEST, -1, "invalid parameter: invalid CBOR payload",
+		},
+		{
+			"Post_UnsupportedFormat",
+			newRequest(CODE_POST, TOPIC_PATH, "text", OPTION_CONTENT_FORMAT, FORMAT_TEXT),
+			nil,
+			CODE_UNSUPPORTED_CONTENT_FORMAT, -1, "unsupported content format: 0",
+		},
+		{
+			"Get",
+			newRequest(CODE_GET, TOPIC_PATH, "", OPTION_URI_QUERY, "name=/a", OPTION_URI_QUERY, "hierarchical=yes",
+				OPTION_URI_QUERY, "selector=site=plant3"),
+			func() *gomock.Call {
+				return topicCtrlrMockObj.EXPECT().ReadTopic("/a", true, "site=plant3", false, -1).Return(testTopics, nil)
+			},
+			CODE_CONTENT, FORMAT_JSON, `{"topics":[{"endpoints":["0.0.0.0:1234"],"name":"/a"}]}`,
+		},
+		{
+			"Get_AcceptCBOR",
+			newRequest(CODE_GET, TOPIC_PATH, "", OPTION_URI_QUERY, "name=/+", OPTION_ACCEPT, FORMAT_CBOR),
+			func() *gomock.Call {
+				return topicCtrlrMockObj.EXPECT().ReadTopic("/+", false, "", false, -1).Return(testTopics, nil)
+			},
+			CODE_CONTENT, FORMAT_CBOR, "\xa1\x66topics\x81\xa2\x64name\x62/a\x69endpoints\x81\x6c0.0.0.0:1234",
+		},
+		{
+			"Get_NotFound",
+			newRequest(CODE_GET, TOPIC_PATH, "", OPTION_URI_QUERY, "name=/b"),
+			func() *gomock.Call {
+				return topicCtrlrMockObj.EXPECT().ReadTopic("/b", false, "", false, -1).Return(nil, errors.NotFound{"/b"})
+			},
+			CODE_NOT_FOUND, -1, "not found target: /b",
+		},
+		{
+			"Get_InvalidHierarchical",
+			newRequest(CODE_GET, TOPIC_PATH, "", OPTION_URI_QUERY, "hierarchical=true"),
+			nil,
+			CODE_BAD_REQUEST, -1, "invalid query: hierarchical",
+		},
+		{
+			"Get_UnknownQuery",
+			newRequest(CODE_GET, TOPIC_PATH, "", OPTION_URI_QUERY, "key=value"),
+			nil,
+			CODE_BAD_REQUEST, -1, "invalid query: key",
+		},
+		{
+			"Get_DuplicateQuery",
+			newRequest(CODE_GET, TOPIC_PATH, "", OPTION_URI_QUERY, "name=/a", OPTION_URI_QUERY, "name=/b"),
+			nil,
+			CODE_BAD_REQUEST, -1, "invalid query: name",
+		},
+		{
+			"Get_NotAcceptable",
+			newRequest(CODE_GET, TOPIC_PATH, "", OPTION_ACCEPT, FORMAT_TEXT),
+			nil,
+			CODE_NOT_ACCEPTABLE, -1, "acceptable formats are JSON and CBOR",
+		},
+		{
+			"Delete",
+			newRequest(CODE_DELETE, TOPIC_PATH, "", OPTION_URI_QUERY, "name=/a", OPTION_URI_QUERY, "endpoint=0.0.0.0:1234"),
+			func() *gomock.Call {
+				return topicCtrlrMockObj.EXPECT().DeleteTopic("/a", "0.0.0.0:1234").Return(nil)
+			},
+			CODE_DELETED, -1, "",
+		},
+		{
+			"Delete_NotFound",
+			newRequest(CODE_DELETE, TOPIC_PATH, "", OPTION_URI_QUERY, "name=/b"),
+			func() *gomock.Call {
+				return topicCtrlrMockObj.EXPECT().DeleteTopic("/b", "").Return(errors.NotFound{"/b"})
+			},
+			CODE_NOT_FOUND, -1, "not found target: /b",
+		},
+		{
+			"Delete_Unavailable",
+			newRequest(CODE_DELETE, TOPIC_PATH, "", OPTION_URI_QUERY, "name=/c"),
+			func() *gomock.Call {
+				return topicCtrlrMockObj.EXPECT().DeleteTopic("/c", "").Return(errors.Unavailable{"not the leader"})
+			},
+			CODE_SERVICE_UNAVAILABLE, -1, "service unavailable: not the leader",
+		},
+		{
+			"InvalidMethod",
+			newRequest(CODE_PUT, TOPIC_PATH, testTopicBody),
+			nil,
+			CODE_METHOD_NOT_ALLOWED, -1, "invalid method: 0.03",
+		},
+		{
+			"InvalidPath",
+			newRequest(CODE_GET, "/tns/invalid", ""),
+			nil,
+			CODE_NOT_FOUND, -1, "unsupported url: /tns/invalid",
+		},
+		{
+			"UnrecognizedCriticalOption",
+			newRequest(CODE_GET, TOPIC_PATH, "", 9, "value"),
+			nil,
+			CODE_BAD_OPTION, -1, "unrecognized option: 9",
+		},
+		{
+			"UnrecognizedElectiveOption",
+			newRequest(CODE_DELETE, TOPIC_PATH, "", OPTION_URI_QUERY, "name=/a", 60, 1),
+			func() *gomock.Call {
+				return topicCtrlrMockObj.EXPECT().DeleteTopic("/a", "").Return(nil)
+			},
+			CODE_DELETED, -1, "",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if tc.expectedCall != nil {
+				gomock.InOrder(tc.expectedCall())
+			}
+
+			resp := handle(tc.req)
+
+			checkResponse(t, resp, tc.expectedCode, tc.expectedFormat, tc.expectedPayload)
+		})
+	}
+}
+
+func TestHandleKeepAlive(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	keepaliveCtrlrMockObj := keepaliveControllerMock.NewMockCommand(ctrl)
+
+	// pass mockObj to a real object.
+	keepaliveExecutor = keepaliveCtrlrMockObj
+
+	testCases := []struct {
+		name            string
+		mockResp        map[string]interface{}
+		mockError       error
+		expectedCode    uint8
+		expectedFormat  int
+		expectedPayload string
+	}{
+		{"Success", nil, nil, CODE_CHANGED, -1, ""},
+		{"Success_Lease", map[string]interface{}{"lease_id": "l1", "ttl": uint(30)}, nil, CODE_CHANGED, FORMAT_JSON, `{"lease_id":"l1","ttl":30}`},
+		{"NotFound", map[string]interface{}{"topic_names": []string{"/a"}}, errors.NotFound{}, CODE_NOT_FOUND, FORMAT_JSON, `{"topic_names":["/a"]}`},
+		{"InvalidParam", nil, errors.InvalidParam{"topic_names"}, CODE_BAD_REQUEST, -1, "invalid parameter: topic_names"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			gomock.InOrder(
+				keepaliveCtrlrMockObj.EXPECT().HandlePing(testPingBody).Return(tc.mockResp, tc.mockError),
+			)
+
+			resp := handle(newRequest(CODE_POST, KEEPALIVE_PATH, testPingBody))
+
+			checkResponse(t, resp, tc.expectedCode, tc.expectedFormat, tc.expectedPayload)
+		})
+	}
+
+	resp := handle(newRequest(CODE_GET, KEEPALIVE_PATH, ""))
+	checkResponse(t, resp, CODE_METHOD_NOT_ALLOWED, -1, "invalid method: 0.01")
+}
+
+func checkResponse(t *testing.T, resp message, expectedCode uint8, expectedFormat int, expectedPayload string) {
+	if resp.code != expectedCode {
+		t.Errorf("Expected code: %s, Actual: %s", codeString(expectedCode), codeString(resp.code))
+	}
+	format, exists := resp.uintOption(OPTION_CONTENT_FORMAT)
+	if expectedFormat < 0 && exists || expectedFormat >= 0 && (!exists || format != uint(expectedFormat)) {
+		t.Errorf("Expected format: %d, Actual: %d (%t)", expectedFormat, format, exists)
+	}
+	if expectedFormat == FORMAT_CBOR {
+		// Order of map keys may differ
+		var expected, actual interface{}
+		cbor.Unmarshal([]byte(expectedPayload), &expected)
+		if err := cbor.Unmarshal(resp.payload, &actual); err != nil || !reflect.DeepEqual(expected, actual) {
+			t.Errorf("Expected payload: %v, Actual: %v", expected, actual)
+		}
+	} else if string(resp.payload) != expectedPayload {
+		t.Errorf("Expected payload: %q, Actual: %q", expectedPayload, resp.payload)
+	}
+}
+
+func TestReceive(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	keepaliveCtrlrMockObj := keepaliveControllerMock.NewMockCommand(ctrl)
+
+	// pass mockObj to a real object.
+	keepaliveExecutor = keepaliveCtrlrMockObj
+
+	now := time.Now()
+	address := "10.0.0.1:5683"
+
+	t.Run("Confirmable", func(t *testing.T) {
+		e := newEndpoint()
+		req := newRequest(CODE_POST, KEEPALIVE_PATH, testPingBody)
+
+		// Handled only once for the retransmission
+		keepaliveCtrlrMockObj.EXPECT().HandlePing(testPingBody).Return(nil, nil)
+
+		data := e.receive(req.marshal(), address, now)
+		resp, err := parseMessage(data)
+		if err != nil {
+			t.Fatalf("parseMessage failed: %s", err.Error())
+		}
+		if resp.messageType != ACKNOWLEDGEMENT || resp.id != req.id || !bytes.Equal(resp.token, req.token) || resp.code != CODE_CHANGED {
+			t.Errorf("Unexpected response: %v", resp)
+		}
+
+		if retransmitted := e.receive(req.marshal(), address, now.Add(time.Second)); !bytes.Equal(retransmitted, data) {
+			t.Errorf("Expected the same response: %x, Actual: %x", data, retransmitted)
+		}
+
+		// From another client
+		keepaliveCtrlrMockObj.EXPECT().HandlePing(testPingBody).Return(nil, nil)
+		e.receive(req.marshal(), "10.0.0.2:5683", now)
+
+		// After the exchange lifetime
+		keepaliveCtrlrMockObj.EXPECT().HandlePing(testPingBody).Return(nil, nil)
+		e.receive(req.marshal(), address, now.Add(EXCHANGE_LIFETIME))
+	})
+
+	t.Run("Confirmable_NoResponse", func(t *testing.T) {
+		e := newEndpoint()
+		req := newRequest(CODE_POST, KEEPALIVE_PATH, testPingBody, OPTION_NO_RESPONSE, 2)
+
+		keepaliveCtrlrMockObj.EXPECT().HandlePing(testPingBody).Return(nil, nil)
+
+		// Acknowledged without the response
+		resp, err := parseMessage(e.receive(req.marshal(), address, now))
+		if err != nil || resp.messageType != ACKNOWLEDGEMENT || resp.id != req.id || resp.code != CODE_EMPTY || len(resp.token) != 0 {
+			t.Errorf("Unexpected response: %v", resp)
+		}
+	})
+
+	t.Run("NonConfirmable", func(t *testing.T) {
+		e := newEndpoint()
+		req := newRequest(CODE_POST, KEEPALIVE_PATH, testPingBody)
+		req.messageType = NON_CONFIRMABLE
+
+		keepaliveCtrlrMockObj.EXPECT().HandlePing(testPingBody).Return(nil, nil)
+
+		resp, err := parseMessage(e.receive(req.marshal(), address, now))
+		if err != nil || resp.messageType != NON_CONFIRMABLE || !bytes.Equal(resp.token, req.token) || resp.code != CODE_CHANGED {
+			t.Errorf("Unexpected response: %v", resp)
+		}
+
+		// Duplicate
+		if data := e.receive(req.marshal(), address, now); data == nil {
+			t.Error("Expected the same response")
+		}
+	})
+
+	t.Run("NonConfirmable_NoResponse", func(t *testing.T) {
+		e := newEndpoint()
+		req := newRequest(CODE_POST, KEEPALIVE_PATH, testPingBody, OPTION_NO_RESPONSE, 2)
+		req.messageType = NON_CONFIRMABLE
+
+		// Successful keep-alive is not answered
+		keepaliveCtrlrMockObj.EXPECT().HandlePing(testPingBody).Return(nil, nil)
+		if data := e.receive(req.marshal(), address, now); data != nil {
+			t.Errorf("Unexpected response: %x", data)
+		}
+
+		// but failed one is answered
+		req.id++
+		keepaliveCtrlrMockObj.EXPECT().HandlePing(testPingBody).Return(map[string]interface{}{"topic_names": []string{"/a"}}, errors.NotFound{})
+		resp, err := parseMessage(e.receive(req.marshal(), address, now))
+		if err != nil || resp.messageType != NON_CONFIRMABLE || resp.code != CODE_NOT_FOUND {
+			t.Errorf("Unexpected response: %v", resp)
+		}
+	})
+
+	t.Run("Ping", func(t *testing.T) {
+		e := newEndpoint()
+		resp, err := parseMessage(e.receive([]byte{0x40, CODE_EMPTY, 0x00, 0x07}, address, now))
+		if err != nil || resp.messageType != RESET || resp.id != 7 {
+			t.Errorf("Unexpected response: %v", resp)
+		}
+	})
+
+	t.Run("Malformed", func(t *testing.T) {
+		e := newEndpoint()
+		resp, err := parseMessage(e.receive([]byte{0x40, CODE_GET, 0x00, 0x07, 0xf1}, address, now))
+		if err != nil || resp.messageType != RESET || resp.id != 7 {
+			t.Errorf("Unexpected response: %v", resp)
+		}
+
+		// Ignored
+		if data := e.receive([]byte{0x50, CODE_GET, 0x00, 0x08, 0xf1}, address, now); data != nil {
+			t.Errorf("Unexpected response: %x", data)
+		}
+		if data := e.receive([]byte{0x60, CODE_EMPTY, 0x00, 0x09}, address, now); data != nil {
+			t.Errorf("Unexpected response: %x", data)
+		}
+	})
+}
+
+func TestRemember(t *testing.T) {
+	e := newEndpoint()
+	now := time.Now()
+
+	for i := 0; i < MAX_EXCHANGES; i++ {
+		expiresAt := now.Add(time.Second)
+		if i%2 == 0 {
+			expiresAt = now
+		}
+		e.exchanges[string(rune(i))] = exchange{expiresAt: expiresAt}
+	}
+
+	// Expired ones are removed
+	e.remember("new", exchange{expiresAt: now.Add(time.Second)}, now)
+	if len(e.exchanges) != MAX_EXCHANGES/2+1 {
+		t.Errorf("Expected exchanges: %d, Actual: %d", MAX_EXCHANGES/2+1, len(e.exchanges))
+	}
+}
+
+func TestServe(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	topicCtrlrMockObj := topicControllerMock.NewMockCommand(ctrl)
+
+	// pass mockObj to a real object.
+	topicExecutor = topicCtrlrMockObj
+
+	topicCtrlrMockObj.EXPECT().ReadTopic("/a", false, "", false, -1).Return(testTopics, nil)
+
+	packetConn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("ListenPacket failed: %s", err.Error())
+	}
+	defer packetConn.Close()
+	go serve(packetConn)
+
+	conn, err := net.Dial("udp", packetConn.LocalAddr().String())
+	if err != nil {
+		t.Fatalf("Dial failed: %s", err.Error())
+	}
+	defer conn.Close()
+
+	req := newRequest(CODE_GET, TOPIC_PATH, "", OPTION_URI_QUERY, "name=/a")
+	conn.Write(req.marshal())
+
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	buf := make([]byte, MAX_MESSAGE_SIZE)
+	n, err := conn.Read(buf)
+	if err != nil {
+		t.Fatalf("Read failed: %s", err.Error())
+	}
+
+	resp, err := parseMessage(buf[:n])
+	if err != nil {
+		t.Fatalf("parseMessage failed: %s", err.Error())
+	}
+	if resp.messageType != ACKNOWLEDGEMENT || resp.id != req.id || resp.code != CODE_CONTENT {
+		t.Errorf("Unexpected response: %v", resp)
+	}
+	checkResponse(t, resp, CODE_CONTENT, FORMAT_JSON, `{"topics":[{"endpoints":["0.0.0.0:1234"],"name":"/a"}]}`)
+}
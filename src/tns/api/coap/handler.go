@@ -0,0 +1,302 @@
+/*******************************************************************************
+ * Copyright 2018 Samsung Electronics All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ *******************************************************************************/
+package coap
+
+import (
+	"encoding/json"
+	"github.com/fxamacker/cbor/v2"
+	"reflect"
+	"strconv"
+	"strings"
+	"tns/api/common"
+	"tns/commons/errors"
+	"tns/commons/logger"
+)
+
+const (
+	TOPIC_PATH     = "/tns/topic"
+	KEEPALIVE_PATH = "/tns/keepalive"
+)
+
+// Decodes CBOR maps with the same types as JSON objects.
+var cborDecMode cbor.DecMode
+
+func init() {
+	var err error
+	cborDecMode, err = cbor.DecOptions{DefaultMapType: reflect.TypeOf(map[string]interface{}{})}.DecMode()
+	if err != nil {
+		panic(err)
+	}
+}
+
+// unsupportedFormat is returned for payloads which are neither JSON nor CBOR.
+type unsupportedFormat struct {
+	format uint
+}
+
+func (e unsupportedFormat) Error() string {
+	return "unsupported content format: " + strconv.FormatUint(uint64(e.format), 10)
+}
+
+// handle returns the response to the request, whose type, ID and token are
+// set by the caller.
+func handle(req message) message {
+	logger.Logging(logger.DEBUG, "IN", req.path())
+	defer logger.Logging(logger.DEBUG, "OUT")
+
+	for _, opt := range req.options {
+		if opt.number&1 == 1 && !isRecognized(opt.number) {
+			return diagnostic(CODE_BAD_OPTION, "unrecognized option: "+strconv.Itoa(int(opt.number)))
+		}
+	}
+
+	format := uint(FORMAT_JSON)
+	if value, exists := req.uintOption(OPTION_CONTENT_FORMAT); exists {
+		format = value
+	}
+	if value, exists := req.uintOption(OPTION_ACCEPT); exists {
+		if value != FORMAT_JSON && value != FORMAT_CBOR {
+			return diagnostic(CODE_NOT_ACCEPTABLE, "acceptable formats are JSON and CBOR")
+		}
+		format = value
+	}
+
+	switch req.path() {
+	case TOPIC_PATH:
+		switch req.code {
+		case CODE_POST:
+			return handlePostTopic(req, format)
+		case CODE_GET:
+			return handleGetTopic(req, format)
+		case CODE_DELETE:
+			return handleDeleteTopic(req)
+		}
+	case KEEPALIVE_PATH:
+		if req.code == CODE_POST {
+			return handlePostKeepAlive(req, format)
+		}
+	default:
+		logger.Logging(logger.DEBUG, "Unknown URL")
+		return reply(errors.NotFoundURL{req.path()})
+	}
+
+	logger.Logging(logger.DEBUG, "Invalid Method")
+	return reply(errors.InvalidMethod{codeString(req.code)})
+}
+
+func handlePostTopic(req message, format uint) message {
+	body, err := readBody(req)
+	if err != nil {
+		return reply(err)
+	}
+
+	resp, created, err := topicExecutor.CreateTopic(body)
+	if err != nil {
+		return reply(err)
+	}
+
+	if created {
+		return content(CODE_CREATED, resp, format)
+	}
+	return content(CODE_CHANGED, resp, format)
+}
+
+func handleGetTopic(req message, format uint) message {
+	// Parse query
+	name := ""
+	hierarchical := false // false is default
+	selector := ""
+
+	queries, err := parseQueries(req, "name", "hierarchical", "selector")
+	if err != nil {
+		return reply(err)
+	}
+	for field, value := range queries {
+		switch field {
+		case "name":
+			name = value
+		case "hierarchical":
+			if value == "yes" {
+				hierarchical = true
+			} else if value != "no" {
+				return reply(errors.InvalidQuery{field})
+			}
+		case "selector":
+			selector = value
+		}
+	}
+
+	resp, err := topicExecutor.ReadTopic(name, hierarchical, selector, false, -1)
+	if err != nil {
+		return reply(err)
+	}
+
+	return content(CODE_CONTENT, resp, format)
+}
+
+func handleDeleteTopic(req message) message {
+	queries, err := parseQueries(req, "name", "endpoint")
+	if err != nil {
+		return reply(err)
+	}
+
+	err = topicExecutor.DeleteTopic(queries["name"], queries["endpoint"])
+	if err != nil {
+		return reply(err)
+	}
+
+	return message{code: CODE_DELETED}
+}
+
+func handlePostKeepAlive(req message, format uint) message {
+	body, err := readBody(req)
+	if err != nil {
+		return reply(err)
+	}
+
+	resp, err := keepaliveExecutor.HandlePing(body)
+	if err != nil {
+		if _, notFound := err.(errors.NotFound); notFound && resp != nil {
+			// Topics which are not registered
+			return content(CODE_NOT_FOUND, resp, format)
+		}
+		return reply(err)
+	}
+
+	if resp == nil {
+		return message{code: CODE_CHANGED}
+	}
+	return content(CODE_CHANGED, resp, format)
+}
+
+// parseQueries returns the values of the Uri-Query options of the given fields.
+func parseQueries(req message, fields ...string) (map[string]string, error) {
+	queries := make(map[string]string)
+	for _, query := range req.queries() {
+		field, value := query, ""
+		if i := strings.Index(query, "="); i >= 0 {
+			field, value = query[:i], query[i+1:]
+		}
+
+		known := false
+		for _, f := range fields {
+			known = known || f == field
+		}
+		if _, exists := queries[field]; exists || !known {
+			// No any array type value so far
+			logger.Logging(logger.DEBUG, "Invalid query: "+field)
+			return nil, errors.InvalidQuery{field}
+		}
+		queries[field] = value
+	}
+	return queries, nil
+}
+
+// readBody returns the payload of the request as a JSON body of the controllers.
+func readBody(req message) (string, error) {
+	if len(req.payload) == 0 {
+		return "", errors.InvalidParam{"body is empty"}
+	}
+
+	format := uint(FORMAT_JSON)
+	if value, exists := req.uintOption(OPTION_CONTENT_FORMAT); exists {
+		format = value
+	}
+
+	switch format {
+	case FORMAT_JSON:
+		return string(req.payload), nil
+	case FORMAT_CBOR:
+		var body interface{}
+		if err := cborDecMode.Unmarshal(req.payload, &body); err != nil {
+			logger.Logging(logger.DEBUG, "Unmarshal failed: "+err.Error())
+			return "", errors.InvalidParam{"invalid CBOR payload"}
+		}
+		data, err := json.Marshal(body)
+		if err != nil {
+			logger.Logging(logger.DEBUG, "Marshal failed: "+err.Error())
+			return "", errors.InvalidParam{"invalid CBOR payload"}
+		}
+		return string(data), nil
+	}
+
+	return "", unsupportedFormat{format}
+}
+
+// content returns the response with the payload of resp in the format.
+func content(code uint8, resp map[string]interface{}, format uint) message {
+	var payload []byte
+	if format == FORMAT_CBOR {
+		var err error
+		payload, err = cbor.Marshal(resp)
+		if err != nil {
+			logger.Logging(logger.ERROR, "Marshal failed: "+err.Error())
+			return reply(errors.InternalServerError{"Failed to encode CBOR"})
+		}
+	} else {
+		format = FORMAT_JSON
+		payload = common.MapToJsonByte(resp)
+	}
+
+	msg := message{code: code, payload: payload}
+	msg.addUintOption(OPTION_CONTENT_FORMAT, format)
+	return msg
+}
+
+// reply returns the response of the error with its message as a diagnostic payload.
+func reply(err error) message {
+	return diagnostic(convertToCode(err), err.Error())
+}
+
+func diagnostic(code uint8, text string) message {
+	return message{code: code, payload: []byte(text)}
+}
+
+// convertToCode converts an error object to the response code in the same way as
+// the status codes of the REST APIs.
+func convertToCode(err error) uint8 {
+	code := uint8(CODE_INTERNAL_SERVER_ERROR)
+
+	switch err.(type) {
+	case errors.InvalidParam,
+		errors.InvalidJSON,
+		errors.InvalidQuery:
+		code = CODE_BAD_REQUEST
+	case errors.InvalidMethod:
+		code = CODE_METHOD_NOT_ALLOWED
+	case errors.NotFoundURL,
+		errors.NotFound:
+		code = CODE_NOT_FOUND
+	case errors.Conflict:
+		code = CODE_CONFLICT
+	case unsupportedFormat:
+		code = CODE_UNSUPPORTED_CONTENT_FORMAT
+	case errors.Unavailable:
+		code = CODE_SERVICE_UNAVAILABLE
+	}
+
+	return code
+}
+
+func isRecognized(number uint16) bool {
+	switch number {
+	case OPTION_URI_HOST, OPTION_URI_PORT, OPTION_URI_PATH, OPTION_CONTENT_FORMAT,
+		OPTION_URI_QUERY, OPTION_ACCEPT, OPTION_NO_RESPONSE:
+		return true
+	}
+	return false
+}
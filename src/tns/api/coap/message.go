@@ -0,0 +1,287 @@
+/*******************************************************************************
+ * Copyright 2018 Samsung Electronics All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ *******************************************************************************/
+package coap
+
+import (
+	"encoding/binary"
+	"fmt"
+	"sort"
+	"strings"
+	"tns/commons/errors"
+)
+
+// Messages are encoded as defined in RFC 7252, section 3:
+//
+//   0                   1                   2                   3
+//   0 1 2 3 4 5 6 7 8 9 0 1 2 3 4 5 6 7 8 9 0 1 2 3 4 5 6 7 8 9 0 1
+//  +-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+//  |Ver| T |  TKL  |      Code     |          Message ID           |
+//  +-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+//  |   Token (if any, TKL bytes) ...
+//  +-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+//  |   Options (if any) ...
+//  +-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+//  |1 1 1 1 1 1 1 1|    Payload (if any) ...
+//  +-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+
+const VERSION = 1
+
+// Types of messages.
+const (
+	CONFIRMABLE     = 0
+	NON_CONFIRMABLE = 1
+	ACKNOWLEDGEMENT = 2
+	RESET           = 3
+)
+
+// Codes of messages, class * 32 + detail, written as "class.detail".
+const (
+	CODE_EMPTY  = 0
+	CODE_GET    = 1
+	CODE_POST   = 2
+	CODE_PUT    = 3
+	CODE_DELETE = 4
+
+	CODE_CREATED                    = 2*32 + 1
+	CODE_DELETED                    = 2*32 + 2
+	CODE_CHANGED                    = 2*32 + 4
+	CODE_CONTENT                    = 2*32 + 5
+	CODE_BAD_REQUEST                = 4*32 + 0
+	CODE_BAD_OPTION                 = 4*32 + 2
+	CODE_NOT_FOUND                  = 4*32 + 4
+	CODE_METHOD_NOT_ALLOWED         = 4*32 + 5
+	CODE_NOT_ACCEPTABLE             = 4*32 + 6
+	CODE_CONFLICT                   = 4*32 + 9 // RFC 8132
+	CODE_UNSUPPORTED_CONTENT_FORMAT = 4*32 + 15
+	CODE_INTERNAL_SERVER_ERROR      = 5*32 + 0
+	CODE_SERVICE_UNAVAILABLE        = 5*32 + 3
+)
+
+// Numbers of options. Options of odd numbers are critical, which should be
+// rejected if they are not recognized.
+const (
+	OPTION_URI_HOST       = 3
+	OPTION_URI_PORT       = 7
+	OPTION_URI_PATH       = 11
+	OPTION_CONTENT_FORMAT = 12
+	OPTION_URI_QUERY      = 15
+	OPTION_ACCEPT         = 17
+	OPTION_NO_RESPONSE    = 258 // RFC 7967
+)
+
+// Content formats of payloads.
+const (
+	FORMAT_TEXT = 0
+	FORMAT_JSON = 50
+	FORMAT_CBOR = 60
+)
+
+const (
+	MAX_TOKEN_LENGTH = 8
+	PAYLOAD_MARKER   = 0xff
+)
+
+type option struct {
+	number uint16
+	value  []byte
+}
+
+type message struct {
+	messageType uint8
+	code        uint8
+	id          uint16
+	token       []byte
+	options     []option // In order of number if parsed
+	payload     []byte
+}
+
+// parseMessage decodes a message. The header is returned together with an error
+// if only the rest is malformed, so that the message can be rejected.
+func parseMessage(data []byte) (message, error) {
+	if len(data) < 4 {
+		return message{}, errors.InvalidParam{"message is too short"}
+	}
+
+	msg := message{
+		messageType: data[0] >> 4 & 0x3,
+		code:        data[1],
+		id:          binary.BigEndian.Uint16(data[2:4]),
+	}
+	if data[0]>>6 != VERSION {
+		return msg, errors.InvalidParam{"unknown version"}
+	}
+
+	tokenLength := int(data[0] & 0xf)
+	if tokenLength > MAX_TOKEN_LENGTH || len(data) < 4+tokenLength {
+		return msg, errors.InvalidParam{"invalid token"}
+	}
+	msg.token = data[4 : 4+tokenLength]
+
+	data = data[4+tokenLength:]
+	number := 0
+	for len(data) != 0 {
+		if data[0] == PAYLOAD_MARKER {
+			if len(data) == 1 {
+				return msg, errors.InvalidParam{"empty payload"}
+			}
+			msg.payload = data[1:]
+			break
+		}
+
+		delta, length := int(data[0]>>4), int(data[0]&0xf)
+		data = data[1:]
+
+		var err error
+		if delta, data, err = parseExtended(delta, data); err != nil {
+			return msg, err
+		}
+		if length, data, err = parseExtended(length, data); err != nil {
+			return msg, err
+		}
+		if len(data) < length {
+			return msg, errors.InvalidParam{"invalid option"}
+		}
+
+		number += delta
+		if number > 0xffff {
+			return msg, errors.InvalidParam{"invalid option"}
+		}
+		msg.options = append(msg.options, option{number: uint16(number), value: data[:length]})
+		data = data[length:]
+	}
+
+	return msg, nil
+}
+
+// parseExtended returns the option delta or length with its extended bytes.
+func parseExtended(value int, data []byte) (int, []byte, error) {
+	switch value {
+	case 13:
+		if len(data) < 1 {
+			return 0, nil, errors.InvalidParam{"invalid option"}
+		}
+		return int(data[0]) + 13, data[1:], nil
+	case 14:
+		if len(data) < 2 {
+			return 0, nil, errors.InvalidParam{"invalid option"}
+		}
+		return int(binary.BigEndian.Uint16(data)) + 269, data[2:], nil
+	case 15:
+		return 0, nil, errors.InvalidParam{"invalid option"}
+	}
+	return value, data, nil
+}
+
+// marshal encodes the message with its options sorted by number.
+func (msg message) marshal() []byte {
+	data := []byte{VERSION<<6 | msg.messageType<<4 | uint8(len(msg.token)), msg.code, 0, 0}
+	binary.BigEndian.PutUint16(data[2:], msg.id)
+	data = append(data, msg.token...)
+
+	options := append([]option(nil), msg.options...)
+	sort.SliceStable(options, func(i, j int) bool { return options[i].number < options[j].number })
+
+	number := 0
+	for _, opt := range options {
+		delta, deltaExtended := extended(int(opt.number) - number)
+		length, lengthExtended := extended(len(opt.value))
+		data = append(data, byte(delta<<4|length))
+		data = append(data, deltaExtended...)
+		data = append(data, lengthExtended...)
+		data = append(data, opt.value...)
+		number = int(opt.number)
+	}
+
+	if len(msg.payload) != 0 {
+		data = append(data, PAYLOAD_MARKER)
+		data = append(data, msg.payload...)
+	}
+
+	return data
+}
+
+// extended returns the 4-bit option delta or length with its extended bytes.
+func extended(value int) (int, []byte) {
+	switch {
+	case value < 13:
+		return value, nil
+	case value < 269:
+		return 13, []byte{byte(value - 13)}
+	default:
+		return 14, []byte{byte((value - 269) >> 8), byte(value - 269)}
+	}
+}
+
+// option returns the value of the first option of the number.
+func (msg message) option(number uint16) ([]byte, bool) {
+	for _, opt := range msg.options {
+		if opt.number == number {
+			return opt.value, true
+		}
+	}
+	return nil, false
+}
+
+// uintOption returns the value of the first option of the number as an unsigned integer.
+func (msg message) uintOption(number uint16) (uint, bool) {
+	value, exists := msg.option(number)
+	if !exists {
+		return 0, false
+	}
+	n := uint(0)
+	for _, b := range value {
+		n = n<<8 | uint(b)
+	}
+	return n, true
+}
+
+// path returns the Uri-Path options joined with '/'.
+func (msg message) path() string {
+	var segments []string
+	for _, opt := range msg.options {
+		if opt.number == OPTION_URI_PATH {
+			segments = append(segments, string(opt.value))
+		}
+	}
+	return "/" + strings.Join(segments, "/")
+}
+
+// queries returns the Uri-Query options.
+func (msg message) queries() []string {
+	var queries []string
+	for _, opt := range msg.options {
+		if opt.number == OPTION_URI_QUERY {
+			queries = append(queries, string(opt.value))
+		}
+	}
+	return queries
+}
+
+// addUintOption adds the option of the number with the unsigned integer in
+// the fewest bytes.
+func (msg *message) addUintOption(number uint16, n uint) {
+	var value []byte
+	for ; n != 0; n >>= 8 {
+		value = append([]byte{byte(n)}, value...)
+	}
+	msg.options = append(msg.options, option{number: number, value: value})
+}
+
+// codeString returns the code in the form of "class.detail", e.g., "4.04".
+func codeString(code uint8) string {
+	return fmt.Sprintf("%d.%02d", code>>5, code&0x1f)
+}
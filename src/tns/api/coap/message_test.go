@@ -0,0 +1,138 @@
+/*******************************************************************************
+ * Copyright 2018 Samsung Electronics All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ *******************************************************************************/
+package coap
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestMarshalAndParseMessage(t *testing.T) {
+	longValue := []byte(strings.Repeat("a", 300))
+
+	msg := message{
+		messageType: CONFIRMABLE,
+		code:        CODE_POST,
+		id:          0x1234,
+		token:       []byte{1, 2, 3, 4},
+		options: []option{
+			{OPTION_NO_RESPONSE, []byte{2}}, // Delta of 2 extended bytes
+			{OPTION_URI_PATH, []byte("tns")},
+			{OPTION_URI_PATH, []byte("topic")},
+			{OPTION_CONTENT_FORMAT, []byte{FORMAT_CBOR}},
+			{OPTION_URI_QUERY, longValue},                       // Length of 2 extended bytes
+			{OPTION_URI_QUERY, []byte(strings.Repeat("b", 20))}, // Length of an extended byte
+		},
+		payload: []byte("{}"),
+	}
+
+	data := msg.marshal()
+	if data[0] != 0x44 || data[1] != CODE_POST || data[2] != 0x12 || data[3] != 0x34 {
+		t.Errorf("Unexpected header: %x", data[:4])
+	}
+
+	parsed, err := parseMessage(data)
+	if err != nil {
+		t.Fatalf("parseMessage failed: %s", err.Error())
+	}
+
+	if parsed.messageType != msg.messageType || parsed.code != msg.code || parsed.id != msg.id ||
+		!bytes.Equal(parsed.token, msg.token) || !bytes.Equal(parsed.payload, msg.payload) {
+		t.Errorf("Expected: %v, Actual: %v", msg, parsed)
+	}
+
+	expectedNumbers := []uint16{OPTION_URI_PATH, OPTION_URI_PATH, OPTION_CONTENT_FORMAT,
+		OPTION_URI_QUERY, OPTION_URI_QUERY, OPTION_NO_RESPONSE}
+	var numbers []uint16
+	for _, opt := range parsed.options {
+		numbers = append(numbers, opt.number)
+	}
+	if !reflect.DeepEqual(numbers, expectedNumbers) {
+		t.Errorf("Expected options: %v, Actual: %v", expectedNumbers, numbers)
+	}
+
+	if path := parsed.path(); path != "/tns/topic" {
+		t.Errorf("Expected path: /tns/topic, Actual: %s", path)
+	}
+	if queries := parsed.queries(); len(queries) != 2 || queries[0] != string(longValue) {
+		t.Errorf("Unexpected queries: %v", queries)
+	}
+	if value, exists := parsed.uintOption(OPTION_NO_RESPONSE); !exists || value != 2 {
+		t.Errorf("Expected No-Response: 2, Actual: %d", value)
+	}
+	if _, exists := parsed.uintOption(OPTION_ACCEPT); exists {
+		t.Error("Unexpected Accept option")
+	}
+}
+
+func TestAddUintOption(t *testing.T) {
+	testCases := []struct {
+		value    uint
+		expected []byte
+	}{
+		{0, nil},
+		{FORMAT_CBOR, []byte{60}},
+		{0x1234, []byte{0x12, 0x34}},
+	}
+
+	for _, tc := range testCases {
+		msg := message{}
+		msg.addUintOption(OPTION_CONTENT_FORMAT, tc.value)
+		if !bytes.Equal(msg.options[0].value, tc.expected) {
+			t.Errorf("Expected: %v, Actual: %v", tc.expected, msg.options[0].value)
+		}
+		if value, _ := msg.uintOption(OPTION_CONTENT_FORMAT); value != tc.value {
+			t.Errorf("Expected: %d, Actual: %d", tc.value, value)
+		}
+	}
+}
+
+func TestParseMessageWithInvalidData(t *testing.T) {
+	testCases := []struct {
+		name string
+		data []byte
+	}{
+		{"TooShort", []byte{0x40, 0x01, 0x00}},
+		{"InvalidVersion", []byte{0x80, 0x01, 0x00, 0x01}},
+		{"InvalidTokenLength", []byte{0x49, 0x01, 0x00, 0x01, 1, 2, 3, 4, 5, 6, 7, 8, 9}},
+		{"TruncatedToken", []byte{0x44, 0x01, 0x00, 0x01, 1, 2}},
+		{"TruncatedOption", []byte{0x40, 0x01, 0x00, 0x01, 0xb5, 't', 'n', 's'}},
+		{"TruncatedExtendedDelta", []byte{0x40, 0x01, 0x00, 0x01, 0xe0, 0x00}},
+		{"ReservedDelta", []byte{0x40, 0x01, 0x00, 0x01, 0xf1, 0x00}},
+		{"ReservedLength", []byte{0x40, 0x01, 0x00, 0x01, 0x1f}},
+		{"EmptyPayload", []byte{0x40, 0x01, 0x00, 0x01, 0xff}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := parseMessage(tc.data); err == nil {
+				t.Error("parseMessage did not return an error")
+			}
+		})
+	}
+}
+
+func TestCodeString(t *testing.T) {
+	if code := codeString(CODE_NOT_FOUND); code != "4.04" {
+		t.Errorf("Expected: 4.04, Actual: %s", code)
+	}
+	if code := codeString(CODE_UNSUPPORTED_CONTENT_FORMAT); code != "4.15" {
+		t.Errorf("Expected: 4.15, Actual: %s", code)
+	}
+}
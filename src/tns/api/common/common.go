@@ -76,7 +76,7 @@ func MapToJsonByte(src map[string]interface{}) []byte {
 //    404 (Not Found)
 //    409 (Conflict)
 //    500 (Internal Server Error)
-//    503 (Service Unavailable) (currently not used)
+//    503 (Service Unavailable)
 func convertToHttpStatusCode(err error) int {
 	code := http.StatusInternalServerError
 
@@ -93,6 +93,8 @@ func convertToHttpStatusCode(err error) int {
 		code = http.StatusConflict // 409
 	case errors.InternalServerError:
 		code = http.StatusInternalServerError // 500
+	case errors.Unavailable:
+		code = http.StatusServiceUnavailable // 503
 		// case errors.DBConnectionError,
 		//     errors.DBOperationError:
 		//     code = http.StatusServiceUnavailable // 503
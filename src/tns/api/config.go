@@ -20,18 +20,38 @@ package api
 import (
 	"github.com/BurntSushi/toml"
 	"os"
+	"tns/api/coap"
+	"tns/api/dns"
+	"tns/api/grpc"
+	"tns/api/mdns"
 	"tns/commons/logger"
+	federationController "tns/controller/federation"
+	relayController "tns/controller/relay"
+	topicDB "tns/db/topic"
 )
 
 type Config struct {
 	Server struct {
-		Ip                string
-		Port              uint
-		KeepAliveInterval uint
-	}
-	Database struct {
-		Name string
+		Ip                   string
+		Port                 uint
+		KeepAliveInterval    uint
+		MinKeepAliveInterval uint // Range of keep-alive interval that topics can request
+		MaxKeepAliveInterval uint
+		GracePeriod          uint // Seconds to keep stale topics after keep-alive interval
+		ValidateDatamodel    bool // Reject topics whose datamodel is not registered
 	}
+	Database   topicDB.Config
+	DNS        dns.Config
+	MDNS       mdns.Config
+	GRPC       grpc.Config
+	CoAP       coap.Config
+	Cluster    topicDB.ClusterConfig
+	Federation federationController.Config
+	Relay      relayController.Config
+
+	// Whether 'enabled' of [federation] is given, since the relay enables
+	// federation with its upstream server only by default
+	federationDefined bool
 }
 
 // Read and parse the configuration file
@@ -43,10 +63,12 @@ func (c *Config) Read(filePath string) error {
 		return err
 	}
 
-	if _, err := toml.DecodeFile(filePath, &c); err != nil {
+	meta, err := toml.DecodeFile(filePath, &c)
+	if err != nil {
 		logger.Logging(logger.ERROR, "DecodeFile failed: "+err.Error())
 		return err
 	}
+	c.federationDefined = meta.IsDefined("federation", "enabled")
 
 	return nil
 }
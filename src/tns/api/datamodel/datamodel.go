@@ -0,0 +1,153 @@
+/*******************************************************************************
+ * Copyright 2018 Samsung Electronics All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ *******************************************************************************/
+
+package datamodel
+
+import (
+	"net/http"
+	"strings"
+	"tns/api/common"
+	"tns/commons/errors"
+	"tns/commons/logger"
+	datamodelController "tns/controller/datamodel"
+)
+
+type Command interface {
+	Handle(w http.ResponseWriter, req *http.Request)
+}
+
+type RequestHandler struct{}
+
+var datamodelExecutor datamodelController.Command
+
+func init() {
+	datamodelExecutor = datamodelController.Executor{}
+}
+
+func (RequestHandler) Handle(w http.ResponseWriter, req *http.Request) {
+	// Check URL
+	url := strings.TrimPrefix(req.URL.Path, "/api/v1"+"/tns/datamodel")
+	if len(url) != 0 {
+		common.WriteError(w, errors.NotFoundURL{url})
+		return
+	}
+
+	switch req.Method {
+	case http.MethodPost:
+		handlePostReq(w, req)
+	case http.MethodGet:
+		handleGetReq(w, req)
+	case http.MethodDelete:
+		handleDeleteReq(w, req)
+	default:
+		logger.Logging(logger.DEBUG, "Invalid Method")
+		common.WriteError(w, errors.InvalidMethod{req.Method})
+		return
+	}
+}
+
+func handlePostReq(w http.ResponseWriter, req *http.Request) {
+	logger.Logging(logger.DEBUG, "IN")
+	defer logger.Logging(logger.DEBUG, "OUT")
+
+	body, err := common.GetBodyFromReq(req)
+	if err != nil {
+		logger.Logging(logger.DEBUG, "GetBodyFromReq failed")
+		common.WriteError(w, err)
+		return
+	}
+
+	err = datamodelExecutor.CreateDatamodel(body)
+	if err != nil {
+		common.WriteError(w, err)
+		return
+	}
+
+	common.WriteResponse(w, http.StatusCreated, nil)
+}
+
+func handleGetReq(w http.ResponseWriter, req *http.Request) {
+	logger.Logging(logger.DEBUG, "IN")
+	defer logger.Logging(logger.DEBUG, "OUT")
+
+	// Parse query
+	id := ""
+	name := ""
+
+	for field, values := range req.URL.Query() {
+		if len(values) != 1 { // No any array type value so far
+			common.WriteError(w, errors.InvalidQuery{field})
+			return
+		}
+
+		switch field {
+		case "id":
+			id = values[0]
+		case "name":
+			name = values[0]
+		default:
+			logger.Logging(logger.DEBUG, "Invalid query: "+field)
+			common.WriteError(w, errors.InvalidQuery{field})
+			return
+		}
+	}
+
+	resp, err := datamodelExecutor.ReadDatamodel(id, name)
+	if err != nil {
+		common.WriteError(w, err)
+		return
+	}
+
+	common.WriteResponse(w, http.StatusOK, common.MapToJsonByte(resp))
+}
+
+func handleDeleteReq(w http.ResponseWriter, req *http.Request) {
+	logger.Logging(logger.DEBUG, "IN")
+	defer logger.Logging(logger.DEBUG, "OUT")
+
+	// Parse query
+	id := ""
+
+	for field, values := range req.URL.Query() {
+		if len(values) != 1 { // No any array type value so far
+			common.WriteError(w, errors.InvalidQuery{field})
+			return
+		}
+
+		switch field {
+		case "id":
+			id = values[0]
+		default:
+			logger.Logging(logger.DEBUG, "Invalid query: "+field)
+			common.WriteError(w, errors.InvalidQuery{field})
+			return
+		}
+	}
+
+	if id == "" {
+		common.WriteError(w, errors.InvalidQuery{"'id' is required"})
+		return
+	}
+
+	err := datamodelExecutor.DeleteDatamodel(id)
+	if err != nil {
+		common.WriteError(w, err)
+		return
+	}
+
+	common.WriteResponse(w, http.StatusOK, nil)
+}
@@ -0,0 +1,193 @@
+/*******************************************************************************
+ * Copyright 2018 Samsung Electronics All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ *******************************************************************************/
+
+package datamodel
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/golang/mock/gomock"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"tns/commons/errors"
+	datamodelControllerMock "tns/controller/datamodel/mocks"
+)
+
+const datamodelUrl = "/api/v1/tns/datamodel"
+
+var testBodyString = `{"datamodel":{"format":"jsonschema","name":"GTC_Robot","schema":"{}","version":"0.0.1"}}`
+
+var Handler Command
+
+func init() {
+	Handler = RequestHandler{}
+}
+
+func TestCallHandleWithInvalidRequest(t *testing.T) {
+	// Mock is not necessary for this test
+
+	testCases := []struct {
+		name         string
+		method       string
+		url          string
+		expectedCode int
+	}{
+		{"InvalidUrl", "POST", datamodelUrl + "/invalid", http.StatusNotFound},
+		{"InvalidMethod_Put", "PUT", datamodelUrl, http.StatusBadRequest},
+		{"EmptyParameter_Post", "POST", datamodelUrl, http.StatusBadRequest},
+		{"InvalidQuery_Get_MultiValue", "GET", datamodelUrl + "?id=a&id=b", http.StatusBadRequest},
+		{"InvalidQuery_Get_InvalidQuery", "GET", datamodelUrl + "?key=value", http.StatusBadRequest},
+		{"InvalidQuery_Delete_NoId", "DELETE", datamodelUrl, http.StatusBadRequest},
+		{"InvalidQuery_Delete_Name", "DELETE", datamodelUrl + "?name=GTC_Robot", http.StatusBadRequest},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(tc.method, tc.url, nil)
+			w := httptest.NewRecorder()
+
+			Handler.Handle(w, req)
+
+			if w.Code != tc.expectedCode {
+				t.Errorf("Expected Code: %s, Actual: %s", http.StatusText(tc.expectedCode), http.StatusText(w.Code))
+			}
+		})
+	}
+}
+
+func TestCallHandlePost(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	datamodelCtrlrMockObj := datamodelControllerMock.NewMockCommand(ctrl)
+
+	// pass mockObj to a real object.
+	datamodelExecutor = datamodelCtrlrMockObj
+
+	testCases := []struct {
+		name         string
+		mockRetError error
+		expectedCode int
+	}{
+		{"Success", nil, http.StatusCreated},
+		{"Conflict", errors.Conflict{}, http.StatusConflict},
+		{"InvalidParam", errors.InvalidParam{}, http.StatusBadRequest},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			gomock.InOrder(
+				datamodelCtrlrMockObj.EXPECT().CreateDatamodel(testBodyString).Return(tc.mockRetError),
+			)
+
+			req := httptest.NewRequest("POST", datamodelUrl, strings.NewReader(testBodyString))
+			w := httptest.NewRecorder()
+
+			Handler.Handle(w, req)
+
+			if w.Code != tc.expectedCode {
+				t.Errorf("Expected Code: %s, Actual: %s", http.StatusText(tc.expectedCode), http.StatusText(w.Code))
+			}
+		})
+	}
+}
+
+func TestCallHandleGet(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	datamodelCtrlrMockObj := datamodelControllerMock.NewMockCommand(ctrl)
+
+	// pass mockObj to a real object.
+	datamodelExecutor = datamodelCtrlrMockObj
+
+	expectedResp := map[string]interface{}{"datamodels": []map[string]interface{}{{"id": "GTC_Robot_0.0.1", "name": "GTC_Robot", "version": "0.0.1"}}}
+	expectedRespByte, _ := json.Marshal(expectedResp)
+
+	testCases := []struct {
+		name         string
+		query        string
+		id           string
+		datamodel    string
+		mockRetResp  map[string]interface{}
+		mockRetError error
+		expectedCode int
+	}{
+		{"Success_Id", "?id=GTC_Robot_0.0.1", "GTC_Robot_0.0.1", "", expectedResp, nil, http.StatusOK},
+		{"Success_Name", "?name=GTC_Robot", "", "GTC_Robot", expectedResp, nil, http.StatusOK},
+		{"Success_All", "", "", "", expectedResp, nil, http.StatusOK},
+		{"NotFound", "?id=GTC_Robot_0.0.2", "GTC_Robot_0.0.2", "", nil, errors.NotFound{}, http.StatusNotFound},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			gomock.InOrder(
+				datamodelCtrlrMockObj.EXPECT().ReadDatamodel(tc.id, tc.datamodel).Return(tc.mockRetResp, tc.mockRetError),
+			)
+
+			req := httptest.NewRequest("GET", datamodelUrl+tc.query, nil)
+			w := httptest.NewRecorder()
+
+			Handler.Handle(w, req)
+
+			if w.Code != tc.expectedCode {
+				t.Errorf("Expected Code: %s, Actual: %s", http.StatusText(tc.expectedCode), http.StatusText(w.Code))
+			}
+			if tc.mockRetError == nil && 0 != bytes.Compare(w.Body.Bytes(), expectedRespByte) {
+				t.Errorf("Expected body: %s, Actual: %s", expectedRespByte, w.Body.Bytes())
+			}
+		})
+	}
+}
+
+func TestCallHandleDelete(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	datamodelCtrlrMockObj := datamodelControllerMock.NewMockCommand(ctrl)
+
+	// pass mockObj to a real object.
+	datamodelExecutor = datamodelCtrlrMockObj
+
+	testCases := []struct {
+		name         string
+		mockRetError error
+		expectedCode int
+	}{
+		{"Success", nil, http.StatusOK},
+		{"NotFound", errors.NotFound{}, http.StatusNotFound},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			gomock.InOrder(
+				datamodelCtrlrMockObj.EXPECT().DeleteDatamodel("GTC_Robot_0.0.1").Return(tc.mockRetError),
+			)
+
+			req := httptest.NewRequest("DELETE", datamodelUrl+"?id=GTC_Robot_0.0.1", nil)
+			w := httptest.NewRecorder()
+
+			Handler.Handle(w, req)
+
+			if w.Code != tc.expectedCode {
+				t.Errorf("Expected Code: %s, Actual: %s", http.StatusText(tc.expectedCode), http.StatusText(w.Code))
+			}
+		})
+	}
+}
@@ -0,0 +1,403 @@
+/*******************************************************************************
+ * Copyright 2018 Samsung Electronics All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ *******************************************************************************/
+package dns
+
+import (
+	"encoding/binary"
+	"golang.org/x/net/dns/dnsmessage"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+	"tns/commons/errors"
+	"tns/commons/logger"
+	topicDB "tns/db/topic"
+)
+
+// Topics are served as DNS records under the configured zone. The name of a topic
+// is mapped to the domain name of its levels in reverse order, each prefixed with
+// '_', e.g., "/a/b/c" to "_c._b._a.tns.local.", which has
+//  - SRV records of the endpoints of the publishers, and
+//  - a TXT record of "datamodel=<datamodel>" and "secured=<true|false>".
+// The target of a SRV record is the host of the endpoint. If the host is an IP
+// address, the target is a name for it in the zone, e.g., "10-0-0-1.tns.local.",
+// which has an A (or AAAA) record of the address.
+
+type Config struct {
+	Enabled bool
+	Ip      string
+	Port    uint
+	Zone    string // Domain of topics (default: tns.local)
+	Ttl     uint   // Seconds that resolvers may cache the records (default: 60)
+}
+
+type Command interface {
+	Serve(config Config) error
+}
+
+type RequestHandler struct{}
+
+const (
+	DEFAULT_ZONE = "tns.local"
+	DEFAULT_TTL  = 60
+
+	UDP_MESSAGE_SIZE = 512 // Larger responses are truncated, then resolvers retry with TCP
+	TCP_IDLE_TIMEOUT = 10 * time.Second
+)
+
+var topicDbExecutor topicDB.Command
+
+func init() {
+	topicDbExecutor = topicDB.Executor{}
+}
+
+// zone is the domain which queries are answered for.
+type zone struct {
+	name string // Lower case, with the trailing dot
+	ttl  uint32
+}
+
+// Serve starts to answer DNS queries over UDP and TCP in background.
+func (RequestHandler) Serve(config Config) error {
+	logger.Logging(logger.DEBUG, "IN")
+	defer logger.Logging(logger.DEBUG, "OUT")
+
+	z, err := newZone(config)
+	if err != nil {
+		return err
+	}
+
+	address := net.JoinHostPort(config.Ip, strconv.FormatUint(uint64(config.Port), 10))
+
+	conn, err := net.ListenPacket("udp", address)
+	if err != nil {
+		logger.Logging(logger.ERROR, "ListenPacket failed: "+err.Error())
+		return err
+	}
+
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		logger.Logging(logger.ERROR, "Listen failed: "+err.Error())
+		conn.Close()
+		return err
+	}
+
+	logger.Logging(logger.DEBUG, "Serve DNS for "+z.name+" on "+address)
+
+	go serveUDP(conn, z)
+	go serveTCP(listener, z)
+
+	return nil
+}
+
+func newZone(config Config) (zone, error) {
+	name := strings.ToLower(strings.Trim(config.Zone, "."))
+	if name == "" {
+		name = DEFAULT_ZONE
+	}
+	for _, label := range strings.Split(name, ".") {
+		if len(label) == 0 || len(label) > 63 {
+			return zone{}, errors.InvalidParam{"invalid zone: " + config.Zone}
+		}
+	}
+	if _, err := dnsmessage.NewName(name + "."); err != nil {
+		return zone{}, errors.InvalidParam{"invalid zone: " + config.Zone}
+	}
+
+	ttl := config.Ttl
+	if ttl == 0 {
+		ttl = DEFAULT_TTL
+	}
+
+	return zone{name: name + ".", ttl: uint32(ttl)}, nil
+}
+
+func serveUDP(conn net.PacketConn, z zone) {
+	buf := make([]byte, 65535)
+	for {
+		n, addr, err := conn.ReadFrom(buf)
+		if err != nil {
+			logger.Logging(logger.ERROR, "ReadFrom failed: "+err.Error())
+			return
+		}
+
+		resp := z.answer(buf[:n], UDP_MESSAGE_SIZE)
+		if resp != nil {
+			conn.WriteTo(resp, addr)
+		}
+	}
+}
+
+func serveTCP(listener net.Listener, z zone) {
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			logger.Logging(logger.ERROR, "Accept failed: "+err.Error())
+			return
+		}
+		go serveTCPConn(conn, z)
+	}
+}
+
+// serveTCPConn answers the queries on the connection, each prefixed with its length.
+func serveTCPConn(conn net.Conn, z zone) {
+	defer conn.Close()
+
+	for {
+		conn.SetDeadline(time.Now().Add(TCP_IDLE_TIMEOUT))
+
+		var length uint16
+		if err := binary.Read(conn, binary.BigEndian, &length); err != nil {
+			return
+		}
+		query := make([]byte, length)
+		if _, err := io.ReadFull(conn, query); err != nil {
+			return
+		}
+
+		resp := z.answer(query, 65535)
+		if resp == nil {
+			return
+		}
+		if err := binary.Write(conn, binary.BigEndian, uint16(len(resp))); err != nil {
+			return
+		}
+		if _, err := conn.Write(resp); err != nil {
+			return
+		}
+	}
+}
+
+// answer returns the response to the query, or nil if it is not a valid query.
+// The response is truncated if it exceeds maxSize.
+func (z zone) answer(query []byte, maxSize int) []byte {
+	var msg dnsmessage.Message
+	if err := msg.Unpack(query); err != nil {
+		if len(query) < 12 { // Too short for the header
+			return nil
+		}
+		msg = dnsmessage.Message{Header: dnsmessage.Header{ID: binary.BigEndian.Uint16(query)}}
+		return pack(reply(msg, dnsmessage.RCodeFormatError), maxSize)
+	}
+
+	switch {
+	case msg.Header.Response:
+		return nil
+	case msg.Header.OpCode != 0:
+		return pack(reply(msg, dnsmessage.RCodeNotImplemented), maxSize)
+	case len(msg.Questions) != 1:
+		return pack(reply(msg, dnsmessage.RCodeFormatError), maxSize)
+	}
+
+	resp := reply(msg, dnsmessage.RCodeSuccess)
+	resp.Header.RCode = z.resolve(msg.Questions[0], &resp)
+
+	return pack(resp, maxSize)
+}
+
+// reply returns the response to msg with the question and no records.
+func reply(msg dnsmessage.Message, rcode dnsmessage.RCode) dnsmessage.Message {
+	return dnsmessage.Message{
+		Header: dnsmessage.Header{
+			ID:               msg.Header.ID,
+			Response:         true,
+			OpCode:           msg.Header.OpCode,
+			Authoritative:    rcode != dnsmessage.RCodeRefused,
+			RecursionDesired: msg.Header.RecursionDesired,
+			RCode:            rcode,
+		},
+		Questions: msg.Questions,
+	}
+}
+
+// pack returns the wire format of resp, without the records if it exceeds maxSize.
+func pack(resp dnsmessage.Message, maxSize int) []byte {
+	buf, err := resp.Pack()
+	if err == nil && len(buf) <= maxSize {
+		return buf
+	}
+	if err != nil {
+		logger.Logging(logger.ERROR, "Pack failed: "+err.Error())
+		resp = reply(resp, dnsmessage.RCodeServerFailure)
+	} else {
+		resp = reply(resp, resp.Header.RCode)
+		resp.Header.Truncated = true
+	}
+	buf, _ = resp.Pack()
+	return buf
+}
+
+// resolve adds the records of the question to resp, and returns the response code.
+func (z zone) resolve(question dnsmessage.Question, resp *dnsmessage.Message) dnsmessage.RCode {
+	name := question.Name.String()
+	logger.Logging(logger.DEBUG, "Query "+question.Type.String()+" "+name)
+
+	if question.Class != dnsmessage.ClassINET && question.Class != dnsmessage.ClassANY {
+		return dnsmessage.RCodeRefused
+	}
+
+	lower := strings.ToLower(name)
+	if lower == z.name {
+		return dnsmessage.RCodeSuccess // Nothing but the topics in the zone
+	}
+	if !strings.HasSuffix(lower, "."+z.name) {
+		return dnsmessage.RCodeRefused
+	}
+	labels := strings.Split(name[:len(name)-len(z.name)-1], ".")
+
+	if ip := parseAddressLabel(labels); ip != nil {
+		if record, ok := z.addressRecord(question.Name, ip); ok &&
+			(question.Type == record.Header.Type || question.Type == dnsmessage.TypeALL) {
+			resp.Answers = append(resp.Answers, record)
+		}
+		return dnsmessage.RCodeSuccess
+	}
+
+	topicName, ok := parseTopicLabels(labels)
+	if !ok {
+		return dnsmessage.RCodeNameError
+	}
+
+	topics, err := topicDbExecutor.ReadTopic(topicName, false, "")
+	switch err.(type) {
+	case nil:
+	case errors.NotFound:
+		return dnsmessage.RCodeNameError
+	default:
+		logger.Logging(logger.ERROR, "ReadTopic failed: "+err.Error())
+		return dnsmessage.RCodeServerFailure
+	}
+	if len(topics) == 0 {
+		// No topic matches the name, which is not an error of ReadTopic
+		return dnsmessage.RCodeNameError
+	}
+
+	for _, topic := range topics {
+		if question.Type == dnsmessage.TypeSRV || question.Type == dnsmessage.TypeALL {
+			z.addServiceRecords(question.Name, topic, resp)
+		}
+		if question.Type == dnsmessage.TypeTXT || question.Type == dnsmessage.TypeALL {
+			resp.Answers = append(resp.Answers, z.textRecord(question.Name, topic))
+		}
+	}
+
+	return dnsmessage.RCodeSuccess
+}
+
+// parseTopicLabels returns the name of the topic from the labels of its domain name.
+func parseTopicLabels(labels []string) (string, bool) {
+	levels := make([]string, len(labels))
+	for i, label := range labels {
+		level := strings.TrimPrefix(label, "_")
+		if level == label || level == "" || strings.ContainsAny(level, "+#*") {
+			return "", false
+		}
+		levels[len(labels)-1-i] = level
+	}
+	return "/" + strings.Join(levels, "/"), true
+}
+
+// parseAddressLabel returns the IP address of the name made by addressLabel.
+func parseAddressLabel(labels []string) net.IP {
+	if len(labels) != 1 {
+		return nil
+	}
+	if ip := net.ParseIP(strings.Replace(labels[0], "-", ".", -1)); ip != nil && ip.To4() != nil {
+		return ip
+	}
+	return net.ParseIP(strings.Replace(labels[0], "-", ":", -1))
+}
+
+// addressLabel returns the label of the name for the IP address in the zone.
+func addressLabel(ip net.IP) string {
+	if ip4 := ip.To4(); ip4 != nil {
+		return strings.Replace(ip4.String(), ".", "-", -1)
+	}
+	return strings.Replace(ip.String(), ":", "-", -1)
+}
+
+func (z zone) header(name dnsmessage.Name, recordType dnsmessage.Type) dnsmessage.ResourceHeader {
+	return dnsmessage.ResourceHeader{Name: name, Type: recordType, Class: dnsmessage.ClassINET, TTL: z.ttl}
+}
+
+func (z zone) addressRecord(name dnsmessage.Name, ip net.IP) (dnsmessage.Resource, bool) {
+	if ip4 := ip.To4(); ip4 != nil {
+		record := dnsmessage.AResource{}
+		copy(record.A[:], ip4)
+		return dnsmessage.Resource{Header: z.header(name, dnsmessage.TypeA), Body: &record}, true
+	}
+	if ip16 := ip.To16(); ip16 != nil {
+		record := dnsmessage.AAAAResource{}
+		copy(record.AAAA[:], ip16)
+		return dnsmessage.Resource{Header: z.header(name, dnsmessage.TypeAAAA), Body: &record}, true
+	}
+	return dnsmessage.Resource{}, false
+}
+
+// addServiceRecords adds SRV records of the endpoints of the topic to the answers,
+// and the addresses of their targets to the additionals.
+func (z zone) addServiceRecords(name dnsmessage.Name, topic map[string]interface{}, resp *dnsmessage.Message) {
+	endpoints, _ := topic["endpoints"].([]string)
+	for _, endpoint := range endpoints {
+		host, portString, err := net.SplitHostPort(endpoint)
+		if err != nil {
+			logger.Logging(logger.ERROR, "Invalid endpoint: "+endpoint)
+			continue
+		}
+		port, err := strconv.ParseUint(portString, 10, 16)
+		if err != nil {
+			logger.Logging(logger.ERROR, "Invalid endpoint: "+endpoint)
+			continue
+		}
+
+		target := strings.TrimSuffix(host, ".") + "."
+		ip := net.ParseIP(host)
+		if ip != nil {
+			target = addressLabel(ip) + "." + z.name
+		}
+		targetName, err := dnsmessage.NewName(target)
+		if err != nil {
+			logger.Logging(logger.ERROR, "Invalid endpoint: "+endpoint)
+			continue
+		}
+
+		resp.Answers = append(resp.Answers, dnsmessage.Resource{
+			Header: z.header(name, dnsmessage.TypeSRV),
+			Body:   &dnsmessage.SRVResource{Port: uint16(port), Target: targetName},
+		})
+		if ip != nil {
+			if record, ok := z.addressRecord(targetName, ip); ok {
+				resp.Additionals = append(resp.Additionals, record)
+			}
+		}
+	}
+}
+
+// textRecord returns the TXT record of the properties of the topic.
+func (z zone) textRecord(name dnsmessage.Name, topic map[string]interface{}) dnsmessage.Resource {
+	datamodel, _ := topic["datamodel"].(string)
+	secured, _ := topic["secured"].(bool)
+
+	return dnsmessage.Resource{
+		Header: z.header(name, dnsmessage.TypeTXT),
+		Body: &dnsmessage.TXTResource{TXT: []string{
+			"datamodel=" + datamodel,
+			"secured=" + strconv.FormatBool(secured),
+		}},
+	}
+}
@@ -0,0 +1,318 @@
+/*******************************************************************************
+ * Copyright 2018 Samsung Electronics All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ *******************************************************************************/
+package dns
+
+import (
+	"encoding/binary"
+	"github.com/golang/mock/gomock"
+	"golang.org/x/net/dns/dnsmessage"
+	"io"
+	"net"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"tns/commons/errors"
+	topicDbMock "tns/db/topic/mocks"
+)
+
+var testZone = zone{name: "tns.local.", ttl: DEFAULT_TTL}
+
+var testTopic = map[string]interface{}{
+	"name":      "/a/b/c",
+	"endpoints": []string{"10.0.0.1:1234", "robot1:5678", "[fe80::1]:9012"},
+	"datamodel": "test_0.0.1",
+	"secured":   true,
+}
+
+func TestCallServeWithInvalidZone(t *testing.T) {
+	for _, zone := range []string{"tns..local", "tns." + strings.Repeat("a", 64)} {
+		err := RequestHandler{}.Serve(Config{Ip: "127.0.0.1", Zone: zone})
+		if _, ok := err.(errors.InvalidParam); !ok {
+			t.Errorf("Expected Error: %s, Actual: %v", errors.InvalidParam{}, err)
+		}
+	}
+}
+
+func TestNewZone(t *testing.T) {
+	testCases := []struct {
+		name         string
+		config       Config
+		expectedZone zone
+	}{
+		{"Default", Config{}, zone{name: "tns.local.", ttl: DEFAULT_TTL}},
+		{"Configured", Config{Zone: "TNS.Example.", Ttl: 10}, zone{name: "tns.example.", ttl: 10}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			z, err := newZone(tc.config)
+			if err != nil {
+				t.Fatalf("newZone returned an error: %s", err.Error())
+			}
+			if z != tc.expectedZone {
+				t.Errorf("Expected Zone: %v, Actual: %v", tc.expectedZone, z)
+			}
+		})
+	}
+}
+
+func TestAnswer(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	topicDbMockObj := topicDbMock.NewMockCommand(ctrl)
+
+	// pass mockObj to a real object.
+	topicDbExecutor = topicDbMockObj
+
+	srvRecords := []string{
+		"SRV 1234 10-0-0-1.tns.local.",
+		"SRV 5678 robot1.",
+		"SRV 9012 fe80--1.tns.local.",
+	}
+	txtRecord := "TXT [datamodel=test_0.0.1 secured=true]"
+	additionals := []string{"A 10.0.0.1", "AAAA fe80::1"}
+
+	testCases := []struct {
+		name                string
+		domain              string
+		queryType           dnsmessage.Type
+		topicName           string
+		mockRetError        error
+		expectedRCode       dnsmessage.RCode
+		expectedAnswers     []string
+		expectedAdditionals []string
+	}{
+		{"SRV", "_c._b._a.tns.local.", dnsmessage.TypeSRV, "/a/b/c", nil, dnsmessage.RCodeSuccess, srvRecords, additionals},
+		{"TXT", "_c._b._a.tns.local.", dnsmessage.TypeTXT, "/a/b/c", nil, dnsmessage.RCodeSuccess, []string{txtRecord}, nil},
+		{"ANY", "_c._b._a.tns.local.", dnsmessage.TypeALL, "/a/b/c", nil, dnsmessage.RCodeSuccess, append(srvRecords, txtRecord), additionals},
+		{"ZoneCaseInsensitive", "_c._b._a.TNS.Local.", dnsmessage.TypeTXT, "/a/b/c", nil, dnsmessage.RCodeSuccess, []string{txtRecord}, nil},
+		{"NoData", "_c._b._a.tns.local.", dnsmessage.TypeA, "/a/b/c", nil, dnsmessage.RCodeSuccess, nil, nil},
+		{"NotFound", "_d._a.tns.local.", dnsmessage.TypeSRV, "/a/d", nil, dnsmessage.RCodeNameError, nil, nil},
+		{"DbFailed", "_d._a.tns.local.", dnsmessage.TypeSRV, "/a/d", errors.InternalServerError{}, dnsmessage.RCodeServerFailure, nil, nil},
+		{"Address_A", "10-0-0-1.tns.local.", dnsmessage.TypeA, "", nil, dnsmessage.RCodeSuccess, []string{"A 10.0.0.1"}, nil},
+		{"Address_AAAA", "fe80--1.tns.local.", dnsmessage.TypeAAAA, "", nil, dnsmessage.RCodeSuccess, []string{"AAAA fe80::1"}, nil},
+		{"Address_NoData", "10-0-0-1.tns.local.", dnsmessage.TypeAAAA, "", nil, dnsmessage.RCodeSuccess, nil, nil},
+		{"Zone", "tns.local.", dnsmessage.TypeSRV, "", nil, dnsmessage.RCodeSuccess, nil, nil},
+		{"NoUnderscore", "c._b._a.tns.local.", dnsmessage.TypeSRV, "", nil, dnsmessage.RCodeNameError, nil, nil},
+		{"Wildcard", "_#._a.tns.local.", dnsmessage.TypeSRV, "", nil, dnsmessage.RCodeNameError, nil, nil},
+		{"OutOfZone", "_a.example.com.", dnsmessage.TypeSRV, "", nil, dnsmessage.RCodeRefused, nil, nil},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if tc.topicName != "" {
+				topics := []map[string]interface{}{testTopic}
+				if tc.expectedRCode != dnsmessage.RCodeSuccess {
+					// ReadTopic returns an empty slice when no topic matches
+					topics = []map[string]interface{}{}
+				}
+				topicDbMockObj.EXPECT().ReadTopic(tc.topicName, false, "").Return(topics, tc.mockRetError)
+			}
+
+			resp := parseResponse(t, testZone.answer(buildQuery(t, 1, tc.domain, tc.queryType), 65535))
+			if resp.Header.ID != 1 || !resp.Header.Response || resp.Header.RCode != tc.expectedRCode {
+				t.Errorf("Unexpected Header: %v", resp.Header)
+			}
+			if answers := describeRecords(resp.Answers); !reflect.DeepEqual(answers, tc.expectedAnswers) {
+				t.Errorf("Expected Answers: %v, Actual: %v", tc.expectedAnswers, answers)
+			}
+			if additionals := describeRecords(resp.Additionals); !reflect.DeepEqual(additionals, tc.expectedAdditionals) {
+				t.Errorf("Expected Additionals: %v, Actual: %v", tc.expectedAdditionals, additionals)
+			}
+		})
+	}
+}
+
+func TestAnswerWithInvalidQuery(t *testing.T) {
+	// Mock is not necessary for this test
+
+	query := buildQuery(t, 1, "_a.tns.local.", dnsmessage.TypeSRV)
+
+	response := append([]byte{}, query...)
+	response[2] |= 0x80 // QR
+
+	notify := append([]byte{}, query...)
+	notify[2] |= 4 << 3 // Opcode NOTIFY
+
+	noQuestion := append([]byte{}, query[:12]...)
+	noQuestion[5] = 0 // QDCOUNT
+
+	testCases := []struct {
+		name          string
+		query         []byte
+		expectedRCode dnsmessage.RCode
+		expectedNil   bool
+	}{
+		{"TooShort", query[:11], 0, true},
+		{"Response", response, 0, true},
+		{"Malformed", query[:20], dnsmessage.RCodeFormatError, false},
+		{"NoQuestion", noQuestion, dnsmessage.RCodeFormatError, false},
+		{"NotImplemented", notify, dnsmessage.RCodeNotImplemented, false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			resp := testZone.answer(tc.query, 65535)
+			if tc.expectedNil {
+				if resp != nil {
+					t.Errorf("Expected no response, Actual: %v", resp)
+				}
+				return
+			}
+			if header := parseResponse(t, resp).Header; header.ID != 1 || header.RCode != tc.expectedRCode {
+				t.Errorf("Expected RCode: %s, Actual: %v", tc.expectedRCode, header)
+			}
+		})
+	}
+}
+
+func TestAnswerTruncated(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	topicDbMockObj := topicDbMock.NewMockCommand(ctrl)
+
+	// pass mockObj to a real object.
+	topicDbExecutor = topicDbMockObj
+
+	endpoints := make([]string, 50)
+	for i := range endpoints {
+		endpoints[i] = net.JoinHostPort(net.IPv4(10, 0, 0, byte(i)).String(), "1234")
+	}
+	topics := []map[string]interface{}{{"name": "/a", "endpoints": endpoints}}
+	topicDbMockObj.EXPECT().ReadTopic("/a", false, "").Return(topics, nil).Times(2)
+
+	query := buildQuery(t, 1, "_a.tns.local.", dnsmessage.TypeSRV)
+
+	resp := parseResponse(t, testZone.answer(query, UDP_MESSAGE_SIZE))
+	if !resp.Header.Truncated || len(resp.Answers) != 0 {
+		t.Errorf("Expected truncated response, Actual: %v %d answers", resp.Header, len(resp.Answers))
+	}
+
+	resp = parseResponse(t, testZone.answer(query, 65535))
+	if resp.Header.Truncated || len(resp.Answers) != len(endpoints) {
+		t.Errorf("Expected %d answers, Actual: %v %d answers", len(endpoints), resp.Header, len(resp.Answers))
+	}
+}
+
+func TestServeUDPAndTCP(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	topicDbMockObj := topicDbMock.NewMockCommand(ctrl)
+
+	// pass mockObj to a real object.
+	topicDbExecutor = topicDbMockObj
+
+	topicDbMockObj.EXPECT().ReadTopic("/a/b/c", false, "").Return([]map[string]interface{}{testTopic}, nil).Times(2)
+
+	packetConn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("ListenPacket failed: %s", err.Error())
+	}
+	defer packetConn.Close()
+	go serveUDP(packetConn, testZone)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen failed: %s", err.Error())
+	}
+	defer listener.Close()
+	go serveTCP(listener, testZone)
+
+	query := buildQuery(t, 1, "_c._b._a.tns.local.", dnsmessage.TypeTXT)
+
+	// UDP
+	conn, err := net.Dial("udp", packetConn.LocalAddr().String())
+	if err != nil {
+		t.Fatalf("Dial failed: %s", err.Error())
+	}
+	defer conn.Close()
+	conn.Write(query)
+	buf := make([]byte, UDP_MESSAGE_SIZE)
+	n, err := conn.Read(buf)
+	if err != nil {
+		t.Fatalf("Read failed: %s", err.Error())
+	}
+	if resp := parseResponse(t, buf[:n]); len(resp.Answers) != 1 {
+		t.Errorf("Expected an answer over UDP, Actual: %v", resp.Answers)
+	}
+
+	// TCP, prefixed with the length
+	conn, err = net.Dial("tcp", listener.Addr().String())
+	if err != nil {
+		t.Fatalf("Dial failed: %s", err.Error())
+	}
+	defer conn.Close()
+	binary.Write(conn, binary.BigEndian, uint16(len(query)))
+	conn.Write(query)
+	var length uint16
+	if err = binary.Read(conn, binary.BigEndian, &length); err != nil {
+		t.Fatalf("Read failed: %s", err.Error())
+	}
+	buf = make([]byte, length)
+	if _, err = io.ReadFull(conn, buf); err != nil {
+		t.Fatalf("Read failed: %s", err.Error())
+	}
+	if resp := parseResponse(t, buf); len(resp.Answers) != 1 {
+		t.Errorf("Expected an answer over TCP, Actual: %v", resp.Answers)
+	}
+}
+
+func buildQuery(t *testing.T, id uint16, domain string, queryType dnsmessage.Type) []byte {
+	msg := dnsmessage.Message{
+		Header: dnsmessage.Header{ID: id, RecursionDesired: true},
+		Questions: []dnsmessage.Question{
+			{Name: dnsmessage.MustNewName(domain), Type: queryType, Class: dnsmessage.ClassINET},
+		},
+	}
+	query, err := msg.Pack()
+	if err != nil {
+		t.Fatalf("Pack failed: %s", err.Error())
+	}
+	return query
+}
+
+func parseResponse(t *testing.T, resp []byte) dnsmessage.Message {
+	var msg dnsmessage.Message
+	if err := msg.Unpack(resp); err != nil {
+		t.Fatalf("Unpack failed: %s", err.Error())
+	}
+	return msg
+}
+
+// describeRecords returns the type and data of the records in short.
+func describeRecords(records []dnsmessage.Resource) []string {
+	var descriptions []string
+	for _, record := range records {
+		description := ""
+		switch body := record.Body.(type) {
+		case *dnsmessage.SRVResource:
+			description = "SRV " + strconv.Itoa(int(body.Port)) + " " + body.Target.String()
+		case *dnsmessage.TXTResource:
+			description = "TXT [" + strings.Join(body.TXT, " ") + "]"
+		case *dnsmessage.AResource:
+			description = "A " + net.IP(body.A[:]).String()
+		case *dnsmessage.AAAAResource:
+			description = "AAAA " + net.IP(body.AAAA[:]).String()
+		}
+		descriptions = append(descriptions, description)
+	}
+	return descriptions
+}
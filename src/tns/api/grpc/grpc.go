@@ -0,0 +1,295 @@
+/*******************************************************************************
+ * Copyright 2018 Samsung Electronics All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ *******************************************************************************/
+package grpc
+
+import (
+	"context"
+	"encoding/json"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"net"
+	"strconv"
+	"strings"
+	"tns/api/grpc/tnspb"
+	"tns/commons/errors"
+	"tns/commons/logger"
+	keepaliveController "tns/controller/keepalive"
+	topicController "tns/controller/topic"
+	watchController "tns/controller/watch"
+)
+
+// TopicNameService of doc/tns.proto is served with the same controllers as the
+// REST APIs. Requests are converted into the bodies and queries of the REST APIs,
+// so that they are validated in the same way.
+
+type Config struct {
+	Enabled bool
+	Ip      string
+	Port    uint
+}
+
+type Command interface {
+	Serve(config Config) error
+}
+
+type RequestHandler struct{}
+
+var topicExecutor topicController.Command
+var keepaliveExecutor keepaliveController.Command
+var watchExecutor watchController.Command
+
+func init() {
+	topicExecutor = topicController.Executor{}
+	keepaliveExecutor = keepaliveController.Executor{}
+	watchExecutor = watchController.Executor{}
+}
+
+// server implements tnspb.TopicNameServiceServer.
+type server struct {
+	tnspb.UnimplementedTopicNameServiceServer
+}
+
+var eventTypes = map[string]tnspb.WatchEvent_Type{
+	watchController.EVENT_CREATED: tnspb.WatchEvent_CREATED,
+	watchController.EVENT_UPDATED: tnspb.WatchEvent_UPDATED,
+	watchController.EVENT_DELETED: tnspb.WatchEvent_DELETED,
+	watchController.EVENT_EXPIRED: tnspb.WatchEvent_EXPIRED,
+	watchController.EVENT_RESET:   tnspb.WatchEvent_RESET,
+}
+
+// Serve starts to serve gRPC requests in background.
+func (RequestHandler) Serve(config Config) error {
+	logger.Logging(logger.DEBUG, "IN")
+	defer logger.Logging(logger.DEBUG, "OUT")
+
+	address := net.JoinHostPort(config.Ip, strconv.FormatUint(uint64(config.Port), 10))
+
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		logger.Logging(logger.ERROR, "Listen failed: "+err.Error())
+		return err
+	}
+
+	logger.Logging(logger.DEBUG, "Serve gRPC on "+address)
+
+	go serve(listener)
+
+	return nil
+}
+
+func serve(listener net.Listener) {
+	s := grpc.NewServer()
+	tnspb.RegisterTopicNameServiceServer(s, server{})
+
+	err := s.Serve(listener)
+	if err != nil {
+		logger.Logging(logger.ERROR, "Serve failed: "+err.Error())
+	}
+}
+
+func (server) RegisterTopic(ctx context.Context, req *tnspb.RegisterTopicRequest) (*tnspb.RegisterTopicResponse, error) {
+	logger.Logging(logger.DEBUG, "IN")
+	defer logger.Logging(logger.DEBUG, "OUT")
+
+	topic := map[string]interface{}{
+		"name":      req.Name,
+		"endpoint":  req.Endpoint,
+		"datamodel": req.Datamodel,
+		"secured":   req.Secured,
+	}
+	if len(req.Labels) != 0 {
+		topic["labels"] = req.Labels
+	}
+	if req.KaInterval != 0 {
+		topic["ka_interval"] = req.KaInterval
+	}
+	if req.LeaseId != "" {
+		topic["lease_id"] = req.LeaseId
+	}
+
+	body, err := json.Marshal(map[string]interface{}{"topic": topic})
+	if err != nil {
+		return nil, convertToStatus(errors.InternalServerError{err.Error()})
+	}
+
+	resp, created, err := topicExecutor.CreateTopic(string(body))
+	if err != nil {
+		return nil, convertToStatus(err)
+	}
+
+	registered := &tnspb.RegisterTopicResponse{Created: created}
+	if interval, exists := resp["ka_interval"].(uint); exists {
+		registered.KaInterval = uint32(interval)
+	}
+	registered.LeaseId, _ = resp["lease_id"].(string)
+
+	return registered, nil
+}
+
+func (server) LookupTopics(ctx context.Context, req *tnspb.LookupTopicsRequest) (*tnspb.LookupTopicsResponse, error) {
+	logger.Logging(logger.DEBUG, "IN")
+	defer logger.Logging(logger.DEBUG, "OUT")
+
+	resp, err := topicExecutor.ReadTopic(req.Name, req.Hierarchical, req.Selector, false, -1)
+	if err != nil {
+		return nil, convertToStatus(err)
+	}
+
+	topics, _ := resp["topics"].([]map[string]interface{})
+	found := &tnspb.LookupTopicsResponse{Topics: make([]*tnspb.Topic, len(topics))}
+	for i, topic := range topics {
+		found.Topics[i] = convertToTopic(topic)
+	}
+
+	return found, nil
+}
+
+func (server) UnregisterTopic(ctx context.Context, req *tnspb.UnregisterTopicRequest) (*tnspb.UnregisterTopicResponse, error) {
+	logger.Logging(logger.DEBUG, "IN")
+	defer logger.Logging(logger.DEBUG, "OUT")
+
+	err := topicExecutor.DeleteTopic(req.Name, req.Endpoint)
+	if err != nil {
+		return nil, convertToStatus(err)
+	}
+
+	return &tnspb.UnregisterTopicResponse{}, nil
+}
+
+func (server) KeepAlive(ctx context.Context, req *tnspb.KeepAliveRequest) (*tnspb.KeepAliveResponse, error) {
+	logger.Logging(logger.DEBUG, "IN")
+	defer logger.Logging(logger.DEBUG, "OUT")
+
+	ping := make(map[string]interface{})
+	if req.LeaseId != "" {
+		ping["lease_id"] = req.LeaseId
+	}
+	if req.LeaseId == "" || len(req.TopicNames) != 0 {
+		ping["topic_names"] = req.TopicNames
+	}
+	if req.Endpoint != "" {
+		ping["endpoint"] = req.Endpoint
+	}
+
+	body, err := json.Marshal(ping)
+	if err != nil {
+		return nil, convertToStatus(errors.InternalServerError{err.Error()})
+	}
+
+	resp, err := keepaliveExecutor.HandlePing(string(body))
+	if err != nil {
+		// Topics which are not registered
+		if names, exists := resp["topic_names"].([]string); exists {
+			err = errors.NotFound{strings.Join(names, ", ")}
+		}
+		return nil, convertToStatus(err)
+	}
+
+	renewed := &tnspb.KeepAliveResponse{}
+	renewed.LeaseId, _ = resp["lease_id"].(string)
+	if ttl, exists := resp["ttl"].(uint); exists {
+		renewed.Ttl = uint32(ttl)
+	}
+
+	return renewed, nil
+}
+
+// Watch sends the events until the client cancels the stream. The stream ends with
+// UNAVAILABLE if the client can not keep up with the events, then the client
+// should watch again with the ID of the last received event.
+func (server) Watch(req *tnspb.WatchRequest, stream tnspb.TopicNameService_WatchServer) error {
+	logger.Logging(logger.DEBUG, "IN")
+	defer logger.Logging(logger.DEBUG, "OUT")
+
+	subscription, err := watchExecutor.Subscribe(req.Name, req.Hierarchical, req.LastEventId)
+	if err != nil {
+		return convertToStatus(err)
+	}
+	defer watchExecutor.Unsubscribe(subscription)
+
+	for {
+		select {
+		case event, ok := <-subscription.Events:
+			if !ok {
+				return status.Error(codes.Unavailable, "too many pending events, watch again with last_event_id")
+			}
+			err := stream.Send(&tnspb.WatchEvent{
+				Id:    event.ID,
+				Type:  eventTypes[event.Type],
+				Topic: convertToTopic(event.Topic),
+			})
+			if err != nil {
+				logger.Logging(logger.DEBUG, "Send failed: "+err.Error())
+				return err
+			}
+		case <-stream.Context().Done():
+			return nil
+		}
+	}
+}
+
+// convertToTopic converts the properties of a topic returned by the controllers
+// or published to the watch stream.
+func convertToTopic(properties map[string]interface{}) *tnspb.Topic {
+	topic := &tnspb.Topic{}
+	topic.Name, _ = properties["name"].(string)
+	topic.Datamodel, _ = properties["datamodel"].(string)
+	topic.Secured, _ = properties["secured"].(bool)
+	topic.Revision, _ = properties["revision"].(int64)
+	topic.Status, _ = properties["status"].(string)
+
+	// Events of registrations and removals have the endpoint of a publisher only
+	if endpoints, exists := properties["endpoints"].([]string); exists {
+		topic.Endpoints = endpoints
+	} else if endpoint, _ := properties["endpoint"].(string); endpoint != "" {
+		topic.Endpoints = []string{endpoint}
+	}
+
+	switch labels := properties["labels"].(type) {
+	case map[string]string:
+		topic.Labels = labels
+	case map[string]interface{}:
+		topic.Labels = make(map[string]string, len(labels))
+		for key, value := range labels {
+			topic.Labels[key], _ = value.(string)
+		}
+	}
+
+	return topic
+}
+
+// convertToStatus converts an error object to a gRPC status in the same way as
+// the status codes of the REST APIs.
+func convertToStatus(err error) error {
+	code := codes.Internal
+
+	switch err.(type) {
+	case errors.InvalidParam,
+		errors.InvalidJSON,
+		errors.InvalidQuery:
+		code = codes.InvalidArgument
+	case errors.NotFound:
+		code = codes.NotFound
+	case errors.Conflict:
+		code = codes.AlreadyExists
+	case errors.Unavailable:
+		code = codes.Unavailable
+	}
+
+	return status.Error(code, err.Error())
+}
@@ -0,0 +1,427 @@
+/*******************************************************************************
+ * Copyright 2018 Samsung Electronics All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ *******************************************************************************/
+package grpc
+
+import (
+	"context"
+	"github.com/golang/mock/gomock"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"io"
+	"net"
+	"reflect"
+	"testing"
+	"tns/api/grpc/tnspb"
+	"tns/commons/errors"
+	keepaliveControllerMock "tns/controller/keepalive/mocks"
+	topicControllerMock "tns/controller/topic/mocks"
+	watchController "tns/controller/watch"
+	watchControllerMock "tns/controller/watch/mocks"
+)
+
+var Handler Command
+
+func init() {
+	Handler = RequestHandler{}
+}
+
+// newClientForTest serves gRPC on a local port, and returns a client connected to it.
+func newClientForTest(t *testing.T) (tnspb.TopicNameServiceClient, func()) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen failed: %s", err.Error())
+	}
+	go serve(listener)
+
+	conn, err := grpc.Dial(listener.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		listener.Close()
+		t.Fatalf("Dial failed: %s", err.Error())
+	}
+
+	return tnspb.NewTopicNameServiceClient(conn), func() {
+		conn.Close()
+		listener.Close()
+	}
+}
+
+func checkCode(t *testing.T, err error, expectedCode codes.Code) {
+	if expectedCode == codes.OK && err != nil {
+		t.Fatalf("Unexpected error: %s", err.Error())
+	}
+	if code := status.Code(err); code != expectedCode {
+		t.Errorf("Expected code: %s, Actual: %s", expectedCode, code)
+	}
+}
+
+func TestCallServeWithInvalidAddress(t *testing.T) {
+	err := Handler.Serve(Config{Enabled: true, Ip: "invalid ip", Port: 0})
+	if err == nil {
+		t.Error("Serve did not return an error")
+	}
+}
+
+func TestCallRegisterTopic(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	topicCtrlrMockObj := topicControllerMock.NewMockCommand(ctrl)
+
+	// pass mockObj to a real object.
+	topicExecutor = topicCtrlrMockObj
+
+	client, closeClient := newClientForTest(t)
+	defer closeClient()
+
+	testCases := []struct {
+		name         string
+		req          *tnspb.RegisterTopicRequest
+		expectedBody string
+		mockResp     map[string]interface{}
+		mockCreated  bool
+		mockError    error
+		expectedResp *tnspb.RegisterTopicResponse
+		expectedCode codes.Code
+	}{
+		{
+			"Success",
+			&tnspb.RegisterTopicRequest{Name: "/a", Endpoint: "0.0.0.0:1234", Datamodel: "test_0.0.1"},
+			`{"topic":{"datamodel":"test_0.0.1","endpoint":"0.0.0.0:1234","name":"/a","secured":false}}`,
+			map[string]interface{}{"ka_interval": uint(200)}, true, nil,
+			&tnspb.RegisterTopicResponse{Created: true, KaInterval: 200},
+			codes.OK,
+		},
+		{
+			"Success_WithLabelsAndInterval",
+			&tnspb.RegisterTopicRequest{Name: "/a", Endpoint: "0.0.0.0:1234", Datamodel: "test_0.0.1", Secured: true,
+				Labels: map[string]string{"site": "plant3"}, KaInterval: 60},
+			`{"topic":{"datamodel":"test_0.0.1","endpoint":"0.0.0.0:1234","ka_interval":60,"labels":{"site":"plant3"},"name":"/a","secured":true}}`,
+			map[string]interface{}{"ka_interval": uint(20)}, false, nil,
+			&tnspb.RegisterTopicResponse{Created: false, KaInterval: 20},
+			codes.OK,
+		},
+		{
+			"Success_WithLease",
+			&tnspb.RegisterTopicRequest{Name: "/a", Endpoint: "0.0.0.0:1234", Datamodel: "test_0.0.1", LeaseId: "l1"},
+			`{"topic":{"datamodel":"test_0.0.1","endpoint":"0.0.0.0:1234","lease_id":"l1","name":"/a","secured":false}}`,
+			map[string]interface{}{"lease_id": "l1"}, true, nil,
+			&tnspb.RegisterTopicResponse{Created: true, LeaseId: "l1"},
+			codes.OK,
+		},
+		{
+			"InvalidParam",
+			&tnspb.RegisterTopicRequest{Name: "/a"},
+			`{"topic":{"datamodel":"","endpoint":"","name":"/a","secured":false}}`,
+			nil, false, errors.InvalidParam{"endpoint"},
+			nil,
+			codes.InvalidArgument,
+		},
+		{
+			"Conflict",
+			&tnspb.RegisterTopicRequest{Name: "/a", Endpoint: "0.0.0.0:1234", Datamodel: "test_0.0.2"},
+			`{"topic":{"datamodel":"test_0.0.2","endpoint":"0.0.0.0:1234","name":"/a","secured":false}}`,
+			nil, false, errors.Conflict{"datamodel"},
+			nil,
+			codes.AlreadyExists,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			gomock.InOrder(
+				topicCtrlrMockObj.EXPECT().CreateTopic(tc.expectedBody).Return(tc.mockResp, tc.mockCreated, tc.mockError),
+			)
+
+			resp, err := client.RegisterTopic(context.Background(), tc.req)
+
+			checkCode(t, err, tc.expectedCode)
+			if tc.expectedResp != nil && (resp.Created != tc.expectedResp.Created ||
+				resp.KaInterval != tc.expectedResp.KaInterval || resp.LeaseId != tc.expectedResp.LeaseId) {
+				t.Errorf("Expected resp: %v, Actual: %v", tc.expectedResp, resp)
+			}
+		})
+	}
+}
+
+func TestCallLookupTopics(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	topicCtrlrMockObj := topicControllerMock.NewMockCommand(ctrl)
+
+	// pass mockObj to a real object.
+	topicExecutor = topicCtrlrMockObj
+
+	client, closeClient := newClientForTest(t)
+	defer closeClient()
+
+	topic := map[string]interface{}{
+		"name":      "/a/b",
+		"endpoint":  "0.0.0.0:1234",
+		"endpoints": []string{"0.0.0.0:1234", "0.0.0.0:5678"},
+		"datamodel": "test_0.0.1",
+		"secured":   true,
+		"labels":    map[string]string{"site": "plant3"},
+		"revision":  int64(2),
+		"status":    "alive",
+	}
+
+	testCases := []struct {
+		name           string
+		req            *tnspb.LookupTopicsRequest
+		mockError      error
+		expectedTopics int
+		expectedCode   codes.Code
+	}{
+		{"Success", &tnspb.LookupTopicsRequest{Name: "/a/b"}, nil, 1, codes.OK},
+		{"Success_Hierarchical", &tnspb.LookupTopicsRequest{Name: "/a", Hierarchical: true}, nil, 1, codes.OK},
+		{"Success_Wildcard", &tnspb.LookupTopicsRequest{Name: "/+/b", Selector: "site=plant3"}, nil, 1, codes.OK},
+		{"NotFound", &tnspb.LookupTopicsRequest{Name: "/c"}, errors.NotFound{"/c"}, 0, codes.NotFound},
+		{"InvalidQuery", &tnspb.LookupTopicsRequest{Name: "/a/#/b"}, errors.InvalidQuery{"name"}, 0, codes.InvalidArgument},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var mockResp map[string]interface{}
+			if tc.mockError == nil {
+				mockResp = map[string]interface{}{"topics": []map[string]interface{}{topic}}
+			}
+
+			gomock.InOrder(
+				topicCtrlrMockObj.EXPECT().ReadTopic(tc.req.Name, tc.req.Hierarchical, tc.req.Selector, false, -1).Return(mockResp, tc.mockError),
+			)
+
+			resp, err := client.LookupTopics(context.Background(), tc.req)
+
+			checkCode(t, err, tc.expectedCode)
+			if len(resp.GetTopics()) != tc.expectedTopics {
+				t.Fatalf("Expected topics: %d, Actual: %d", tc.expectedTopics, len(resp.GetTopics()))
+			}
+			if tc.expectedTopics != 0 {
+				found := resp.Topics[0]
+				if found.Name != "/a/b" || !reflect.DeepEqual(found.Endpoints, []string{"0.0.0.0:1234", "0.0.0.0:5678"}) ||
+					found.Datamodel != "test_0.0.1" || !found.Secured || found.Labels["site"] != "plant3" ||
+					found.Revision != 2 || found.Status != "alive" {
+					t.Errorf("Unexpected topic: %v", found)
+				}
+			}
+		})
+	}
+}
+
+func TestCallUnregisterTopic(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	topicCtrlrMockObj := topicControllerMock.NewMockCommand(ctrl)
+
+	// pass mockObj to a real object.
+	topicExecutor = topicCtrlrMockObj
+
+	client, closeClient := newClientForTest(t)
+	defer closeClient()
+
+	testCases := []struct {
+		name         string
+		req          *tnspb.UnregisterTopicRequest
+		mockError    error
+		expectedCode codes.Code
+	}{
+		{"Success", &tnspb.UnregisterTopicRequest{Name: "/a"}, nil, codes.OK},
+		{"Success_Publisher", &tnspb.UnregisterTopicRequest{Name: "/a", Endpoint: "0.0.0.0:1234"}, nil, codes.OK},
+		{"NotFound", &tnspb.UnregisterTopicRequest{Name: "/b"}, errors.NotFound{"/b"}, codes.NotFound},
+		{"InternalServerError", &tnspb.UnregisterTopicRequest{Name: "/c"}, errors.InternalServerError{}, codes.Internal},
+		{"Unavailable", &tnspb.UnregisterTopicRequest{Name: "/d"}, errors.Unavailable{"not the leader"}, codes.Unavailable},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			gomock.InOrder(
+				topicCtrlrMockObj.EXPECT().DeleteTopic(tc.req.Name, tc.req.Endpoint).Return(tc.mockError),
+			)
+
+			_, err := client.UnregisterTopic(context.Background(), tc.req)
+
+			checkCode(t, err, tc.expectedCode)
+		})
+	}
+}
+
+func TestCallKeepAlive(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	keepaliveCtrlrMockObj := keepaliveControllerMock.NewMockCommand(ctrl)
+
+	// pass mockObj to a real object.
+	keepaliveExecutor = keepaliveCtrlrMockObj
+
+	client, closeClient := newClientForTest(t)
+	defer closeClient()
+
+	testCases := []struct {
+		name         string
+		req          *tnspb.KeepAliveRequest
+		expectedBody string
+		mockResp     map[string]interface{}
+		mockError    error
+		expectedResp *tnspb.KeepAliveResponse
+		expectedCode codes.Code
+	}{
+		{
+			"Success",
+			&tnspb.KeepAliveRequest{TopicNames: []string{"/a", "/b"}},
+			`{"topic_names":["/a","/b"]}`,
+			nil, nil,
+			&tnspb.KeepAliveResponse{},
+			codes.OK,
+		},
+		{
+			"Success_Publisher",
+			&tnspb.KeepAliveRequest{TopicNames: []string{"/a"}, Endpoint: "0.0.0.0:1234"},
+			`{"endpoint":"0.0.0.0:1234","topic_names":["/a"]}`,
+			nil, nil,
+			&tnspb.KeepAliveResponse{},
+			codes.OK,
+		},
+		{
+			"Success_Lease",
+			&tnspb.KeepAliveRequest{LeaseId: "l1"},
+			`{"lease_id":"l1"}`,
+			map[string]interface{}{"lease_id": "l1", "ttl": uint(30)}, nil,
+			&tnspb.KeepAliveResponse{LeaseId: "l1", Ttl: 30},
+			codes.OK,
+		},
+		{
+			"InvalidParam_LeaseWithTopicNames",
+			&tnspb.KeepAliveRequest{TopicNames: []string{"/a"}, LeaseId: "l1"},
+			`{"lease_id":"l1","topic_names":["/a"]}`,
+			nil, errors.InvalidParam{"'topic_names' can not be given with 'lease_id'"},
+			nil,
+			codes.InvalidArgument,
+		},
+		{
+			"NotFound",
+			&tnspb.KeepAliveRequest{TopicNames: []string{"/a", "/b"}},
+			`{"topic_names":["/a","/b"]}`,
+			map[string]interface{}{"topic_names": []string{"/b"}}, errors.NotFound{},
+			nil,
+			codes.NotFound,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			gomock.InOrder(
+				keepaliveCtrlrMockObj.EXPECT().HandlePing(tc.expectedBody).Return(tc.mockResp, tc.mockError),
+			)
+
+			resp, err := client.KeepAlive(context.Background(), tc.req)
+
+			checkCode(t, err, tc.expectedCode)
+			if tc.expectedResp != nil && (resp.LeaseId != tc.expectedResp.LeaseId || resp.Ttl != tc.expectedResp.Ttl) {
+				t.Errorf("Expected resp: %v, Actual: %v", tc.expectedResp, resp)
+			}
+			if tc.name == "NotFound" && status.Convert(err).Message() != "not found target: /b" {
+				t.Errorf("Unexpected message: %s", status.Convert(err).Message())
+			}
+		})
+	}
+}
+
+func TestCallWatch(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	watchCtrlrMockObj := watchControllerMock.NewMockCommand(ctrl)
+
+	// pass mockObj to a real object.
+	watchExecutor = watchCtrlrMockObj
+
+	client, closeClient := newClientForTest(t)
+	defer closeClient()
+
+	// Closed after the pending events, as if the subscriber is dropped
+	events := make(chan watchController.Event, 2)
+	events <- watchController.Event{ID: "x-1", Type: watchController.EVENT_CREATED, Topic: map[string]interface{}{
+		"name": "/a", "endpoint": "0.0.0.0:1234", "datamodel": "test_0.0.1", "labels": map[string]interface{}{"site": "plant3"}}}
+	events <- watchController.Event{ID: "x-2", Type: watchController.EVENT_EXPIRED, Topic: map[string]interface{}{
+		"name": "/a", "endpoint": "0.0.0.0:1234"}}
+	close(events)
+	subscription := &watchController.Subscription{Events: events}
+
+	gomock.InOrder(
+		watchCtrlrMockObj.EXPECT().Subscribe("/a", true, "x-0").Return(subscription, nil),
+		watchCtrlrMockObj.EXPECT().Unsubscribe(subscription),
+	)
+
+	stream, err := client.Watch(context.Background(), &tnspb.WatchRequest{Name: "/a", Hierarchical: true, LastEventId: "x-0"})
+	if err != nil {
+		t.Fatalf("Watch failed: %s", err.Error())
+	}
+
+	expected := []struct {
+		id        string
+		eventType tnspb.WatchEvent_Type
+		datamodel string
+	}{
+		{"x-1", tnspb.WatchEvent_CREATED, "test_0.0.1"},
+		{"x-2", tnspb.WatchEvent_EXPIRED, ""},
+	}
+	for _, e := range expected {
+		event, err := stream.Recv()
+		if err != nil {
+			t.Fatalf("Recv failed: %s", err.Error())
+		}
+		if event.Id != e.id || event.Type != e.eventType || event.Topic.Name != "/a" ||
+			!reflect.DeepEqual(event.Topic.Endpoints, []string{"0.0.0.0:1234"}) || event.Topic.Datamodel != e.datamodel {
+			t.Errorf("Unexpected event: %v", event)
+		}
+	}
+
+	_, err = stream.Recv()
+	if err == io.EOF || status.Code(err) != codes.Unavailable {
+		t.Errorf("Expected code: %s, Actual: %v", codes.Unavailable, err)
+	}
+}
+
+func TestCallWatchWithInvalidFilter(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	watchCtrlrMockObj := watchControllerMock.NewMockCommand(ctrl)
+
+	// pass mockObj to a real object.
+	watchExecutor = watchCtrlrMockObj
+
+	client, closeClient := newClientForTest(t)
+	defer closeClient()
+
+	gomock.InOrder(
+		watchCtrlrMockObj.EXPECT().Subscribe("/a/#/b", false, "").Return(nil, errors.InvalidQuery{}),
+	)
+
+	stream, err := client.Watch(context.Background(), &tnspb.WatchRequest{Name: "/a/#/b"})
+	if err != nil {
+		t.Fatalf("Watch failed: %s", err.Error())
+	}
+
+	_, err = stream.Recv()
+	checkCode(t, err, codes.InvalidArgument)
+}
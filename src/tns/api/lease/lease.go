@@ -0,0 +1,145 @@
+/*******************************************************************************
+ * Copyright 2018 Samsung Electronics All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ *******************************************************************************/
+package lease
+
+import (
+	"net/http"
+	"strings"
+	"tns/api/common"
+	"tns/commons/errors"
+	"tns/commons/logger"
+	keepaliveController "tns/controller/keepalive"
+)
+
+type Command interface {
+	Handle(w http.ResponseWriter, req *http.Request)
+}
+
+type RequestHandler struct{}
+
+var keepaliveExecutor keepaliveController.Command
+
+func init() {
+	keepaliveExecutor = keepaliveController.Executor{}
+}
+
+// Handle grants, reads and revokes leases.
+// Leases are renewed by keep-alive with 'lease_id'.
+func (RequestHandler) Handle(w http.ResponseWriter, req *http.Request) {
+	// Check URL
+	url := strings.TrimPrefix(req.URL.Path, "/api/v1"+"/tns/lease")
+	if len(url) != 0 {
+		common.WriteError(w, errors.NotFoundURL{url})
+		return
+	}
+
+	switch req.Method {
+	case http.MethodPost:
+		handlePostReq(w, req)
+	case http.MethodGet:
+		handleGetReq(w, req)
+	case http.MethodDelete:
+		handleDeleteReq(w, req)
+	default:
+		logger.Logging(logger.DEBUG, "Invalid Method")
+		common.WriteError(w, errors.InvalidMethod{req.Method})
+		return
+	}
+}
+
+func handlePostReq(w http.ResponseWriter, req *http.Request) {
+	logger.Logging(logger.DEBUG, "IN")
+	defer logger.Logging(logger.DEBUG, "OUT")
+
+	body, err := common.GetBodyFromReq(req)
+	if err != nil {
+		logger.Logging(logger.DEBUG, "GetBodyFromReq failed")
+		common.WriteError(w, err)
+		return
+	}
+
+	resp, err := keepaliveExecutor.GrantLease(body)
+	if err != nil {
+		common.WriteError(w, err)
+		return
+	}
+
+	common.WriteResponse(w, http.StatusCreated, common.MapToJsonByte(resp))
+}
+
+func handleGetReq(w http.ResponseWriter, req *http.Request) {
+	logger.Logging(logger.DEBUG, "IN")
+	defer logger.Logging(logger.DEBUG, "OUT")
+
+	id, err := parseID(req)
+	if err != nil {
+		common.WriteError(w, err)
+		return
+	}
+
+	resp, err := keepaliveExecutor.ReadLease(id)
+	if err != nil {
+		common.WriteError(w, err)
+		return
+	}
+
+	common.WriteResponse(w, http.StatusOK, common.MapToJsonByte(resp))
+}
+
+func handleDeleteReq(w http.ResponseWriter, req *http.Request) {
+	logger.Logging(logger.DEBUG, "IN")
+	defer logger.Logging(logger.DEBUG, "OUT")
+
+	id, err := parseID(req)
+	if err != nil {
+		common.WriteError(w, err)
+		return
+	}
+
+	err = keepaliveExecutor.RevokeLease(id)
+	if err != nil {
+		common.WriteError(w, err)
+		return
+	}
+
+	common.WriteResponse(w, http.StatusOK, nil)
+}
+
+// parseID returns the ID of the lease in 'id' query, which is required.
+func parseID(req *http.Request) (string, error) {
+	id := ""
+
+	for field, values := range req.URL.Query() {
+		if len(values) != 1 { // No any array type value so far
+			return "", errors.InvalidQuery{field}
+		}
+
+		switch field {
+		case "id":
+			id = values[0]
+		default:
+			logger.Logging(logger.DEBUG, "Invalid query: "+field)
+			return "", errors.InvalidQuery{field}
+		}
+	}
+
+	if id == "" {
+		return "", errors.InvalidQuery{"'id' is required"}
+	}
+
+	return id, nil
+}
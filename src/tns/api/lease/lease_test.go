@@ -0,0 +1,194 @@
+/*******************************************************************************
+ * Copyright 2018 Samsung Electronics All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ *******************************************************************************/
+package lease
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/golang/mock/gomock"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"tns/commons/errors"
+	keepaliveControllerMock "tns/controller/keepalive/mocks"
+)
+
+const leaseUrl = "/api/v1/tns/lease"
+
+var testBodyString = `{"ttl":60}`
+
+var Handler Command
+
+func init() {
+	Handler = RequestHandler{}
+}
+
+func TestCallHandleWithInvalidRequest(t *testing.T) {
+	// Mock is not necessary for this test
+
+	testCases := []struct {
+		name         string
+		method       string
+		url          string
+		expectedCode int
+	}{
+		{"InvalidUrl", "POST", leaseUrl + "/invalid", http.StatusNotFound},
+		{"InvalidMethod_Put", "PUT", leaseUrl, http.StatusBadRequest},
+		{"EmptyParameter_Post", "POST", leaseUrl, http.StatusBadRequest},
+		{"InvalidQuery_Get_NoId", "GET", leaseUrl, http.StatusBadRequest},
+		{"InvalidQuery_Get_MultiValue", "GET", leaseUrl + "?id=a&id=b", http.StatusBadRequest},
+		{"InvalidQuery_Delete_InvalidQuery", "DELETE", leaseUrl + "?key=value", http.StatusBadRequest},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(tc.method, tc.url, nil)
+			w := httptest.NewRecorder()
+
+			Handler.Handle(w, req)
+
+			if w.Code != tc.expectedCode {
+				t.Errorf("Expected Code: %s, Actual: %s", http.StatusText(tc.expectedCode), http.StatusText(w.Code))
+			}
+		})
+	}
+}
+
+func TestCallHandlePost(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	kaCtrlrMockObj := keepaliveControllerMock.NewMockCommand(ctrl)
+
+	// pass mockObj to a real object.
+	keepaliveExecutor = kaCtrlrMockObj
+
+	expectedResp := map[string]interface{}{"lease_id": "0123456789abcdef", "ttl": 60}
+	expectedRespByte, _ := json.Marshal(expectedResp)
+
+	testCases := []struct {
+		name         string
+		mockRetResp  map[string]interface{}
+		mockRetError error
+		expectedCode int
+	}{
+		{"Success", expectedResp, nil, http.StatusCreated},
+		{"InvalidParam", nil, errors.InvalidParam{}, http.StatusBadRequest},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			gomock.InOrder(
+				kaCtrlrMockObj.EXPECT().GrantLease(testBodyString).Return(tc.mockRetResp, tc.mockRetError),
+			)
+
+			req := httptest.NewRequest("POST", leaseUrl, strings.NewReader(testBodyString))
+			w := httptest.NewRecorder()
+
+			Handler.Handle(w, req)
+
+			if w.Code != tc.expectedCode {
+				t.Errorf("Expected Code: %s, Actual: %s", http.StatusText(tc.expectedCode), http.StatusText(w.Code))
+			}
+			if tc.mockRetError == nil && 0 != bytes.Compare(w.Body.Bytes(), expectedRespByte) {
+				t.Errorf("Expected body: %s, Actual: %s", expectedRespByte, w.Body.Bytes())
+			}
+		})
+	}
+}
+
+func TestCallHandleGet(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	kaCtrlrMockObj := keepaliveControllerMock.NewMockCommand(ctrl)
+
+	// pass mockObj to a real object.
+	keepaliveExecutor = kaCtrlrMockObj
+
+	expectedResp := map[string]interface{}{"lease_id": "0123456789abcdef", "ttl": 60, "remaining": 30,
+		"topics": []map[string]interface{}{{"name": "/a", "endpoint": "0.0.0.0:1234"}}}
+	expectedRespByte, _ := json.Marshal(expectedResp)
+
+	testCases := []struct {
+		name         string
+		mockRetResp  map[string]interface{}
+		mockRetError error
+		expectedCode int
+	}{
+		{"Success", expectedResp, nil, http.StatusOK},
+		{"NotFound", nil, errors.NotFound{}, http.StatusNotFound},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			gomock.InOrder(
+				kaCtrlrMockObj.EXPECT().ReadLease("0123456789abcdef").Return(tc.mockRetResp, tc.mockRetError),
+			)
+
+			req := httptest.NewRequest("GET", leaseUrl+"?id=0123456789abcdef", nil)
+			w := httptest.NewRecorder()
+
+			Handler.Handle(w, req)
+
+			if w.Code != tc.expectedCode {
+				t.Errorf("Expected Code: %s, Actual: %s", http.StatusText(tc.expectedCode), http.StatusText(w.Code))
+			}
+			if tc.mockRetError == nil && 0 != bytes.Compare(w.Body.Bytes(), expectedRespByte) {
+				t.Errorf("Expected body: %s, Actual: %s", expectedRespByte, w.Body.Bytes())
+			}
+		})
+	}
+}
+
+func TestCallHandleDelete(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	kaCtrlrMockObj := keepaliveControllerMock.NewMockCommand(ctrl)
+
+	// pass mockObj to a real object.
+	keepaliveExecutor = kaCtrlrMockObj
+
+	testCases := []struct {
+		name         string
+		mockRetError error
+		expectedCode int
+	}{
+		{"Success", nil, http.StatusOK},
+		{"NotFound", errors.NotFound{}, http.StatusNotFound},
+		{"DbFailed", errors.InternalServerError{}, http.StatusInternalServerError},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			gomock.InOrder(
+				kaCtrlrMockObj.EXPECT().RevokeLease("0123456789abcdef").Return(tc.mockRetError),
+			)
+
+			req := httptest.NewRequest("DELETE", leaseUrl+"?id=0123456789abcdef", nil)
+			w := httptest.NewRecorder()
+
+			Handler.Handle(w, req)
+
+			if w.Code != tc.expectedCode {
+				t.Errorf("Expected Code: %s, Actual: %s", http.StatusText(tc.expectedCode), http.StatusText(w.Code))
+			}
+		})
+	}
+}
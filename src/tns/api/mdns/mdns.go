@@ -0,0 +1,183 @@
+/*******************************************************************************
+ * Copyright 2018 Samsung Electronics All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ *******************************************************************************/
+package mdns
+
+import (
+	"golang.org/x/net/dns/dnsmessage"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"tns/commons/errors"
+	"tns/commons/logger"
+	watchController "tns/controller/watch"
+	topicDB "tns/db/topic"
+)
+
+// TNS server is advertised on the local link with multicast DNS-SD (RFC 6762,
+// RFC 6763) as an instance of "_tns._tcp", so that clients can find it without
+// its address. Optionally, each topic is advertised as an instance of the
+// configured service type, named by the topic name, e.g., "/a/b/c._ezmq._tcp.local."
+// which has SRV records of its endpoints and a TXT record of its name, datamodel
+// and secured. The records of a topic are announced when it changes, and
+// withdrawn with goodbye packets (TTL 0) when it is removed or expires.
+
+type Config struct {
+	Enabled      bool
+	Interface    string // Network interface to advertise on (default: system default)
+	Instance     string // Instance name of TNS server (default: host name)
+	TopicService string // Service type of topics, e.g., "_ezmq._tcp", topics are not advertised if empty
+	Ttl          uint   // Seconds that the records may be cached (default: 120)
+}
+
+type Command interface {
+	Advertise(config Config, ip string, port uint) error
+}
+
+type RequestHandler struct{}
+
+const (
+	MDNS_ADDRESS = "224.0.0.251:5353"
+	DEFAULT_TTL  = 120
+
+	SERVER_SERVICE = "_tns._tcp"
+	API_PATH       = "/api/v1/tns"
+)
+
+var topicDbExecutor topicDB.Command
+var watchExecutor watchController.Command
+
+func init() {
+	topicDbExecutor = topicDB.Executor{}
+	watchExecutor = watchController.Executor{}
+}
+
+// Advertise starts to advertise TNS server of ip and port, and the topics if configured.
+// All addresses of the interface are advertised if ip is unspecified.
+func (RequestHandler) Advertise(config Config, ip string, port uint) error {
+	logger.Logging(logger.DEBUG, "IN")
+	defer logger.Logging(logger.DEBUG, "OUT")
+
+	var iface *net.Interface
+	if config.Interface != "" {
+		var err error
+		iface, err = net.InterfaceByName(config.Interface)
+		if err != nil {
+			logger.Logging(logger.ERROR, "InterfaceByName failed: "+err.Error())
+			return errors.InvalidParam{"invalid interface: " + config.Interface}
+		}
+	}
+
+	addresses, err := serverAddresses(iface, ip)
+	if err != nil {
+		logger.Logging(logger.ERROR, "serverAddresses failed: "+err.Error())
+		return err
+	}
+
+	host, err := os.Hostname()
+	if err != nil {
+		logger.Logging(logger.ERROR, "Hostname failed: "+err.Error())
+		return err
+	}
+	host = strings.SplitN(host, ".", 2)[0]
+
+	group, _ := net.ResolveUDPAddr("udp4", MDNS_ADDRESS)
+	conn, err := net.ListenMulticastUDP("udp4", iface, group)
+	if err != nil {
+		logger.Logging(logger.ERROR, "ListenMulticastUDP failed: "+err.Error())
+		return err
+	}
+
+	r, err := newResponder(conn, group, config, host, addresses, port)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+
+	return r.start()
+}
+
+// serverAddresses returns ip, or the addresses of the interface if ip is unspecified.
+// All up and multicast interfaces but loopback are used if iface is nil.
+func serverAddresses(iface *net.Interface, ip string) ([]net.IP, error) {
+	if parsed := net.ParseIP(ip); parsed != nil && !parsed.IsUnspecified() {
+		return []net.IP{parsed}, nil
+	}
+
+	ifaces := []net.Interface{}
+	if iface != nil {
+		ifaces = append(ifaces, *iface)
+	} else {
+		all, err := net.Interfaces()
+		if err != nil {
+			return nil, err
+		}
+		for _, i := range all {
+			if i.Flags&net.FlagUp != 0 && i.Flags&net.FlagMulticast != 0 && i.Flags&net.FlagLoopback == 0 {
+				ifaces = append(ifaces, i)
+			}
+		}
+	}
+
+	var addresses []net.IP
+	for _, i := range ifaces {
+		addrs, err := i.Addrs()
+		if err != nil {
+			return nil, err
+		}
+		for _, addr := range addrs {
+			if ipNet, ok := addr.(*net.IPNet); ok && !ipNet.IP.IsLinkLocalUnicast() {
+				addresses = append(addresses, ipNet.IP)
+			}
+		}
+	}
+	if len(addresses) == 0 {
+		return nil, errors.InvalidParam{"no address to advertise"}
+	}
+	return addresses, nil
+}
+
+// fqdn returns the name in the local domain for the labels.
+func fqdn(labels ...string) (dnsmessage.Name, error) {
+	for _, label := range labels {
+		if label == "" || len(label) > 63 || strings.Contains(label, ".") {
+			return dnsmessage.Name{}, errors.InvalidParam{"invalid label: " + label}
+		}
+	}
+	return dnsmessage.NewName(strings.Join(labels, ".") + ".local.")
+}
+
+// addressLabel returns the label of the host name for the IP address.
+func addressLabel(ip net.IP) string {
+	if ip4 := ip.To4(); ip4 != nil {
+		return strings.Replace(ip4.String(), ".", "-", -1)
+	}
+	return strings.Replace(ip.String(), ":", "-", -1)
+}
+
+// splitEndpoint returns the host and port of the endpoint.
+func splitEndpoint(endpoint string) (string, uint16, error) {
+	host, portString, err := net.SplitHostPort(endpoint)
+	if err != nil {
+		return "", 0, err
+	}
+	port, err := strconv.ParseUint(portString, 10, 16)
+	if err != nil {
+		return "", 0, err
+	}
+	return host, uint16(port), nil
+}
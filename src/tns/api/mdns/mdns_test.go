@@ -0,0 +1,317 @@
+/*******************************************************************************
+ * Copyright 2018 Samsung Electronics All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ *******************************************************************************/
+package mdns
+
+import (
+	"github.com/golang/mock/gomock"
+	"golang.org/x/net/dns/dnsmessage"
+	"net"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+	"tns/commons/errors"
+	watchController "tns/controller/watch"
+	topicDbMock "tns/db/topic/mocks"
+)
+
+var serverRecords = []string{
+	"_tns._tcp.local. PTR tns-host._tns._tcp.local. 120",
+	"tns-host._tns._tcp.local. SRV tns-host.local.:48323 120",
+	"tns-host._tns._tcp.local. TXT [path=/api/v1/tns] 120",
+}
+
+var serverAddressRecords = []string{"tns-host.local. A 127.0.0.1 120"}
+
+func TestCallAdvertiseWithInvalidInterface(t *testing.T) {
+	err := RequestHandler{}.Advertise(Config{Interface: "unknown0"}, "", 48323)
+	if _, ok := err.(errors.InvalidParam); !ok {
+		t.Errorf("Expected Error: %s, Actual: %v", errors.InvalidParam{}, err)
+	}
+}
+
+func TestServerAddresses(t *testing.T) {
+	addresses, err := serverAddresses(nil, "10.0.0.1")
+	if err != nil || len(addresses) != 1 || !addresses[0].Equal(net.IPv4(10, 0, 0, 1)) {
+		t.Errorf("Expected Addresses: [10.0.0.1], Actual: %v %v", addresses, err)
+	}
+}
+
+func TestNewResponderWithInvalidConfig(t *testing.T) {
+	testCases := []struct {
+		name   string
+		config Config
+	}{
+		{"TopicService_NoUnderscore", Config{TopicService: "ezmq._tcp"}},
+		{"TopicService_InvalidProtocol", Config{TopicService: "_ezmq._sctp"}},
+		{"TopicService_NoProtocol", Config{TopicService: "_ezmq"}},
+		{"Instance_Dot", Config{Instance: "tns.server"}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := newResponder(nil, nil, tc.config, "tns-host", nil, 48323)
+			if _, ok := err.(errors.InvalidParam); !ok {
+				t.Errorf("Expected Error: %s, Actual: %v", errors.InvalidParam{}, err)
+			}
+		})
+	}
+}
+
+func TestAdvertiseServer(t *testing.T) {
+	// Mock is not necessary for this test
+
+	r, group := startResponderForTest(t, Config{})
+	defer r.close()
+	defer group.Close()
+
+	// Announced on start
+	resp := readResponseForTest(t, group)
+	if answers := describeRecords(resp.Answers); !reflect.DeepEqual(answers, serverRecords) {
+		t.Errorf("Expected Answers: %v, Actual: %v", serverRecords, answers)
+	}
+	if additionals := describeRecords(resp.Additionals); !reflect.DeepEqual(additionals, serverAddressRecords) {
+		t.Errorf("Expected Additionals: %v, Actual: %v", serverAddressRecords, additionals)
+	}
+
+	testCases := []struct {
+		name                string
+		domain              string
+		queryType           dnsmessage.Type
+		expectedAnswers     []string
+		expectedAdditionals []string
+	}{
+		{"Browse", "_tns._tcp.local.", dnsmessage.TypePTR, serverRecords[:1], append(serverRecords[1:], serverAddressRecords...)},
+		{"Services", "_services._dns-sd._udp.local.", dnsmessage.TypePTR, []string{"_services._dns-sd._udp.local. PTR _tns._tcp.local. 120"}, nil},
+		{"Instance_SRV", "TNS-Host._tns._tcp.local.", dnsmessage.TypeSRV, serverRecords[1:2], serverAddressRecords},
+		{"Instance_ANY", "tns-host._tns._tcp.local.", dnsmessage.TypeALL, serverRecords[1:], serverAddressRecords},
+		{"Host", "tns-host.local.", dnsmessage.TypeA, serverAddressRecords, nil},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Sent from the mDNS port, and answered to the group
+			sendQueryForTest(t, group, r, 0, tc.domain, tc.queryType)
+
+			resp := readResponseForTest(t, group)
+			if answers := describeRecords(resp.Answers); !reflect.DeepEqual(answers, tc.expectedAnswers) {
+				t.Errorf("Expected Answers: %v, Actual: %v", tc.expectedAnswers, answers)
+			}
+			if additionals := describeRecords(resp.Additionals); !reflect.DeepEqual(additionals, tc.expectedAdditionals) {
+				t.Errorf("Expected Additionals: %v, Actual: %v", tc.expectedAdditionals, additionals)
+			}
+		})
+	}
+
+	t.Run("Unknown", func(t *testing.T) {
+		sendQueryForTest(t, group, r, 0, "unknown.local.", dnsmessage.TypeA)
+		expectNoResponseForTest(t, group)
+	})
+
+	t.Run("LegacyUnicast", func(t *testing.T) {
+		client, err := net.ListenPacket("udp4", "127.0.0.1:0")
+		if err != nil {
+			t.Fatalf("ListenPacket failed: %s", err.Error())
+		}
+		defer client.Close()
+
+		sendQueryForTest(t, client, r, 7, "_tns._tcp.local.", dnsmessage.TypePTR)
+
+		resp := readResponseForTest(t, client)
+		if resp.Header.ID != 7 || len(resp.Questions) != 1 {
+			t.Errorf("Expected the ID and question of the query, Actual: %v %v", resp.Header, resp.Questions)
+		}
+		expected := []string{"_tns._tcp.local. PTR tns-host._tns._tcp.local. 10"}
+		if answers := describeRecords(resp.Answers); !reflect.DeepEqual(answers, expected) {
+			t.Errorf("Expected Answers: %v, Actual: %v", expected, answers)
+		}
+		for _, record := range resp.Additionals {
+			if record.Header.Class != dnsmessage.ClassINET || record.Header.TTL != LEGACY_TTL {
+				t.Errorf("Unexpected Header: %v", record.Header)
+			}
+		}
+	})
+}
+
+func TestAdvertiseTopics(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	topicDbMockObj := topicDbMock.NewMockCommand(ctrl)
+
+	// pass mockObj to a real object.
+	topicDbExecutor = topicDbMockObj
+
+	topic := func(endpoints ...string) map[string]interface{} {
+		return map[string]interface{}{"name": "/a/b", "endpoints": endpoints, "datamodel": "test_0.0.1", "secured": false}
+	}
+
+	gomock.InOrder(
+		topicDbMockObj.EXPECT().ReadTopicAll("").Return([]map[string]interface{}{topic("10.0.0.1:1234")}, nil),
+		topicDbMockObj.EXPECT().ReadTopic("/a/b", false, "").Return([]map[string]interface{}{topic("10.0.0.1:1234", "robot2:5678")}, nil),
+		topicDbMockObj.EXPECT().ReadTopic("/a/b", false, "").Return([]map[string]interface{}{topic("robot2:5678")}, nil),
+		topicDbMockObj.EXPECT().ReadTopic("/a/b", false, "").Return(nil, errors.NotFound{}),
+	)
+
+	r, group := startResponderForTest(t, Config{TopicService: "_ezmq._tcp"})
+	defer r.close()
+	defer group.Close()
+
+	ptr := "_ezmq._tcp.local. PTR /a/b._ezmq._tcp.local. "
+	srv1 := "/a/b._ezmq._tcp.local. SRV 10-0-0-1.local.:1234 "
+	srv2 := "/a/b._ezmq._tcp.local. SRV robot2.local.:5678 "
+	txt := "/a/b._ezmq._tcp.local. TXT [name=/a/b datamodel=test_0.0.1 secured=false] "
+
+	readResponseForTest(t, group) // Server
+
+	steps := []struct {
+		name                string
+		publish             func()
+		expectedAnswers     []string
+		expectedAdditionals []string
+	}{
+		{"Start", func() {}, []string{ptr + "120", srv1 + "120", txt + "120"}, []string{"10-0-0-1.local. A 10.0.0.1 120"}},
+		{"Joined", func() {
+			watchController.Executor{}.Publish(watchController.EVENT_CREATED, map[string]interface{}{"name": "/a/b", "endpoint": "robot2:5678"})
+		}, []string{ptr + "120", srv1 + "120", srv2 + "120", txt + "120"}, []string{"10-0-0-1.local. A 10.0.0.1 120"}},
+		{"Expired", func() {
+			watchController.Executor{}.Publish(watchController.EVENT_EXPIRED, map[string]interface{}{"name": "/a/b", "endpoint": "10.0.0.1:1234"})
+		}, []string{ptr + "120", srv2 + "120", txt + "120", srv1 + "0"}, nil},
+		{"Deleted", func() {
+			watchController.Executor{}.Publish(watchController.EVENT_DELETED, map[string]interface{}{"name": "/a/b"})
+		}, []string{ptr + "0", srv2 + "0", txt + "0"}, nil},
+	}
+
+	for _, step := range steps {
+		t.Run(step.name, func(t *testing.T) {
+			step.publish()
+
+			resp := readResponseForTest(t, group)
+			if answers := describeRecords(resp.Answers); !reflect.DeepEqual(answers, step.expectedAnswers) {
+				t.Errorf("Expected Answers: %v, Actual: %v", step.expectedAnswers, answers)
+			}
+			if additionals := describeRecords(resp.Additionals); !reflect.DeepEqual(additionals, step.expectedAdditionals) {
+				t.Errorf("Expected Additionals: %v, Actual: %v", step.expectedAdditionals, additionals)
+			}
+		})
+	}
+
+	// Withdrawn
+	sendQueryForTest(t, group, r, 0, "_ezmq._tcp.local.", dnsmessage.TypePTR)
+	expectNoResponseForTest(t, group)
+}
+
+func TestHandleQueryWithKnownAnswer(t *testing.T) {
+	// Mock is not necessary for this test
+
+	r, group := startResponderForTest(t, Config{})
+	defer r.close()
+	defer group.Close()
+
+	readResponseForTest(t, group) // Announcement
+
+	query := dnsmessage.Message{
+		Questions: []dnsmessage.Question{{Name: dnsmessage.MustNewName("_tns._tcp.local."), Type: dnsmessage.TypePTR, Class: dnsmessage.ClassINET}},
+		Answers:   []dnsmessage.Resource{r.server.ptr},
+	}
+	buf, _ := query.Pack()
+	group.WriteTo(buf, r.conn.LocalAddr())
+
+	expectNoResponseForTest(t, group)
+}
+
+// startResponderForTest starts a responder on loopback, whose group is the returned connection.
+func startResponderForTest(t *testing.T, config Config) (*responder, net.PacketConn) {
+	group, err := net.ListenPacket("udp4", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("ListenPacket failed: %s", err.Error())
+	}
+	conn, err := net.ListenPacket("udp4", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("ListenPacket failed: %s", err.Error())
+	}
+
+	r, err := newResponder(conn, group.LocalAddr().(*net.UDPAddr), config, "tns-host", []net.IP{net.IPv4(127, 0, 0, 1)}, 48323)
+	if err != nil {
+		t.Fatalf("newResponder returned an error: %s", err.Error())
+	}
+	if err = r.start(); err != nil {
+		t.Fatalf("start returned an error: %s", err.Error())
+	}
+	return r, group
+}
+
+func sendQueryForTest(t *testing.T, conn net.PacketConn, r *responder, id uint16, domain string, queryType dnsmessage.Type) {
+	query := dnsmessage.Message{
+		Header:    dnsmessage.Header{ID: id},
+		Questions: []dnsmessage.Question{{Name: dnsmessage.MustNewName(domain), Type: queryType, Class: dnsmessage.ClassINET}},
+	}
+	buf, err := query.Pack()
+	if err != nil {
+		t.Fatalf("Pack failed: %s", err.Error())
+	}
+	if _, err = conn.WriteTo(buf, r.conn.LocalAddr()); err != nil {
+		t.Fatalf("WriteTo failed: %s", err.Error())
+	}
+}
+
+func readResponseForTest(t *testing.T, conn net.PacketConn) dnsmessage.Message {
+	buf := make([]byte, 9000)
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	n, _, err := conn.ReadFrom(buf)
+	if err != nil {
+		t.Fatalf("ReadFrom failed: %s", err.Error())
+	}
+
+	var msg dnsmessage.Message
+	if err = msg.Unpack(buf[:n]); err != nil {
+		t.Fatalf("Unpack failed: %s", err.Error())
+	}
+	if !msg.Header.Response || !msg.Header.Authoritative {
+		t.Errorf("Unexpected Header: %v", msg.Header)
+	}
+	return msg
+}
+
+func expectNoResponseForTest(t *testing.T, conn net.PacketConn) {
+	buf := make([]byte, 9000)
+	conn.SetReadDeadline(time.Now().Add(200 * time.Millisecond))
+	if _, _, err := conn.ReadFrom(buf); err == nil {
+		t.Errorf("Expected no response")
+	}
+}
+
+// describeRecords returns the name, type, data and TTL of the records in short.
+func describeRecords(records []dnsmessage.Resource) []string {
+	var descriptions []string
+	for _, record := range records {
+		description := ""
+		switch body := record.Body.(type) {
+		case *dnsmessage.PTRResource:
+			description = "PTR " + body.PTR.String()
+		case *dnsmessage.SRVResource:
+			description = "SRV " + body.Target.String() + ":" + strconv.Itoa(int(body.Port))
+		case *dnsmessage.TXTResource:
+			description = "TXT [" + strings.Join(body.TXT, " ") + "]"
+		case *dnsmessage.AResource:
+			description = "A " + net.IP(body.A[:]).String()
+		}
+		descriptions = append(descriptions, record.Header.Name.String()+" "+description+" "+strconv.Itoa(int(record.Header.TTL)))
+	}
+	return descriptions
+}
@@ -0,0 +1,551 @@
+/*******************************************************************************
+ * Copyright 2018 Samsung Electronics All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ *******************************************************************************/
+package mdns
+
+import (
+	"golang.org/x/net/dns/dnsmessage"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"tns/commons/errors"
+	"tns/commons/logger"
+	watchController "tns/controller/watch"
+)
+
+const (
+	LEGACY_TTL       = 10      // Maximum TTL in responses to legacy unicast queries
+	MAX_PACKET_SIZE  = 1400    // Bytes, to fit in the MTU of Ethernet
+	CACHE_FLUSH      = 1 << 15 // Class bit of the records which only TNS server has
+	UNICAST_RESPONSE = 1 << 15 // Class bit of the questions which want unicast responses
+)
+
+var servicesName = dnsmessage.MustNewName("_services._dns-sd._udp.local.")
+
+// service is an instance of a service type with its records.
+type service struct {
+	ptr       dnsmessage.Resource   // PTR from the service type to the instance
+	records   []dnsmessage.Resource // SRV and TXT of the instance
+	addresses []dnsmessage.Resource // A and AAAA of the targets of SRV
+}
+
+// responder answers the queries for the advertised services, and announces
+// the changes of them to the multicast group.
+type responder struct {
+	sync.Mutex
+	conn         net.PacketConn
+	group        *net.UDPAddr
+	ttl          uint32
+	topicService dnsmessage.Name    // Service type of topics, empty if topics are not advertised
+	server       service            // TNS server itself
+	topics       map[string]service // Advertised topics by name
+	stop         chan struct{}
+}
+
+func newResponder(conn net.PacketConn, group *net.UDPAddr, config Config, host string, addresses []net.IP, port uint) (*responder, error) {
+	r := &responder{
+		conn:   conn,
+		group:  group,
+		ttl:    uint32(config.Ttl),
+		topics: make(map[string]service),
+		stop:   make(chan struct{}),
+	}
+	if r.ttl == 0 {
+		r.ttl = DEFAULT_TTL
+	}
+
+	if config.TopicService != "" {
+		labels := strings.Split(config.TopicService, ".")
+		if len(labels) != 2 || !strings.HasPrefix(labels[0], "_") || (labels[1] != "_tcp" && labels[1] != "_udp") {
+			return nil, errors.InvalidParam{"invalid service type: " + config.TopicService}
+		}
+		name, err := fqdn(labels...)
+		if err != nil {
+			return nil, errors.InvalidParam{"invalid service type: " + config.TopicService}
+		}
+		r.topicService = name
+	}
+
+	instance := config.Instance
+	if instance == "" {
+		instance = host
+	}
+	serviceType, _ := fqdn(strings.Split(SERVER_SERVICE, ".")...)
+	instanceName, err := fqdn(append([]string{instance}, strings.Split(SERVER_SERVICE, ".")...)...)
+	if err != nil {
+		return nil, errors.InvalidParam{"invalid instance name: " + instance}
+	}
+	target, err := fqdn(host)
+	if err != nil {
+		return nil, errors.InvalidParam{"invalid host name: " + host}
+	}
+
+	r.server = service{
+		ptr: r.record(serviceType, dnsmessage.TypePTR, &dnsmessage.PTRResource{PTR: instanceName}, false),
+		records: []dnsmessage.Resource{
+			r.record(instanceName, dnsmessage.TypeSRV, &dnsmessage.SRVResource{Port: uint16(port), Target: target}, true),
+			r.record(instanceName, dnsmessage.TypeTXT, &dnsmessage.TXTResource{TXT: []string{"path=" + API_PATH}}, true),
+		},
+	}
+	for _, address := range addresses {
+		r.server.addresses = append(r.server.addresses, r.addressRecord(target, address))
+	}
+
+	return r, nil
+}
+
+// start announces the services, and starts to answer queries and to follow the changes of topics.
+func (r *responder) start() error {
+	if r.advertisesTopics() {
+		// Subscribed before reading topics not to miss the changes
+		subscription, err := watchExecutor.Subscribe("", false, "")
+		if err != nil {
+			logger.Logging(logger.ERROR, "Subscribe failed: "+err.Error())
+			return err
+		}
+		go r.watch(subscription)
+	}
+
+	r.send(append([]dnsmessage.Resource{r.server.ptr}, r.server.records...), r.server.addresses, r.group)
+	if r.advertisesTopics() {
+		r.refreshAll()
+	}
+
+	go r.serve()
+
+	return nil
+}
+
+// close stops the responder without goodbye packets.
+func (r *responder) close() {
+	close(r.stop)
+	r.conn.Close()
+}
+
+func (r *responder) advertisesTopics() bool {
+	return r.topicService.Length != 0
+}
+
+func (r *responder) record(name dnsmessage.Name, recordType dnsmessage.Type, body dnsmessage.ResourceBody, unique bool) dnsmessage.Resource {
+	class := dnsmessage.ClassINET
+	if unique {
+		class |= CACHE_FLUSH
+	}
+	return dnsmessage.Resource{
+		Header: dnsmessage.ResourceHeader{Name: name, Type: recordType, Class: class, TTL: r.ttl},
+		Body:   body,
+	}
+}
+
+func (r *responder) addressRecord(name dnsmessage.Name, ip net.IP) dnsmessage.Resource {
+	if ip4 := ip.To4(); ip4 != nil {
+		body := &dnsmessage.AResource{}
+		copy(body.A[:], ip4)
+		return r.record(name, dnsmessage.TypeA, body, true)
+	}
+	body := &dnsmessage.AAAAResource{}
+	copy(body.AAAA[:], ip.To16())
+	return r.record(name, dnsmessage.TypeAAAA, body, true)
+}
+
+// topicInstance returns the service of the topic, false if it can not be advertised.
+func (r *responder) topicInstance(topic map[string]interface{}) (service, bool) {
+	name, _ := topic["name"].(string)
+	labels := append([]string{name}, strings.Split(r.topicService.String(), ".")[:2]...)
+	instanceName, err := fqdn(labels...)
+	if err != nil {
+		logger.Logging(logger.DEBUG, "Topic can not be advertised: "+name)
+		return service{}, false
+	}
+
+	datamodel, _ := topic["datamodel"].(string)
+	secured, _ := topic["secured"].(bool)
+
+	s := service{ptr: r.record(r.topicService, dnsmessage.TypePTR, &dnsmessage.PTRResource{PTR: instanceName}, false)}
+
+	endpoints, _ := topic["endpoints"].([]string)
+	for _, endpoint := range endpoints {
+		host, port, err := splitEndpoint(endpoint)
+		if err != nil {
+			logger.Logging(logger.ERROR, "Invalid endpoint: "+endpoint)
+			continue
+		}
+
+		ip := net.ParseIP(host)
+		var target dnsmessage.Name
+		switch {
+		case ip != nil:
+			target, err = fqdn(addressLabel(ip))
+		case strings.Contains(strings.TrimSuffix(host, "."), "."):
+			target, err = dnsmessage.NewName(strings.TrimSuffix(host, ".") + ".")
+		default:
+			target, err = fqdn(host)
+		}
+		if err != nil {
+			logger.Logging(logger.ERROR, "Invalid endpoint: "+endpoint)
+			continue
+		}
+
+		s.records = append(s.records, r.record(instanceName, dnsmessage.TypeSRV, &dnsmessage.SRVResource{Port: port, Target: target}, true))
+		if ip != nil {
+			s.addresses = append(s.addresses, r.addressRecord(target, ip))
+		}
+	}
+
+	s.records = append(s.records, r.record(instanceName, dnsmessage.TypeTXT, &dnsmessage.TXTResource{TXT: []string{
+		"name=" + name,
+		"datamodel=" + datamodel,
+		"secured=" + strconv.FormatBool(secured),
+	}}, true))
+
+	return s, true
+}
+
+// watch follows the changes of topics until the responder is closed.
+func (r *responder) watch(subscription *watchController.Subscription) {
+	logger.Logging(logger.DEBUG, "Start mDNS watch")
+	defer logger.Logging(logger.DEBUG, "mDNS watch Finished")
+
+	lastEventID := ""
+	for {
+		dropped := false
+		for !dropped {
+			select {
+			case event, ok := <-subscription.Events:
+				if !ok {
+					dropped = true
+					break
+				}
+				lastEventID = event.ID
+				if event.Type == watchController.EVENT_RESET {
+					r.refreshAll()
+				} else if name, ok := event.Topic["name"].(string); ok {
+					r.refresh(name)
+				}
+			case <-r.stop:
+				watchExecutor.Unsubscribe(subscription)
+				return
+			}
+		}
+
+		var err error
+		subscription, err = watchExecutor.Subscribe("", false, lastEventID)
+		if err != nil {
+			logger.Logging(logger.ERROR, "Subscribe failed: "+err.Error())
+			return
+		}
+	}
+}
+
+// refresh reads the topic, and announces its changes.
+func (r *responder) refresh(name string) {
+	topics, err := topicDbExecutor.ReadTopic(name, false, "")
+	switch err.(type) {
+	case nil:
+	case errors.NotFound:
+		topics = nil
+	default:
+		logger.Logging(logger.ERROR, "ReadTopic failed: "+err.Error())
+		return
+	}
+
+	if len(topics) == 0 {
+		r.update(name, service{}, false)
+	}
+	for _, topic := range topics {
+		s, ok := r.topicInstance(topic)
+		r.update(name, s, ok)
+	}
+}
+
+// refreshAll reads all topics, and announces their changes.
+func (r *responder) refreshAll() {
+	topics, err := topicDbExecutor.ReadTopicAll("")
+	switch err.(type) {
+	case nil:
+	case errors.NotFound:
+	default:
+		logger.Logging(logger.ERROR, "ReadTopicAll failed: "+err.Error())
+		return
+	}
+
+	current := make(map[string]bool, len(topics))
+	for _, topic := range topics {
+		name, _ := topic["name"].(string)
+		current[name] = true
+		s, ok := r.topicInstance(topic)
+		r.update(name, s, ok)
+	}
+
+	r.Lock()
+	var removed []string
+	for name := range r.topics {
+		if !current[name] {
+			removed = append(removed, name)
+		}
+	}
+	r.Unlock()
+
+	for _, name := range removed {
+		r.update(name, service{}, false)
+	}
+}
+
+// update replaces the service of the topic, which is removed if not exists.
+// The records no longer valid are withdrawn, and the new ones are announced.
+func (r *responder) update(name string, s service, exists bool) {
+	r.Lock()
+	old, advertised := r.topics[name]
+	if exists {
+		r.topics[name] = s
+	} else {
+		delete(r.topics, name)
+	}
+	r.Unlock()
+
+	current := make(map[string]bool)
+	var answers []dnsmessage.Resource
+	if exists {
+		answers = append([]dnsmessage.Resource{s.ptr}, s.records...)
+		for _, record := range answers {
+			current[recordKey(record)] = true
+		}
+	}
+
+	changed := exists != advertised
+	if advertised {
+		for _, record := range append([]dnsmessage.Resource{old.ptr}, old.records...) {
+			if !current[recordKey(record)] {
+				record.Header.TTL = 0 // Goodbye
+				answers = append(answers, record)
+				changed = true
+			} else {
+				delete(current, recordKey(record))
+			}
+		}
+	}
+	if !changed && len(current) == 0 {
+		return
+	}
+
+	logger.Logging(logger.DEBUG, "Announce "+name)
+	r.send(answers, s.addresses, r.group)
+}
+
+// serve answers the queries until the responder is closed.
+func (r *responder) serve() {
+	buf := make([]byte, 9000)
+	for {
+		n, src, err := r.conn.ReadFrom(buf)
+		if err != nil {
+			select {
+			case <-r.stop:
+			default:
+				logger.Logging(logger.ERROR, "ReadFrom failed: "+err.Error())
+			}
+			return
+		}
+		r.handleQuery(buf[:n], src)
+	}
+}
+
+// handleQuery answers the query from src. Queries not from the mDNS port are
+// legacy unicast ones, which are answered to src as unicast DNS.
+func (r *responder) handleQuery(query []byte, src net.Addr) {
+	var msg dnsmessage.Message
+	if err := msg.Unpack(query); err != nil || msg.Header.Response || msg.Header.OpCode != 0 {
+		return
+	}
+
+	udpSrc, _ := src.(*net.UDPAddr)
+	legacy := udpSrc == nil || udpSrc.Port != r.group.Port
+
+	// Known answers are not answered again
+	known := make(map[string]bool, len(msg.Answers))
+	for _, record := range msg.Answers {
+		if record.Header.TTL >= r.ttl/2 {
+			known[recordKey(record)] = true
+		}
+	}
+
+	var answers, additionals []dnsmessage.Resource
+	unicast := true
+	for _, question := range msg.Questions {
+		unicast = unicast && question.Class&UNICAST_RESPONSE != 0
+		question.Class &^= UNICAST_RESPONSE
+		if question.Class != dnsmessage.ClassINET && question.Class != dnsmessage.ClassANY {
+			continue
+		}
+		a, b := r.answer(question)
+		for _, record := range a {
+			if !known[recordKey(record)] {
+				answers = append(answers, record)
+			}
+		}
+		additionals = append(additionals, b...)
+	}
+	if len(answers) == 0 {
+		return
+	}
+	answers = dedupe(answers, nil)
+	additionals = dedupe(additionals, answers)
+
+	if !legacy {
+		dst := net.Addr(r.group)
+		if unicast {
+			dst = src
+		}
+		r.send(answers, additionals, dst)
+		return
+	}
+
+	for _, records := range [][]dnsmessage.Resource{answers, additionals} {
+		for i := range records {
+			records[i].Header.Class &^= CACHE_FLUSH
+			if records[i].Header.TTL > LEGACY_TTL {
+				records[i].Header.TTL = LEGACY_TTL
+			}
+		}
+	}
+	resp := dnsmessage.Message{
+		Header:      dnsmessage.Header{ID: msg.Header.ID, Response: true, Authoritative: true},
+		Questions:   msg.Questions,
+		Answers:     answers,
+		Additionals: additionals,
+	}
+	r.write(resp, src)
+}
+
+// answer returns the answers and the additional records to the question.
+func (r *responder) answer(question dnsmessage.Question) ([]dnsmessage.Resource, []dnsmessage.Resource) {
+	r.Lock()
+	defer r.Unlock()
+
+	services := []service{r.server}
+	for _, s := range r.topics {
+		services = append(services, s)
+	}
+
+	matches := func(record dnsmessage.Resource) bool {
+		return strings.EqualFold(record.Header.Name.String(), question.Name.String()) &&
+			(question.Type == record.Header.Type || question.Type == dnsmessage.TypeALL)
+	}
+
+	var answers, additionals []dnsmessage.Resource
+
+	// Service type enumeration
+	if strings.EqualFold(question.Name.String(), servicesName.String()) &&
+		(question.Type == dnsmessage.TypePTR || question.Type == dnsmessage.TypeALL) {
+		answers = append(answers, r.record(servicesName, dnsmessage.TypePTR, &dnsmessage.PTRResource{PTR: r.server.ptr.Header.Name}, false))
+		if r.advertisesTopics() {
+			answers = append(answers, r.record(servicesName, dnsmessage.TypePTR, &dnsmessage.PTRResource{PTR: r.topicService}, false))
+		}
+	}
+
+	for _, s := range services {
+		if matches(s.ptr) {
+			answers = append(answers, s.ptr)
+			additionals = append(additionals, s.records...)
+			additionals = append(additionals, s.addresses...)
+		}
+		for _, record := range s.records {
+			if matches(record) {
+				answers = append(answers, record)
+				if record.Header.Type == dnsmessage.TypeSRV {
+					additionals = append(additionals, s.addresses...)
+				}
+			}
+		}
+		for _, record := range s.addresses {
+			if matches(record) {
+				answers = append(answers, record)
+			}
+		}
+	}
+
+	return answers, additionals
+}
+
+// send sends the records as a mDNS response to dst, split into packets if too large.
+// Additional records are left out if they do not fit.
+func (r *responder) send(answers []dnsmessage.Resource, additionals []dnsmessage.Resource, dst net.Addr) {
+	resp := dnsmessage.Message{
+		Header:      dnsmessage.Header{Response: true, Authoritative: true},
+		Answers:     answers,
+		Additionals: additionals,
+	}
+
+	buf, err := resp.Pack()
+	if err == nil && len(buf) > MAX_PACKET_SIZE {
+		if len(additionals) != 0 {
+			r.send(answers, nil, dst)
+			return
+		}
+		if len(answers) > 1 {
+			r.send(answers[:len(answers)/2], nil, dst)
+			r.send(answers[len(answers)/2:], nil, dst)
+			return
+		}
+	}
+	r.write(resp, dst)
+}
+
+func (r *responder) write(resp dnsmessage.Message, dst net.Addr) {
+	buf, err := resp.Pack()
+	if err != nil {
+		logger.Logging(logger.ERROR, "Pack failed: "+err.Error())
+		return
+	}
+	if _, err = r.conn.WriteTo(buf, dst); err != nil {
+		logger.Logging(logger.ERROR, "WriteTo failed: "+err.Error())
+	}
+}
+
+// recordKey returns the key which identifies the record regardless of its TTL.
+func recordKey(record dnsmessage.Resource) string {
+	key := strings.ToLower(record.Header.Name.String()) + " " + record.Header.Type.String() + " "
+	switch body := record.Body.(type) {
+	case *dnsmessage.PTRResource:
+		key += strings.ToLower(body.PTR.String())
+	case *dnsmessage.SRVResource:
+		key += strings.ToLower(body.Target.String()) + ":" + strconv.Itoa(int(body.Port))
+	case *dnsmessage.TXTResource:
+		key += strings.Join(body.TXT, "\x00")
+	case *dnsmessage.AResource:
+		key += net.IP(body.A[:]).String()
+	case *dnsmessage.AAAAResource:
+		key += net.IP(body.AAAA[:]).String()
+	}
+	return key
+}
+
+// dedupe returns the records without duplicates and the ones in excluded.
+func dedupe(records []dnsmessage.Resource, excluded []dnsmessage.Resource) []dnsmessage.Resource {
+	seen := make(map[string]bool, len(records)+len(excluded))
+	for _, record := range excluded {
+		seen[recordKey(record)] = true
+	}
+
+	var deduped []dnsmessage.Resource
+	for _, record := range records {
+		if key := recordKey(record); !seen[key] {
+			seen[key] = true
+			deduped = append(deduped, record)
+		}
+	}
+	return deduped
+}
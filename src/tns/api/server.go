@@ -21,12 +21,25 @@ import (
 	"fmt"
 	"net/http"
 	"strings"
+	"tns/api/cluster"
+	"tns/api/coap"
 	"tns/api/common"
+	"tns/api/datamodel"
+	"tns/api/dns"
+	"tns/api/grpc"
 	"tns/api/keepalive"
+	"tns/api/lease"
+	"tns/api/mdns"
 	"tns/api/topic"
+	"tns/api/watch"
+	"tns/api/webhook"
 	"tns/commons/errors"
 	"tns/commons/logger"
+	federationController "tns/controller/federation"
 	keepaliveController "tns/controller/keepalive"
+	relayController "tns/controller/relay"
+	topicController "tns/controller/topic"
+	webhookController "tns/controller/webhook"
 	topicDB "tns/db/topic"
 )
 
@@ -37,17 +50,51 @@ var Handler RequestHandler
 var config = Config{}
 var topicHandler topic.Command
 var keepAliveHandler keepalive.Command
+var datamodelHandler datamodel.Command
+var leaseHandler lease.Command
+var watchHandler watch.Command
+var webhookHandler webhook.Command
+var dnsHandler dns.Command
+var mdnsHandler mdns.Command
+var grpcHandler grpc.Command
+var coapHandler coap.Command
+var clusterHandler cluster.Command
 var keepaliveExecutor keepaliveController.Command
+var topicExecutor topicController.Command
+var webhookExecutor webhookController.Command
+var federationExecutor federationController.Command
+var relayExecutor relayController.Command
 var topicDbExecutor topicDB.Command
+var clusterExecutor topicDB.Cluster
 
 func init() {
 	topicHandler = topic.RequestHandler{}
 	keepAliveHandler = keepalive.RequestHandler{}
+	datamodelHandler = datamodel.RequestHandler{}
+	leaseHandler = lease.RequestHandler{}
+	watchHandler = watch.RequestHandler{}
+	webhookHandler = webhook.RequestHandler{}
+	dnsHandler = dns.RequestHandler{}
+	mdnsHandler = mdns.RequestHandler{}
+	grpcHandler = grpc.RequestHandler{}
+	coapHandler = coap.RequestHandler{}
+	clusterHandler = cluster.RequestHandler{}
 	keepaliveExecutor = keepaliveController.Executor{}
+	topicExecutor = topicController.Executor{}
+	webhookExecutor = webhookController.Executor{}
+	federationExecutor = federationController.Executor{}
+	relayExecutor = relayController.Executor{}
 	topicDbExecutor = topicDB.Executor{}
+	clusterExecutor = topicDB.RaftExecutor{}
 }
 
-func RunServer(filePath string) {
+// RunServer reads the configuration file and serves REST APIs.
+// If devMode is true, topics are kept in memory instead of the configured database.
+// If relayUpstream is given, the relay mode is enabled with it as the upstream server.
+// In the cluster mode, topics are replicated to all nodes, and keep-alives are
+// handled by the leader only. In the relay mode, topics are registered and
+// kept alive on the upstream server on behalf of the local publishers.
+func RunServer(filePath string, devMode bool, relayUpstream string) {
 	logger.Logging(logger.DEBUG, "RUN TNS Server")
 
 	err := config.Read(filePath)
@@ -56,18 +103,129 @@ func RunServer(filePath string) {
 		return
 	}
 
-	err = topicDbExecutor.Connect(config.Database.Name)
+	if devMode {
+		logger.Logging(logger.DEBUG, "Development mode, in-memory database is used")
+		config.Database.Type = topicDB.MEMORY_DB
+		config.Cluster.Enabled = false
+	}
+
+	if config.Cluster.Enabled {
+		// Only REST API requests are passed to the leader
+		if config.GRPC.Enabled || config.CoAP.Enabled {
+			logger.Logging(logger.ERROR, "gRPC and CoAP can not be enabled in cluster mode")
+			return
+		}
+		logger.Logging(logger.DEBUG, "Cluster mode, node: "+config.Cluster.NodeId)
+		config.Database.Type = topicDB.RAFT_DB
+		config.Database.Cluster = config.Cluster
+	}
+
+	if relayUpstream != "" {
+		config.Relay.Enabled = true
+		config.Relay.Upstream = relayUpstream
+	}
+
+	if config.Relay.Enabled {
+		if config.Cluster.Enabled {
+			logger.Logging(logger.ERROR, "Cluster and relay can not be enabled together")
+			return
+		}
+		// Lookups of the topics not found locally are cached from the upstream server,
+		// unless federation is disabled explicitly
+		if !config.federationDefined {
+			logger.Logging(logger.DEBUG, "Federation is enabled for relay with upstream: "+config.Relay.Upstream)
+			config.Federation = federationController.Config{Enabled: true, Upstream: config.Relay.Upstream}
+		} else if !config.Federation.Enabled {
+			logger.Logging(logger.DEBUG, "Federation is disabled, lookups are not passed upstream")
+		}
+	}
+
+	err = topicDbExecutor.Connect(config.Database)
 	if err != nil {
 		logger.Logging(logger.ERROR, "Failed to connect to DB")
 		return
 	}
 
-	err = keepaliveExecutor.InitKeepAlive(config.Server.KeepAliveInterval)
+	topicExecutor.SetDatamodelValidation(config.Server.ValidateDatamodel)
+
+	kaConfig := keepaliveController.Config{
+		Interval:    config.Server.KeepAliveInterval,
+		GracePeriod: config.Server.GracePeriod,
+		MinInterval: config.Server.MinKeepAliveInterval,
+		MaxInterval: config.Server.MaxKeepAliveInterval,
+		// Leases are not replicated, so they would be lost on a change of the leader
+		NoLease: config.Cluster.Enabled,
+	}
+	if config.Cluster.Enabled {
+		// Followers stand by until they are elected
+		clusterExecutor.WatchLeadership(func(leader bool) {
+			kaConfig.Standby = !leader
+			if err := keepaliveExecutor.InitKeepAlive(kaConfig); err != nil {
+				logger.Logging(logger.ERROR, "Failed to initialize KeepAlive")
+			}
+		})
+	} else {
+		err = keepaliveExecutor.InitKeepAlive(kaConfig)
+		if err != nil {
+			logger.Logging(logger.ERROR, "Failed to initialize KeepAlive")
+			return
+		}
+	}
+
+	err = webhookExecutor.InitWebhook()
 	if err != nil {
-		logger.Logging(logger.ERROR, "Failed to initialize KeepAlive")
+		logger.Logging(logger.ERROR, "Failed to initialize Webhook")
 		return
 	}
 
+	if config.Relay.Enabled {
+		err = relayExecutor.InitRelay(config.Relay)
+		if err != nil {
+			logger.Logging(logger.ERROR, "Failed to initialize Relay")
+			return
+		}
+	}
+
+	if config.Federation.Enabled {
+		err = federationExecutor.InitFederation(config.Federation)
+		if err != nil {
+			logger.Logging(logger.ERROR, "Failed to initialize Federation")
+			return
+		}
+	}
+
+	if config.DNS.Enabled {
+		err = dnsHandler.Serve(config.DNS)
+		if err != nil {
+			logger.Logging(logger.ERROR, "Failed to serve DNS")
+			return
+		}
+	}
+
+	if config.GRPC.Enabled {
+		err = grpcHandler.Serve(config.GRPC)
+		if err != nil {
+			logger.Logging(logger.ERROR, "Failed to serve gRPC")
+			return
+		}
+	}
+
+	if config.CoAP.Enabled {
+		err = coapHandler.Serve(config.CoAP)
+		if err != nil {
+			logger.Logging(logger.ERROR, "Failed to serve CoAP")
+			return
+		}
+	}
+
+	if config.MDNS.Enabled {
+		err = mdnsHandler.Advertise(config.MDNS, config.Server.Ip, config.Server.Port)
+		if err != nil {
+			logger.Logging(logger.ERROR, "Failed to advertise with mDNS")
+			return
+		}
+	}
+
 	svrUrl := config.Server.Ip + ":" + fmt.Sprint(config.Server.Port)
 	http.ListenAndServe(svrUrl, &Handler)
 }
@@ -76,6 +234,11 @@ func (RequestHandler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
 	logger.Logging(logger.DEBUG, "IN receive msg", req.Method, req.URL.String())
 	defer logger.Logging(logger.DEBUG, "OUT")
 
+	if config.Cluster.Enabled && !strings.Contains(req.URL.Path, "/tns/cluster") &&
+		clusterHandler.Forward(w, req, config.Cluster.Redirect) {
+		return
+	}
+
 	switch url := req.URL.Path; {
 	case !strings.Contains(url, "/api/v1"):
 		logger.Logging(logger.DEBUG, "Unknown URL")
@@ -87,6 +250,21 @@ func (RequestHandler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
 	case strings.Contains(url, "/tns/keepalive"):
 		keepAliveHandler.Handle(w, req)
 
+	case strings.Contains(url, "/tns/datamodel"):
+		datamodelHandler.Handle(w, req)
+
+	case strings.Contains(url, "/tns/lease"):
+		leaseHandler.Handle(w, req)
+
+	case strings.Contains(url, "/tns/watch"):
+		watchHandler.Handle(w, req)
+
+	case strings.Contains(url, "/tns/webhook"):
+		webhookHandler.Handle(w, req)
+
+	case strings.Contains(url, "/tns/cluster") && config.Cluster.Enabled:
+		clusterHandler.Handle(w, req)
+
 	default:
 		logger.Logging(logger.DEBUG, "Unknown URL")
 		common.WriteError(w, errors.NotFoundURL{url})
@@ -18,13 +18,40 @@
 package api
 
 import (
+	"bufio"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
 	"github.com/golang/mock/gomock"
+	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"reflect"
+	"strings"
 	"testing"
+	"time"
+	clusterApiMock "tns/api/cluster/mocks"
+	"tns/api/coap"
+	"tns/api/datamodel"
+	datamodelApiMock "tns/api/datamodel/mocks"
+	"tns/api/dns"
+	"tns/api/grpc"
+	"tns/api/keepalive"
 	kaApiMock "tns/api/keepalive/mocks"
+	"tns/api/lease"
+	leaseApiMock "tns/api/lease/mocks"
+	"tns/api/topic"
 	topicApiMock "tns/api/topic/mocks"
+	"tns/api/watch"
+	watchApiMock "tns/api/watch/mocks"
+	"tns/api/webhook"
+	webhookApiMock "tns/api/webhook/mocks"
+	federationController "tns/controller/federation"
+	keepaliveController "tns/controller/keepalive"
+	relayController "tns/controller/relay"
+	topicDB "tns/db/topic"
 )
 
 func TestCallServeHTTPWithInvalidUrl(t *testing.T) {
@@ -91,6 +118,161 @@ func TestCallServeHTTPWithKeepAliveUrl(t *testing.T) {
 	Handler.ServeHTTP(w, req)
 }
 
+func TestCallServeHTTPWithDatamodelUrl(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	datamodelApiMockObj := datamodelApiMock.NewMockCommand(ctrl)
+
+	// pass mockObj to a real object.
+	datamodelHandler = datamodelApiMockObj
+
+	req := httptest.NewRequest("GET", "/api/v1/tns/datamodel", nil)
+	w := httptest.NewRecorder()
+
+	gomock.InOrder(
+		datamodelApiMockObj.EXPECT().Handle(w, req),
+	)
+
+	Handler.ServeHTTP(w, req)
+}
+
+func TestCallServeHTTPWithLeaseUrl(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	leaseApiMockObj := leaseApiMock.NewMockCommand(ctrl)
+
+	// pass mockObj to a real object.
+	leaseHandler = leaseApiMockObj
+
+	req := httptest.NewRequest("POST", "/api/v1/tns/lease", nil)
+	w := httptest.NewRecorder()
+
+	gomock.InOrder(
+		leaseApiMockObj.EXPECT().Handle(w, req),
+	)
+
+	Handler.ServeHTTP(w, req)
+}
+
+func TestCallServeHTTPWithWatchUrl(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	watchApiMockObj := watchApiMock.NewMockCommand(ctrl)
+
+	// pass mockObj to a real object.
+	watchHandler = watchApiMockObj
+
+	req := httptest.NewRequest("GET", "/api/v1/tns/watch", nil)
+	w := httptest.NewRecorder()
+
+	gomock.InOrder(
+		watchApiMockObj.EXPECT().Handle(w, req),
+	)
+
+	Handler.ServeHTTP(w, req)
+}
+
+func TestCallServeHTTPWithWebhookUrl(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	webhookApiMockObj := webhookApiMock.NewMockCommand(ctrl)
+
+	// pass mockObj to a real object.
+	webhookHandler = webhookApiMockObj
+
+	req := httptest.NewRequest("POST", "/api/v1/tns/webhook", nil)
+	w := httptest.NewRecorder()
+
+	gomock.InOrder(
+		webhookApiMockObj.EXPECT().Handle(w, req),
+	)
+
+	Handler.ServeHTTP(w, req)
+}
+
+func TestCallServeHTTPWithClusterUrl(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	clusterApiMockObj := clusterApiMock.NewMockCommand(ctrl)
+
+	// pass mockObj to a real object.
+	clusterHandler = clusterApiMockObj
+
+	config = Config{}
+	config.Cluster.Enabled = true
+	defer func() { config = Config{} }()
+
+	req := httptest.NewRequest("GET", "/api/v1/tns/cluster", nil)
+	w := httptest.NewRecorder()
+
+	gomock.InOrder(
+		clusterApiMockObj.EXPECT().Handle(w, req),
+	)
+
+	Handler.ServeHTTP(w, req)
+}
+
+func TestCallServeHTTPWithClusterUrlNotEnabled(t *testing.T) {
+	// Mock is not necessary for this test
+
+	config = Config{}
+
+	req := httptest.NewRequest("GET", "/api/v1/tns/cluster", nil)
+	w := httptest.NewRecorder()
+
+	Handler.ServeHTTP(w, req)
+
+	expectedCode := http.StatusNotFound
+	if w.Code != expectedCode {
+		t.Errorf("Expected Code: %s, Actual: %s", http.StatusText(expectedCode), http.StatusText(w.Code))
+	}
+}
+
+func TestCallServeHTTPWithCluster(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	clusterApiMockObj := clusterApiMock.NewMockCommand(ctrl)
+	topicApiMockObj := topicApiMock.NewMockCommand(ctrl)
+
+	// pass mockObj to a real object.
+	clusterHandler = clusterApiMockObj
+	topicHandler = topicApiMockObj
+
+	config = Config{}
+	config.Cluster.Enabled = true
+	config.Cluster.Redirect = true
+	defer func() { config = Config{} }()
+
+	t.Run("Forwarded", func(t *testing.T) {
+		req := httptest.NewRequest("POST", "/api/v1/tns/topic", nil)
+		w := httptest.NewRecorder()
+
+		gomock.InOrder(
+			clusterApiMockObj.EXPECT().Forward(w, req, true).Return(true),
+		)
+
+		Handler.ServeHTTP(w, req)
+	})
+
+	t.Run("Leader", func(t *testing.T) {
+		req := httptest.NewRequest("POST", "/api/v1/tns/topic", nil)
+		w := httptest.NewRecorder()
+
+		gomock.InOrder(
+			clusterApiMockObj.EXPECT().Forward(w, req, true).Return(false),
+			topicApiMockObj.EXPECT().Handle(w, req),
+		)
+
+		Handler.ServeHTTP(w, req)
+	})
+}
+
 func TestCallRead(t *testing.T) {
 	tomlFile, err := os.Create("test.toml")
 	if err != nil {
@@ -107,6 +289,201 @@ func TestCallRead(t *testing.T) {
 	config.Read(tomlFile.Name())
 }
 
+func TestCallReadWithFederationDefined(t *testing.T) {
+	testCases := []struct {
+		name            string
+		content         string
+		expectedDefined bool
+	}{
+		{"Enabled", "[federation]\nenabled = true", true},
+		{"Disabled", "[federation]\nenabled = false", true},
+		{"NotGiven", "[federation]\ntimeout = 5", false},
+		{"NoSection", "[server]\nip = \"0.0.0.0\"", false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tomlFile, err := os.Create("test.toml")
+			if err != nil {
+				t.Error("Create failed")
+			}
+			defer os.Remove(tomlFile.Name())
+
+			_, err = tomlFile.Write([]byte(tc.content))
+			if err != nil {
+				t.Error("Write failed")
+			}
+
+			config = Config{}
+			if err = config.Read(tomlFile.Name()); err != nil {
+				t.Fatalf("Read returned an error: %s", err.Error())
+			}
+
+			if config.federationDefined != tc.expectedDefined {
+				t.Errorf("Expected federationDefined: %v, Actual: %v", tc.expectedDefined, config.federationDefined)
+			}
+		})
+	}
+}
+
+func TestCallReadWithDNS(t *testing.T) {
+	tomlFile, err := os.Create("test.toml")
+	if err != nil {
+		t.Error("Create failed")
+	}
+	defer os.Remove(tomlFile.Name())
+
+	_, err = tomlFile.Write([]byte("[dns]\nenabled = true\nport = 5300\nzone = \"tns.example\"\nttl = 30"))
+	if err != nil {
+		t.Error("Write failed")
+	}
+
+	config = Config{}
+	if err = config.Read(tomlFile.Name()); err != nil {
+		t.Fatalf("Read returned an error: %s", err.Error())
+	}
+
+	expected := dns.Config{Enabled: true, Port: 5300, Zone: "tns.example", Ttl: 30}
+	if config.DNS != expected {
+		t.Errorf("Expected DNS: %v, Actual: %v", expected, config.DNS)
+	}
+}
+
+func TestCallReadWithGRPC(t *testing.T) {
+	tomlFile, err := os.Create("test.toml")
+	if err != nil {
+		t.Error("Create failed")
+	}
+	defer os.Remove(tomlFile.Name())
+
+	_, err = tomlFile.Write([]byte("[grpc]\nenabled = true\nip = \"127.0.0.1\"\nport = 48324"))
+	if err != nil {
+		t.Error("Write failed")
+	}
+
+	config = Config{}
+	if err = config.Read(tomlFile.Name()); err != nil {
+		t.Fatalf("Read returned an error: %s", err.Error())
+	}
+
+	expected := grpc.Config{Enabled: true, Ip: "127.0.0.1", Port: 48324}
+	if config.GRPC != expected {
+		t.Errorf("Expected GRPC: %v, Actual: %v", expected, config.GRPC)
+	}
+}
+
+func TestCallReadWithCoAP(t *testing.T) {
+	tomlFile, err := os.Create("test.toml")
+	if err != nil {
+		t.Error("Create failed")
+	}
+	defer os.Remove(tomlFile.Name())
+
+	_, err = tomlFile.Write([]byte("[coap]\nenabled = true\nport = 5683"))
+	if err != nil {
+		t.Error("Write failed")
+	}
+
+	config = Config{}
+	if err = config.Read(tomlFile.Name()); err != nil {
+		t.Fatalf("Read returned an error: %s", err.Error())
+	}
+
+	expected := coap.Config{Enabled: true, Port: 5683}
+	if config.CoAP != expected {
+		t.Errorf("Expected CoAP: %v, Actual: %v", expected, config.CoAP)
+	}
+}
+
+func TestCallReadWithCluster(t *testing.T) {
+	tomlFile, err := os.Create("test.toml")
+	if err != nil {
+		t.Error("Create failed")
+	}
+	defer os.Remove(tomlFile.Name())
+
+	_, err = tomlFile.Write([]byte("[cluster]\nenabled = true\nnodeId = \"node1\"\ndataDir = \"/var/lib/tns\"\n" +
+		"[[cluster.nodes]]\nid = \"node1\"\naddress = \"127.0.0.1:48330\"\napiAddress = \"127.0.0.1:48323\"\n" +
+		"[[cluster.nodes]]\nid = \"node2\"\naddress = \"127.0.0.1:48331\"\napiAddress = \"127.0.0.1:48324\""))
+	if err != nil {
+		t.Error("Write failed")
+	}
+
+	config = Config{}
+	if err = config.Read(tomlFile.Name()); err != nil {
+		t.Fatalf("Read returned an error: %s", err.Error())
+	}
+	defer func() { config = Config{} }()
+
+	expected := topicDB.ClusterConfig{
+		Enabled: true,
+		NodeId:  "node1",
+		DataDir: "/var/lib/tns",
+		Nodes: []topicDB.ClusterNode{
+			{Id: "node1", Address: "127.0.0.1:48330", ApiAddress: "127.0.0.1:48323"},
+			{Id: "node2", Address: "127.0.0.1:48331", ApiAddress: "127.0.0.1:48324"},
+		},
+	}
+	if !reflect.DeepEqual(config.Cluster, expected) {
+		t.Errorf("Expected Cluster: %v, Actual: %v", expected, config.Cluster)
+	}
+}
+
+func TestCallReadWithFederation(t *testing.T) {
+	tomlFile, err := os.Create("test.toml")
+	if err != nil {
+		t.Error("Create failed")
+	}
+	defer os.Remove(tomlFile.Name())
+
+	_, err = tomlFile.Write([]byte("[federation]\nenabled = true\nupstream = \"plant:48323\"\ncacheTtl = 10\nmaxHops = 2\n" +
+		"[[federation.downstreams]]\nprefix = \"/plant/cell1\"\naddress = \"cell1:48323\""))
+	if err != nil {
+		t.Error("Write failed")
+	}
+
+	config = Config{}
+	if err = config.Read(tomlFile.Name()); err != nil {
+		t.Fatalf("Read returned an error: %s", err.Error())
+	}
+	defer func() { config = Config{} }()
+
+	expected := federationController.Config{
+		Enabled:     true,
+		Upstream:    "plant:48323",
+		Downstreams: []federationController.Downstream{{Prefix: "/plant/cell1", Address: "cell1:48323"}},
+		CacheTtl:    10,
+		MaxHops:     2,
+	}
+	if !reflect.DeepEqual(config.Federation, expected) {
+		t.Errorf("Expected Federation: %v, Actual: %v", expected, config.Federation)
+	}
+}
+
+func TestCallReadWithRelay(t *testing.T) {
+	tomlFile, err := os.Create("test.toml")
+	if err != nil {
+		t.Error("Create failed")
+	}
+	defer os.Remove(tomlFile.Name())
+
+	_, err = tomlFile.Write([]byte("[relay]\nenabled = true\nupstream = \"central:48323\"\nkeepAliveInterval = 120\ntimeout = 5"))
+	if err != nil {
+		t.Error("Write failed")
+	}
+
+	config = Config{}
+	if err = config.Read(tomlFile.Name()); err != nil {
+		t.Fatalf("Read returned an error: %s", err.Error())
+	}
+	defer func() { config = Config{} }()
+
+	expected := relayController.Config{Enabled: true, Upstream: "central:48323", KeepAliveInterval: 120, Timeout: 5}
+	if config.Relay != expected {
+		t.Errorf("Expected Relay: %v, Actual: %v", expected, config.Relay)
+	}
+}
+
 func TestCallRead_OpenFailed(t *testing.T) {
 	config = Config{}
 	err := config.Read("nonExistsFile")
@@ -133,3 +510,351 @@ func TestCallRead_DecodeFailed(t *testing.T) {
 		t.Error("Read did not return an error")
 	}
 }
+
+func TestServeHTTPWithMemoryDB(t *testing.T) {
+	// Real handlers, controllers and in-memory DB are used for this test
+	topicHandler = topic.RequestHandler{}
+	keepAliveHandler = keepalive.RequestHandler{}
+
+	if err := topicDbExecutor.Connect(topicDB.Config{Type: topicDB.MEMORY_DB}); err != nil {
+		t.Fatalf("Connect returned an error: %s", err.Error())
+	}
+	defer topicDbExecutor.Close()
+
+	if err := keepaliveExecutor.InitKeepAlive(keepaliveController.Config{Interval: 600}); err != nil {
+		t.Fatalf("InitKeepAlive returned an error: %s", err.Error())
+	}
+
+	topicBody := `{"topic":{"name":"/a/b","endpoint":"0.0.0.0:1234","datamodel":"test_0.0.1"}}`
+
+	testSteps := []struct {
+		name         string
+		method       string
+		url          string
+		body         string
+		expectedCode int
+	}{
+		{"Register", "POST", "/api/v1/tns/topic", topicBody, http.StatusCreated},
+		{"Reregister", "POST", "/api/v1/tns/topic", topicBody, http.StatusOK},
+		{"Register_Conflict", "POST", "/api/v1/tns/topic", strings.NewReplacer("1234", "9999", "0.0.1", "0.0.2").Replace(topicBody), http.StatusConflict},
+		{"Discover", "GET", "/api/v1/tns/topic?name=/a/b", "", http.StatusOK},
+		{"Discover_Hierarchical", "GET", "/api/v1/tns/topic?name=/a&hierarchical=yes", "", http.StatusOK},
+		{"Discover_Wildcard", "GET", "/api/v1/tns/topic?name=/%2B/b", "", http.StatusOK},
+		{"Label", "PATCH", "/api/v1/tns/topic", `{"topic":{"name":"/a/b","labels":{"site":"plant3"}}}`, http.StatusOK},
+		{"Discover_Selector", "GET", "/api/v1/tns/topic?selector=site%3Dplant3", "", http.StatusOK},
+		{"Discover_SelectorNotFound", "GET", "/api/v1/tns/topic?name=/a/b&selector=site!%3Dplant3", "", http.StatusNotFound},
+		{"Discover_InvalidSelector", "GET", "/api/v1/tns/topic?selector=site%20in%20(plant3", "", http.StatusBadRequest},
+		{"KeepAlive", "POST", "/api/v1/tns/keepalive", `{"topic_names":["/a/b"]}`, http.StatusOK},
+		{"Discover_Liveness", "GET", "/api/v1/tns/topic?name=/a/b&liveness=yes", "", http.StatusOK},
+		{"Discover_ExpiringWithin", "GET", "/api/v1/tns/topic?expiring_within=3600", "", http.StatusOK},
+		{"Discover_ExpiringWithinNotFound", "GET", "/api/v1/tns/topic?expiring_within=60", "", http.StatusNotFound},
+		{"Join", "POST", "/api/v1/tns/topic", strings.Replace(topicBody, "1234", "5678", 1), http.StatusCreated},
+		{"KeepAlive_Publisher", "POST", "/api/v1/tns/keepalive", `{"topic_names":["/a/b"],"endpoint":"0.0.0.0:5678"}`, http.StatusOK},
+		{"Leave", "DELETE", "/api/v1/tns/topic?name=/a/b&endpoint=0.0.0.0:5678", "", http.StatusOK},
+		{"KeepAlive_PublisherNotFound", "POST", "/api/v1/tns/keepalive", `{"topic_names":["/a/b"],"endpoint":"0.0.0.0:5678"}`, http.StatusNotFound},
+		{"Discover_AfterLeave", "GET", "/api/v1/tns/topic?name=/a/b", "", http.StatusOK},
+		{"Unregister", "DELETE", "/api/v1/tns/topic?name=/a/b", "", http.StatusOK},
+		{"Unregister_NotFound", "DELETE", "/api/v1/tns/topic?name=/a/b", "", http.StatusNotFound},
+		{"Discover_NotFound", "GET", "/api/v1/tns/topic?name=/a/b", "", http.StatusNotFound},
+		{"KeepAlive_NotFound", "POST", "/api/v1/tns/keepalive", `{"topic_names":["/a/b"]}`, http.StatusNotFound},
+	}
+
+	for _, step := range testSteps {
+		req := httptest.NewRequest(step.method, step.url, strings.NewReader(step.body))
+		w := httptest.NewRecorder()
+
+		Handler.ServeHTTP(w, req)
+
+		if w.Code != step.expectedCode {
+			t.Errorf("%s: Expected Code: %s, Actual: %s", step.name, http.StatusText(step.expectedCode), http.StatusText(w.Code))
+		}
+	}
+}
+
+func TestServeHTTPWithDatamodelValidation(t *testing.T) {
+	// Real handlers, controllers and in-memory DB are used for this test
+	topicHandler = topic.RequestHandler{}
+	datamodelHandler = datamodel.RequestHandler{}
+
+	if err := topicDbExecutor.Connect(topicDB.Config{Type: topicDB.MEMORY_DB}); err != nil {
+		t.Fatalf("Connect returned an error: %s", err.Error())
+	}
+	defer topicDbExecutor.Close()
+
+	if err := keepaliveExecutor.InitKeepAlive(keepaliveController.Config{Interval: 600}); err != nil {
+		t.Fatalf("InitKeepAlive returned an error: %s", err.Error())
+	}
+
+	topicExecutor.SetDatamodelValidation(true)
+	defer topicExecutor.SetDatamodelValidation(false)
+
+	datamodelBody := `{"datamodel":{"name":"test","version":"0.0.1","format":"jsonschema","schema":"{\"type\":\"object\"}"}}`
+	topicBody := `{"topic":{"name":"/a/b","endpoint":"0.0.0.0:1234","datamodel":"test_0.0.1"}}`
+
+	testSteps := []struct {
+		name         string
+		method       string
+		url          string
+		body         string
+		expectedCode int
+	}{
+		{"Register_UnknownDatamodel", "POST", "/api/v1/tns/topic", topicBody, http.StatusBadRequest},
+		{"RegisterDatamodel", "POST", "/api/v1/tns/datamodel", datamodelBody, http.StatusCreated},
+		{"RegisterDatamodel_Conflict", "POST", "/api/v1/tns/datamodel", datamodelBody, http.StatusConflict},
+		{"Register", "POST", "/api/v1/tns/topic", topicBody, http.StatusCreated},
+		{"Update_UnknownDatamodel", "PATCH", "/api/v1/tns/topic", `{"topic":{"name":"/a/b","datamodel":"test_0.0.2"}}`, http.StatusBadRequest},
+		{"DiscoverDatamodel", "GET", "/api/v1/tns/datamodel?id=test_0.0.1", "", http.StatusOK},
+		{"DiscoverDatamodel_Name", "GET", "/api/v1/tns/datamodel?name=test", "", http.StatusOK},
+		{"DeleteDatamodel", "DELETE", "/api/v1/tns/datamodel?id=test_0.0.1", "", http.StatusOK},
+		{"DiscoverDatamodel_NotFound", "GET", "/api/v1/tns/datamodel?id=test_0.0.1", "", http.StatusNotFound},
+		{"Unregister", "DELETE", "/api/v1/tns/topic?name=/a/b", "", http.StatusOK},
+	}
+
+	for _, step := range testSteps {
+		req := httptest.NewRequest(step.method, step.url, strings.NewReader(step.body))
+		w := httptest.NewRecorder()
+
+		Handler.ServeHTTP(w, req)
+
+		if w.Code != step.expectedCode {
+			t.Errorf("%s: Expected Code: %s, Actual: %s", step.name, http.StatusText(step.expectedCode), http.StatusText(w.Code))
+		}
+	}
+}
+
+func TestServeHTTPWithLease(t *testing.T) {
+	// Real handlers, controllers and in-memory DB are used for this test
+	topicHandler = topic.RequestHandler{}
+	keepAliveHandler = keepalive.RequestHandler{}
+	leaseHandler = lease.RequestHandler{}
+
+	if err := topicDbExecutor.Connect(topicDB.Config{Type: topicDB.MEMORY_DB}); err != nil {
+		t.Fatalf("Connect returned an error: %s", err.Error())
+	}
+	defer topicDbExecutor.Close()
+
+	if err := keepaliveExecutor.InitKeepAlive(keepaliveController.Config{Interval: 600}); err != nil {
+		t.Fatalf("InitKeepAlive returned an error: %s", err.Error())
+	}
+
+	req := httptest.NewRequest("POST", "/api/v1/tns/lease", strings.NewReader(`{"ttl":600}`))
+	w := httptest.NewRecorder()
+	Handler.ServeHTTP(w, req)
+
+	granted := make(map[string]interface{})
+	if w.Code != http.StatusCreated || json.Unmarshal(w.Body.Bytes(), &granted) != nil {
+		t.Fatalf("Grant failed: %s %s", http.StatusText(w.Code), w.Body.String())
+	}
+	id, _ := granted["lease_id"].(string)
+
+	topicBody := `{"topic":{"name":"/a/b","endpoint":"0.0.0.0:1234","datamodel":"test_0.0.1","lease_id":"` + id + `"}}`
+
+	testSteps := []struct {
+		name         string
+		method       string
+		url          string
+		body         string
+		expectedCode int
+		eventually   bool // Publishers are removed from DB in the background
+	}{
+		{"Register", "POST", "/api/v1/tns/topic", topicBody, http.StatusCreated, false},
+		{"Register_Another", "POST", "/api/v1/tns/topic", strings.Replace(topicBody, "/a/b", "/a/c", 1), http.StatusCreated, false},
+		{"Register_UnknownLease", "POST", "/api/v1/tns/topic", strings.Replace(topicBody, id, "unknown", 1), http.StatusBadRequest, false},
+		{"ReadLease", "GET", "/api/v1/tns/lease?id=" + id, "", http.StatusOK, false},
+		{"Renew", "POST", "/api/v1/tns/keepalive", `{"lease_id":"` + id + `"}`, http.StatusOK, false},
+		{"Renew_WithTopicNames", "POST", "/api/v1/tns/keepalive", `{"lease_id":"` + id + `","topic_names":["/a/b"]}`, http.StatusBadRequest, false},
+		{"Discover", "GET", "/api/v1/tns/topic?name=/a/%2B", "", http.StatusOK, false},
+		{"Revoke", "DELETE", "/api/v1/tns/lease?id=" + id, "", http.StatusOK, false},
+		{"Discover_AfterRevoke", "GET", "/api/v1/tns/topic?name=/a/%2B", "", http.StatusNotFound, true},
+		{"Renew_NotFound", "POST", "/api/v1/tns/keepalive", `{"lease_id":"` + id + `"}`, http.StatusNotFound, false},
+		{"Revoke_NotFound", "DELETE", "/api/v1/tns/lease?id=" + id, "", http.StatusNotFound, false},
+	}
+
+	for _, step := range testSteps {
+		var w *httptest.ResponseRecorder
+		for retry := 0; retry < 100; retry++ {
+			req := httptest.NewRequest(step.method, step.url, strings.NewReader(step.body))
+			w = httptest.NewRecorder()
+
+			Handler.ServeHTTP(w, req)
+
+			if !step.eventually || w.Code == step.expectedCode {
+				break
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+
+		if w.Code != step.expectedCode {
+			t.Errorf("%s: Expected Code: %s, Actual: %s", step.name, http.StatusText(step.expectedCode), http.StatusText(w.Code))
+		}
+	}
+}
+
+func TestServeHTTPWithWatch(t *testing.T) {
+	// Real handlers, controllers and in-memory DB are used for this test
+	topicHandler = topic.RequestHandler{}
+	watchHandler = watch.RequestHandler{}
+
+	if err := topicDbExecutor.Connect(topicDB.Config{Type: topicDB.MEMORY_DB}); err != nil {
+		t.Fatalf("Connect returned an error: %s", err.Error())
+	}
+	defer topicDbExecutor.Close()
+
+	if err := keepaliveExecutor.InitKeepAlive(keepaliveController.Config{Interval: 600}); err != nil {
+		t.Fatalf("InitKeepAlive returned an error: %s", err.Error())
+	}
+
+	server := httptest.NewServer(&Handler)
+	defer server.Close()
+
+	watchStream := func(query string, lastEventID string) (*http.Response, *bufio.Reader) {
+		req, _ := http.NewRequest("GET", server.URL+"/api/v1/tns/watch"+query, nil)
+		if lastEventID != "" {
+			req.Header.Set("Last-Event-ID", lastEventID)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("Watch failed: %s", err.Error())
+		}
+		if resp.StatusCode != http.StatusOK || resp.Header.Get("Content-Type") != "text/event-stream" {
+			t.Fatalf("Watch failed: %s %s", resp.Status, resp.Header.Get("Content-Type"))
+		}
+		return resp, bufio.NewReader(resp.Body)
+	}
+
+	// readEvent returns the ID and type of the next event, skipping comments
+	readEvent := func(stream *bufio.Reader) (string, string) {
+		id, eventType := "", ""
+		for {
+			line, err := stream.ReadString('\n')
+			if err != nil {
+				t.Fatalf("ReadString failed: %s", err.Error())
+			}
+			switch {
+			case strings.HasPrefix(line, "id: "):
+				id = strings.TrimSpace(line[len("id: "):])
+			case strings.HasPrefix(line, "event: "):
+				eventType = strings.TrimSpace(line[len("event: "):])
+			case line == "\n" && eventType != "":
+				return id, eventType
+			}
+		}
+	}
+
+	request := func(method string, url string, body string) {
+		req := httptest.NewRequest(method, url, strings.NewReader(body))
+		w := httptest.NewRecorder()
+		Handler.ServeHTTP(w, req)
+	}
+
+	resp, stream := watchStream("?name=/a&hierarchical=yes", "")
+
+	topicBody := `{"topic":{"name":"/a/b","endpoint":"0.0.0.0:1234","datamodel":"test_0.0.1"}}`
+	request("POST", "/api/v1/tns/topic", strings.Replace(topicBody, "/a/b", "/x", 1)) // Not matched
+	request("POST", "/api/v1/tns/topic", topicBody)
+	request("PATCH", "/api/v1/tns/topic", `{"topic":{"name":"/a/b","labels":{"site":"plant3"}}}`)
+
+	created, eventType := readEvent(stream)
+	if eventType != "created" {
+		t.Errorf("Expected Event: created, Actual: %s", eventType)
+	}
+	if _, eventType = readEvent(stream); eventType != "updated" {
+		t.Errorf("Expected Event: updated, Actual: %s", eventType)
+	}
+	resp.Body.Close()
+
+	request("DELETE", "/api/v1/tns/topic?name=/a/b", "")
+
+	// Resume after the created event
+	resp, stream = watchStream("?name=/a&hierarchical=yes", created)
+	defer resp.Body.Close()
+
+	for _, expected := range []string{"updated", "deleted"} {
+		if _, eventType = readEvent(stream); eventType != expected {
+			t.Errorf("Expected Event: %s, Actual: %s", expected, eventType)
+		}
+	}
+
+	resp, stream = watchStream("", "unknown-1")
+	defer resp.Body.Close()
+	if _, eventType = readEvent(stream); eventType != "reset" {
+		t.Errorf("Expected Event: reset, Actual: %s", eventType)
+	}
+}
+
+func TestServeHTTPWithWebhook(t *testing.T) {
+	// Real handlers, controllers and in-memory DB are used for this test
+	topicHandler = topic.RequestHandler{}
+	webhookHandler = webhook.RequestHandler{}
+
+	if err := topicDbExecutor.Connect(topicDB.Config{Type: topicDB.MEMORY_DB}); err != nil {
+		t.Fatalf("Connect returned an error: %s", err.Error())
+	}
+	defer topicDbExecutor.Close()
+
+	if err := keepaliveExecutor.InitKeepAlive(keepaliveController.Config{Interval: 600}); err != nil {
+		t.Fatalf("InitKeepAlive returned an error: %s", err.Error())
+	}
+	if err := webhookExecutor.InitWebhook(); err != nil {
+		t.Fatalf("InitWebhook returned an error: %s", err.Error())
+	}
+
+	type notification struct {
+		event     string
+		signature string
+		body      []byte
+	}
+	notifications := make(chan notification, 8)
+
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		body, _ := ioutil.ReadAll(req.Body)
+		notifications <- notification{req.Header.Get("X-TNS-Event"), req.Header.Get("X-TNS-Signature"), body}
+	}))
+	defer receiver.Close()
+
+	request := func(method string, url string, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, url, strings.NewReader(body))
+		w := httptest.NewRecorder()
+		Handler.ServeHTTP(w, req)
+		return w
+	}
+
+	w := request("POST", "/api/v1/tns/webhook", `{"webhook":{"url":"`+receiver.URL+`","name":"/a","hierarchical":true,"events":["created","deleted"],"secret":"secret"}}`)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("Expected Code: %s, Actual: %s", http.StatusText(http.StatusCreated), http.StatusText(w.Code))
+	}
+	var registered map[string]map[string]interface{}
+	json.Unmarshal(w.Body.Bytes(), &registered)
+	id, _ := registered["webhook"]["id"].(string)
+	defer request("DELETE", "/api/v1/tns/webhook?id="+id, "")
+
+	topicBody := `{"topic":{"name":"/a/b","endpoint":"0.0.0.0:1234","datamodel":"test_0.0.1"}}`
+	request("POST", "/api/v1/tns/topic", strings.Replace(topicBody, "/a/b", "/x", 1)) // Not matched
+	request("POST", "/api/v1/tns/topic", topicBody)
+	request("PATCH", "/api/v1/tns/topic", `{"topic":{"name":"/a/b","labels":{"site":"plant3"}}}`) // Not subscribed
+	request("DELETE", "/api/v1/tns/topic?name=/a/b", "")
+
+	for _, expected := range []string{"created", "deleted"} {
+		select {
+		case n := <-notifications:
+			if n.event != expected {
+				t.Errorf("Expected Event: %s, Actual: %s", expected, n.event)
+			}
+			mac := hmac.New(sha256.New, []byte("secret"))
+			mac.Write(n.body)
+			if signature := "sha256=" + hex.EncodeToString(mac.Sum(nil)); n.signature != signature {
+				t.Errorf("Expected Signature: %s, Actual: %s", signature, n.signature)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("Expected Event: %s, but not notified", expected)
+		}
+	}
+
+	w = request("GET", "/api/v1/tns/webhook?id="+id, "")
+	if w.Code != http.StatusOK {
+		t.Errorf("Expected Code: %s, Actual: %s", http.StatusText(http.StatusOK), http.StatusText(w.Code))
+	}
+	if strings.Contains(w.Body.String(), "secret") {
+		t.Errorf("Expected the secret not to be returned: %s", w.Body.String())
+	}
+}
@@ -19,10 +19,12 @@ package topic
 
 import (
 	"net/http"
+	"strconv"
 	"strings"
 	"tns/api/common"
 	"tns/commons/errors"
 	"tns/commons/logger"
+	federationController "tns/controller/federation"
 	topicController "tns/controller/topic"
 )
 
@@ -49,6 +51,10 @@ func (RequestHandler) Handle(w http.ResponseWriter, req *http.Request) {
 	switch req.Method {
 	case http.MethodPost:
 		handlePostReq(w, req)
+	case http.MethodPut:
+		handleUpdateReq(w, req, false)
+	case http.MethodPatch:
+		handleUpdateReq(w, req, true)
 	case http.MethodGet:
 		handleGetReq(w, req)
 	case http.MethodDelete:
@@ -71,15 +77,42 @@ func handlePostReq(w http.ResponseWriter, req *http.Request) {
 		return
 	}
 
-	resp, err := topicExecutor.CreateTopic(body)
+	resp, created, err := topicExecutor.CreateTopic(body)
 	if err != nil {
 		common.WriteError(w, err)
 		return
 	}
 
+	// Registered again by the same publisher
+	if !created {
+		common.WriteResponse(w, http.StatusOK, common.MapToJsonByte(resp))
+		return
+	}
+
 	common.WriteResponse(w, http.StatusCreated, common.MapToJsonByte(resp))
 }
 
+// handleUpdateReq handles PUT (full replacement) and PATCH (partial update) requests.
+func handleUpdateReq(w http.ResponseWriter, req *http.Request, partial bool) {
+	logger.Logging(logger.DEBUG, "IN")
+	defer logger.Logging(logger.DEBUG, "OUT")
+
+	body, err := common.GetBodyFromReq(req)
+	if err != nil {
+		logger.Logging(logger.DEBUG, "GetBodyFromReq failed")
+		common.WriteError(w, err)
+		return
+	}
+
+	resp, err := topicExecutor.UpdateTopic(body, partial)
+	if err != nil {
+		common.WriteError(w, err)
+		return
+	}
+
+	common.WriteResponse(w, http.StatusOK, common.MapToJsonByte(resp))
+}
+
 func handleGetReq(w http.ResponseWriter, req *http.Request) {
 	logger.Logging(logger.DEBUG, "IN")
 	defer logger.Logging(logger.DEBUG, "OUT")
@@ -87,6 +120,9 @@ func handleGetReq(w http.ResponseWriter, req *http.Request) {
 	// Parse query
 	name := ""
 	hierarchical := false // false is default
+	selector := ""        // All topics if empty
+	liveness := false
+	expiringWithin := -1 // All topics if negative
 
 	for field, values := range req.URL.Query() {
 		if len(values) != 1 {
@@ -106,6 +142,24 @@ func handleGetReq(w http.ResponseWriter, req *http.Request) {
 				common.WriteError(w, errors.InvalidQuery{field})
 				return
 			}
+		case "selector":
+			selector = values[0]
+		case "liveness":
+			if values[0] == "yes" {
+				liveness = true
+			} else if values[0] == "no" {
+				liveness = false
+			} else {
+				common.WriteError(w, errors.InvalidQuery{field})
+				return
+			}
+		case "expiring_within":
+			seconds, err := strconv.ParseUint(values[0], 10, 31)
+			if err != nil {
+				common.WriteError(w, errors.InvalidQuery{field})
+				return
+			}
+			expiringWithin = int(seconds)
 		default:
 			logger.Logging(logger.DEBUG, "Invalid query: "+field)
 			common.WriteError(w, errors.InvalidQuery{field})
@@ -113,7 +167,25 @@ func handleGetReq(w http.ResponseWriter, req *http.Request) {
 		}
 	}
 
-	resp, err := topicExecutor.ReadTopic(name, hierarchical)
+	// The keep-alive state tells why the topics expire soon
+	if expiringWithin >= 0 {
+		liveness = true
+	}
+
+	var resp map[string]interface{}
+	var err error
+
+	// Lookups forwarded by the federated servers
+	if value := req.Header.Get(federationController.HOPS_HEADER); value != "" {
+		hops, parseErr := strconv.ParseUint(value, 10, 8)
+		if parseErr != nil || liveness {
+			common.WriteError(w, errors.InvalidParam{"invalid " + federationController.HOPS_HEADER})
+			return
+		}
+		resp, err = topicExecutor.ReadTopicWithHops(name, hierarchical, selector, uint(hops))
+	} else {
+		resp, err = topicExecutor.ReadTopic(name, hierarchical, selector, liveness, expiringWithin)
+	}
 	if err != nil {
 		common.WriteError(w, err)
 		return
@@ -128,6 +200,7 @@ func handleDeleteReq(w http.ResponseWriter, req *http.Request) {
 
 	// Parse query
 	name := ""
+	endpoint := "" // All publishers if empty
 
 	for field, values := range req.URL.Query() {
 		if len(values) != 1 { // No any array type value so far
@@ -138,6 +211,8 @@ func handleDeleteReq(w http.ResponseWriter, req *http.Request) {
 		switch field {
 		case "name":
 			name = values[0]
+		case "endpoint":
+			endpoint = values[0]
 		default:
 			logger.Logging(logger.DEBUG, "Invalid query: "+field)
 			common.WriteError(w, errors.InvalidQuery{field})
@@ -145,7 +220,7 @@ func handleDeleteReq(w http.ResponseWriter, req *http.Request) {
 		}
 	}
 
-	err := topicExecutor.DeleteTopic(name)
+	err := topicExecutor.DeleteTopic(name, endpoint)
 	if err != nil {
 		common.WriteError(w, err)
 		return
@@ -23,6 +23,7 @@ import (
 	"github.com/golang/mock/gomock"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"testing"
 	"tns/commons/errors"
 	topicControllerMock "tns/controller/topic/mocks"
@@ -54,8 +55,10 @@ func TestCallHandleWithInvalidRequest(t *testing.T) {
 		expectedCode int
 	}{
 		{"InvalidUrl", "POST", topicUrl + "/invalid", http.StatusNotFound},
-		{"InvalidMethod_Put", "PUT", topicUrl, http.StatusBadRequest},
+		{"InvalidMethod_Options", "OPTIONS", topicUrl, http.StatusBadRequest},
 		{"EmptyParameter_Post", "POST", topicUrl, http.StatusBadRequest},
+		{"EmptyParameter_Put", "PUT", topicUrl, http.StatusBadRequest},
+		{"EmptyParameter_Patch", "PATCH", topicUrl, http.StatusBadRequest},
 		{"InvalidQuery_Get_MultiValue", "GET", topicUrl + "?name=a&name=b", http.StatusBadRequest},
 		{"InvalidQuery_Get_InvalidValue", "GET", topicUrl + "?hierarchical=invalid", http.StatusBadRequest},
 		{"InvalidQuery_Get_InvalidQuery", "GET", topicUrl + "?key=value", http.StatusBadRequest},
@@ -90,22 +93,34 @@ func TestCallHandlePost(t *testing.T) {
 	expectedResp := map[string]interface{}{"ka_interval": 200}
 	expectedRespByte, _ := json.Marshal(expectedResp)
 
-	gomock.InOrder(
-		topicCtrlrMockObj.EXPECT().CreateTopic(testBodyString).Return(expectedResp, nil),
-	)
+	testCases := []struct {
+		name         string
+		mockCreated  bool
+		expectedCode int
+	}{
+		{"Created", true, http.StatusCreated},
+		{"Reregistered", false, http.StatusOK},
+	}
 
-	body, _ := json.Marshal(testBody)
-	req := httptest.NewRequest("POST", topicUrl, bytes.NewReader(body))
-	w := httptest.NewRecorder()
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			gomock.InOrder(
+				topicCtrlrMockObj.EXPECT().CreateTopic(testBodyString).Return(expectedResp, tc.mockCreated, nil),
+			)
 
-	Handler.Handle(w, req)
+			body, _ := json.Marshal(testBody)
+			req := httptest.NewRequest("POST", topicUrl, bytes.NewReader(body))
+			w := httptest.NewRecorder()
 
-	expectedCode := http.StatusCreated
-	if w.Code != expectedCode {
-		t.Errorf("Expected Code: %s, Actual: %s", http.StatusText(expectedCode), http.StatusText(w.Code))
-	}
-	if 0 != bytes.Compare(w.Body.Bytes(), expectedRespByte) {
-		t.Errorf("Expected body: %s, Actual: %s", body, w.Body.Bytes())
+			Handler.Handle(w, req)
+
+			if w.Code != tc.expectedCode {
+				t.Errorf("Expected Code: %s, Actual: %s", http.StatusText(tc.expectedCode), http.StatusText(w.Code))
+			}
+			if 0 != bytes.Compare(w.Body.Bytes(), expectedRespByte) {
+				t.Errorf("Expected body: %s, Actual: %s", body, w.Body.Bytes())
+			}
+		})
 	}
 }
 
@@ -131,7 +146,7 @@ func TestCallHandlePostWithInvalidPayload(t *testing.T) {
 	topicExecutor = topicCtrlrMockObj
 
 	gomock.InOrder(
-		topicCtrlrMockObj.EXPECT().CreateTopic(testBodyString).Return(nil, errors.InvalidParam{}),
+		topicCtrlrMockObj.EXPECT().CreateTopic(testBodyString).Return(nil, false, errors.InvalidParam{}),
 	)
 
 	body, _ := json.Marshal(testBody)
@@ -146,6 +161,75 @@ func TestCallHandlePostWithInvalidPayload(t *testing.T) {
 	}
 }
 
+func TestCallHandleUpdate(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	topicCtrlrMockObj := topicControllerMock.NewMockCommand(ctrl)
+
+	// pass mockObj to a real object.
+	topicExecutor = topicCtrlrMockObj
+
+	expectedResp := map[string]interface{}{"topic": map[string]interface{}{"name": "/a", "endpoint": "0.0.0.0:1234", "datamodel": "test_0.0.1", "secured": false, "revision": 2}}
+	expectedRespByte, _ := json.Marshal(expectedResp)
+
+	testCases := []struct {
+		name    string
+		method  string
+		partial bool
+	}{
+		{"Put", "PUT", false},
+		{"Patch", "PATCH", true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			gomock.InOrder(
+				topicCtrlrMockObj.EXPECT().UpdateTopic(testBodyString, tc.partial).Return(expectedResp, nil),
+			)
+
+			body, _ := json.Marshal(testBody)
+			req := httptest.NewRequest(tc.method, topicUrl, bytes.NewReader(body))
+			w := httptest.NewRecorder()
+
+			Handler.Handle(w, req)
+
+			expectedCode := http.StatusOK
+			if w.Code != expectedCode {
+				t.Errorf("Expected Code: %s, Actual: %s", http.StatusText(expectedCode), http.StatusText(w.Code))
+			}
+			if 0 != bytes.Compare(w.Body.Bytes(), expectedRespByte) {
+				t.Errorf("Expected body: %s, Actual: %s", expectedRespByte, w.Body.Bytes())
+			}
+		})
+	}
+}
+
+func TestCallHandleUpdateWithConflict(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	topicCtrlrMockObj := topicControllerMock.NewMockCommand(ctrl)
+
+	// pass mockObj to a real object.
+	topicExecutor = topicCtrlrMockObj
+
+	gomock.InOrder(
+		topicCtrlrMockObj.EXPECT().UpdateTopic(testBodyString, true).Return(nil, errors.Conflict{}),
+	)
+
+	body, _ := json.Marshal(testBody)
+	req := httptest.NewRequest("PATCH", topicUrl, bytes.NewReader(body))
+	w := httptest.NewRecorder()
+
+	Handler.Handle(w, req)
+
+	expectedCode := http.StatusConflict
+	if w.Code != expectedCode {
+		t.Errorf("Expected Code: %s, Actual: %s", http.StatusText(expectedCode), http.StatusText(w.Code))
+	}
+}
+
 func TestCallHandleGet(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -160,12 +244,13 @@ func TestCallHandleGet(t *testing.T) {
 
 	name := "/a"
 	hierarchical := "yes"
+	selector := "site=plant3,line in (1,2)"
 
 	gomock.InOrder(
-		topicCtrlrMockObj.EXPECT().ReadTopic(name, true).Return(expectedResp, nil),
+		topicCtrlrMockObj.EXPECT().ReadTopic(name, true, selector, false, -1).Return(expectedResp, nil),
 	)
 
-	req := httptest.NewRequest("GET", topicUrl+"?name="+name+"&hierarchical="+hierarchical, nil)
+	req := httptest.NewRequest("GET", topicUrl+"?name="+name+"&hierarchical="+hierarchical+"&selector="+url.QueryEscape(selector), nil)
 	w := httptest.NewRecorder()
 
 	Handler.Handle(w, req)
@@ -180,6 +265,91 @@ func TestCallHandleGet(t *testing.T) {
 	}
 }
 
+func TestCallHandleGetWithLiveness(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	topicCtrlrMockObj := topicControllerMock.NewMockCommand(ctrl)
+
+	// pass mockObj to a real object.
+	topicExecutor = topicCtrlrMockObj
+
+	testCases := []struct {
+		name           string
+		query          string
+		liveness       bool
+		expiringWithin int
+		expectedCode   int
+	}{
+		{"Liveness", "?liveness=yes", true, -1, http.StatusOK},
+		{"Liveness_No", "?liveness=no", false, -1, http.StatusOK},
+		{"ExpiringWithin", "?expiring_within=30", true, 30, http.StatusOK},
+		{"ExpiringWithin_Zero", "?liveness=no&expiring_within=0", true, 0, http.StatusOK},
+		{"InvalidQuery_Liveness", "?liveness=true", false, -1, http.StatusBadRequest},
+		{"InvalidQuery_ExpiringWithin", "?expiring_within=-1", false, -1, http.StatusBadRequest},
+		{"InvalidQuery_ExpiringWithin_NotNumber", "?expiring_within=soon", false, -1, http.StatusBadRequest},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// mock will be called only for the valid queries.
+			if tc.expectedCode == http.StatusOK {
+				topicCtrlrMockObj.EXPECT().ReadTopic("", false, "", tc.liveness, tc.expiringWithin).Return(map[string]interface{}{}, nil)
+			}
+
+			req := httptest.NewRequest("GET", topicUrl+tc.query, nil)
+			w := httptest.NewRecorder()
+
+			Handler.Handle(w, req)
+
+			if w.Code != tc.expectedCode {
+				t.Errorf("Expected Code: %s, Actual: %s", http.StatusText(tc.expectedCode), http.StatusText(w.Code))
+			}
+		})
+	}
+}
+
+func TestCallHandleGetWithHops(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	topicCtrlrMockObj := topicControllerMock.NewMockCommand(ctrl)
+
+	// pass mockObj to a real object.
+	topicExecutor = topicCtrlrMockObj
+
+	testCases := []struct {
+		name         string
+		query        string
+		hops         string
+		expectedCode int
+	}{
+		{"Success", "?name=/a&hierarchical=yes", "1", http.StatusOK},
+		{"InvalidHops", "?name=/a", "one", http.StatusBadRequest},
+		{"InvalidHops_Negative", "?name=/a", "-1", http.StatusBadRequest},
+		{"InvalidQuery_Liveness", "?name=/a&liveness=yes", "1", http.StatusBadRequest},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// mock will be called only for the valid queries.
+			if tc.expectedCode == http.StatusOK {
+				topicCtrlrMockObj.EXPECT().ReadTopicWithHops("/a", true, "", uint(1)).Return(map[string]interface{}{}, nil)
+			}
+
+			req := httptest.NewRequest("GET", topicUrl+tc.query, nil)
+			req.Header.Set("X-TNS-Hops", tc.hops)
+			w := httptest.NewRecorder()
+
+			Handler.Handle(w, req)
+
+			if w.Code != tc.expectedCode {
+				t.Errorf("Expected Code: %s, Actual: %s", http.StatusText(tc.expectedCode), http.StatusText(w.Code))
+			}
+		})
+	}
+}
+
 func TestCallHandleGetWithNonExistTopic(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -193,7 +363,7 @@ func TestCallHandleGetWithNonExistTopic(t *testing.T) {
 	hierarchical := "no"
 
 	gomock.InOrder(
-		topicCtrlrMockObj.EXPECT().ReadTopic(name, false).Return(nil, errors.NotFound{}),
+		topicCtrlrMockObj.EXPECT().ReadTopic(name, false, "", false, -1).Return(nil, errors.NotFound{}),
 	)
 
 	req := httptest.NewRequest("GET", topicUrl+"?name="+name+"&hierarchical="+hierarchical, nil)
@@ -219,7 +389,7 @@ func TestCallHandleDelete(t *testing.T) {
 	name := "/a"
 
 	gomock.InOrder(
-		topicCtrlrMockObj.EXPECT().DeleteTopic(name).Return(nil),
+		topicCtrlrMockObj.EXPECT().DeleteTopic(name, "").Return(nil),
 	)
 
 	req := httptest.NewRequest("DELETE", topicUrl+"?name="+name, nil)
@@ -233,6 +403,33 @@ func TestCallHandleDelete(t *testing.T) {
 	}
 }
 
+func TestCallHandleDeleteWithEndpoint(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	topicCtrlrMockObj := topicControllerMock.NewMockCommand(ctrl)
+
+	// pass mockObj to a real object.
+	topicExecutor = topicCtrlrMockObj
+
+	name := "/a"
+	endpoint := "0.0.0.0:1234"
+
+	gomock.InOrder(
+		topicCtrlrMockObj.EXPECT().DeleteTopic(name, endpoint).Return(nil),
+	)
+
+	req := httptest.NewRequest("DELETE", topicUrl+"?name="+name+"&endpoint="+endpoint, nil)
+	w := httptest.NewRecorder()
+
+	Handler.Handle(w, req)
+
+	expectedCode := http.StatusOK
+	if w.Code != expectedCode {
+		t.Errorf("Expected Code: %s, Actual: %s", http.StatusText(expectedCode), http.StatusText(w.Code))
+	}
+}
+
 func TestCallHandleDeleteWithNonExistTopic(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -245,7 +442,7 @@ func TestCallHandleDeleteWithNonExistTopic(t *testing.T) {
 	name := "/a"
 
 	gomock.InOrder(
-		topicCtrlrMockObj.EXPECT().DeleteTopic(name).Return(errors.NotFound{}),
+		topicCtrlrMockObj.EXPECT().DeleteTopic(name, "").Return(errors.NotFound{}),
 	)
 
 	req := httptest.NewRequest("DELETE", topicUrl+"?name="+name, nil)
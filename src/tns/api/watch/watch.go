@@ -0,0 +1,148 @@
+/*******************************************************************************
+ * Copyright 2018 Samsung Electronics All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ *******************************************************************************/
+package watch
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+	"tns/api/common"
+	"tns/commons/errors"
+	"tns/commons/logger"
+	watchController "tns/controller/watch"
+)
+
+type Command interface {
+	Handle(w http.ResponseWriter, req *http.Request)
+}
+
+type RequestHandler struct{}
+
+var watchExecutor watchController.Command
+
+// Comments are sent at this interval while there is no event,
+// so that idle connections are not closed by proxies.
+var heartbeatInterval = 15 * time.Second
+
+func init() {
+	watchExecutor = watchController.Executor{}
+}
+
+// Handle streams the changes of topics as Server-Sent Events.
+func (RequestHandler) Handle(w http.ResponseWriter, req *http.Request) {
+	// Check URL
+	url := strings.TrimPrefix(req.URL.Path, "/api/v1"+"/tns/watch")
+	if len(url) != 0 {
+		common.WriteError(w, errors.NotFoundURL{url})
+		return
+	}
+
+	switch req.Method {
+	case http.MethodGet:
+		handleGetReq(w, req)
+	default:
+		logger.Logging(logger.DEBUG, "Invalid Method")
+		common.WriteError(w, errors.InvalidMethod{req.Method})
+		return
+	}
+}
+
+func handleGetReq(w http.ResponseWriter, req *http.Request) {
+	logger.Logging(logger.DEBUG, "IN")
+	defer logger.Logging(logger.DEBUG, "OUT")
+
+	// Parse query
+	name := ""            // All topics if empty
+	hierarchical := false // false is default
+	lastEventID := ""
+
+	for field, values := range req.URL.Query() {
+		if len(values) != 1 {
+			common.WriteError(w, errors.InvalidQuery{field}) // No any array type value so far
+			return
+		}
+
+		switch field {
+		case "name":
+			name = values[0]
+		case "hierarchical":
+			if values[0] == "yes" {
+				hierarchical = true
+			} else if values[0] == "no" {
+				hierarchical = false
+			} else {
+				common.WriteError(w, errors.InvalidQuery{field})
+				return
+			}
+		case "last_event_id":
+			lastEventID = values[0]
+		default:
+			logger.Logging(logger.DEBUG, "Invalid query: "+field)
+			common.WriteError(w, errors.InvalidQuery{field})
+			return
+		}
+	}
+
+	// Sent by EventSource on reconnection
+	if id := req.Header.Get("Last-Event-ID"); id != "" {
+		lastEventID = id
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		common.WriteError(w, errors.InternalServerError{"streaming is not supported"})
+		return
+	}
+
+	subscription, err := watchExecutor.Subscribe(name, hierarchical, lastEventID)
+	if err != nil {
+		common.WriteError(w, err)
+		return
+	}
+	defer watchExecutor.Unsubscribe(subscription)
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	heartbeat := time.NewTicker(heartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case event, ok := <-subscription.Events:
+			if !ok {
+				// Dropped, the client resumes after reconnection
+				return
+			}
+			writeEvent(w, event)
+		case <-heartbeat.C:
+			fmt.Fprint(w, ": heartbeat\n\n")
+		case <-req.Context().Done():
+			return
+		}
+		flusher.Flush()
+	}
+}
+
+// writeEvent writes the event in the format of Server-Sent Events.
+func writeEvent(w http.ResponseWriter, event watchController.Event) {
+	data := common.MapToJsonByte(map[string]interface{}{"topic": event.Topic})
+	fmt.Fprintf(w, "id: %s\nevent: %s\ndata: %s\n\n", event.ID, event.Type, data)
+}
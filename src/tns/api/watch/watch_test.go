@@ -0,0 +1,143 @@
+/*******************************************************************************
+ * Copyright 2018 Samsung Electronics All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ *******************************************************************************/
+package watch
+
+import (
+	"github.com/golang/mock/gomock"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"tns/commons/errors"
+	watchController "tns/controller/watch"
+	watchControllerMock "tns/controller/watch/mocks"
+)
+
+const watchUrl = "/api/v1/tns/watch"
+
+var Handler Command
+
+func init() {
+	Handler = RequestHandler{}
+}
+
+func TestCallHandleWithInvalidRequest(t *testing.T) {
+	// Mock is not necessary for this test
+
+	testCases := []struct {
+		name         string
+		method       string
+		url          string
+		expectedCode int
+	}{
+		{"InvalidUrl", "GET", watchUrl + "/invalid", http.StatusNotFound},
+		{"InvalidMethod_Post", "POST", watchUrl, http.StatusBadRequest},
+		{"InvalidQuery_Hierarchical", "GET", watchUrl + "?name=/a&hierarchical=true", http.StatusBadRequest},
+		{"InvalidQuery_MultiValue", "GET", watchUrl + "?name=/a&name=/b", http.StatusBadRequest},
+		{"InvalidQuery_Unknown", "GET", watchUrl + "?key=value", http.StatusBadRequest},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(tc.method, tc.url, nil)
+			w := httptest.NewRecorder()
+
+			Handler.Handle(w, req)
+
+			if w.Code != tc.expectedCode {
+				t.Errorf("Expected Code: %s, Actual: %s", http.StatusText(tc.expectedCode), http.StatusText(w.Code))
+			}
+		})
+	}
+}
+
+func TestCallHandleGet(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	watchCtrlrMockObj := watchControllerMock.NewMockCommand(ctrl)
+
+	// pass mockObj to a real object.
+	watchExecutor = watchCtrlrMockObj
+
+	testCases := []struct {
+		name                string
+		query               string
+		lastEventIDHeader   string
+		expectedLastEventID string
+	}{
+		{"Success", "?name=/a&hierarchical=yes", "", ""},
+		{"Success_LastEventIDQuery", "?name=/a&hierarchical=yes&last_event_id=x-1", "", "x-1"},
+		{"Success_LastEventIDHeader", "?name=/a&hierarchical=yes&last_event_id=x-1", "x-2", "x-2"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Closed after the pending event, as if the subscriber is dropped
+			events := make(chan watchController.Event, 1)
+			events <- watchController.Event{ID: "x-3", Type: watchController.EVENT_CREATED, Topic: map[string]interface{}{"name": "/a"}}
+			close(events)
+			subscription := &watchController.Subscription{Events: events}
+
+			gomock.InOrder(
+				watchCtrlrMockObj.EXPECT().Subscribe("/a", true, tc.expectedLastEventID).Return(subscription, nil),
+				watchCtrlrMockObj.EXPECT().Unsubscribe(subscription),
+			)
+
+			req := httptest.NewRequest("GET", watchUrl+tc.query, nil)
+			if tc.lastEventIDHeader != "" {
+				req.Header.Set("Last-Event-ID", tc.lastEventIDHeader)
+			}
+			w := httptest.NewRecorder()
+
+			Handler.Handle(w, req)
+
+			if w.Code != http.StatusOK {
+				t.Errorf("Expected Code: %s, Actual: %s", http.StatusText(http.StatusOK), http.StatusText(w.Code))
+			}
+			if contentType := w.Header().Get("Content-Type"); contentType != "text/event-stream" {
+				t.Errorf("Expected Content-Type: text/event-stream, Actual: %s", contentType)
+			}
+			expectedBody := "id: x-3\nevent: created\ndata: {\"topic\":{\"name\":\"/a\"}}\n\n"
+			if w.Body.String() != expectedBody {
+				t.Errorf("Expected body: %q, Actual: %q", expectedBody, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestCallHandleGetWithInvalidFilter(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	watchCtrlrMockObj := watchControllerMock.NewMockCommand(ctrl)
+
+	// pass mockObj to a real object.
+	watchExecutor = watchCtrlrMockObj
+
+	gomock.InOrder(
+		watchCtrlrMockObj.EXPECT().Subscribe("/a/#/b", false, "").Return(nil, errors.InvalidQuery{}),
+	)
+
+	req := httptest.NewRequest("GET", watchUrl+"?name=/a/%23/b", nil)
+	w := httptest.NewRecorder()
+
+	Handler.Handle(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected Code: %s, Actual: %s", http.StatusText(http.StatusBadRequest), http.StatusText(w.Code))
+	}
+}
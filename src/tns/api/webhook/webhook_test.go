@@ -0,0 +1,196 @@
+/*******************************************************************************
+ * Copyright 2018 Samsung Electronics All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ *******************************************************************************/
+package webhook
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/golang/mock/gomock"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"tns/commons/errors"
+	webhookControllerMock "tns/controller/webhook/mocks"
+)
+
+const webhookUrl = "/api/v1/tns/webhook"
+
+var testBodyString = `{"webhook":{"events":["created"],"name":"/a","url":"http://127.0.0.1:8080/hook"}}`
+
+var Handler Command
+
+func init() {
+	Handler = RequestHandler{}
+}
+
+func TestCallHandleWithInvalidRequest(t *testing.T) {
+	// Mock is not necessary for this test
+
+	testCases := []struct {
+		name         string
+		method       string
+		url          string
+		expectedCode int
+	}{
+		{"InvalidUrl", "POST", webhookUrl + "/invalid", http.StatusNotFound},
+		{"InvalidMethod_Put", "PUT", webhookUrl, http.StatusBadRequest},
+		{"EmptyParameter_Post", "POST", webhookUrl, http.StatusBadRequest},
+		{"InvalidQuery_Get_MultiValue", "GET", webhookUrl + "?id=a&id=b", http.StatusBadRequest},
+		{"InvalidQuery_Get_InvalidQuery", "GET", webhookUrl + "?key=value", http.StatusBadRequest},
+		{"InvalidQuery_Delete_NoId", "DELETE", webhookUrl, http.StatusBadRequest},
+		{"InvalidQuery_Delete_InvalidQuery", "DELETE", webhookUrl + "?name=/a", http.StatusBadRequest},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(tc.method, tc.url, nil)
+			w := httptest.NewRecorder()
+
+			Handler.Handle(w, req)
+
+			if w.Code != tc.expectedCode {
+				t.Errorf("Expected Code: %s, Actual: %s", http.StatusText(tc.expectedCode), http.StatusText(w.Code))
+			}
+		})
+	}
+}
+
+func TestCallHandlePost(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	webhookCtrlrMockObj := webhookControllerMock.NewMockCommand(ctrl)
+
+	// pass mockObj to a real object.
+	webhookExecutor = webhookCtrlrMockObj
+
+	expectedResp := map[string]interface{}{"webhook": map[string]interface{}{"id": "0123456789abcdef", "secret": "secret"}}
+	expectedRespByte, _ := json.Marshal(expectedResp)
+
+	testCases := []struct {
+		name         string
+		mockRetResp  map[string]interface{}
+		mockRetError error
+		expectedCode int
+	}{
+		{"Success", expectedResp, nil, http.StatusCreated},
+		{"InvalidParam", nil, errors.InvalidParam{}, http.StatusBadRequest},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			gomock.InOrder(
+				webhookCtrlrMockObj.EXPECT().CreateWebhook(testBodyString).Return(tc.mockRetResp, tc.mockRetError),
+			)
+
+			req := httptest.NewRequest("POST", webhookUrl, strings.NewReader(testBodyString))
+			w := httptest.NewRecorder()
+
+			Handler.Handle(w, req)
+
+			if w.Code != tc.expectedCode {
+				t.Errorf("Expected Code: %s, Actual: %s", http.StatusText(tc.expectedCode), http.StatusText(w.Code))
+			}
+			if tc.mockRetError == nil && 0 != bytes.Compare(w.Body.Bytes(), expectedRespByte) {
+				t.Errorf("Expected body: %s, Actual: %s", expectedRespByte, w.Body.Bytes())
+			}
+		})
+	}
+}
+
+func TestCallHandleGet(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	webhookCtrlrMockObj := webhookControllerMock.NewMockCommand(ctrl)
+
+	// pass mockObj to a real object.
+	webhookExecutor = webhookCtrlrMockObj
+
+	expectedResp := map[string]interface{}{"webhooks": []map[string]interface{}{{"id": "0123456789abcdef", "url": "http://127.0.0.1:8080/hook"}}}
+	expectedRespByte, _ := json.Marshal(expectedResp)
+
+	testCases := []struct {
+		name         string
+		query        string
+		id           string
+		mockRetResp  map[string]interface{}
+		mockRetError error
+		expectedCode int
+	}{
+		{"Success_Id", "?id=0123456789abcdef", "0123456789abcdef", expectedResp, nil, http.StatusOK},
+		{"Success_All", "", "", expectedResp, nil, http.StatusOK},
+		{"NotFound", "?id=unknown", "unknown", nil, errors.NotFound{}, http.StatusNotFound},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			gomock.InOrder(
+				webhookCtrlrMockObj.EXPECT().ReadWebhook(tc.id).Return(tc.mockRetResp, tc.mockRetError),
+			)
+
+			req := httptest.NewRequest("GET", webhookUrl+tc.query, nil)
+			w := httptest.NewRecorder()
+
+			Handler.Handle(w, req)
+
+			if w.Code != tc.expectedCode {
+				t.Errorf("Expected Code: %s, Actual: %s", http.StatusText(tc.expectedCode), http.StatusText(w.Code))
+			}
+			if tc.mockRetError == nil && 0 != bytes.Compare(w.Body.Bytes(), expectedRespByte) {
+				t.Errorf("Expected body: %s, Actual: %s", expectedRespByte, w.Body.Bytes())
+			}
+		})
+	}
+}
+
+func TestCallHandleDelete(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	webhookCtrlrMockObj := webhookControllerMock.NewMockCommand(ctrl)
+
+	// pass mockObj to a real object.
+	webhookExecutor = webhookCtrlrMockObj
+
+	testCases := []struct {
+		name         string
+		mockRetError error
+		expectedCode int
+	}{
+		{"Success", nil, http.StatusOK},
+		{"NotFound", errors.NotFound{}, http.StatusNotFound},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			gomock.InOrder(
+				webhookCtrlrMockObj.EXPECT().DeleteWebhook("0123456789abcdef").Return(tc.mockRetError),
+			)
+
+			req := httptest.NewRequest("DELETE", webhookUrl+"?id=0123456789abcdef", nil)
+			w := httptest.NewRecorder()
+
+			Handler.Handle(w, req)
+
+			if w.Code != tc.expectedCode {
+				t.Errorf("Expected Code: %s, Actual: %s", http.StatusText(tc.expectedCode), http.StatusText(w.Code))
+			}
+		})
+	}
+}
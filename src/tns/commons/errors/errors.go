@@ -117,6 +117,18 @@ func (e Conflict) Error() string {
 	return "conflict: " + e.Message
 }
 
+// Struct Unavailable will be used for return case of error
+// which the request can not be served by this server at the moment,
+// e.g., writes on a follower of the cluster.
+type Unavailable struct {
+	Message string
+}
+
+// Error sets an error message of Unavailable.
+func (e Unavailable) Error() string {
+	return "service unavailable: " + e.Message
+}
+
 // // Struct DBConnectionError will be used for return case of error
 // // which connection failed with db server.
 // type DBConnectionError struct {
@@ -53,6 +53,8 @@ func TestTError(t *testing.T) {
 			testError: &InternalServerError{msg}},
 		{testName: "Conflict", testPrefix: "conflict",
 			testError: &Conflict{msg}},
+		{testName: "Unavailable", testPrefix: "service unavailable",
+			testError: &Unavailable{msg}},
 	}
 
 	testFunc := func(err commonsError, prefix string) {
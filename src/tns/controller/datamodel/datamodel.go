@@ -0,0 +1,114 @@
+/*******************************************************************************
+ * Copyright 2018 Samsung Electronics All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ *******************************************************************************/
+
+package datamodel
+
+import (
+	"tns/commons/errors"
+	"tns/commons/logger"
+	"tns/commons/util"
+	topicDB "tns/db/topic"
+)
+
+type Command interface {
+	CreateDatamodel(body string) error
+	ReadDatamodel(id string, name string) (map[string]interface{}, error)
+	DeleteDatamodel(id string) error
+}
+
+// Executor implements the Command interface.
+type Executor struct{}
+
+var topicDbExecutor topicDB.Command
+
+func init() {
+	topicDbExecutor = topicDB.Executor{}
+}
+
+func (Executor) CreateDatamodel(body string) error {
+	logger.Logging(logger.DEBUG, "IN")
+	defer logger.Logging(logger.DEBUG, "OUT")
+
+	bodyMap, err := util.ConvertJsonToMap(body)
+	if err != nil {
+		logger.Logging(logger.ERROR, "ConvertJsonToMap failed: "+err.Error())
+		return err
+	}
+
+	datamodel, exists := bodyMap["datamodel"].(map[string]interface{})
+	if !exists {
+		logger.Logging(logger.DEBUG, "'datamodel' does not present in body")
+		return errors.InvalidParam{"'datamodel' field is required"}
+	}
+
+	err = topicDbExecutor.CreateDatamodel(datamodel)
+	if err != nil {
+		logger.Logging(logger.DEBUG, "CreateDatamodel failed: "+err.Error())
+		return err
+	}
+
+	return nil
+}
+
+// ReadDatamodel returns the datamodel of the given ID. If id is empty, all versions
+// of the datamodel of the given name, or all datamodels if name is also empty, are returned.
+func (Executor) ReadDatamodel(id string, name string) (map[string]interface{}, error) {
+	logger.Logging(logger.DEBUG, "IN")
+	defer logger.Logging(logger.DEBUG, "OUT")
+
+	var datamodels []map[string]interface{}
+
+	if id != "" {
+		datamodel, err := topicDbExecutor.ReadDatamodel(id)
+		if err != nil {
+			return nil, err
+		}
+		datamodels = []map[string]interface{}{datamodel}
+	} else {
+		var err error
+		datamodels, err = topicDbExecutor.ReadDatamodelAll(name)
+		if err != nil {
+			return nil, err
+		} else if len(datamodels) == 0 {
+			logger.Logging(logger.DEBUG, "Nothing found")
+			if name == "" {
+				name = "datamodel is empty"
+			}
+			return nil, errors.NotFound{name}
+		}
+	}
+
+	resp := make(map[string]interface{})
+	resp["datamodels"] = datamodels
+
+	return resp, nil
+}
+
+// DeleteDatamodel removes the datamodel of the given ID.
+// Topics which have already been registered with it are not affected.
+func (Executor) DeleteDatamodel(id string) error {
+	logger.Logging(logger.DEBUG, "IN")
+	defer logger.Logging(logger.DEBUG, "OUT")
+
+	err := topicDbExecutor.DeleteDatamodel(id)
+	if err != nil {
+		logger.Logging(logger.DEBUG, "DeleteDatamodel failed: "+err.Error())
+		return err
+	}
+
+	return nil
+}
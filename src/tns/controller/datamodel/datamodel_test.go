@@ -0,0 +1,149 @@
+/*******************************************************************************
+ * Copyright 2018 Samsung Electronics All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ *******************************************************************************/
+
+package datamodel
+
+import (
+	"github.com/golang/mock/gomock"
+	"reflect"
+	"testing"
+	"tns/commons/errors"
+	topicDbMock "tns/db/topic/mocks"
+)
+
+var Handler Command
+
+func init() {
+	Handler = Executor{}
+}
+
+func TestCallCreateDatamodel(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	topicDbMockObj := topicDbMock.NewMockCommand(ctrl)
+
+	// pass mockObj to a real object.
+	topicDbExecutor = topicDbMockObj
+
+	dummyBodyString := `{"datamodel":{"name":"GTC_Robot","version":"0.0.1","format":"jsonschema","schema":"{}"}}`
+	dummyDatamodel := map[string]interface{}{"name": "GTC_Robot", "version": "0.0.1", "format": "jsonschema", "schema": "{}"}
+
+	testCases := []struct {
+		name            string
+		dummyBodyString string
+		callDb          bool
+		mockRetError    error
+		expectedError   error
+	}{
+		{"Success", dummyBodyString, true, nil, nil},
+		{"DbFailed", dummyBodyString, true, errors.Conflict{}, errors.Conflict{}},
+		{"InvalidJson", "{", false, nil, errors.InvalidJSON{}},
+		{"NoDatamodel", `{"topic":{}}`, false, nil, errors.InvalidParam{}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if tc.callDb {
+				topicDbMockObj.EXPECT().CreateDatamodel(dummyDatamodel).Return(tc.mockRetError)
+			}
+
+			err := Handler.CreateDatamodel(tc.dummyBodyString)
+			if reflect.TypeOf(err) != reflect.TypeOf(tc.expectedError) {
+				t.Errorf("Expected Error: %s, Actual: %s", tc.expectedError, err)
+			}
+		})
+	}
+}
+
+func TestCallReadDatamodel(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	topicDbMockObj := topicDbMock.NewMockCommand(ctrl)
+
+	// pass mockObj to a real object.
+	topicDbExecutor = topicDbMockObj
+
+	datamodel := map[string]interface{}{"id": "GTC_Robot_0.0.1"}
+	successResp := map[string]interface{}{"datamodels": []map[string]interface{}{datamodel}}
+
+	testCases := []struct {
+		name          string
+		id            string
+		datamodelName string
+		mockRetError  error
+		expectedResp  map[string]interface{}
+		expectedError error
+	}{
+		{"Success_Id", "GTC_Robot_0.0.1", "", nil, successResp, nil},
+		{"Success_Name", "", "GTC_Robot", nil, successResp, nil},
+		{"NotFound_Id", "GTC_Robot_0.0.2", "", errors.NotFound{}, nil, errors.NotFound{}},
+		{"NotFound_Name", "", "Unknown", nil, nil, errors.NotFound{}},
+		{"DbFailed", "", "", errors.InternalServerError{}, nil, errors.InternalServerError{}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if tc.id != "" {
+				topicDbMockObj.EXPECT().ReadDatamodel(tc.id).Return(datamodel, tc.mockRetError)
+			} else if tc.expectedResp != nil {
+				topicDbMockObj.EXPECT().ReadDatamodelAll(tc.datamodelName).Return([]map[string]interface{}{datamodel}, tc.mockRetError)
+			} else {
+				topicDbMockObj.EXPECT().ReadDatamodelAll(tc.datamodelName).Return([]map[string]interface{}{}, tc.mockRetError)
+			}
+
+			resp, err := Handler.ReadDatamodel(tc.id, tc.datamodelName)
+			if reflect.TypeOf(err) != reflect.TypeOf(tc.expectedError) {
+				t.Errorf("Expected Error: %s, Actual: %s", tc.expectedError, err)
+			}
+			if !reflect.DeepEqual(resp, tc.expectedResp) {
+				t.Errorf("Expected Resp: %v, Actual: %v", tc.expectedResp, resp)
+			}
+		})
+	}
+}
+
+func TestCallDeleteDatamodel(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	topicDbMockObj := topicDbMock.NewMockCommand(ctrl)
+
+	// pass mockObj to a real object.
+	topicDbExecutor = topicDbMockObj
+
+	testCases := []struct {
+		name          string
+		mockRetError  error
+		expectedError error
+	}{
+		{"Success", nil, nil},
+		{"NotFound", errors.NotFound{}, errors.NotFound{}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			topicDbMockObj.EXPECT().DeleteDatamodel("GTC_Robot_0.0.1").Return(tc.mockRetError)
+
+			err := Handler.DeleteDatamodel("GTC_Robot_0.0.1")
+			if reflect.TypeOf(err) != reflect.TypeOf(tc.expectedError) {
+				t.Errorf("Expected Error: %s, Actual: %s", tc.expectedError, err)
+			}
+		})
+	}
+}
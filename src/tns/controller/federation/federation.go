@@ -0,0 +1,316 @@
+/*******************************************************************************
+ * Copyright 2018 Samsung Electronics All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ *******************************************************************************/
+package federation
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"tns/commons/errors"
+	"tns/commons/logger"
+	topicDB "tns/db/topic"
+)
+
+// Servers are federated in a hierarchy, e.g., a TNS server per factory cell
+// under a plant-level one. Lookups of names which are not found locally are
+// forwarded to the downstream servers delegated the prefix of the name, or to
+// the upstream server if none is delegated. The results are annotated with
+// the address of the server which has found them in 'source', and cached for
+// a while. Forwarded lookups carry the number of servers passed through in
+// HOPS_HEADER, which stops the lookups looping between servers.
+
+type Config struct {
+	Enabled     bool
+	Upstream    string       // "host:port" of REST APIs of the upstream server
+	Downstreams []Downstream // Servers delegated subtrees of topic names
+	CacheTtl    uint         // Seconds, DEFAULT_CACHE_TTL if 0
+	MaxHops     uint         // Servers a lookup passes through, DEFAULT_MAX_HOPS if 0
+	Timeout     uint         // Seconds to wait for each server, DEFAULT_TIMEOUT if 0
+}
+
+type Downstream struct {
+	Prefix  string // e.g., "/plant/cell1"
+	Address string // "host:port" of REST APIs
+}
+
+type Command interface {
+	InitFederation(config Config) error
+	Lookup(name string, hierarchical bool, selector string, hops uint) []map[string]interface{}
+}
+
+// Executor implements the Command interface.
+type Executor struct{}
+
+const (
+	HOPS_HEADER  = "X-TNS-Hops"
+	SOURCE_FIELD = "source"
+
+	DEFAULT_CACHE_TTL = 30 // Second
+	DEFAULT_MAX_HOPS  = 3
+	DEFAULT_TIMEOUT   = 5    // Second
+	MAX_CACHE_ENTRIES = 1024 // Lookups kept in the cache
+)
+
+// cacheEntry is the result of a lookup, which may be empty.
+type cacheEntry struct {
+	topics    []map[string]interface{}
+	expiresAt time.Time
+}
+
+type federationInfo struct {
+	sync.Mutex
+	config Config
+	client *http.Client
+	cache  map[string]cacheEntry // "name hierarchical selector":entry
+}
+
+var info federationInfo
+
+// InitFederation validates config and starts to forward lookups if it is enabled.
+// The cache is cleared.
+func (Executor) InitFederation(config Config) error {
+	logger.Logging(logger.DEBUG, "IN")
+	defer logger.Logging(logger.DEBUG, "OUT")
+
+	if config.Enabled {
+		for _, downstream := range config.Downstreams {
+			if !strings.HasPrefix(downstream.Prefix, topicDB.LEVEL_SEPARATOR) ||
+				strings.HasSuffix(downstream.Prefix, topicDB.LEVEL_SEPARATOR) ||
+				strings.ContainsAny(downstream.Prefix, topicDB.WILDCARD+topicDB.SINGLE_LEVEL_WILDCARD+topicDB.MULTI_LEVEL_WILDCARD) {
+				return errors.InvalidParam{"invalid prefix of downstream: " + downstream.Prefix}
+			}
+			if downstream.Address == "" {
+				return errors.InvalidParam{"address of downstream is required: " + downstream.Prefix}
+			}
+		}
+		if config.CacheTtl == 0 {
+			config.CacheTtl = DEFAULT_CACHE_TTL
+		}
+		if config.MaxHops == 0 {
+			config.MaxHops = DEFAULT_MAX_HOPS
+		}
+		if config.Timeout == 0 {
+			config.Timeout = DEFAULT_TIMEOUT
+		}
+	}
+
+	info.Lock()
+	defer info.Unlock()
+
+	info.config = config
+	info.client = &http.Client{Timeout: time.Duration(config.Timeout) * time.Second}
+	info.cache = make(map[string]cacheEntry)
+
+	return nil
+}
+
+// Lookup returns the topics matched by name on the federated servers, which
+// have been forwarded over hops servers so far. Servers which fail are skipped.
+// nil is returned if federation is disabled, or the lookup has passed too many servers.
+func (Executor) Lookup(name string, hierarchical bool, selector string, hops uint) []map[string]interface{} {
+	logger.Logging(logger.DEBUG, "IN")
+	defer logger.Logging(logger.DEBUG, "OUT")
+
+	info.Lock()
+	config, client := info.config, info.client
+	info.Unlock()
+
+	if !config.Enabled || name == "" {
+		return nil
+	}
+	if hops >= config.MaxHops {
+		logger.Logging(logger.DEBUG, "Too many hops: "+strconv.FormatUint(uint64(hops), 10))
+		return nil
+	}
+
+	key := name + " " + strconv.FormatBool(hierarchical) + " " + selector
+	now := time.Now()
+
+	info.Lock()
+	entry, exists := info.cache[key]
+	info.Unlock()
+	if exists && now.Before(entry.expiresAt) {
+		logger.Logging(logger.DEBUG, "Cached: "+key)
+		return copyTopics(entry.topics)
+	}
+
+	addresses := targets(config, name, hierarchical)
+	results := make([][]map[string]interface{}, len(addresses))
+
+	var wg sync.WaitGroup
+	for i, address := range addresses {
+		wg.Add(1)
+		go func(i int, address string) {
+			defer wg.Done()
+			topics, err := query(client, address, name, hierarchical, selector, hops+1)
+			if err != nil {
+				logger.Logging(logger.ERROR, "Lookup on "+address+" failed: "+err.Error())
+				return
+			}
+			results[i] = topics
+		}(i, address)
+	}
+	wg.Wait()
+
+	topics := make([]map[string]interface{}, 0)
+	for _, result := range results {
+		topics = append(topics, result...)
+	}
+
+	info.Lock()
+	remember(key, cacheEntry{topics: topics, expiresAt: now.Add(time.Duration(config.CacheTtl) * time.Second)}, now)
+	info.Unlock()
+
+	return copyTopics(topics)
+}
+
+// targets returns the addresses of the downstream servers delegated the names
+// matched by name, or of the upstream server if none is delegated.
+func targets(config Config, name string, hierarchical bool) []string {
+	// Every matched name starts with literal
+	literal := name
+	if i := strings.IndexAny(name, topicDB.WILDCARD+topicDB.SINGLE_LEVEL_WILDCARD+topicDB.MULTI_LEVEL_WILDCARD); i >= 0 {
+		literal = name[:i]
+	} else if hierarchical {
+		literal = name + topicDB.LEVEL_SEPARATOR
+	}
+
+	var addresses []string
+	for _, downstream := range config.Downstreams {
+		switch {
+		case name == downstream.Prefix || strings.HasPrefix(name, downstream.Prefix+topicDB.LEVEL_SEPARATOR):
+			// The name is in the delegated subtree
+		case literal != name && strings.HasPrefix(downstream.Prefix, literal):
+			// The delegated subtree may have matched names
+		default:
+			continue
+		}
+		addresses = append(addresses, downstream.Address)
+	}
+
+	if len(addresses) == 0 && config.Upstream != "" {
+		addresses = append(addresses, config.Upstream)
+	}
+
+	return addresses
+}
+
+// query looks up name on the server of address with the REST API, and returns
+// the found topics annotated with their source.
+func query(client *http.Client, address string, name string, hierarchical bool, selector string, hops uint) ([]map[string]interface{}, error) {
+	values := url.Values{}
+	values.Set("name", name)
+	if hierarchical {
+		values.Set("hierarchical", "yes")
+	}
+	if selector != "" {
+		values.Set("selector", selector)
+	}
+
+	req, err := http.NewRequest(http.MethodGet, "http://"+address+"/api/v1/tns/topic?"+values.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set(HOPS_HEADER, strconv.FormatUint(uint64(hops), 10))
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return nil, nil
+	default:
+		return nil, errors.Unknown{"unexpected status " + resp.Status}
+	}
+
+	var body struct {
+		Topics []map[string]interface{} `json:"topics"`
+	}
+	if err = json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, err
+	}
+
+	for _, topic := range body.Topics {
+		normalize(topic)
+		// Topics found further away keep their original source
+		if source, _ := topic[SOURCE_FIELD].(string); source == "" {
+			topic[SOURCE_FIELD] = address
+		}
+	}
+
+	return body.Topics, nil
+}
+
+// normalize converts the fields decoded from JSON into the types of the fields
+// of topics read from DB.
+func normalize(topic map[string]interface{}) {
+	if values, ok := topic["endpoints"].([]interface{}); ok {
+		endpoints := make([]string, 0, len(values))
+		for _, value := range values {
+			if endpoint, ok := value.(string); ok {
+				endpoints = append(endpoints, endpoint)
+			}
+		}
+		topic["endpoints"] = endpoints
+	}
+	if values, ok := topic["labels"].(map[string]interface{}); ok {
+		labels := make(map[string]string, len(values))
+		for key, value := range values {
+			labels[key], _ = value.(string)
+		}
+		topic["labels"] = labels
+	}
+}
+
+// remember keeps the entry, removing the expired ones if too many are kept.
+// It is called with info locked.
+func remember(key string, entry cacheEntry, now time.Time) {
+	if len(info.cache) >= MAX_CACHE_ENTRIES {
+		for k, old := range info.cache {
+			if !now.Before(old.expiresAt) {
+				delete(info.cache, k)
+			}
+		}
+	}
+	if len(info.cache) >= MAX_CACHE_ENTRIES {
+		logger.Logging(logger.DEBUG, "Too many cached lookups, cache is reset")
+		info.cache = make(map[string]cacheEntry)
+	}
+	info.cache[key] = entry
+}
+
+// copyTopics returns a copy of the topics, which can be modified by callers
+// without changing the cache.
+func copyTopics(topics []map[string]interface{}) []map[string]interface{} {
+	copied := make([]map[string]interface{}, 0, len(topics))
+	for _, topic := range topics {
+		c := make(map[string]interface{}, len(topic))
+		for key, value := range topic {
+			c[key] = value
+		}
+		copied = append(copied, c)
+	}
+	return copied
+}
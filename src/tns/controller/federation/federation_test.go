@@ -0,0 +1,244 @@
+/*******************************************************************************
+ * Copyright 2018 Samsung Electronics All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ *******************************************************************************/
+package federation
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+)
+
+var Handler Command
+
+func init() {
+	Handler = Executor{}
+}
+
+// serverForTest is a federated server which returns body with status to lookups,
+// and records the queries and hops of them.
+type serverForTest struct {
+	sync.Mutex
+	*httptest.Server
+	status  int
+	body    string
+	queries []string
+	hops    []string
+}
+
+func newServerForTest(status int, body string) *serverForTest {
+	s := &serverForTest{status: status, body: body}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		s.Lock()
+		s.queries = append(s.queries, req.URL.RawQuery)
+		s.hops = append(s.hops, req.Header.Get(HOPS_HEADER))
+		s.Unlock()
+		w.WriteHeader(s.status)
+		w.Write([]byte(s.body))
+	}))
+	return s
+}
+
+func (s *serverForTest) address() string {
+	return strings.TrimPrefix(s.URL, "http://")
+}
+
+func (s *serverForTest) requests() int {
+	s.Lock()
+	defer s.Unlock()
+	return len(s.queries)
+}
+
+func TestCallInitFederationWithInvalidConfig(t *testing.T) {
+	testCases := []struct {
+		name       string
+		downstream Downstream
+	}{
+		{"NoSeparator", Downstream{"plant/cell1", "cell1:48323"}},
+		{"TrailingSeparator", Downstream{"/plant/cell1/", "cell1:48323"}},
+		{"Wildcard", Downstream{"/plant/+", "cell1:48323"}},
+		{"NoAddress", Downstream{"/plant/cell1", ""}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := Handler.InitFederation(Config{Enabled: true, Downstreams: []Downstream{tc.downstream}})
+			if err == nil {
+				t.Error("InitFederation did not return an error")
+			}
+		})
+	}
+}
+
+func TestTargets(t *testing.T) {
+	config := Config{
+		Upstream: "plant:48323",
+		Downstreams: []Downstream{
+			{"/plant/cell1", "cell1:48323"},
+			{"/plant/cell2", "cell2:48323"},
+		},
+	}
+
+	testCases := []struct {
+		name         string
+		topicName    string
+		hierarchical bool
+		expected     []string
+	}{
+		{"Delegated", "/plant/cell1/robot", false, []string{"cell1:48323"}},
+		{"Delegated_Prefix", "/plant/cell2", false, []string{"cell2:48323"}},
+		{"Delegated_Hierarchical", "/plant", true, []string{"cell1:48323", "cell2:48323"}},
+		{"Delegated_Wildcard", "/plant/+/robot", false, []string{"cell1:48323", "cell2:48323"}},
+		{"Delegated_WildcardInLevel", "/plant/cell1*", false, []string{"cell1:48323"}},
+		{"Upstream_SimilarPrefix", "/plant/cell10", false, []string{"plant:48323"}},
+		{"Upstream_NotHierarchical", "/plant", false, []string{"plant:48323"}},
+		{"Upstream", "/office/printer", true, []string{"plant:48323"}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			addresses := targets(config, tc.topicName, tc.hierarchical)
+			if !reflect.DeepEqual(addresses, tc.expected) {
+				t.Errorf("Expected: %v, Actual: %v", tc.expected, addresses)
+			}
+		})
+	}
+
+	if addresses := targets(Config{}, "/a", false); len(addresses) != 0 {
+		t.Errorf("Unexpected targets without upstream: %v", addresses)
+	}
+}
+
+func TestCallLookup(t *testing.T) {
+	cell1 := newServerForTest(http.StatusOK,
+		`{"topics":[{"name":"/plant/cell1/robot","endpoints":["10.0.0.1:1883"],"labels":{"line":"1"},"status":"alive"}]}`)
+	defer cell1.Close()
+	cell2 := newServerForTest(http.StatusOK,
+		`{"topics":[{"name":"/plant/cell2/robot","endpoints":["10.0.0.2:1883"],"status":"stale","source":"line3:48323"}]}`)
+	defer cell2.Close()
+	cell3 := newServerForTest(http.StatusNotFound, `{"error":"not found"}`)
+	defer cell3.Close()
+	cell4 := newServerForTest(http.StatusInternalServerError, "")
+	defer cell4.Close()
+
+	err := Handler.InitFederation(Config{
+		Enabled: true,
+		Downstreams: []Downstream{
+			{"/plant/cell1", cell1.address()},
+			{"/plant/cell2", cell2.address()},
+			{"/plant/cell3", cell3.address()},
+			{"/plant/cell4", cell4.address()},
+		},
+	})
+	if err != nil {
+		t.Fatalf("InitFederation returned an error: %s", err.Error())
+	}
+	defer Handler.InitFederation(Config{})
+
+	expected := []map[string]interface{}{
+		{"name": "/plant/cell1/robot", "endpoints": []string{"10.0.0.1:1883"}, "labels": map[string]string{"line": "1"},
+			"status": "alive", "source": cell1.address()},
+		{"name": "/plant/cell2/robot", "endpoints": []string{"10.0.0.2:1883"}, "status": "stale", "source": "line3:48323"},
+	}
+
+	topics := Handler.Lookup("/plant/+/robot", false, "line=1", 1)
+	if !reflect.DeepEqual(topics, expected) {
+		t.Errorf("Expected: %v, Actual: %v", expected, topics)
+	}
+	for _, s := range []*serverForTest{cell1, cell2, cell3, cell4} {
+		if s.requests() != 1 {
+			t.Fatalf("Expected 1 request, Actual: %d", s.requests())
+		}
+		if s.hops[0] != "2" || s.queries[0] != "name=%2Fplant%2F%2B%2Frobot&selector=line%3D1" {
+			t.Errorf("Unexpected request: %s, hops: %s", s.queries[0], s.hops[0])
+		}
+	}
+
+	// Cached results are not changed by callers
+	topics[0]["status"] = "changed"
+	topics = Handler.Lookup("/plant/+/robot", false, "line=1", 1)
+	if !reflect.DeepEqual(topics, expected) {
+		t.Errorf("Expected: %v, Actual: %v", expected, topics)
+	}
+	if cell1.requests() != 1 {
+		t.Errorf("Cached lookup is forwarded")
+	}
+
+	// Nothing found is cached as well
+	if topics = Handler.Lookup("/plant/cell3/robot", false, "", 0); len(topics) != 0 {
+		t.Errorf("Unexpected topics: %v", topics)
+	}
+	Handler.Lookup("/plant/cell3/robot", false, "", 0)
+	if cell3.requests() != 2 {
+		t.Errorf("Expected 2 requests, Actual: %d", cell3.requests())
+	}
+
+	// Too many hops
+	if topics = Handler.Lookup("/plant/cell1/arm", false, "", DEFAULT_MAX_HOPS); topics != nil {
+		t.Errorf("Unexpected topics: %v", topics)
+	}
+	if cell1.requests() != 1 {
+		t.Errorf("Lookup is forwarded with too many hops")
+	}
+}
+
+func TestCallLookupWithUpstream(t *testing.T) {
+	upstream := newServerForTest(http.StatusOK, `{"topics":[{"name":"/office/printer","endpoints":["10.1.0.1:1883"]}]}`)
+	defer upstream.Close()
+
+	err := Handler.InitFederation(Config{Enabled: true, Upstream: upstream.address(), CacheTtl: 60, MaxHops: 1})
+	if err != nil {
+		t.Fatalf("InitFederation returned an error: %s", err.Error())
+	}
+	defer Handler.InitFederation(Config{})
+
+	expected := []map[string]interface{}{
+		{"name": "/office/printer", "endpoints": []string{"10.1.0.1:1883"}, "source": upstream.address()},
+	}
+
+	topics := Handler.Lookup("/office/printer", false, "", 0)
+	if !reflect.DeepEqual(topics, expected) {
+		t.Errorf("Expected: %v, Actual: %v", expected, topics)
+	}
+	if upstream.requests() != 1 || upstream.hops[0] != "1" {
+		t.Errorf("Unexpected requests: %v, hops: %v", upstream.queries, upstream.hops)
+	}
+
+	// Lookups from the other servers are not forwarded again
+	if topics = Handler.Lookup("/office/fax", false, "", 1); topics != nil {
+		t.Errorf("Unexpected topics: %v", topics)
+	}
+
+	// Empty name is not forwarded
+	if topics = Handler.Lookup("", false, "", 0); topics != nil {
+		t.Errorf("Unexpected topics: %v", topics)
+	}
+
+	if upstream.requests() != 1 {
+		t.Errorf("Expected 1 request, Actual: %d", upstream.requests())
+	}
+}
+
+func TestCallLookupWithoutFederation(t *testing.T) {
+	Handler.InitFederation(Config{})
+
+	if topics := Handler.Lookup("/a", true, "", 0); topics != nil {
+		t.Errorf("Unexpected topics: %v", topics)
+	}
+}
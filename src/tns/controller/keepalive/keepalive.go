@@ -22,90 +22,285 @@ import (
 	"tns/commons/errors"
 	"tns/commons/logger"
 	"tns/commons/util"
+	watchController "tns/controller/watch"
 	topicDB "tns/db/topic"
 )
 
 type Command interface {
-	InitKeepAlive(interval uint) error
-	AddTopic(name string)
+	InitKeepAlive(config Config) error
+	AddTopic(name string, endpoint string, interval uint)
 	DeleteTopic(name string)
+	DeletePublisher(name string, endpoint string)
+	SetEndpoints(name string, endpoints []string)
 	HandlePing(body string) (map[string]interface{}, error)
-	GetInterval() uint
+	GetInterval(name string) uint
+	GetStatus(name string) string
+	GetLiveness(name string) map[string]Liveness
+	GrantLease(body string) (map[string]interface{}, error)
+	ReadLease(id string) (map[string]interface{}, error)
+	RevokeLease(id string) error
+	AddTopicWithLease(name string, endpoint string, id string) error
 }
 
 // Executor implements the Command interface.
 type Executor struct{}
 
-type kaTableType map[string]time.Time // "topic":"timestamp"
+// Config holds the keep-alive settings of the [server] section in the configuration file.
+// Intervals are in seconds, and a topic expires when it has not sent keep-alive
+// for its interval.
+type Config struct {
+	Interval    uint // Default interval of topics
+	GracePeriod uint // Stale topics are kept for this period after their interval
+	MinInterval uint // Minimum interval that topics can request, Interval if 0
+	MaxInterval uint // Maximum interval that topics can request, Interval if 0
+
+	// If true, publishers are neither restored from DB nor expired, and keep-alive
+	// fails with Unavailable, e.g., on followers of the cluster, which should pass
+	// requests to the leader. It is left by InitKeepAlive without it.
+	Standby bool
+
+	// If true, leases are not granted, e.g., in the cluster, where they are
+	// kept by the leader only and would be lost on a change of the leader.
+	NoLease bool
+}
+
+// Liveness is the keep-alive state of a publisher.
+type Liveness struct {
+	LastSeen  time.Time // Time of the last keep-alive
+	ExpiresAt time.Time // Time when the publisher is removed without keep-alive
+}
+
+type kaTableType map[string]map[string]*kaEntry // "topic":{"endpoint":entry}
 
 type keepAliveInfo struct {
 	sync.Mutex
-	table    kaTableType
-	interval uint
+	table       kaTableType
+	expiry      kaHeap          // Entries of table by deadline
+	intervals   map[string]uint // Intervals requested by topics
+	leases      map[string]*kaLease
+	interval    uint
+	minInterval uint
+	maxInterval uint
+	gracePeriod time.Duration
+	wakeUp      chan struct{} // Notifies the timer loop of an earlier deadline
+	stop        chan struct{} // Closed to stop the timer loop and deletion worker
+	standby     bool
+	noLease     bool
 }
 
 const kaPingFrequency = 3
 
+// Status of topics in keep-alive.
+// A publisher which has missed its keep-alive interval becomes stale, and it is
+// removed only if it does not send keep-alive within the grace period.
+const (
+	STATUS_ALIVE = "alive"
+	STATUS_STALE = "stale"
+)
+
 var topicDbExecutor topicDB.Command
+var watchExecutor watchController.Command
 var kaInfo keepAliveInfo
 
 func init() {
 	topicDbExecutor = topicDB.Executor{}
+	watchExecutor = watchController.Executor{}
 }
 
-func (Executor) InitKeepAlive(interval uint) error {
+// InitKeepAlive starts to expire publishers which have not sent keep-alive
+// for the interval of their topics plus the grace period.
+// It can be called again, e.g., to leave the standby.
+func (Executor) InitKeepAlive(config Config) error {
 	logger.Logging(logger.DEBUG, "IN")
 	defer logger.Logging(logger.DEBUG, "OUT")
 
-	// Read Topics from DB
-	topics, err := topicDbExecutor.ReadTopicAll()
-	if err != nil {
-		logger.Logging(logger.ERROR, "ReadTopicAll failed")
-		return err
+	// Read last keep-alive times of Topics from DB
+	var lastSeen map[string]map[string]time.Time
+	var intervals map[string]uint
+	if !config.Standby {
+		var err error
+		lastSeen, err = topicDbExecutor.ReadLastSeenAll()
+		if err != nil {
+			logger.Logging(logger.ERROR, "ReadLastSeenAll failed")
+			return err
+		}
+
+		intervals, err = topicDbExecutor.ReadIntervalAll()
+		if err != nil {
+			logger.Logging(logger.ERROR, "ReadIntervalAll failed")
+			return err
+		}
+	}
+
+	kaInfo.Lock()
+	defer kaInfo.Unlock()
+
+	// Stop the timer loop and leases of the previous initialization
+	if kaInfo.stop != nil {
+		close(kaInfo.stop)
+	}
+	for _, lease := range kaInfo.leases {
+		lease.timer.Stop()
+	}
+	kaInfo.leases = make(map[string]*kaLease)
+
+	kaInfo.interval = config.Interval
+	kaInfo.minInterval = config.MinInterval
+	if kaInfo.minInterval == 0 {
+		kaInfo.minInterval = config.Interval
+	}
+	kaInfo.maxInterval = config.MaxInterval
+	if kaInfo.maxInterval == 0 {
+		kaInfo.maxInterval = config.Interval
+	}
+	kaInfo.gracePeriod = time.Duration(config.GracePeriod) * time.Second
+	kaInfo.standby = config.Standby
+	kaInfo.noLease = config.NoLease
+
+	kaInfo.intervals = make(map[string]uint, len(intervals))
+	for name, interval := range intervals {
+		kaInfo.intervals[name] = interval
 	}
 
 	// Init Keepalive Table
+	// Topics are restored with their persisted timestamps, so that topics
+	// whose publishers stopped before a restart are expired as usual.
 	logger.Logging(logger.DEBUG, "Initialize Keep-alive Table")
 	kaInfo.table = make(kaTableType)
+	kaInfo.expiry = kaHeap{}
+	kaInfo.wakeUp = make(chan struct{}, 1)
+	kaInfo.stop = make(chan struct{})
 	currTime := time.Now()
-	for _, topic := range topics {
-		logger.Logging(logger.DEBUG, topic["name"].(string))
-		kaInfo.table[topic["name"].(string)] = currTime
+	for name, publishers := range lastSeen {
+		logger.Logging(logger.DEBUG, name)
+		for endpoint, timestamp := range publishers {
+			if timestamp.IsZero() || timestamp.After(currTime) {
+				// Never recorded, or the clock went backwards
+				timestamp = currTime
+			}
+			setLastSeen(name, endpoint, timestamp)
+		}
 	}
 
-	kaInfo.interval = interval
+	if config.Standby {
+		logger.Logging(logger.DEBUG, "Keep-alive is on standby")
+		return nil
+	}
 
 	// Start Timer loop
-	go keepAliveTimerLoop(interval)
+	deletion := make(chan expiredPublisher, DELETION_QUEUE_SIZE)
+	go keepAliveTimerLoop(kaInfo.wakeUp, deletion, kaInfo.stop)
+	go deletionWorker(deletion, kaInfo.stop)
 
 	return nil
 }
 
-func (Executor) AddTopic(name string) {
+// AddTopic starts keep-alive of the publisher of the given endpoint.
+// If interval is not 0, it is the period of keep-alive requested by the publisher,
+// and the interval of the topic is changed accordingly within the configured range.
+func (Executor) AddTopic(name string, endpoint string, interval uint) {
 	currTime := time.Now()
 
 	kaInfo.Lock()
-	kaInfo.table[name] = currTime
+	if interval != 0 {
+		interval = clampInterval(interval * kaPingFrequency)
+		kaInfo.intervals[name] = interval
+		rescheduleTopic(name)
+	}
+	// Registered again without the lease
+	if entry, exists := kaInfo.table[name][endpoint]; exists {
+		detachLease(entry)
+	}
+	setLastSeen(name, endpoint, currTime)
 	kaInfo.Unlock()
 
-	logger.Logging(logger.DEBUG, "Topic added: "+name)
+	persistLastSeen([]string{name}, endpoint, currTime)
+	if interval != 0 {
+		if err := topicDbExecutor.UpdateInterval(name, interval); err != nil {
+			logger.Logging(logger.ERROR, "UpdateInterval failed: "+err.Error())
+		}
+	}
+
+	logger.Logging(logger.DEBUG, "Topic added: "+name+" "+endpoint)
 }
 
+// DeleteTopic stops keep-alive of all publishers of the topic.
 func (Executor) DeleteTopic(name string) {
 	kaInfo.Lock()
-	delete(kaInfo.table, name)
+	for _, entry := range kaInfo.table[name] {
+		removeEntry(entry)
+	}
 	kaInfo.Unlock()
 
 	logger.Logging(logger.DEBUG, "Topic deleted: "+name)
 }
 
+// DeletePublisher stops keep-alive of the publisher of the given endpoint.
+func (Executor) DeletePublisher(name string, endpoint string) {
+	kaInfo.Lock()
+	if entry, exists := kaInfo.table[name][endpoint]; exists {
+		removeEntry(entry)
+	}
+	kaInfo.Unlock()
+
+	logger.Logging(logger.DEBUG, "Publisher deleted: "+name+" "+endpoint)
+}
+
+// SetEndpoints replaces the publishers of the topic with the given endpoints.
+// Publishers which have already existed keep their timestamps.
+func (Executor) SetEndpoints(name string, endpoints []string) {
+	currTime := time.Now()
+
+	kept := make(map[string]bool, len(endpoints))
+	for _, endpoint := range endpoints {
+		kept[endpoint] = true
+	}
+
+	kaInfo.Lock()
+	for endpoint, entry := range kaInfo.table[name] {
+		if !kept[endpoint] {
+			removeEntry(entry)
+		}
+	}
+	for _, endpoint := range endpoints {
+		if _, exists := kaInfo.table[name][endpoint]; !exists {
+			setLastSeen(name, endpoint, currTime)
+		}
+	}
+	kaInfo.Unlock()
+
+	logger.Logging(logger.DEBUG, "Publishers set: "+name)
+}
+
+// HandlePing records the keep-alive of the topics in body, or renews the lease
+// if 'lease_id' is given instead of 'topic_names'.
 func (Executor) HandlePing(body string) (map[string]interface{}, error) {
+	if err := checkStandby(); err != nil {
+		return nil, err
+	}
+
 	bodyMap, err := util.ConvertJsonToMap(body)
 	if err != nil {
 		logger.Logging(logger.ERROR, "ConvertJsonToMap failed: "+err.Error())
 		return nil, err
 	}
 
+	if value, exists := bodyMap["lease_id"]; exists {
+		id, ok := value.(string)
+		if !ok {
+			return nil, errors.InvalidParam{"lease_id"}
+		}
+		if _, exists := bodyMap["topic_names"]; exists {
+			return nil, errors.InvalidParam{"'topic_names' can not be given with 'lease_id'"}
+		}
+
+		resp, err := renewLease(id)
+		if err != nil {
+			return map[string]interface{}{"lease_id": id}, err
+		}
+		return resp, nil
+	}
+
 	topicNamesInterface, exists := bodyMap["topic_names"].([]interface{})
 	if !exists {
 		logger.Logging(logger.DEBUG, "'topic_names' does not present in body")
@@ -121,21 +316,44 @@ func (Executor) HandlePing(body string) (map[string]interface{}, error) {
 		topicNames[i] = name
 	}
 
-	var notFound []string
+	// If endpoint is not given, all publishers of the topics are kept alive.
+	endpoint := ""
+	if value, exists := bodyMap["endpoint"]; exists {
+		endpoint, exists = value.(string)
+		if !exists {
+			return nil, errors.InvalidParam{"endpoint"}
+		}
+	}
+
+	var found, notFound []string
 	currTime := time.Now()
 
 	kaInfo.Lock()
 	for _, name := range topicNames {
-		_, exists := kaInfo.table[name]
-		if exists {
-			// Update timestamp
-			kaInfo.table[name] = currTime
-		} else {
+		publishers := kaInfo.table[name]
+		if _, exists := publishers[endpoint]; endpoint != "" && !exists {
+			notFound = append(notFound, name)
+			continue
+		}
+		if len(publishers) == 0 {
 			notFound = append(notFound, name)
+			continue
+		}
+
+		// Update timestamp
+		for key := range publishers {
+			if endpoint == "" || key == endpoint {
+				setLastSeen(name, key, currTime)
+			}
 		}
+		found = append(found, name)
 	}
 	kaInfo.Unlock()
 
+	if len(found) != 0 {
+		persistLastSeen(found, endpoint, currTime)
+	}
+
 	if len(notFound) != 0 {
 		resp := make(map[string]interface{})
 		resp["topic_names"] = notFound
@@ -146,32 +364,88 @@ func (Executor) HandlePing(body string) (map[string]interface{}, error) {
 	return nil, nil
 }
 
-func (Executor) GetInterval() uint {
-	return kaInfo.interval / kaPingFrequency
+// GetInterval returns the period that publishers of the topic should send keep-alive.
+func (Executor) GetInterval(name string) uint {
+	kaInfo.Lock()
+	defer kaInfo.Unlock()
+
+	return topicInterval(name) / kaPingFrequency
 }
 
-func keepAliveTimerLoop(interval uint) {
-	logger.Logging(logger.DEBUG, "Start KeepAlive Timer loop")
-	defer logger.Logging(logger.DEBUG, "KeepAlive Timer loop Finished")
+// GetStatus returns STATUS_STALE if all publishers of the topic have missed
+// their keep-alive interval, otherwise STATUS_ALIVE.
+// Publishers attached to a lease are alive until the lease expires.
+func (Executor) GetStatus(name string) string {
+	kaInfo.Lock()
+	defer kaInfo.Unlock()
 
-	timeDurationSec := time.Duration(interval) * time.Second
-	ticker := time.NewTicker(timeDurationSec)
+	timeDurationSec := time.Duration(topicInterval(name)) * time.Second
 
-	for range ticker.C {
-		kaInfo.Lock()
-		for topic, timestamp := range kaInfo.table {
-			// Remove expired topics
-			if time.Since(timestamp) > timeDurationSec {
-				logger.Logging(logger.DEBUG, "KeepAlive time expired: "+topic)
-				// Delete topic from DB
-				if err := topicDbExecutor.DeleteTopic(topic); err != nil {
-					logger.Logging(logger.ERROR, "DeleteTopic failed")
-				}
-				// Delete from KA table
-				delete(kaInfo.table, topic)
-				logger.Logging(logger.DEBUG, "Topic deleted: "+topic)
-			}
+	publishers, exists := kaInfo.table[name]
+	if !exists {
+		return STATUS_ALIVE
+	}
+	for _, entry := range publishers {
+		if entry.lease != nil || time.Since(entry.lastSeen) <= timeDurationSec {
+			return STATUS_ALIVE
+		}
+	}
+	return STATUS_STALE
+}
+
+// GetLiveness returns the keep-alive state of the publishers of the topic by endpoint.
+// Publishers attached to a lease expire with the lease.
+func (Executor) GetLiveness(name string) map[string]Liveness {
+	kaInfo.Lock()
+	defer kaInfo.Unlock()
+
+	liveness := make(map[string]Liveness, len(kaInfo.table[name]))
+	for endpoint, entry := range kaInfo.table[name] {
+		expiresAt := entry.deadline
+		if entry.lease != nil {
+			expiresAt = entry.lease.deadline
 		}
-		kaInfo.Unlock()
+		liveness[endpoint] = Liveness{LastSeen: entry.lastSeen, ExpiresAt: expiresAt}
+	}
+	return liveness
+}
+
+// topicInterval returns the interval of the topic in seconds.
+// kaInfo should be locked by the caller.
+func topicInterval(name string) uint {
+	if interval, exists := kaInfo.intervals[name]; exists {
+		return interval
+	}
+	return kaInfo.interval
+}
+
+// clampInterval limits the requested interval to the configured range.
+func clampInterval(interval uint) uint {
+	if interval < kaInfo.minInterval {
+		return kaInfo.minInterval
+	}
+	if interval > kaInfo.maxInterval {
+		return kaInfo.maxInterval
+	}
+	return interval
+}
+
+// checkStandby returns Unavailable if keep-alive is on standby.
+func checkStandby() error {
+	kaInfo.Lock()
+	defer kaInfo.Unlock()
+
+	if kaInfo.standby {
+		logger.Logging(logger.DEBUG, "Keep-alive is on standby")
+		return errors.Unavailable{"keep-alive is handled by the leader"}
+	}
+	return nil
+}
+
+// persistLastSeen stores the keep-alive timestamps to DB so that they survive a restart.
+// A failure is not fatal since the in-memory table is still up to date.
+func persistLastSeen(names []string, endpoint string, timestamp time.Time) {
+	if err := topicDbExecutor.UpdateLastSeen(names, endpoint, timestamp); err != nil {
+		logger.Logging(logger.ERROR, "UpdateLastSeen failed: "+err.Error())
 	}
 }
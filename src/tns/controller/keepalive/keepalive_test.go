@@ -19,9 +19,13 @@ package keepalive
 import (
 	"github.com/golang/mock/gomock"
 	"reflect"
+	"sort"
+	"strconv"
 	"testing"
 	"time"
 	"tns/commons/errors"
+	watchController "tns/controller/watch"
+	watchControllerMock "tns/controller/watch/mocks"
 	topicDbMock "tns/db/topic/mocks"
 )
 
@@ -41,39 +45,108 @@ func TestCallInitKeepAlive(t *testing.T) {
 	topicDbExecutor = topicDbMockObj
 
 	var dummyInterval uint = 10
+	// Recent enough not to expire while the timer loop is running
+	dummyLastSeen := time.Now().Add(-time.Second)
 
 	testCases := []struct {
-		name        string
-		dummyTopics []map[string]interface{}
-		dummyError  error
+		name           string
+		dummyLastSeen  map[string]map[string]time.Time
+		dummyIntervals map[string]uint
+		dummyError     error
+		restored       bool
 	}{
-		{"Success", []map[string]interface{}{{"name": "/a", "endpoint": "0.0.0.0:1234", "datamodel": "test_0.0.1"}}, nil},
-		{"DbFailed", nil, errors.Unknown{}},
+		{"Success", map[string]map[string]time.Time{"/a": {"0.0.0.0:1234": dummyLastSeen}}, map[string]uint{"/a": 30}, nil, true},
+		{"Success_NeverSeen", map[string]map[string]time.Time{"/a": {"0.0.0.0:1234": time.Time{}}}, nil, nil, false},
+		{"DbFailed", nil, nil, errors.Unknown{}, false},
 	}
 
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
-			gomock.InOrder(
-				topicDbMockObj.EXPECT().ReadTopicAll().Return(tc.dummyTopics, tc.dummyError),
-			)
+			topicDbMockObj.EXPECT().ReadLastSeenAll().Return(tc.dummyLastSeen, tc.dummyError)
+			if tc.dummyError == nil {
+				topicDbMockObj.EXPECT().ReadIntervalAll().Return(tc.dummyIntervals, nil)
+			}
 
-			err := Handler.InitKeepAlive(dummyInterval)
+			err := Handler.InitKeepAlive(Config{Interval: dummyInterval})
+			defer stopKeepAliveForTest()
 			if err != tc.dummyError {
 				t.Fail()
 			}
 			if err == nil {
-				dummyName := tc.dummyTopics[0]["name"].(string)
-				if _, exist := kaInfo.table[dummyName]; !exist {
-					t.Fail()
+				kaInfo.Lock()
+				defer kaInfo.Unlock()
+
+				entry, exist := kaInfo.table["/a"]["0.0.0.0:1234"]
+				if !exist {
+					t.FailNow()
+				}
+				timestamp := entry.lastSeen
+				if tc.restored != timestamp.Equal(dummyLastSeen) {
+					t.Errorf("Unexpected timestamp: %v", timestamp)
+				}
+				if timestamp.IsZero() {
+					t.Errorf("Timestamp is not initialized")
 				}
 				if kaInfo.interval != dummyInterval {
 					t.Fail()
 				}
+				if !reflect.DeepEqual(kaInfo.intervals, tc.dummyIntervals) && len(tc.dummyIntervals) != 0 {
+					t.Errorf("Unexpected intervals: %v", kaInfo.intervals)
+				}
 			}
 		})
 	}
 }
 
+func TestCallInitKeepAliveWithStandby(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	topicDbMockObj := topicDbMock.NewMockCommand(ctrl)
+
+	// pass mockObj to a real object.
+	topicDbExecutor = topicDbMockObj
+
+	var dummyInterval uint = 10
+	body := `{"topic_names":["/a"]}`
+
+	// Nothing is read from DB on standby
+	err := Handler.InitKeepAlive(Config{Interval: dummyInterval, Standby: true})
+	if err != nil {
+		t.Fatalf("InitKeepAlive returned an error: %s", err.Error())
+	}
+
+	_, err = Handler.HandlePing(body)
+	if reflect.TypeOf(err) != reflect.TypeOf(errors.Unavailable{}) {
+		t.Errorf("Expected Error: %T, Actual: %v", errors.Unavailable{}, err)
+	}
+	_, err = Handler.GrantLease(`{"ttl":10}`)
+	if reflect.TypeOf(err) != reflect.TypeOf(errors.Unavailable{}) {
+		t.Errorf("Expected Error: %T, Actual: %v", errors.Unavailable{}, err)
+	}
+	if interval := Handler.GetInterval("/a"); interval != dummyInterval/kaPingFrequency {
+		t.Errorf("Expected interval: %d, Actual: %d", dummyInterval/kaPingFrequency, interval)
+	}
+
+	// Leave the standby, e.g., elected as the leader
+	dummyLastSeen := time.Now()
+	gomock.InOrder(
+		topicDbMockObj.EXPECT().ReadLastSeenAll().Return(map[string]map[string]time.Time{"/a": {"0.0.0.0:1234": dummyLastSeen}}, nil),
+		topicDbMockObj.EXPECT().ReadIntervalAll().Return(nil, nil),
+		topicDbMockObj.EXPECT().UpdateLastSeen([]string{"/a"}, "", gomock.Any()).Return(nil),
+	)
+
+	err = Handler.InitKeepAlive(Config{Interval: dummyInterval})
+	if err != nil {
+		t.Fatalf("InitKeepAlive returned an error: %s", err.Error())
+	}
+	defer stopKeepAliveForTest()
+
+	if _, err = Handler.HandlePing(body); err != nil {
+		t.Errorf("HandlePing returned an error: %s", err.Error())
+	}
+}
+
 func TestCallAddTopicAndDeleteTopic(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -85,7 +158,9 @@ func TestCallAddTopicAndDeleteTopic(t *testing.T) {
 
 	dummyTopicName := "/a"
 
-	Handler.AddTopic(dummyTopicName)
+	topicDbMockObj.EXPECT().UpdateLastSeen([]string{dummyTopicName}, "0.0.0.0:1234", gomock.Any()).Return(nil)
+
+	Handler.AddTopic(dummyTopicName, "0.0.0.0:1234", 0)
 	if _, exist := kaInfo.table[dummyTopicName]; !exist {
 		t.Errorf("Topic does not exist: %s", dummyTopicName)
 	}
@@ -96,6 +171,67 @@ func TestCallAddTopicAndDeleteTopic(t *testing.T) {
 	}
 }
 
+func TestCallAddTopicAndDeletePublisher(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	topicDbMockObj := topicDbMock.NewMockCommand(ctrl)
+
+	// pass mockObj to a real object.
+	topicDbExecutor = topicDbMockObj
+
+	dummyTopicName := "/a"
+
+	topicDbMockObj.EXPECT().UpdateLastSeen([]string{dummyTopicName}, gomock.Any(), gomock.Any()).Return(nil).Times(2)
+
+	Handler.AddTopic(dummyTopicName, "0.0.0.0:1234", 0)
+	Handler.AddTopic(dummyTopicName, "0.0.0.0:5678", 0)
+	if len(kaInfo.table[dummyTopicName]) != 2 {
+		t.Errorf("Unexpected publishers: %v", kaInfo.table[dummyTopicName])
+	}
+
+	// others are not affected
+	Handler.DeletePublisher(dummyTopicName, "0.0.0.0:1234")
+	if _, exist := kaInfo.table[dummyTopicName]["0.0.0.0:5678"]; !exist {
+		t.Errorf("Publisher does not exist: %s", "0.0.0.0:5678")
+	}
+
+	// the last publisher
+	Handler.DeletePublisher(dummyTopicName, "0.0.0.0:5678")
+	if _, exist := kaInfo.table[dummyTopicName]; exist {
+		t.Errorf("Topic exists: %s", dummyTopicName)
+	}
+}
+
+func TestCallSetEndpoints(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	topicDbMockObj := topicDbMock.NewMockCommand(ctrl)
+
+	// pass mockObj to a real object.
+	topicDbExecutor = topicDbMockObj
+
+	dummyTopicName := "/a"
+	dummyTimestamp := time.Now().Add(-time.Minute)
+
+	initKeepAliveForTest(10, 0, map[string]map[string]time.Time{
+		dummyTopicName: {"0.0.0.0:1234": dummyTimestamp, "0.0.0.0:5678": dummyTimestamp},
+	}, nil)
+
+	Handler.SetEndpoints(dummyTopicName, []string{"0.0.0.0:1234", "0.0.0.0:9999"})
+
+	publishers := kaInfo.table[dummyTopicName]
+	if len(publishers) != 2 || !publishers["0.0.0.0:1234"].lastSeen.Equal(dummyTimestamp) || publishers["0.0.0.0:9999"].lastSeen.Equal(dummyTimestamp) {
+		t.Errorf("Unexpected publishers: %v", publishers)
+	}
+	if len(kaInfo.expiry) != 2 {
+		t.Errorf("Unexpected entries in scheduler: %d", len(kaInfo.expiry))
+	}
+
+	Handler.DeleteTopic(dummyTopicName)
+}
+
 func TestCallHandlePing(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -107,7 +243,14 @@ func TestCallHandlePing(t *testing.T) {
 
 	dummyBodyString := `{"topic_names":["/a"]}`
 
-	Handler.AddTopic("/a")
+	gomock.InOrder(
+		topicDbMockObj.EXPECT().UpdateLastSeen([]string{"/a"}, "0.0.0.0:1234", gomock.Any()).Return(nil),
+		topicDbMockObj.EXPECT().UpdateLastSeen([]string{"/a"}, "", gomock.Any()).Return(errors.Unknown{}),
+	)
+
+	Handler.AddTopic("/a", "0.0.0.0:1234", 0)
+
+	// failure of persisting timestamps is not reported to the publisher
 
 	_, err := Handler.HandlePing(dummyBodyString)
 	if err != nil {
@@ -134,12 +277,16 @@ func TestCallHandlePingExpectedError(t *testing.T) {
 		{"InvalidKey", `{"invalid_key":["/a","/b"]}`, nil, errors.InvalidParam{}},
 		{"InvalidValue", `{"topic_names":["/a",1]}`, nil, errors.InvalidParam{}},
 		{"TopicNameNotFound", `{"topic_names":["/a","/b"]}`, map[string]interface{}{"topic_names": []string{"/b"}}, errors.NotFound{}},
+		{"InvalidEndpoint", `{"topic_names":["/a"],"endpoint":1}`, nil, errors.InvalidParam{}},
+		{"EndpointNotFound", `{"topic_names":["/a"],"endpoint":"0.0.0.0:5678"}`, map[string]interface{}{"topic_names": []string{"/a"}}, errors.NotFound{}},
 	}
 
+	topicDbMockObj.EXPECT().UpdateLastSeen([]string{"/a"}, gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
 
-			Handler.AddTopic("/a") // add "/a"
+			Handler.AddTopic("/a", "0.0.0.0:1234", 0) // add "/a"
 
 			resp, err := Handler.HandlePing(tc.dummyBodyString)
 
@@ -167,17 +314,18 @@ func TestCallGetInterval(t *testing.T) {
 	var dummyInterval uint = 100
 	expectedRetVal := dummyInterval / kaPingFrequency
 
-	topicDbMockObj.EXPECT().ReadTopicAll()
+	topicDbMockObj.EXPECT().ReadLastSeenAll()
+	topicDbMockObj.EXPECT().ReadIntervalAll()
 
-	Handler.InitKeepAlive(dummyInterval)
+	Handler.InitKeepAlive(Config{Interval: dummyInterval})
 
-	interval := Handler.GetInterval()
+	interval := Handler.GetInterval("/a")
 	if interval != expectedRetVal {
 		t.Errorf("Expected val: %d, Actual: %d", expectedRetVal, interval)
 	}
 }
 
-func TestKeepAliveTimerLoopCalled(t *testing.T) {
+func TestCallAddTopicWithInterval(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
@@ -186,25 +334,417 @@ func TestKeepAliveTimerLoopCalled(t *testing.T) {
 	// pass mockObj to a real object.
 	topicDbExecutor = topicDbMockObj
 
-	var dummyTopics = []map[string]interface{}{{"name": "/a", "endpoint": "0.0.0.0:1234", "datamodel": "test_0.0.1"}}
-	var dummyInterval uint = 1
-	const waitingTimeForTopicExpired = 2
+	initKeepAliveForTest(60, 0, nil, nil)
+	kaInfo.minInterval = 30
+	kaInfo.maxInterval = 300
 
-	gomock.InOrder(
-		topicDbMockObj.EXPECT().ReadTopicAll().Return(dummyTopics, nil),
-		topicDbMockObj.EXPECT().DeleteTopic("/a").Return(nil),
-		topicDbMockObj.EXPECT().DeleteTopic("/tmp").Return(errors.Unknown{}),
-	)
+	testCases := []struct {
+		name             string
+		requested        uint
+		expectedInterval uint // stored as the interval of topic
+	}{
+		{"Default", 0, 0},
+		{"Granted", 20, 60},
+		{"ClampedToMin", 5, 30},
+		{"ClampedToMax", 1000, 300},
+	}
+
+	topicDbMockObj.EXPECT().UpdateLastSeen([]string{"/a"}, "0.0.0.0:1234", gomock.Any()).Return(nil).AnyTimes()
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if tc.expectedInterval != 0 {
+				topicDbMockObj.EXPECT().UpdateInterval("/a", tc.expectedInterval).Return(nil)
+			}
+
+			Handler.AddTopic("/a", "0.0.0.0:1234", tc.requested)
+
+			expectedPeriod := tc.expectedInterval / kaPingFrequency
+			if tc.expectedInterval == 0 {
+				expectedPeriod = kaInfo.interval / kaPingFrequency
+			}
+			if period := Handler.GetInterval("/a"); period != expectedPeriod {
+				t.Errorf("Expected Interval: %d, Actual: %d", expectedPeriod, period)
+			}
+
+			Handler.DeleteTopic("/a")
+		})
+	}
+}
+
+func TestCallGetStatus(t *testing.T) {
+	initKeepAliveForTest(10, 0, map[string]map[string]time.Time{
+		"/alive":    {"0.0.0.0:1234": time.Now().Add(-15 * time.Second), "0.0.0.0:5678": time.Now()},
+		"/stale":    {"0.0.0.0:1234": time.Now().Add(-15 * time.Second)},
+		"/interval": {"0.0.0.0:1234": time.Now().Add(-15 * time.Second)},
+	}, map[string]uint{"/interval": 20})
 
-	// add "/a"
-	Handler.InitKeepAlive(dummyInterval)
+	testCases := []struct {
+		name           string
+		topicName      string
+		expectedStatus string
+	}{
+		{"Alive", "/alive", STATUS_ALIVE},
+		{"Stale", "/stale", STATUS_STALE},
+		{"Alive_OwnInterval", "/interval", STATUS_ALIVE},
+		{"NotInTable", "/unknown", STATUS_ALIVE},
+	}
 
-	// "/a" will be deleted after dummyInterval seconds
-	time.Sleep(waitingTimeForTopicExpired * time.Second)
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			status := Handler.GetStatus(tc.topicName)
+			if status != tc.expectedStatus {
+				t.Errorf("Expected Status: %s, Actual: %s", tc.expectedStatus, status)
+			}
+		})
+	}
+}
 
-	// add "/tmp"
-	Handler.AddTopic("/tmp")
+func TestCallGetLiveness(t *testing.T) {
+	lastSeen := time.Now().Add(-5 * time.Second)
+	initKeepAliveForTest(10, 3, map[string]map[string]time.Time{
+		"/a": {"0.0.0.0:1234": lastSeen},
+	}, map[string]uint{"/a": 20})
 
-	// topicDbMock will return error
-	time.Sleep(waitingTimeForTopicExpired * time.Second)
+	lease := &kaLease{id: "lease", ttl: 10, deadline: lastSeen.Add(time.Minute), entries: make(map[*kaEntry]bool)}
+	kaInfo.Lock()
+	entry := getEntry("/a", "0.0.0.0:5678")
+	entry.lastSeen = lastSeen
+	entry.lease = lease
+	lease.entries[entry] = true
+	kaInfo.Unlock()
+
+	testCases := []struct {
+		name             string
+		topicName        string
+		expectedLiveness map[string]Liveness
+	}{
+		{"Success", "/a", map[string]Liveness{
+			"0.0.0.0:1234": {LastSeen: lastSeen, ExpiresAt: lastSeen.Add(23 * time.Second)},
+			"0.0.0.0:5678": {LastSeen: lastSeen, ExpiresAt: lastSeen.Add(time.Minute)},
+		}},
+		{"NotInTable", "/unknown", map[string]Liveness{}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			liveness := Handler.GetLiveness(tc.topicName)
+			if !reflect.DeepEqual(liveness, tc.expectedLiveness) {
+				t.Errorf("Expected Liveness: %v, Actual: %v", tc.expectedLiveness, liveness)
+			}
+		})
+	}
+}
+
+func TestPopExpiredWithGracePeriod(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	topicDbMockObj := topicDbMock.NewMockCommand(ctrl)
+
+	// pass mockObj to a real object.
+	topicDbExecutor = topicDbMockObj
+
+	// "/d" has its own interval
+	initKeepAliveForTest(10, 10, map[string]map[string]time.Time{
+		"/a": {"0.0.0.0:1234": time.Now().Add(-5 * time.Second), "0.0.0.0:5678": time.Now().Add(-25 * time.Second)},
+		"/b": {"0.0.0.0:1234": time.Now().Add(-15 * time.Second)},
+		"/c": {"0.0.0.0:1234": time.Now().Add(-25 * time.Second)},
+		"/d": {"0.0.0.0:1234": time.Now().Add(-25 * time.Second)},
+	}, map[string]uint{"/c": 10, "/d": 30})
+
+	expired := popExpired(time.Now())
+
+	expectedExpired := []expiredPublisher{{"/a", "0.0.0.0:5678"}, {"/c", "0.0.0.0:1234"}}
+	sort.Slice(expired, func(i, j int) bool { return expired[i].name < expired[j].name })
+	if !reflect.DeepEqual(expired, expectedExpired) {
+		t.Errorf("Expected Expired: %v, Actual: %v", expectedExpired, expired)
+	}
+	if _, exists := kaInfo.table["/b"]["0.0.0.0:1234"]; !exists {
+		t.Error("Stale publisher is removed in its grace period")
+	}
+	if _, exists := kaInfo.table["/c"]; exists {
+		t.Error("Topic without publishers is not removed")
+	}
+	if _, exists := kaInfo.intervals["/c"]; exists {
+		t.Error("Interval of removed topic is not removed")
+	}
+	if Handler.GetStatus("/d") != STATUS_ALIVE {
+		t.Errorf("Topic is expired before its own interval: %v", kaInfo.table["/d"])
+	}
+	if len(kaInfo.table["/a"]) != 1 || len(kaInfo.expiry) != 3 {
+		t.Errorf("Unexpected publishers: %v", kaInfo.table["/a"])
+	}
+
+	// Stale topic is revived by keep-alive
+	topicDbMockObj.EXPECT().UpdateLastSeen([]string{"/b"}, "0.0.0.0:1234", gomock.Any()).Return(nil)
+
+	if Handler.GetStatus("/b") != STATUS_STALE {
+		t.Errorf("Expected Status: %s", STATUS_STALE)
+	}
+	if _, err := Handler.HandlePing(`{"topic_names":["/b"],"endpoint":"0.0.0.0:1234"}`); err != nil {
+		t.Errorf("HandlePing returned an error: %s", err.Error())
+	}
+	if Handler.GetStatus("/b") != STATUS_ALIVE {
+		t.Errorf("Expected Status: %s", STATUS_ALIVE)
+	}
+	if deadline := kaInfo.table["/b"]["0.0.0.0:1234"].deadline; time.Until(deadline) < 19*time.Second {
+		t.Errorf("Deadline is not extended: %v", deadline)
+	}
+}
+
+func TestDeletePublisher(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	topicDbMockObj := topicDbMock.NewMockCommand(ctrl)
+	watchControllerMockObj := watchControllerMock.NewMockCommand(ctrl)
+
+	// pass mockObj to a real object.
+	topicDbExecutor = topicDbMockObj
+	watchExecutor = watchControllerMockObj
+	defer func() { watchExecutor = watchController.Executor{} }()
+
+	deleteRetryDelay = time.Millisecond
+	defer func() { deleteRetryDelay = 100 * time.Millisecond }()
+
+	initKeepAliveForTest(10, 0, map[string]map[string]time.Time{"/registered": {"0.0.0.0:1234": time.Now()}}, nil)
+
+	dummyTopics := []map[string]interface{}{{"name": "/a", "endpoints": []string{"0.0.0.0:1234"}, "datamodel": "test_0.0.1",
+		"secured": false, "labels": map[string]string{"site": "plant3"}}}
+	dummyProperties := map[string]interface{}{"name": "/a", "endpoint": "0.0.0.0:1234", "datamodel": "test_0.0.1",
+		"secured": false, "labels": map[string]interface{}{"site": "plant3"}}
+	registerAgain := func(string, string) {
+		kaInfo.Lock()
+		setLastSeen("/a", "0.0.0.0:1234", time.Now())
+		kaInfo.Unlock()
+	}
+
+	testCases := []struct {
+		name       string
+		topicName  string
+		expect     func() []*gomock.Call
+		registered bool // Whether the publisher is in keep-alive afterwards
+	}{
+		{"Success", "/a", func() []*gomock.Call {
+			return []*gomock.Call{
+				topicDbMockObj.EXPECT().ReadTopic("/a", false, "").Return(dummyTopics, nil),
+				topicDbMockObj.EXPECT().DeletePublisher("/a", "0.0.0.0:1234").Return(nil),
+				watchControllerMockObj.EXPECT().Publish(watchController.EVENT_EXPIRED,
+					map[string]interface{}{"name": "/a", "endpoint": "0.0.0.0:1234"}),
+			}
+		}, false},
+		{"Success_Retried", "/a", func() []*gomock.Call {
+			return []*gomock.Call{
+				topicDbMockObj.EXPECT().ReadTopic("/a", false, "").Return(dummyTopics, nil),
+				topicDbMockObj.EXPECT().DeletePublisher("/a", "0.0.0.0:1234").Return(errors.Unknown{}),
+				topicDbMockObj.EXPECT().ReadTopic("/a", false, "").Return(nil, errors.InternalServerError{}),
+				topicDbMockObj.EXPECT().ReadTopic("/a", false, "").Return(dummyTopics, nil),
+				topicDbMockObj.EXPECT().DeletePublisher("/a", "0.0.0.0:1234").Return(nil),
+				watchControllerMockObj.EXPECT().Publish(watchController.EVENT_EXPIRED,
+					map[string]interface{}{"name": "/a", "endpoint": "0.0.0.0:1234"}),
+			}
+		}, false},
+		{"NotFound", "/a", func() []*gomock.Call {
+			return []*gomock.Call{
+				topicDbMockObj.EXPECT().ReadTopic("/a", false, "").Return([]map[string]interface{}{}, nil),
+			}
+		}, false},
+		{"NotFound_Deleted", "/a", func() []*gomock.Call {
+			return []*gomock.Call{
+				topicDbMockObj.EXPECT().ReadTopic("/a", false, "").Return(dummyTopics, nil),
+				topicDbMockObj.EXPECT().DeletePublisher("/a", "0.0.0.0:1234").Return(errors.NotFound{}),
+			}
+		}, false},
+		{"GaveUp", "/a", func() []*gomock.Call {
+			var calls []*gomock.Call
+			for i := 0; i < MAX_DELETE_RETRY; i++ {
+				calls = append(calls,
+					topicDbMockObj.EXPECT().ReadTopic("/a", false, "").Return(dummyTopics, nil),
+					topicDbMockObj.EXPECT().DeletePublisher("/a", "0.0.0.0:1234").Return(errors.Unknown{}))
+			}
+			return calls
+		}, true},
+		{"RegisteredAgain", "/registered", func() []*gomock.Call { return nil }, true},
+		{"RegisteredDuringDeletion", "/a", func() []*gomock.Call {
+			// Removed from DB after it was registered again, which is restored
+			return []*gomock.Call{
+				topicDbMockObj.EXPECT().ReadTopic("/a", false, "").Return(dummyTopics, nil),
+				topicDbMockObj.EXPECT().DeletePublisher("/a", "0.0.0.0:1234").Do(registerAgain).Return(nil),
+				topicDbMockObj.EXPECT().ReadTopic("/a", false, "").Return([]map[string]interface{}{}, nil),
+				topicDbMockObj.EXPECT().CreateTopic(dummyProperties).Return(true, nil),
+			}
+		}, true},
+		{"RegisteredAfterDeletion", "/a", func() []*gomock.Call {
+			return []*gomock.Call{
+				topicDbMockObj.EXPECT().ReadTopic("/a", false, "").Return(dummyTopics, nil),
+				topicDbMockObj.EXPECT().DeletePublisher("/a", "0.0.0.0:1234").Do(registerAgain).Return(nil),
+				topicDbMockObj.EXPECT().ReadTopic("/a", false, "").Return(dummyTopics, nil),
+			}
+		}, true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			gomock.InOrder(tc.expect()...)
+
+			deletePublisher(expiredPublisher{tc.topicName, "0.0.0.0:1234"})
+
+			// The publisher which could not be deleted expires again later
+			kaInfo.Lock()
+			entry, registered := kaInfo.table[tc.topicName]["0.0.0.0:1234"]
+			if registered && tc.topicName != "/registered" {
+				removeEntry(entry)
+			}
+			kaInfo.Unlock()
+			if registered != tc.registered {
+				t.Errorf("Expected registered: %t, Actual: %t", tc.registered, registered)
+			}
+			if tc.name == "GaveUp" && entry.deadline.Before(time.Now().Add(deleteRescheduleDelay/2)) {
+				t.Errorf("Unexpected deadline: %v", entry.deadline)
+			}
+		})
+	}
+}
+
+func TestKeepAliveTimerLoopCalled(t *testing.T) {
+	now := time.Now()
+	dummyLastSeen := map[string]map[string]time.Time{
+		"/a": {"0.0.0.0:1234": now.Add(-time.Hour)},
+		"/b": {"0.0.0.0:1234": now.Add(time.Hour)},
+	}
+	initKeepAliveForTest(1, 0, dummyLastSeen, nil)
+	defer stopKeepAliveForTest()
+
+	kaInfo.Lock()
+	kaInfo.stop = make(chan struct{})
+	wakeUp, stop := kaInfo.wakeUp, kaInfo.stop
+	kaInfo.Unlock()
+
+	deletion := make(chan expiredPublisher)
+	go keepAliveTimerLoop(wakeUp, deletion, stop)
+
+	// "/a" has already expired
+	expectDeletion(t, deletion, expiredPublisher{"/a", "0.0.0.0:1234"})
+
+	// "/tmp" expires earlier than "/b", which wakes up the timer loop
+	kaInfo.Lock()
+	setLastSeen("/tmp", "0.0.0.0:1234", now.Add(-time.Hour))
+	kaInfo.Unlock()
+	expectDeletion(t, deletion, expiredPublisher{"/tmp", "0.0.0.0:1234"})
+
+	kaInfo.Lock()
+	defer kaInfo.Unlock()
+	if _, exists := kaInfo.table["/b"]["0.0.0.0:1234"]; !exists || len(kaInfo.table) != 1 {
+		t.Errorf("Expected only /b in the table, Actual: %v", kaInfo.table)
+	}
+}
+
+// expectDeletion fails the test unless the timer loop passes the publisher to
+// the deletion worker.
+func expectDeletion(t *testing.T, deletion <-chan expiredPublisher, expected expiredPublisher) {
+	select {
+	case publisher := <-deletion:
+		if publisher != expected {
+			t.Errorf("Expected deletion: %v, Actual: %v", expected, publisher)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("Expected deletion: %v, Actual: none", expected)
+	}
+}
+
+// initKeepAliveForTest initializes kaInfo without starting the timer loop.
+func initKeepAliveForTest(interval uint, gracePeriod uint, lastSeen map[string]map[string]time.Time, intervals map[string]uint) {
+	stopKeepAliveForTest()
+
+	kaInfo.Lock()
+	defer kaInfo.Unlock()
+
+	kaInfo.interval = interval
+	kaInfo.minInterval = interval
+	kaInfo.maxInterval = interval
+	kaInfo.gracePeriod = time.Duration(gracePeriod) * time.Second
+	kaInfo.intervals = make(map[string]uint)
+	for name, interval := range intervals {
+		kaInfo.intervals[name] = interval
+	}
+	kaInfo.table = make(kaTableType)
+	kaInfo.expiry = kaHeap{}
+	kaInfo.leases = make(map[string]*kaLease)
+	kaInfo.wakeUp = make(chan struct{}, 1)
+	kaInfo.standby = false
+	kaInfo.noLease = false
+	for name, publishers := range lastSeen {
+		for endpoint, timestamp := range publishers {
+			setLastSeen(name, endpoint, timestamp)
+		}
+	}
+}
+
+// stopKeepAliveForTest stops the timer loop started by InitKeepAlive.
+func stopKeepAliveForTest() {
+	kaInfo.Lock()
+	defer kaInfo.Unlock()
+
+	if kaInfo.stop != nil {
+		close(kaInfo.stop)
+		kaInfo.stop = nil
+	}
+}
+
+func BenchmarkSetLastSeen(b *testing.B) {
+	for _, size := range []int{1000, 10000, 100000} {
+		b.Run(strconv.Itoa(size), func(b *testing.B) {
+			initKeepAliveForTest(10, 0, benchmarkTable(size), nil)
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				setLastSeen("/topic"+strconv.Itoa(i%size), "0.0.0.0:1234", time.Now())
+			}
+		})
+	}
+}
+
+// The timer loop checks expiry without anything expired for most of the time.
+func BenchmarkPopExpired(b *testing.B) {
+	for _, size := range []int{1000, 10000, 100000} {
+		b.Run(strconv.Itoa(size), func(b *testing.B) {
+			initKeepAliveForTest(10, 0, benchmarkTable(size), nil)
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				popExpired(time.Now())
+			}
+		})
+	}
+}
+
+// BenchmarkScanExpired measures the full table scan which popExpired replaced.
+func BenchmarkScanExpired(b *testing.B) {
+	for _, size := range []int{1000, 10000, 100000} {
+		b.Run(strconv.Itoa(size), func(b *testing.B) {
+			initKeepAliveForTest(10, 0, benchmarkTable(size), nil)
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				currTime := time.Now()
+				for name, publishers := range kaInfo.table {
+					for _, entry := range publishers {
+						if currTime.After(deadlineOf(name, entry.lastSeen)) {
+							b.Fatal("Unexpected expiry")
+						}
+					}
+				}
+			}
+		})
+	}
+}
+
+func benchmarkTable(size int) map[string]map[string]time.Time {
+	table := make(map[string]map[string]time.Time, size)
+	currTime := time.Now()
+	for i := 0; i < size; i++ {
+		table["/topic"+strconv.Itoa(i)] = map[string]time.Time{"0.0.0.0:1234": currTime}
+	}
+	return table
 }
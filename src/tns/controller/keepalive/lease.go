@@ -0,0 +1,341 @@
+/*******************************************************************************
+ * Copyright 2018 Samsung Electronics All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ *******************************************************************************/
+package keepalive
+
+import (
+	"container/heap"
+	"crypto/rand"
+	"encoding/hex"
+	"math"
+	"sort"
+	"time"
+	"tns/commons/errors"
+	"tns/commons/logger"
+	"tns/commons/util"
+	watchController "tns/controller/watch"
+)
+
+// A lease keeps all publishers attached to it alive with a single keep-alive.
+// Publishers attached to a lease are not expired by the scheduler, and they
+// are removed together when the lease is revoked or expires.
+// Leases are not persisted. After a restart, renewal of a lease fails with NotFound
+// and its publishers fall back to the keep-alive interval of their topics.
+
+const LEASE_ID_LENGTH = 8 // Bytes, hex encoded
+
+// kaLease is a lease granted to a client.
+type kaLease struct {
+	id       string
+	ttl      uint // Seconds
+	deadline time.Time
+	timer    *time.Timer
+	entries  map[*kaEntry]bool
+}
+
+// GrantLease grants a lease with the TTL in body, limited to the configured
+// range of intervals, and returns its ID with the granted TTL.
+// Leases are not supported if they are disabled by InitKeepAlive.
+func (Executor) GrantLease(body string) (map[string]interface{}, error) {
+	if err := checkStandby(); err != nil {
+		return nil, err
+	}
+
+	kaInfo.Lock()
+	noLease := kaInfo.noLease
+	kaInfo.Unlock()
+	if noLease {
+		logger.Logging(logger.DEBUG, "Leases are disabled")
+		return nil, errors.InvalidMethod{"leases are not supported in cluster mode"}
+	}
+
+	bodyMap, err := util.ConvertJsonToMap(body)
+	if err != nil {
+		logger.Logging(logger.ERROR, "ConvertJsonToMap failed: "+err.Error())
+		return nil, err
+	}
+
+	value, exists := bodyMap["ttl"]
+	if !exists {
+		logger.Logging(logger.DEBUG, "'ttl' does not present in body")
+		return nil, errors.InvalidParam{"'ttl' field is required"}
+	}
+	ttl, ok := value.(float64)
+	if !ok || ttl < 1 || ttl > math.MaxUint32 || ttl != math.Trunc(ttl) {
+		return nil, errors.InvalidParam{"'ttl' field must be a positive integer"}
+	}
+
+	id, err := newLeaseID()
+	if err != nil {
+		logger.Logging(logger.ERROR, "newLeaseID failed: "+err.Error())
+		return nil, errors.InternalServerError{"Failed to generate lease ID"}
+	}
+
+	kaInfo.Lock()
+	lease := &kaLease{id: id, ttl: clampInterval(uint(ttl)), entries: make(map[*kaEntry]bool)}
+	lease.deadline = time.Now().Add(time.Duration(lease.ttl) * time.Second)
+	lease.timer = time.AfterFunc(time.Duration(lease.ttl)*time.Second, func() { expireLease(lease) })
+	kaInfo.leases[id] = lease
+	resp := lease.convertToMap()
+	kaInfo.Unlock()
+
+	logger.Logging(logger.DEBUG, "Lease granted: "+id)
+
+	return resp, nil
+}
+
+// ReadLease returns the lease with its remaining TTL and attached publishers.
+func (Executor) ReadLease(id string) (map[string]interface{}, error) {
+	kaInfo.Lock()
+	defer kaInfo.Unlock()
+
+	lease, exists := kaInfo.leases[id]
+	if !exists {
+		return nil, errors.NotFound{"lease " + id}
+	}
+
+	topics := make([]map[string]interface{}, 0, len(lease.entries))
+	for entry := range lease.entries {
+		topics = append(topics, map[string]interface{}{"name": entry.name, "endpoint": entry.endpoint})
+	}
+	sort.Slice(topics, func(i, j int) bool {
+		if topics[i]["name"] != topics[j]["name"] {
+			return topics[i]["name"].(string) < topics[j]["name"].(string)
+		}
+		return topics[i]["endpoint"].(string) < topics[j]["endpoint"].(string)
+	})
+
+	resp := lease.convertToMap()
+	resp["remaining"] = uint(math.Ceil(time.Until(lease.deadline).Seconds()))
+	resp["topics"] = topics
+
+	return resp, nil
+}
+
+// RevokeLease revokes the lease and removes all publishers attached to it.
+// The publishers are removed from DB in the background.
+func (Executor) RevokeLease(id string) error {
+	kaInfo.Lock()
+	lease, exists := kaInfo.leases[id]
+	if !exists {
+		kaInfo.Unlock()
+		return errors.NotFound{"lease " + id}
+	}
+	publishers := removeLease(lease)
+	kaInfo.Unlock()
+
+	logger.Logging(logger.DEBUG, "Lease revoked: "+id)
+
+	go deleteLeasePublishers(publishers, watchController.EVENT_DELETED)
+
+	return nil
+}
+
+// AddTopicWithLease starts keep-alive of the publisher of the given endpoint
+// by the lease. The publisher is detached from its previous lease, if any.
+func (Executor) AddTopicWithLease(name string, endpoint string, id string) error {
+	currTime := time.Now()
+
+	kaInfo.Lock()
+	lease, exists := kaInfo.leases[id]
+	if !exists {
+		kaInfo.Unlock()
+		return errors.NotFound{"lease " + id}
+	}
+
+	entry := getEntry(name, endpoint)
+	detachLease(entry)
+	if entry.index >= 0 {
+		heap.Remove(&kaInfo.expiry, entry.index)
+	}
+	entry.lastSeen = currTime
+	entry.lease = lease
+	lease.entries[entry] = true
+	kaInfo.Unlock()
+
+	persistLastSeen([]string{name}, endpoint, currTime)
+
+	logger.Logging(logger.DEBUG, "Topic added with lease: "+name+" "+endpoint+" "+id)
+
+	return nil
+}
+
+// renewLease extends the lease by its TTL, and records the keep-alive
+// of its publishers.
+func renewLease(id string) (map[string]interface{}, error) {
+	currTime := time.Now()
+
+	kaInfo.Lock()
+	lease, exists := kaInfo.leases[id]
+	if !exists {
+		kaInfo.Unlock()
+		return nil, errors.NotFound{"lease " + id}
+	}
+	lease.deadline = currTime.Add(time.Duration(lease.ttl) * time.Second)
+	lease.timer.Reset(time.Duration(lease.ttl) * time.Second)
+
+	names := make(map[string][]string) // "endpoint":["topic"]
+	for entry := range lease.entries {
+		entry.lastSeen = currTime
+		names[entry.endpoint] = append(names[entry.endpoint], entry.name)
+	}
+	resp := lease.convertToMap()
+	kaInfo.Unlock()
+
+	for endpoint, names := range names {
+		persistLastSeen(names, endpoint, currTime)
+	}
+
+	return resp, nil
+}
+
+// expireLease removes the publishers of the lease if it has not been renewed
+// since the timer fired.
+func expireLease(lease *kaLease) {
+	kaInfo.Lock()
+	if kaInfo.leases[lease.id] != lease || time.Now().Before(lease.deadline) {
+		kaInfo.Unlock()
+		return
+	}
+	publishers := removeLease(lease)
+	kaInfo.Unlock()
+
+	logger.Logging(logger.DEBUG, "Lease expired: "+lease.id)
+
+	deleteLeasePublishers(publishers, watchController.EVENT_EXPIRED)
+}
+
+// The followings should be called with kaInfo locked.
+
+// removeLease removes the lease with its publishers from keep-alive, and returns
+// the endpoints of the publishers by topic name.
+func removeLease(lease *kaLease) map[string][]string {
+	publishers := make(map[string][]string)
+	for entry := range lease.entries {
+		publishers[entry.name] = append(publishers[entry.name], entry.endpoint)
+		removeEntry(entry)
+	}
+
+	lease.timer.Stop()
+	delete(kaInfo.leases, lease.id)
+
+	return publishers
+}
+
+// detachLease detaches the publisher from its lease, if any.
+func detachLease(entry *kaEntry) {
+	if entry.lease != nil {
+		delete(entry.lease.entries, entry)
+		entry.lease = nil
+	}
+}
+
+func (lease *kaLease) convertToMap() map[string]interface{} {
+	return map[string]interface{}{
+		"lease_id": lease.id,
+		"ttl":      lease.ttl,
+	}
+}
+
+// deleteLeasePublishers removes the publishers of a lease from DB at once,
+// outside of kaInfo lock. The removal is retried up to MAX_DELETE_RETRY times
+// on failures like deletePublisher, and then the publishers are rescheduled to
+// expire again one by one.
+// The removal is not atomic on all databases, e.g., mongoDB removes them topic
+// by topic, so some of them may have been removed when it fails. They are
+// ignored on the retry, and watchers are notified of all of them once the retry
+// succeeds, but not of the ones removed before it gives up.
+// Publishers registered again during the removal are restored in DB.
+// Watchers are notified of the removed publishers with eventType.
+func deleteLeasePublishers(publishers map[string][]string, eventType string) {
+	// Publishers registered again, or removed by themselves, are not removed
+	batch := make(map[string][]string, len(publishers))
+	properties := make(map[expiredPublisher]map[string]interface{})
+	var pending []expiredPublisher
+	for _, name := range sortedNames(publishers) {
+		for _, endpoint := range publishers[name] {
+			publisher := expiredPublisher{name, endpoint}
+			if isRegistered(publisher) {
+				continue
+			}
+			props, err := readPublisher(publisher)
+			if _, notFound := err.(errors.NotFound); notFound {
+				continue
+			}
+			// Not restored if it could not be read
+			properties[publisher] = props
+			batch[name] = append(batch[name], endpoint)
+			pending = append(pending, publisher)
+		}
+	}
+	if len(pending) == 0 {
+		return
+	}
+
+	var err error
+	delay := deleteRetryDelay
+	for retry := 0; retry < MAX_DELETE_RETRY; retry++ {
+		if retry != 0 {
+			time.Sleep(delay)
+			delay *= 2
+		}
+
+		err = topicDbExecutor.DeletePublishers(batch)
+		if err == nil {
+			break
+		}
+		logger.Logging(logger.ERROR, "DeletePublishers failed: "+err.Error())
+	}
+
+	if err != nil {
+		logger.Logging(logger.ERROR, "DeletePublishers gave up, rescheduled")
+
+		kaInfo.Lock()
+		for _, publisher := range pending {
+			rescheduleDeletion(publisher.name, publisher.endpoint)
+		}
+		kaInfo.Unlock()
+		return
+	}
+
+	for _, publisher := range pending {
+		if isRegistered(publisher) {
+			if properties[publisher] != nil {
+				restorePublisher(publisher, properties[publisher])
+			}
+			continue
+		}
+		watchExecutor.Publish(eventType, map[string]interface{}{"name": publisher.name, "endpoint": publisher.endpoint})
+	}
+}
+
+// sortedNames returns the topic names of publishers in order.
+func sortedNames(publishers map[string][]string) []string {
+	names := make([]string, 0, len(publishers))
+	for name := range publishers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func newLeaseID() (string, error) {
+	id := make([]byte, LEASE_ID_LENGTH)
+	if _, err := rand.Read(id); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(id), nil
+}
@@ -0,0 +1,332 @@
+/*******************************************************************************
+ * Copyright 2018 Samsung Electronics All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ *******************************************************************************/
+package keepalive
+
+import (
+	"github.com/golang/mock/gomock"
+	"reflect"
+	"testing"
+	"time"
+	"tns/commons/errors"
+	watchController "tns/controller/watch"
+	watchControllerMock "tns/controller/watch/mocks"
+	topicDbMock "tns/db/topic/mocks"
+)
+
+func TestCallGrantLease(t *testing.T) {
+	// Mock is not necessary for this test
+
+	initKeepAliveForTest(60, 0, nil, nil)
+	kaInfo.minInterval = 30
+	kaInfo.maxInterval = 300
+
+	testCases := []struct {
+		name          string
+		body          string
+		expectedTTL   uint
+		expectedError error
+	}{
+		{"Success", `{"ttl":60}`, 60, nil},
+		{"Success_Min", `{"ttl":10}`, 30, nil},
+		{"Success_Max", `{"ttl":1000}`, 300, nil},
+		{"WithoutTTL", `{}`, 0, errors.InvalidParam{}},
+		{"InvalidTTL", `{"ttl":-1}`, 0, errors.InvalidParam{}},
+		{"InvalidTTL_Fraction", `{"ttl":1.5}`, 0, errors.InvalidParam{}},
+		{"InvalidJSON", `{"ttl":`, 0, errors.InvalidJSON{}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			resp, err := Handler.GrantLease(tc.body)
+			if reflect.TypeOf(err) != reflect.TypeOf(tc.expectedError) {
+				t.Errorf("Expected Error: %s, Actual: %s", tc.expectedError, err)
+			}
+			if err != nil {
+				return
+			}
+
+			id, _ := resp["lease_id"].(string)
+			if len(id) != LEASE_ID_LENGTH*2 || resp["ttl"] != tc.expectedTTL {
+				t.Errorf("Unexpected response: %v", resp)
+			}
+			if _, exists := kaInfo.leases[id]; !exists {
+				t.Errorf("Lease is not granted: %s", id)
+			}
+		})
+	}
+
+	initKeepAliveForTest(60, 0, nil, nil)
+}
+
+func TestCallGrantLeaseWithNoLease(t *testing.T) {
+	// Mock is not necessary for this test
+
+	initKeepAliveForTest(60, 0, nil, nil)
+	kaInfo.noLease = true
+	defer initKeepAliveForTest(60, 0, nil, nil)
+
+	_, err := Handler.GrantLease(`{"ttl":60}`)
+	if reflect.TypeOf(err) != reflect.TypeOf(errors.InvalidMethod{}) {
+		t.Errorf("Expected Error: %s, Actual: %s", errors.InvalidMethod{}, err)
+	}
+	if len(kaInfo.leases) != 0 {
+		t.Errorf("Lease is granted: %v", kaInfo.leases)
+	}
+}
+
+func TestLease(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	topicDbMockObj := topicDbMock.NewMockCommand(ctrl)
+	watchControllerMockObj := watchControllerMock.NewMockCommand(ctrl)
+
+	// pass mockObj to a real object.
+	topicDbExecutor = topicDbMockObj
+	watchExecutor = watchControllerMockObj
+	defer func() { watchExecutor = watchController.Executor{} }()
+
+	initKeepAliveForTest(60, 0, nil, nil)
+
+	resp, err := Handler.GrantLease(`{"ttl":60}`)
+	if err != nil {
+		t.Fatalf("GrantLease returned an error: %s", err.Error())
+	}
+	id := resp["lease_id"].(string)
+
+	// Attach
+	topicDbMockObj.EXPECT().UpdateLastSeen(gomock.Any(), "0.0.0.0:1234", gomock.Any()).Return(nil).Times(2)
+
+	for _, name := range []string{"/a", "/b"} {
+		if err := Handler.AddTopicWithLease(name, "0.0.0.0:1234", id); err != nil {
+			t.Errorf("AddTopicWithLease returned an error: %s", err.Error())
+		}
+	}
+	if err := Handler.AddTopicWithLease("/c", "0.0.0.0:1234", "unknown"); reflect.TypeOf(err) != reflect.TypeOf(errors.NotFound{}) {
+		t.Errorf("Expected Error: %s, Actual: %s", errors.NotFound{}, err)
+	}
+	if len(kaInfo.expiry) != 0 {
+		t.Errorf("Publishers with lease are scheduled: %d", len(kaInfo.expiry))
+	}
+
+	resp, err = Handler.ReadLease(id)
+	expectedTopics := []map[string]interface{}{{"name": "/a", "endpoint": "0.0.0.0:1234"}, {"name": "/b", "endpoint": "0.0.0.0:1234"}}
+	if err != nil || !reflect.DeepEqual(resp["topics"], expectedTopics) || resp["remaining"] != uint(60) {
+		t.Errorf("Unexpected lease: %v, %v", resp, err)
+	}
+
+	// Renew
+	topicDbMockObj.EXPECT().UpdateLastSeen(gomock.Any(), "0.0.0.0:1234", gomock.Any()).Return(nil)
+
+	resp, err = Handler.HandlePing(`{"lease_id":"` + id + `"}`)
+	if err != nil || resp["lease_id"] != id || resp["ttl"] != uint(60) {
+		t.Errorf("Unexpected renewal: %v, %v", resp, err)
+	}
+	if _, err = Handler.HandlePing(`{"lease_id":"unknown"}`); reflect.TypeOf(err) != reflect.TypeOf(errors.NotFound{}) {
+		t.Errorf("Expected Error: %s, Actual: %s", errors.NotFound{}, err)
+	}
+
+	// Registered again without the lease
+	topicDbMockObj.EXPECT().UpdateLastSeen([]string{"/b"}, "0.0.0.0:1234", gomock.Any()).Return(nil)
+
+	Handler.AddTopic("/b", "0.0.0.0:1234", 0)
+	if len(kaInfo.leases[id].entries) != 1 || len(kaInfo.expiry) != 1 {
+		t.Errorf("Publisher is not detached from lease: %v", kaInfo.table["/b"])
+	}
+
+	// Revoke, publishers are removed from DB in the background
+	deleted := make(chan struct{})
+	gomock.InOrder(
+		topicDbMockObj.EXPECT().ReadTopic("/a", false, "").Return(leaseTopicsForTest("/a"), nil),
+		topicDbMockObj.EXPECT().DeletePublishers(map[string][]string{"/a": {"0.0.0.0:1234"}}).Return(nil),
+		watchControllerMockObj.EXPECT().Publish(watchController.EVENT_DELETED,
+			map[string]interface{}{"name": "/a", "endpoint": "0.0.0.0:1234"}).Do(func(string, map[string]interface{}) {
+			close(deleted)
+		}),
+	)
+
+	if err := Handler.RevokeLease(id); err != nil {
+		t.Errorf("RevokeLease returned an error: %s", err.Error())
+	}
+	select {
+	case <-deleted:
+	case <-time.After(3 * time.Second):
+		t.Fatal("Publishers are not removed from DB")
+	}
+	if _, exists := kaInfo.table["/a"]; exists {
+		t.Error("Publisher is not removed with lease")
+	}
+	if _, exists := kaInfo.table["/b"]; !exists {
+		t.Error("Publisher without lease is removed")
+	}
+	if err := Handler.RevokeLease(id); reflect.TypeOf(err) != reflect.TypeOf(errors.NotFound{}) {
+		t.Errorf("Expected Error: %s, Actual: %s", errors.NotFound{}, err)
+	}
+}
+
+func TestLeaseExpired(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	topicDbMockObj := topicDbMock.NewMockCommand(ctrl)
+	watchControllerMockObj := watchControllerMock.NewMockCommand(ctrl)
+
+	// pass mockObj to a real object.
+	topicDbExecutor = topicDbMockObj
+	watchExecutor = watchControllerMockObj
+	defer func() { watchExecutor = watchController.Executor{} }()
+
+	initKeepAliveForTest(1, 0, nil, nil)
+
+	resp, err := Handler.GrantLease(`{"ttl":1}`)
+	if err != nil {
+		t.Fatalf("GrantLease returned an error: %s", err.Error())
+	}
+	id := resp["lease_id"].(string)
+
+	deleted := make(chan struct{})
+	gomock.InOrder(
+		topicDbMockObj.EXPECT().UpdateLastSeen([]string{"/a"}, "0.0.0.0:1234", gomock.Any()).Return(nil),
+		topicDbMockObj.EXPECT().ReadTopic("/a", false, "").Return(leaseTopicsForTest("/a"), nil),
+		topicDbMockObj.EXPECT().DeletePublishers(map[string][]string{"/a": {"0.0.0.0:1234"}}).Return(nil),
+		watchControllerMockObj.EXPECT().Publish(watchController.EVENT_EXPIRED,
+			map[string]interface{}{"name": "/a", "endpoint": "0.0.0.0:1234"}).Do(func(string, map[string]interface{}) {
+			close(deleted)
+		}),
+	)
+
+	if err := Handler.AddTopicWithLease("/a", "0.0.0.0:1234", id); err != nil {
+		t.Errorf("AddTopicWithLease returned an error: %s", err.Error())
+	}
+
+	select {
+	case <-deleted:
+	case <-time.After(3 * time.Second):
+		t.Fatal("Lease is not expired")
+	}
+
+	if _, err := Handler.ReadLease(id); reflect.TypeOf(err) != reflect.TypeOf(errors.NotFound{}) {
+		t.Errorf("Expected Error: %s, Actual: %s", errors.NotFound{}, err)
+	}
+	if _, exists := kaInfo.table["/a"]; exists {
+		t.Error("Publisher is not removed with lease")
+	}
+}
+
+func TestDeleteLeasePublishers(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	topicDbMockObj := topicDbMock.NewMockCommand(ctrl)
+	watchControllerMockObj := watchControllerMock.NewMockCommand(ctrl)
+
+	// pass mockObj to a real object.
+	topicDbExecutor = topicDbMockObj
+	watchExecutor = watchControllerMockObj
+	defer func() { watchExecutor = watchController.Executor{} }()
+
+	deleteRetryDelay = time.Millisecond
+	defer func() { deleteRetryDelay = 100 * time.Millisecond }()
+
+	publishers := map[string][]string{"/a": {"0.0.0.0:1234"}, "/b": {"0.0.0.0:1234"}}
+	publishDeleted := func(names ...string) []*gomock.Call {
+		var calls []*gomock.Call
+		for _, name := range names {
+			calls = append(calls, watchControllerMockObj.EXPECT().Publish(watchController.EVENT_DELETED,
+				map[string]interface{}{"name": name, "endpoint": "0.0.0.0:1234"}))
+		}
+		return calls
+	}
+	readTopics := func() []*gomock.Call {
+		return []*gomock.Call{
+			topicDbMockObj.EXPECT().ReadTopic("/a", false, "").Return(leaseTopicsForTest("/a"), nil),
+			topicDbMockObj.EXPECT().ReadTopic("/b", false, "").Return(leaseTopicsForTest("/b"), nil),
+		}
+	}
+
+	testCases := []struct {
+		name        string
+		expect      func() []*gomock.Call
+		rescheduled int
+	}{
+		{"Success", func() []*gomock.Call {
+			calls := append(readTopics(), topicDbMockObj.EXPECT().DeletePublishers(publishers).Return(nil))
+			return append(calls, publishDeleted("/a", "/b")...)
+		}, 0},
+		{"Success_PartiallyFailed", func() []*gomock.Call {
+			// "/a" has been removed by the failed removal, which is ignored on the retry
+			calls := append(readTopics(),
+				topicDbMockObj.EXPECT().DeletePublishers(publishers).Return(errors.InternalServerError{}),
+				topicDbMockObj.EXPECT().DeletePublishers(publishers).Return(nil))
+			return append(calls, publishDeleted("/a", "/b")...)
+		}, 0},
+		{"Success_RemovedByPublisher", func() []*gomock.Call {
+			return append([]*gomock.Call{
+				topicDbMockObj.EXPECT().ReadTopic("/a", false, "").Return([]map[string]interface{}{}, nil),
+				topicDbMockObj.EXPECT().ReadTopic("/b", false, "").Return(leaseTopicsForTest("/b"), nil),
+				topicDbMockObj.EXPECT().DeletePublishers(map[string][]string{"/b": {"0.0.0.0:1234"}}).Return(nil),
+			}, publishDeleted("/b")...)
+		}, 0},
+		{"Success_RegisteredDuringDeletion", func() []*gomock.Call {
+			// "/a" is restored in DB after it is removed
+			calls := append(readTopics(),
+				topicDbMockObj.EXPECT().DeletePublishers(publishers).Do(func(map[string][]string) {
+					kaInfo.Lock()
+					setLastSeen("/a", "0.0.0.0:1234", time.Now())
+					kaInfo.Unlock()
+				}).Return(nil),
+				topicDbMockObj.EXPECT().ReadTopic("/a", false, "").Return([]map[string]interface{}{}, nil),
+				topicDbMockObj.EXPECT().CreateTopic(map[string]interface{}{"name": "/a", "endpoint": "0.0.0.0:1234",
+					"datamodel": "test_0.0.1", "secured": false, "labels": map[string]interface{}{}}).Return(true, nil))
+			return append(calls, publishDeleted("/b")...)
+		}, 1},
+		{"GaveUp", func() []*gomock.Call {
+			return append(readTopics(),
+				topicDbMockObj.EXPECT().DeletePublishers(publishers).Return(errors.Unknown{}).Times(MAX_DELETE_RETRY))
+		}, 2},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			initKeepAliveForTest(10, 0, nil, nil)
+			gomock.InOrder(tc.expect()...)
+
+			deleteLeasePublishers(publishers, watchController.EVENT_DELETED)
+
+			// Publishers which could not be removed expire again one by one
+			kaInfo.Lock()
+			defer kaInfo.Unlock()
+			if len(kaInfo.expiry) != tc.rescheduled {
+				t.Errorf("Expected %d publishers scheduled, Actual: %d", tc.rescheduled, len(kaInfo.expiry))
+			}
+			if tc.name != "GaveUp" {
+				return
+			}
+			for _, entry := range kaInfo.expiry {
+				if entry.deadline.Before(time.Now().Add(deleteRescheduleDelay / 2)) {
+					t.Errorf("Unexpected deadline: %v", entry.deadline)
+				}
+			}
+		})
+	}
+}
+
+// leaseTopicsForTest returns the topic of the publisher at "0.0.0.0:1234" as read from DB.
+func leaseTopicsForTest(name string) []map[string]interface{} {
+	return []map[string]interface{}{{"name": name, "endpoints": []string{"0.0.0.0:1234"}, "datamodel": "test_0.0.1",
+		"secured": false, "labels": map[string]string{}}}
+}
@@ -24,6 +24,7 @@ package mock_keepalive
 import (
 	gomock "github.com/golang/mock/gomock"
 	reflect "reflect"
+	keepalive "tns/controller/keepalive"
 )
 
 // MockCommand is a mock of Command interface
@@ -50,25 +51,25 @@ func (m *MockCommand) EXPECT() *MockCommandMockRecorder {
 }
 
 // InitKeepAlive mocks base method
-func (m *MockCommand) InitKeepAlive(interval uint) error {
-	ret := m.ctrl.Call(m, "InitKeepAlive", interval)
+func (m *MockCommand) InitKeepAlive(config keepalive.Config) error {
+	ret := m.ctrl.Call(m, "InitKeepAlive", config)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // InitKeepAlive indicates an expected call of InitKeepAlive
-func (mr *MockCommandMockRecorder) InitKeepAlive(interval interface{}) *gomock.Call {
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InitKeepAlive", reflect.TypeOf((*MockCommand)(nil).InitKeepAlive), interval)
+func (mr *MockCommandMockRecorder) InitKeepAlive(config interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InitKeepAlive", reflect.TypeOf((*MockCommand)(nil).InitKeepAlive), config)
 }
 
 // AddTopic mocks base method
-func (m *MockCommand) AddTopic(name string) {
-	m.ctrl.Call(m, "AddTopic", name)
+func (m *MockCommand) AddTopic(name, endpoint string, interval uint) {
+	m.ctrl.Call(m, "AddTopic", name, endpoint, interval)
 }
 
 // AddTopic indicates an expected call of AddTopic
-func (mr *MockCommandMockRecorder) AddTopic(name interface{}) *gomock.Call {
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddTopic", reflect.TypeOf((*MockCommand)(nil).AddTopic), name)
+func (mr *MockCommandMockRecorder) AddTopic(name, endpoint, interval interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddTopic", reflect.TypeOf((*MockCommand)(nil).AddTopic), name, endpoint, interval)
 }
 
 // DeleteTopic mocks base method
@@ -81,6 +82,26 @@ func (mr *MockCommandMockRecorder) DeleteTopic(name interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteTopic", reflect.TypeOf((*MockCommand)(nil).DeleteTopic), name)
 }
 
+// DeletePublisher mocks base method
+func (m *MockCommand) DeletePublisher(name, endpoint string) {
+	m.ctrl.Call(m, "DeletePublisher", name, endpoint)
+}
+
+// DeletePublisher indicates an expected call of DeletePublisher
+func (mr *MockCommandMockRecorder) DeletePublisher(name, endpoint interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeletePublisher", reflect.TypeOf((*MockCommand)(nil).DeletePublisher), name, endpoint)
+}
+
+// SetEndpoints mocks base method
+func (m *MockCommand) SetEndpoints(name string, endpoints []string) {
+	m.ctrl.Call(m, "SetEndpoints", name, endpoints)
+}
+
+// SetEndpoints indicates an expected call of SetEndpoints
+func (mr *MockCommandMockRecorder) SetEndpoints(name, endpoints interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetEndpoints", reflect.TypeOf((*MockCommand)(nil).SetEndpoints), name, endpoints)
+}
+
 // HandlePing mocks base method
 func (m *MockCommand) HandlePing(body string) (map[string]interface{}, error) {
 	ret := m.ctrl.Call(m, "HandlePing", body)
@@ -95,13 +116,87 @@ func (mr *MockCommandMockRecorder) HandlePing(body interface{}) *gomock.Call {
 }
 
 // GetInterval mocks base method
-func (m *MockCommand) GetInterval() uint {
-	ret := m.ctrl.Call(m, "GetInterval")
+func (m *MockCommand) GetInterval(name string) uint {
+	ret := m.ctrl.Call(m, "GetInterval", name)
 	ret0, _ := ret[0].(uint)
 	return ret0
 }
 
 // GetInterval indicates an expected call of GetInterval
-func (mr *MockCommandMockRecorder) GetInterval() *gomock.Call {
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetInterval", reflect.TypeOf((*MockCommand)(nil).GetInterval))
+func (mr *MockCommandMockRecorder) GetInterval(name interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetInterval", reflect.TypeOf((*MockCommand)(nil).GetInterval), name)
+}
+
+// GetStatus mocks base method
+func (m *MockCommand) GetStatus(name string) string {
+	ret := m.ctrl.Call(m, "GetStatus", name)
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// GetStatus indicates an expected call of GetStatus
+func (mr *MockCommandMockRecorder) GetStatus(name interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetStatus", reflect.TypeOf((*MockCommand)(nil).GetStatus), name)
+}
+
+// GetLiveness mocks base method
+func (m *MockCommand) GetLiveness(name string) map[string]keepalive.Liveness {
+	ret := m.ctrl.Call(m, "GetLiveness", name)
+	ret0, _ := ret[0].(map[string]keepalive.Liveness)
+	return ret0
+}
+
+// GetLiveness indicates an expected call of GetLiveness
+func (mr *MockCommandMockRecorder) GetLiveness(name interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLiveness", reflect.TypeOf((*MockCommand)(nil).GetLiveness), name)
+}
+
+// GrantLease mocks base method
+func (m *MockCommand) GrantLease(body string) (map[string]interface{}, error) {
+	ret := m.ctrl.Call(m, "GrantLease", body)
+	ret0, _ := ret[0].(map[string]interface{})
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GrantLease indicates an expected call of GrantLease
+func (mr *MockCommandMockRecorder) GrantLease(body interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GrantLease", reflect.TypeOf((*MockCommand)(nil).GrantLease), body)
+}
+
+// ReadLease mocks base method
+func (m *MockCommand) ReadLease(id string) (map[string]interface{}, error) {
+	ret := m.ctrl.Call(m, "ReadLease", id)
+	ret0, _ := ret[0].(map[string]interface{})
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReadLease indicates an expected call of ReadLease
+func (mr *MockCommandMockRecorder) ReadLease(id interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadLease", reflect.TypeOf((*MockCommand)(nil).ReadLease), id)
+}
+
+// RevokeLease mocks base method
+func (m *MockCommand) RevokeLease(id string) error {
+	ret := m.ctrl.Call(m, "RevokeLease", id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeLease indicates an expected call of RevokeLease
+func (mr *MockCommandMockRecorder) RevokeLease(id interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeLease", reflect.TypeOf((*MockCommand)(nil).RevokeLease), id)
+}
+
+// AddTopicWithLease mocks base method
+func (m *MockCommand) AddTopicWithLease(name, endpoint, id string) error {
+	ret := m.ctrl.Call(m, "AddTopicWithLease", name, endpoint, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddTopicWithLease indicates an expected call of AddTopicWithLease
+func (mr *MockCommandMockRecorder) AddTopicWithLease(name, endpoint, id interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddTopicWithLease", reflect.TypeOf((*MockCommand)(nil).AddTopicWithLease), name, endpoint, id)
 }
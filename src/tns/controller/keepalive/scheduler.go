@@ -0,0 +1,360 @@
+/*******************************************************************************
+ * Copyright 2018 Samsung Electronics All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ *******************************************************************************/
+package keepalive
+
+import (
+	"container/heap"
+	"time"
+	"tns/commons/errors"
+	"tns/commons/logger"
+	watchController "tns/controller/watch"
+)
+
+// The expiry scheduler keeps publishers in a min-heap ordered by their deadlines,
+// so that the timer loop sleeps until the earliest deadline and takes only the
+// expired publishers out of it, instead of scanning the whole table.
+// Expired publishers are removed from DB by a worker outside of kaInfo lock.
+
+const (
+	DELETION_QUEUE_SIZE = 1024
+	MAX_DELETE_RETRY    = 3
+)
+
+// Delay before the first retry of a failed deletion, doubled on each retry.
+var deleteRetryDelay = 100 * time.Millisecond
+
+// Delay before a publisher expires again after its deletion has given up.
+var deleteRescheduleDelay = time.Minute
+
+// kaEntry is a publisher of a topic in keep-alive.
+type kaEntry struct {
+	name     string
+	endpoint string
+	lastSeen time.Time
+	deadline time.Time // lastSeen + interval of the topic + grace period
+	index    int       // Index in kaHeap, maintained by heap.Interface, -1 if not in it
+	lease    *kaLease  // Lease which keeps the publisher alive instead of the scheduler
+}
+
+// kaHeap implements heap.Interface, the entry of the earliest deadline comes first.
+type kaHeap []*kaEntry
+
+func (h kaHeap) Len() int           { return len(h) }
+func (h kaHeap) Less(i, j int) bool { return h[i].deadline.Before(h[j].deadline) }
+
+func (h kaHeap) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].index = i
+	h[j].index = j
+}
+
+func (h *kaHeap) Push(x interface{}) {
+	entry := x.(*kaEntry)
+	entry.index = len(*h)
+	*h = append(*h, entry)
+}
+
+func (h *kaHeap) Pop() interface{} {
+	old := *h
+	n := len(old)
+	entry := old[n-1]
+	old[n-1] = nil
+	entry.index = -1
+	*h = old[:n-1]
+	return entry
+}
+
+// expiredPublisher is a publisher to be removed from DB.
+type expiredPublisher struct {
+	name     string
+	endpoint string
+}
+
+// The followings should be called with kaInfo locked.
+
+// setLastSeen records the keep-alive time of the publisher and reschedules it.
+// Publishers attached to a lease are not scheduled.
+func setLastSeen(name string, endpoint string, lastSeen time.Time) {
+	entry := getEntry(name, endpoint)
+	entry.lastSeen = lastSeen
+	if entry.lease != nil {
+		return
+	}
+	entry.deadline = deadlineOf(name, lastSeen)
+
+	if entry.index >= 0 {
+		heap.Fix(&kaInfo.expiry, entry.index)
+	} else {
+		heap.Push(&kaInfo.expiry, entry)
+	}
+
+	if entry.index == 0 {
+		wakeUpTimerLoop()
+	}
+}
+
+// getEntry returns the publisher in the table, which is added if not exists.
+func getEntry(name string, endpoint string) *kaEntry {
+	publishers, exists := kaInfo.table[name]
+	if !exists {
+		publishers = make(map[string]*kaEntry)
+		kaInfo.table[name] = publishers
+	}
+
+	entry, exists := publishers[endpoint]
+	if !exists {
+		entry = &kaEntry{name: name, endpoint: endpoint, index: -1}
+		publishers[endpoint] = entry
+	}
+	return entry
+}
+
+// rescheduleTopic updates the deadlines of all publishers of the topic,
+// e.g., after its interval is changed.
+func rescheduleTopic(name string) {
+	for _, entry := range kaInfo.table[name] {
+		if entry.index < 0 {
+			continue
+		}
+		entry.deadline = deadlineOf(name, entry.lastSeen)
+		heap.Fix(&kaInfo.expiry, entry.index)
+	}
+	wakeUpTimerLoop()
+}
+
+// removeEntry stops keep-alive of the publisher.
+// The topic is removed from the table with its last publisher.
+func removeEntry(entry *kaEntry) {
+	if entry.index >= 0 {
+		heap.Remove(&kaInfo.expiry, entry.index)
+	}
+	detachLease(entry)
+
+	publishers := kaInfo.table[entry.name]
+	delete(publishers, entry.endpoint)
+	if len(publishers) == 0 {
+		delete(kaInfo.table, entry.name)
+		delete(kaInfo.intervals, entry.name)
+	}
+}
+
+// popExpired removes the publishers whose deadlines have passed and returns them.
+func popExpired(now time.Time) []expiredPublisher {
+	var expired []expiredPublisher
+	for len(kaInfo.expiry) != 0 && !kaInfo.expiry[0].deadline.After(now) {
+		entry := heap.Pop(&kaInfo.expiry).(*kaEntry)
+		removeEntry(entry)
+		expired = append(expired, expiredPublisher{entry.name, entry.endpoint})
+	}
+	return expired
+}
+
+func deadlineOf(name string, lastSeen time.Time) time.Time {
+	return lastSeen.Add(expiryPeriod(name))
+}
+
+// expiryPeriod returns the time from the last keep-alive until a publisher of
+// the topic is removed.
+func expiryPeriod(name string) time.Duration {
+	return time.Duration(topicInterval(name))*time.Second + kaInfo.gracePeriod
+}
+
+// rescheduleDeletion puts the publisher which could not be removed from DB back
+// into the schedule, so that it expires again after deleteRescheduleDelay.
+// Keep-alive of the publisher in the meantime makes it alive again.
+func rescheduleDeletion(name string, endpoint string) {
+	if _, registered := kaInfo.table[name][endpoint]; registered {
+		return
+	}
+	deadline := time.Now().Add(deleteRescheduleDelay)
+	setLastSeen(name, endpoint, deadline.Add(-expiryPeriod(name)))
+}
+
+// wakeUpTimerLoop lets the timer loop recalculate its sleep for an earlier deadline.
+func wakeUpTimerLoop() {
+	select {
+	case kaInfo.wakeUp <- struct{}{}:
+	default:
+		// Already notified
+	}
+}
+
+// keepAliveTimerLoop sleeps until the earliest deadline and passes the expired
+// publishers to the deletion worker, until stop is closed.
+func keepAliveTimerLoop(wakeUp <-chan struct{}, deletion chan<- expiredPublisher, stop <-chan struct{}) {
+	logger.Logging(logger.DEBUG, "Start KeepAlive Timer loop")
+	defer logger.Logging(logger.DEBUG, "KeepAlive Timer loop Finished")
+
+	for {
+		kaInfo.Lock()
+		expired := popExpired(time.Now())
+		var timer *time.Timer
+		var timeout <-chan time.Time
+		if len(kaInfo.expiry) != 0 {
+			timer = time.NewTimer(time.Until(kaInfo.expiry[0].deadline))
+			timeout = timer.C
+		}
+		kaInfo.Unlock()
+
+		stopped := false
+		for _, publisher := range expired {
+			logger.Logging(logger.DEBUG, "KeepAlive time expired: "+publisher.name+" "+publisher.endpoint)
+			select {
+			case deletion <- publisher:
+			case <-stop:
+				stopped = true
+			}
+			if stopped {
+				break
+			}
+		}
+
+		if !stopped {
+			select {
+			case <-timeout:
+			case <-wakeUp:
+			case <-stop:
+				stopped = true
+			}
+		}
+
+		if timer != nil {
+			timer.Stop()
+		}
+		if stopped {
+			return
+		}
+	}
+}
+
+// deletionWorker removes the expired publishers from DB until stop is closed.
+func deletionWorker(deletion <-chan expiredPublisher, stop <-chan struct{}) {
+	for {
+		select {
+		case publisher := <-deletion:
+			deletePublisher(publisher)
+		case <-stop:
+			return
+		}
+	}
+}
+
+// deletePublisher removes the publisher from DB, the topic is deleted with its
+// last publisher. It is retried up to MAX_DELETE_RETRY times on failures, and
+// then rescheduled to expire again.
+// DB is not accessed under kaInfo lock, so the publisher registered again
+// during the deletion is restored in DB afterwards.
+func deletePublisher(publisher expiredPublisher) {
+	delay := deleteRetryDelay
+	for retry := 0; retry < MAX_DELETE_RETRY; retry++ {
+		if retry != 0 {
+			time.Sleep(delay)
+			delay *= 2
+		}
+
+		if isRegistered(publisher) {
+			return
+		}
+
+		properties, err := readPublisher(publisher)
+		if err == nil {
+			err = topicDbExecutor.DeletePublisher(publisher.name, publisher.endpoint)
+		}
+
+		switch err.(type) {
+		case nil:
+			if isRegistered(publisher) {
+				restorePublisher(publisher, properties)
+				return
+			}
+			logger.Logging(logger.DEBUG, "Publisher deleted: "+publisher.name+" "+publisher.endpoint)
+			watchExecutor.Publish(watchController.EVENT_EXPIRED, map[string]interface{}{"name": publisher.name, "endpoint": publisher.endpoint})
+			return
+		case errors.NotFound:
+			// Already removed by the publisher
+			return
+		}
+		logger.Logging(logger.ERROR, "DeletePublisher failed: "+err.Error())
+	}
+
+	logger.Logging(logger.ERROR, "DeletePublisher gave up, rescheduled: "+publisher.name+" "+publisher.endpoint)
+
+	kaInfo.Lock()
+	rescheduleDeletion(publisher.name, publisher.endpoint)
+	kaInfo.Unlock()
+}
+
+// isRegistered returns whether the publisher is in keep-alive, e.g., registered
+// again after it has expired.
+func isRegistered(publisher expiredPublisher) bool {
+	kaInfo.Lock()
+	defer kaInfo.Unlock()
+
+	_, registered := kaInfo.table[publisher.name][publisher.endpoint]
+	return registered
+}
+
+// readPublisher returns the properties to register the publisher again, which
+// are read from its topic in DB. NotFound is returned if it is not in DB.
+func readPublisher(publisher expiredPublisher) (map[string]interface{}, error) {
+	topics, err := topicDbExecutor.ReadTopic(publisher.name, false, "")
+	if err != nil {
+		return nil, err
+	}
+
+	for _, topic := range topics {
+		endpoints, _ := topic["endpoints"].([]string)
+		for _, endpoint := range endpoints {
+			if endpoint != publisher.endpoint {
+				continue
+			}
+
+			properties := map[string]interface{}{"name": publisher.name, "endpoint": endpoint}
+			for _, key := range []string{"datamodel", "secured"} {
+				if value, exists := topic[key]; exists {
+					properties[key] = value
+				}
+			}
+			// Labels are given as in JSON
+			if labels, exists := topic["labels"].(map[string]string); exists {
+				labelsMap := make(map[string]interface{}, len(labels))
+				for key, value := range labels {
+					labelsMap[key] = value
+				}
+				properties["labels"] = labelsMap
+			}
+			return properties, nil
+		}
+	}
+
+	return nil, errors.NotFound{publisher.name}
+}
+
+// restorePublisher registers the publisher in DB again with the properties read
+// before its deletion, if it has been removed by the deletion after it was
+// registered again.
+func restorePublisher(publisher expiredPublisher, properties map[string]interface{}) {
+	if _, err := readPublisher(publisher); err == nil {
+		// Registered again after the deletion
+		return
+	}
+
+	logger.Logging(logger.DEBUG, "Publisher registered again during deletion: "+publisher.name+" "+publisher.endpoint)
+	if _, err := topicDbExecutor.CreateTopic(properties); err != nil {
+		logger.Logging(logger.ERROR, "CreateTopic failed: "+err.Error())
+	}
+}
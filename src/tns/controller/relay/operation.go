@@ -0,0 +1,283 @@
+/*******************************************************************************
+ * Copyright 2018 Samsung Electronics All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ *******************************************************************************/
+package relay
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"tns/commons/errors"
+	"tns/commons/logger"
+	watchController "tns/controller/watch"
+)
+
+// Kinds of operations.
+const (
+	opRequest   = iota // Passes a change of a topic upstream
+	opKeepAlive        // Keeps all local topics alive upstream
+	opResync           // Registers all local topics upstream again
+)
+
+// operation is sent to the upstream server with its REST APIs.
+type operation struct {
+	id     uint64
+	kind   int
+	method string
+	path   string // e.g., "/tns/topic"
+	query  url.Values
+	body   map[string]interface{}
+}
+
+// convertToOperation returns the operation which applies the event of a local
+// topic upstream.
+func convertToOperation(event watchController.Event) operation {
+	topic := event.Topic
+	name, _ := topic["name"].(string)
+	endpoint, _ := topic["endpoint"].(string)
+
+	switch event.Type {
+	case watchController.EVENT_CREATED:
+		return registration(topic)
+
+	case watchController.EVENT_UPDATED:
+		if _, updated := topic["endpoints"]; !updated {
+			// Registered again by the publisher, whose event has the publisher only
+			return registration(topic)
+		}
+		return update(topic)
+
+	case watchController.EVENT_DELETED, watchController.EVENT_EXPIRED:
+		query := url.Values{"name": {name}}
+		if endpoint != "" {
+			query.Set("endpoint", endpoint)
+		}
+		return operation{kind: opRequest, method: http.MethodDelete, path: "/tns/topic", query: query}
+	}
+
+	// Events have been missed
+	return operation{kind: opResync}
+}
+
+// registration returns the operation which registers the publisher of the topic.
+func registration(topic map[string]interface{}) operation {
+	registered := make(map[string]interface{})
+	for _, key := range []string{"name", "endpoint", "datamodel", "secured", "labels"} {
+		if value, exists := topic[key]; exists {
+			registered[key] = value
+		}
+	}
+	return operation{kind: opRequest, method: http.MethodPost, path: "/tns/topic",
+		body: map[string]interface{}{"topic": registered}}
+}
+
+// update returns the operation which updates the topic with its current properties.
+// The endpoint is replaced only for a topic with a single publisher, and
+// labels removed from a topic with multiple publishers are kept upstream,
+// since such a topic can be updated only partially.
+func update(topic map[string]interface{}) operation {
+	updated := make(map[string]interface{})
+	for _, key := range []string{"name", "datamodel", "secured", "labels"} {
+		if value, exists := topic[key]; exists {
+			updated[key] = value
+		}
+	}
+
+	method := http.MethodPatch
+	if endpoints, _ := topic["endpoints"].([]string); len(endpoints) == 1 {
+		method = http.MethodPut
+		updated["endpoint"] = endpoints[0]
+	}
+	return operation{kind: opRequest, method: method, path: "/tns/topic",
+		body: map[string]interface{}{"topic": updated}}
+}
+
+// send applies the operation upstream. An error is returned if the upstream
+// server is not reachable, or fails to handle it, so that it should be retried.
+// Operations rejected by the upstream server are not retried.
+func (op operation) send(client *http.Client, upstream string) error {
+	switch op.kind {
+	case opKeepAlive:
+		return sendKeepAlive(client, upstream)
+	case opResync:
+		return sendResync(client, upstream)
+	}
+
+	status, _, err := request(client, upstream, op.method, op.path, op.query, op.body)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case status < http.StatusBadRequest:
+	case op.method == http.MethodDelete && status == http.StatusNotFound:
+		// Removed or expired upstream already
+	default:
+		logger.Logging(logger.ERROR, "Rejected by upstream: "+op.method+" "+op.path+", status "+strconv.Itoa(status))
+	}
+	return nil
+}
+
+// sendKeepAlive keeps all local topics alive upstream, with one keep-alive for
+// each local endpoint, so that the other publishers of the topics upstream,
+// e.g., of the other gateways, still expire without their own keep-alives.
+// Publishers which have expired upstream, e.g., while the uplink was down,
+// are registered again.
+func sendKeepAlive(client *http.Client, upstream string) error {
+	topics, err := topicDbExecutor.ReadTopicAll("")
+	if err != nil {
+		logger.Logging(logger.ERROR, "ReadTopicAll failed: "+err.Error())
+		return nil
+	}
+
+	// Names of the topics by endpoint, in the order of the topics
+	var endpoints []string
+	names := make(map[string][]string)
+	for _, topic := range topics {
+		name, _ := topic["name"].(string)
+		publishers, _ := topic["endpoints"].([]string)
+		for _, endpoint := range publishers {
+			if _, exists := names[endpoint]; !exists {
+				endpoints = append(endpoints, endpoint)
+			}
+			names[endpoint] = append(names[endpoint], name)
+		}
+	}
+
+	expired := make(map[string]map[string]bool)
+	for _, endpoint := range endpoints {
+		status, resp, err := request(client, upstream, http.MethodPost, "/tns/keepalive", nil,
+			map[string]interface{}{"topic_names": names[endpoint], "endpoint": endpoint})
+		if err != nil {
+			return err
+		}
+
+		switch status {
+		case http.StatusOK:
+			continue
+		case http.StatusNotFound:
+		default:
+			logger.Logging(logger.ERROR, "Keep-alive rejected by upstream, status "+strconv.Itoa(status))
+			continue
+		}
+
+		var notFound struct {
+			TopicNames []string `json:"topic_names"`
+		}
+		json.Unmarshal(resp, &notFound)
+
+		for _, name := range notFound.TopicNames {
+			if expired[name] == nil {
+				expired[name] = make(map[string]bool)
+			}
+			expired[name][endpoint] = true
+		}
+	}
+	if len(expired) == 0 {
+		return nil
+	}
+	logger.Logging(logger.DEBUG, "Register expired topics again: "+strconv.Itoa(len(expired)))
+
+	return register(client, upstream, topics, expired)
+}
+
+// sendResync registers all local topics upstream.
+func sendResync(client *http.Client, upstream string) error {
+	topics, err := topicDbExecutor.ReadTopicAll("")
+	if err != nil {
+		logger.Logging(logger.ERROR, "ReadTopicAll failed: "+err.Error())
+		return err
+	}
+
+	return register(client, upstream, topics, nil)
+}
+
+// register registers the publishers of the topics upstream, only the ones
+// in publishers by name and endpoint unless it is nil.
+func register(client *http.Client, upstream string, topics []map[string]interface{}, publishers map[string]map[string]bool) error {
+	for _, topic := range topics {
+		name, _ := topic["name"].(string)
+		if publishers != nil && publishers[name] == nil {
+			continue
+		}
+
+		endpoints, _ := topic["endpoints"].([]string)
+		for _, endpoint := range endpoints {
+			if publishers != nil && !publishers[name][endpoint] {
+				continue
+			}
+
+			publisher := make(map[string]interface{}, len(topic)+1)
+			for key, value := range topic {
+				publisher[key] = value
+			}
+			publisher["endpoint"] = endpoint
+
+			if err := registration(publisher).send(client, upstream); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// request sends the request to the REST API of the upstream server, and returns
+// the status and body of the response. An error is returned for 5xx statuses.
+func request(client *http.Client, upstream string, method string, path string, query url.Values, body map[string]interface{}) (int, []byte, error) {
+	var reader *bytes.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return 0, nil, err
+		}
+		reader = bytes.NewReader(data)
+	} else {
+		reader = bytes.NewReader(nil)
+	}
+
+	target := "http://" + upstream + "/api/v1" + path
+	if len(query) != 0 {
+		target += "?" + query.Encode()
+	}
+
+	req, err := http.NewRequest(method, target, reader)
+	if err != nil {
+		return 0, nil, err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer resp.Body.Close()
+
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	if resp.StatusCode >= http.StatusInternalServerError {
+		return resp.StatusCode, data, errors.Unavailable{method + " " + path + ": " + resp.Status}
+	}
+
+	return resp.StatusCode, data, nil
+}
@@ -0,0 +1,302 @@
+/*******************************************************************************
+ * Copyright 2018 Samsung Electronics All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ *******************************************************************************/
+package relay
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"tns/commons/errors"
+	"tns/commons/logger"
+	watchController "tns/controller/watch"
+	topicDB "tns/db/topic"
+)
+
+// A relay runs on a gateway in front of an upstream TNS server. Publishers
+// behind the gateway register and keep alive their topics on the relay as on
+// any TNS server, and the relay passes the changes of the topics upstream on
+// their behalf. Instead of the keep-alives of each publisher, one keep-alive
+// of all local topics of each endpoint is sent upstream. Changes are queued
+// while the uplink is down, and sent in order when it is restored.
+
+type Config struct {
+	Enabled           bool
+	Upstream          string // "host:port" of REST APIs of the upstream server
+	KeepAliveInterval uint   // Seconds between keep-alives sent upstream, DEFAULT_KEEPALIVE_INTERVAL if 0
+	Timeout           uint   // Seconds to wait for the upstream server, DEFAULT_TIMEOUT if 0
+}
+
+type Command interface {
+	InitRelay(config Config) error
+}
+
+// Executor implements the Command interface.
+type Executor struct{}
+
+const (
+	DEFAULT_KEEPALIVE_INTERVAL = 60 // Second
+	DEFAULT_TIMEOUT            = 10 // Second
+	MAX_QUEUE_SIZE             = 1024
+	MAX_RETRY_DELAY            = time.Minute
+)
+
+type relayInfo struct {
+	sync.Mutex
+	config Config
+	client *http.Client
+	queue  []operation    // Operations to be sent upstream, the oldest first
+	seq    uint64         // ID of the last queued operation
+	wake   chan struct{}  // Signaled when an operation is queued
+	stop   chan struct{}  // Closed to stop the relay, nil if stopped
+	done   sync.WaitGroup // Goroutines of the relay
+	up     bool           // False after a failure until an operation succeeds
+}
+
+var topicDbExecutor topicDB.Command
+var watchExecutor watchController.Command
+var info relayInfo
+
+// Delay before the first retry of a failed operation, doubled on each retry.
+var retryDelay = time.Second
+
+func init() {
+	topicDbExecutor = topicDB.Executor{}
+	watchExecutor = watchController.Executor{}
+}
+
+// InitRelay starts to pass the changes of local topics to the upstream server
+// if config is enabled. The relay started before is stopped with its queue,
+// after the operation being sent is finished.
+// All local topics are registered upstream first.
+func (Executor) InitRelay(config Config) error {
+	logger.Logging(logger.DEBUG, "IN")
+	defer logger.Logging(logger.DEBUG, "OUT")
+
+	if config.Enabled {
+		if config.Upstream == "" || strings.Contains(config.Upstream, "/") {
+			return errors.InvalidParam{"invalid address of upstream: " + config.Upstream}
+		}
+		if config.KeepAliveInterval == 0 {
+			config.KeepAliveInterval = DEFAULT_KEEPALIVE_INTERVAL
+		}
+		if config.Timeout == 0 {
+			config.Timeout = DEFAULT_TIMEOUT
+		}
+	}
+
+	info.Lock()
+	if info.stop != nil {
+		close(info.stop)
+		info.stop = nil
+	}
+	info.Unlock()
+	info.done.Wait()
+
+	info.Lock()
+	defer info.Unlock()
+
+	info.config = config
+	info.queue = nil
+
+	if !config.Enabled {
+		return nil
+	}
+
+	// Subscribed here not to miss the events published right after
+	subscription, err := watchExecutor.Subscribe("", false, "")
+	if err != nil {
+		logger.Logging(logger.ERROR, "Subscribe failed: "+err.Error())
+		return err
+	}
+
+	info.client = &http.Client{Timeout: time.Duration(config.Timeout) * time.Second}
+	info.wake = make(chan struct{}, 1)
+	info.stop = make(chan struct{})
+	info.up = true
+
+	// Topics may have been changed while the relay was not running
+	enqueue(operation{kind: opResync})
+
+	info.done.Add(3)
+	go dispatcher(subscription, info.stop)
+	go sender(info.wake, info.stop)
+	go keepAliver(time.Duration(config.KeepAliveInterval)*time.Second, info.stop)
+
+	return nil
+}
+
+// dispatcher queues the operations for the events of the subscription until
+// stop is closed. It resumes from the last received event when its subscription
+// is dropped.
+func dispatcher(subscription *watchController.Subscription, stop <-chan struct{}) {
+	logger.Logging(logger.DEBUG, "Start Relay dispatcher")
+	defer logger.Logging(logger.DEBUG, "Relay dispatcher Finished")
+	defer info.done.Done()
+
+	lastEventID := ""
+	for {
+		dropped := false
+		for !dropped {
+			select {
+			case event, ok := <-subscription.Events:
+				if !ok {
+					dropped = true
+					break
+				}
+				lastEventID = event.ID
+				info.Lock()
+				if info.stop == stop {
+					enqueue(convertToOperation(event))
+				}
+				info.Unlock()
+			case <-stop:
+				watchExecutor.Unsubscribe(subscription)
+				return
+			}
+		}
+
+		var err error
+		subscription, err = watchExecutor.Subscribe("", false, lastEventID)
+		if err != nil {
+			logger.Logging(logger.ERROR, "Subscribe failed: "+err.Error())
+			return
+		}
+	}
+}
+
+// keepAliver queues a keep-alive of all local topics every interval until
+// stop is closed.
+func keepAliver(interval time.Duration, stop <-chan struct{}) {
+	defer info.done.Done()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			info.Lock()
+			if info.stop == stop {
+				enqueue(operation{kind: opKeepAlive})
+			}
+			info.Unlock()
+		case <-stop:
+			return
+		}
+	}
+}
+
+// sender sends the queued operations upstream one by one in order until stop
+// is closed. Failed operations are retried with exponential backoff.
+func sender(wake <-chan struct{}, stop <-chan struct{}) {
+	logger.Logging(logger.DEBUG, "Start Relay sender")
+	defer logger.Logging(logger.DEBUG, "Relay sender Finished")
+	defer info.done.Done()
+
+	delay := retryDelay
+	for {
+		info.Lock()
+		if info.stop != stop {
+			info.Unlock()
+			return
+		}
+		var op operation
+		pending := len(info.queue) != 0
+		if pending {
+			op = info.queue[0]
+		}
+		client, upstream := info.client, info.config.Upstream
+		info.Unlock()
+
+		if !pending {
+			select {
+			case <-wake:
+				continue
+			case <-stop:
+				return
+			}
+		}
+
+		err := op.send(client, upstream)
+
+		info.Lock()
+		if info.stop != stop {
+			info.Unlock()
+			return
+		}
+		if err == nil {
+			if !info.up {
+				logger.Logging(logger.DEBUG, "Uplink is restored, pending: "+strconv.Itoa(len(info.queue)-1))
+			}
+			info.up = true
+			// The queue may have been replaced on overflow
+			if len(info.queue) != 0 && info.queue[0].id == op.id {
+				info.queue = info.queue[1:]
+			}
+			info.Unlock()
+			delay = retryDelay
+			continue
+		}
+		if info.up {
+			logger.Logging(logger.ERROR, "Uplink is down, changes are queued: "+err.Error())
+		}
+		info.up = false
+		info.Unlock()
+
+		select {
+		case <-time.After(delay):
+		case <-stop:
+			return
+		}
+		delay *= 2
+		if delay > MAX_RETRY_DELAY {
+			delay = MAX_RETRY_DELAY
+		}
+	}
+}
+
+// The followings should be called with info locked.
+
+// enqueue appends the operation to the queue. Keep-alives and resyncs already
+// queued are not queued again. If the queue is full, it is replaced by a resync,
+// which registers all local topics again instead of the lost changes.
+func enqueue(op operation) {
+	if op.kind != opRequest {
+		for _, queued := range info.queue {
+			if queued.kind == op.kind {
+				return
+			}
+		}
+	}
+
+	if len(info.queue) >= MAX_QUEUE_SIZE {
+		logger.Logging(logger.ERROR, "Too many changes queued, topics will be registered again")
+		info.queue = nil
+		op = operation{kind: opResync}
+	}
+
+	info.seq++
+	op.id = info.seq
+	info.queue = append(info.queue, op)
+
+	select {
+	case info.wake <- struct{}{}:
+	default:
+	}
+}
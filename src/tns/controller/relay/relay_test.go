@@ -0,0 +1,340 @@
+/*******************************************************************************
+ * Copyright 2018 Samsung Electronics All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ *******************************************************************************/
+package relay
+
+import (
+	"encoding/json"
+	"github.com/golang/mock/gomock"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+	watchController "tns/controller/watch"
+	watchControllerMock "tns/controller/watch/mocks"
+	topicDB "tns/db/topic"
+	topicDbMock "tns/db/topic/mocks"
+)
+
+var Handler Command
+
+func init() {
+	Handler = Executor{}
+}
+
+// upstreamForTest records the requests as "METHOD path?query name endpoint",
+// and responds with status, or with 404 for keep-alives of expired by endpoint.
+type upstreamForTest struct {
+	sync.Mutex
+	*httptest.Server
+	status   int
+	expired  map[string][]string
+	requests []string
+}
+
+func newUpstreamForTest(status int) *upstreamForTest {
+	u := &upstreamForTest{status: status}
+	u.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		u.Lock()
+		defer u.Unlock()
+
+		if u.status != http.StatusOK {
+			w.WriteHeader(u.status)
+			return
+		}
+
+		var body struct {
+			Topic      map[string]interface{} `json:"topic"`
+			TopicNames []string               `json:"topic_names"`
+			Endpoint   string                 `json:"endpoint"`
+		}
+		data, _ := ioutil.ReadAll(req.Body)
+		json.Unmarshal(data, &body)
+
+		record := req.Method + " " + req.URL.Path
+		if req.URL.RawQuery != "" {
+			record += "?" + req.URL.RawQuery
+		}
+		if body.Topic != nil {
+			record += " " + body.Topic["name"].(string) + " " + body.Topic["endpoint"].(string)
+		}
+		if body.TopicNames != nil {
+			record += " " + strings.Join(body.TopicNames, ",") + " " + body.Endpoint
+		}
+		u.requests = append(u.requests, record)
+
+		if body.TopicNames != nil && len(u.expired[body.Endpoint]) != 0 {
+			w.WriteHeader(http.StatusNotFound)
+			resp, _ := json.Marshal(map[string]interface{}{"topic_names": u.expired[body.Endpoint]})
+			w.Write(resp)
+			delete(u.expired, body.Endpoint)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	return u
+}
+
+func (u *upstreamForTest) address() string {
+	return strings.TrimPrefix(u.URL, "http://")
+}
+
+func (u *upstreamForTest) setStatus(status int) {
+	u.Lock()
+	defer u.Unlock()
+	u.status = status
+}
+
+// waitForRequests waits until n requests are received, and returns them.
+func (u *upstreamForTest) waitForRequests(t *testing.T, n int) []string {
+	for i := 0; i < 200; i++ {
+		u.Lock()
+		requests := append([]string{}, u.requests...)
+		u.Unlock()
+		if len(requests) >= n {
+			return requests
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("Timed out waiting for %d requests", n)
+	return nil
+}
+
+func TestConvertToOperation(t *testing.T) {
+	labels := map[string]string{"site": "plant3"}
+
+	testCases := []struct {
+		name     string
+		event    watchController.Event
+		expected operation
+	}{
+		{"Created", watchController.Event{Type: watchController.EVENT_CREATED,
+			Topic: map[string]interface{}{"name": "/a", "endpoint": "10.0.0.1:1883", "datamodel": "test_0.0.1", "labels": labels}},
+			operation{kind: opRequest, method: "POST", path: "/tns/topic", body: map[string]interface{}{
+				"topic": map[string]interface{}{"name": "/a", "endpoint": "10.0.0.1:1883", "datamodel": "test_0.0.1", "labels": labels}}}},
+		{"Updated_Registration", watchController.Event{Type: watchController.EVENT_UPDATED,
+			Topic: map[string]interface{}{"name": "/a", "endpoint": "10.0.0.1:1883", "datamodel": "test_0.0.1"}},
+			operation{kind: opRequest, method: "POST", path: "/tns/topic", body: map[string]interface{}{
+				"topic": map[string]interface{}{"name": "/a", "endpoint": "10.0.0.1:1883", "datamodel": "test_0.0.1"}}}},
+		{"Updated_SinglePublisher", watchController.Event{Type: watchController.EVENT_UPDATED,
+			Topic: map[string]interface{}{"name": "/a", "endpoint": "10.0.0.1:1883", "endpoints": []string{"10.0.0.1:1883"}, "datamodel": "test_0.0.2", "secured": true, "revision": int64(2)}},
+			operation{kind: opRequest, method: "PUT", path: "/tns/topic", body: map[string]interface{}{
+				"topic": map[string]interface{}{"name": "/a", "endpoint": "10.0.0.1:1883", "datamodel": "test_0.0.2", "secured": true}}}},
+		{"Updated_MultiplePublishers", watchController.Event{Type: watchController.EVENT_UPDATED,
+			Topic: map[string]interface{}{"name": "/a", "endpoint": "10.0.0.1:1883", "endpoints": []string{"10.0.0.1:1883", "10.0.0.2:1883"}, "datamodel": "test_0.0.2", "labels": labels}},
+			operation{kind: opRequest, method: "PATCH", path: "/tns/topic", body: map[string]interface{}{
+				"topic": map[string]interface{}{"name": "/a", "datamodel": "test_0.0.2", "labels": labels}}}},
+		{"Deleted", watchController.Event{Type: watchController.EVENT_DELETED, Topic: map[string]interface{}{"name": "/a"}},
+			operation{kind: opRequest, method: "DELETE", path: "/tns/topic", query: url.Values{"name": {"/a"}}}},
+		{"Deleted_Publisher", watchController.Event{Type: watchController.EVENT_DELETED,
+			Topic: map[string]interface{}{"name": "/a", "endpoint": "10.0.0.1:1883"}},
+			operation{kind: opRequest, method: "DELETE", path: "/tns/topic", query: url.Values{"name": {"/a"}, "endpoint": {"10.0.0.1:1883"}}}},
+		{"Expired", watchController.Event{Type: watchController.EVENT_EXPIRED,
+			Topic: map[string]interface{}{"name": "/a", "endpoint": "10.0.0.1:1883"}},
+			operation{kind: opRequest, method: "DELETE", path: "/tns/topic", query: url.Values{"name": {"/a"}, "endpoint": {"10.0.0.1:1883"}}}},
+		{"Reset", watchController.Event{Type: watchController.EVENT_RESET, Topic: map[string]interface{}{}},
+			operation{kind: opResync}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			op := convertToOperation(tc.event)
+			if !reflect.DeepEqual(op, tc.expected) {
+				t.Errorf("Expected: %v, Actual: %v", tc.expected, op)
+			}
+		})
+	}
+}
+
+func TestConvertToOperationWithUpdatedTopic(t *testing.T) {
+	// Events are built from the topics updated on the in-memory DB as UpdateTopic does
+	db := topicDB.Executor{}
+	if err := db.Connect(topicDB.Config{Type: topicDB.MEMORY_DB}); err != nil {
+		t.Fatalf("Connect returned an error: %s", err.Error())
+	}
+	defer db.Close()
+
+	for _, endpoint := range []string{"10.0.0.1:1883", "10.0.0.2:1883"} {
+		topic := map[string]interface{}{"name": "/a", "endpoint": endpoint, "datamodel": "test_0.0.1"}
+		if _, err := db.CreateTopic(topic); err != nil {
+			t.Fatalf("CreateTopic returned an error: %s", err.Error())
+		}
+	}
+	if _, err := db.CreateTopic(map[string]interface{}{"name": "/b", "endpoint": "10.0.0.1:1883", "datamodel": "test_0.0.1"}); err != nil {
+		t.Fatalf("CreateTopic returned an error: %s", err.Error())
+	}
+
+	testCases := []struct {
+		name       string
+		properties map[string]interface{}
+		partial    bool
+		expected   operation
+	}{
+		{"SinglePublisher", map[string]interface{}{"name": "/b", "endpoint": "10.0.0.3:1883", "datamodel": "test_0.0.2"}, false,
+			operation{kind: opRequest, method: "PUT", path: "/tns/topic", body: map[string]interface{}{
+				"topic": map[string]interface{}{"name": "/b", "endpoint": "10.0.0.3:1883", "datamodel": "test_0.0.2",
+					"secured": false, "labels": map[string]string{}}}}},
+		{"MultiplePublishers", map[string]interface{}{"name": "/a", "datamodel": "test_0.0.2"}, true,
+			operation{kind: opRequest, method: "PATCH", path: "/tns/topic", body: map[string]interface{}{
+				"topic": map[string]interface{}{"name": "/a", "datamodel": "test_0.0.2",
+					"secured": false, "labels": map[string]string{}}}}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			updated, err := db.UpdateTopic(tc.properties, tc.partial)
+			if err != nil {
+				t.Fatalf("UpdateTopic returned an error: %s", err.Error())
+			}
+			delete(updated, "registered_at")
+
+			op := convertToOperation(watchController.Event{Type: watchController.EVENT_UPDATED, Topic: updated})
+			if !reflect.DeepEqual(op, tc.expected) {
+				t.Errorf("Expected: %v, Actual: %v", tc.expected, op)
+			}
+		})
+	}
+}
+
+func TestCallInitRelayWithInvalidConfig(t *testing.T) {
+	testCases := []struct {
+		name     string
+		upstream string
+	}{
+		{"NoUpstream", ""},
+		{"Url", "http://central:48323"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := Handler.InitRelay(Config{Enabled: true, Upstream: tc.upstream})
+			if err == nil {
+				t.Error("InitRelay did not return an error")
+			}
+		})
+	}
+}
+
+func TestEnqueue(t *testing.T) {
+	info.Lock()
+	defer info.Unlock()
+
+	info.wake = make(chan struct{}, 1)
+	defer func() { info.queue = nil }()
+
+	// Keep-alives are not queued twice
+	info.queue = nil
+	enqueue(operation{kind: opKeepAlive})
+	enqueue(operation{kind: opRequest, method: "DELETE"})
+	enqueue(operation{kind: opKeepAlive})
+	if len(info.queue) != 2 || info.queue[0].kind != opKeepAlive || info.queue[1].kind != opRequest {
+		t.Errorf("Unexpected queue: %v", info.queue)
+	}
+
+	// Overflow is replaced by a resync
+	for len(info.queue) < MAX_QUEUE_SIZE {
+		enqueue(operation{kind: opRequest, method: "DELETE"})
+	}
+	enqueue(operation{kind: opRequest, method: "POST"})
+	if len(info.queue) != 1 || info.queue[0].kind != opResync {
+		t.Errorf("Unexpected queue of %d operations", len(info.queue))
+	}
+}
+
+func TestRelay(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	topicDbMockObj := topicDbMock.NewMockCommand(ctrl)
+	watchControllerMockObj := watchControllerMock.NewMockCommand(ctrl)
+
+	// pass mockObj to a real object.
+	topicDbExecutor = topicDbMockObj
+	watchExecutor = watchControllerMockObj
+	defer func() { watchExecutor = watchController.Executor{} }()
+
+	retryDelay = 10 * time.Millisecond
+	defer func() { retryDelay = time.Second }()
+
+	events := make(chan watchController.Event, 16)
+	localTopics := func() []map[string]interface{} {
+		return []map[string]interface{}{
+			{"name": "/a", "endpoints": []string{"10.0.0.1:1883", "10.0.0.2:1883"}, "datamodel": "test_0.0.1", "revision": int64(1)},
+			{"name": "/d", "endpoints": []string{"10.0.0.2:1883"}, "datamodel": "test_0.0.1", "revision": int64(1)},
+		}
+	}
+
+	watchControllerMockObj.EXPECT().Subscribe("", false, "").Return(&watchController.Subscription{Events: events}, nil)
+	watchControllerMockObj.EXPECT().Unsubscribe(gomock.Any()).AnyTimes()
+	topicDbMockObj.EXPECT().ReadTopicAll("").DoAndReturn(func(string) ([]map[string]interface{}, error) {
+		return localTopics(), nil
+	}).AnyTimes()
+
+	// Uplink is down at first
+	upstream := newUpstreamForTest(http.StatusServiceUnavailable)
+	defer upstream.Close()
+
+	err := Handler.InitRelay(Config{Enabled: true, Upstream: upstream.address(), KeepAliveInterval: 3600})
+	if err != nil {
+		t.Fatalf("InitRelay returned an error: %s", err.Error())
+	}
+	defer Handler.InitRelay(Config{})
+
+	events <- watchController.Event{ID: "1", Type: watchController.EVENT_CREATED,
+		Topic: map[string]interface{}{"name": "/b", "endpoint": "10.0.0.3:1883", "datamodel": "test_0.0.1"}}
+	events <- watchController.Event{ID: "2", Type: watchController.EVENT_EXPIRED,
+		Topic: map[string]interface{}{"name": "/c", "endpoint": "10.0.0.4:1883"}}
+
+	time.Sleep(50 * time.Millisecond)
+	upstream.setStatus(http.StatusOK)
+
+	// Queued changes are sent in order when the uplink is restored
+	expected := []string{
+		"POST /api/v1/tns/topic /a 10.0.0.1:1883",
+		"POST /api/v1/tns/topic /a 10.0.0.2:1883",
+		"POST /api/v1/tns/topic /d 10.0.0.2:1883",
+		"POST /api/v1/tns/topic /b 10.0.0.3:1883",
+		"DELETE /api/v1/tns/topic?endpoint=10.0.0.4%3A1883&name=%2Fc",
+	}
+	requests := upstream.waitForRequests(t, len(expected))
+	if !reflect.DeepEqual(requests, expected) {
+		t.Errorf("Expected: %v, Actual: %v", expected, requests)
+	}
+
+	// Publishers expired upstream are registered again after the keep-alive
+	upstream.Lock()
+	upstream.expired = map[string][]string{"10.0.0.2:1883": {"/a"}}
+	upstream.Unlock()
+
+	info.Lock()
+	enqueue(operation{kind: opKeepAlive})
+	info.Unlock()
+
+	expected = append(expected,
+		"POST /api/v1/tns/keepalive /a 10.0.0.1:1883",
+		"POST /api/v1/tns/keepalive /a,/d 10.0.0.2:1883",
+		"POST /api/v1/tns/topic /a 10.0.0.2:1883",
+	)
+	requests = upstream.waitForRequests(t, len(expected))
+	if !reflect.DeepEqual(requests, expected) {
+		t.Errorf("Expected: %v, Actual: %v", expected, requests)
+	}
+}
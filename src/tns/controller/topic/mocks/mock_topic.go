@@ -50,11 +50,12 @@ func (m *MockCommand) EXPECT() *MockCommandMockRecorder {
 }
 
 // CreateTopic mocks base method
-func (m *MockCommand) CreateTopic(body string) (map[string]interface{}, error) {
+func (m *MockCommand) CreateTopic(body string) (map[string]interface{}, bool, error) {
 	ret := m.ctrl.Call(m, "CreateTopic", body)
 	ret0, _ := ret[0].(map[string]interface{})
-	ret1, _ := ret[1].(error)
-	return ret0, ret1
+	ret1, _ := ret[1].(bool)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
 }
 
 // CreateTopic indicates an expected call of CreateTopic
@@ -62,27 +63,63 @@ func (mr *MockCommandMockRecorder) CreateTopic(body interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateTopic", reflect.TypeOf((*MockCommand)(nil).CreateTopic), body)
 }
 
+// UpdateTopic mocks base method
+func (m *MockCommand) UpdateTopic(body string, partial bool) (map[string]interface{}, error) {
+	ret := m.ctrl.Call(m, "UpdateTopic", body, partial)
+	ret0, _ := ret[0].(map[string]interface{})
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateTopic indicates an expected call of UpdateTopic
+func (mr *MockCommandMockRecorder) UpdateTopic(body, partial interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateTopic", reflect.TypeOf((*MockCommand)(nil).UpdateTopic), body, partial)
+}
+
 // ReadTopic mocks base method
-func (m *MockCommand) ReadTopic(name string, hierarchical bool) (map[string]interface{}, error) {
-	ret := m.ctrl.Call(m, "ReadTopic", name, hierarchical)
+func (m *MockCommand) ReadTopic(name string, hierarchical bool, selector string, liveness bool, expiringWithin int) (map[string]interface{}, error) {
+	ret := m.ctrl.Call(m, "ReadTopic", name, hierarchical, selector, liveness, expiringWithin)
 	ret0, _ := ret[0].(map[string]interface{})
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // ReadTopic indicates an expected call of ReadTopic
-func (mr *MockCommandMockRecorder) ReadTopic(name, hierarchical interface{}) *gomock.Call {
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadTopic", reflect.TypeOf((*MockCommand)(nil).ReadTopic), name, hierarchical)
+func (mr *MockCommandMockRecorder) ReadTopic(name, hierarchical, selector, liveness, expiringWithin interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadTopic", reflect.TypeOf((*MockCommand)(nil).ReadTopic), name, hierarchical, selector, liveness, expiringWithin)
+}
+
+// ReadTopicWithHops mocks base method
+func (m *MockCommand) ReadTopicWithHops(name string, hierarchical bool, selector string, hops uint) (map[string]interface{}, error) {
+	ret := m.ctrl.Call(m, "ReadTopicWithHops", name, hierarchical, selector, hops)
+	ret0, _ := ret[0].(map[string]interface{})
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReadTopicWithHops indicates an expected call of ReadTopicWithHops
+func (mr *MockCommandMockRecorder) ReadTopicWithHops(name, hierarchical, selector, hops interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadTopicWithHops", reflect.TypeOf((*MockCommand)(nil).ReadTopicWithHops), name, hierarchical, selector, hops)
 }
 
 // DeleteTopic mocks base method
-func (m *MockCommand) DeleteTopic(name string) error {
-	ret := m.ctrl.Call(m, "DeleteTopic", name)
+func (m *MockCommand) DeleteTopic(name, endpoint string) error {
+	ret := m.ctrl.Call(m, "DeleteTopic", name, endpoint)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // DeleteTopic indicates an expected call of DeleteTopic
-func (mr *MockCommandMockRecorder) DeleteTopic(name interface{}) *gomock.Call {
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteTopic", reflect.TypeOf((*MockCommand)(nil).DeleteTopic), name)
+func (mr *MockCommandMockRecorder) DeleteTopic(name, endpoint interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteTopic", reflect.TypeOf((*MockCommand)(nil).DeleteTopic), name, endpoint)
+}
+
+// SetDatamodelValidation mocks base method
+func (m *MockCommand) SetDatamodelValidation(enabled bool) {
+	m.ctrl.Call(m, "SetDatamodelValidation", enabled)
+}
+
+// SetDatamodelValidation indicates an expected call of SetDatamodelValidation
+func (mr *MockCommandMockRecorder) SetDatamodelValidation(enabled interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetDatamodelValidation", reflect.TypeOf((*MockCommand)(nil).SetDatamodelValidation), enabled)
 }
@@ -18,17 +18,25 @@
 package topic
 
 import (
+	"math"
+	"strconv"
+	"time"
 	"tns/commons/errors"
 	"tns/commons/logger"
 	"tns/commons/util"
+	federationController "tns/controller/federation"
 	keepaliveController "tns/controller/keepalive"
+	watchController "tns/controller/watch"
 	topicDB "tns/db/topic"
 )
 
 type Command interface {
-	CreateTopic(body string) (map[string]interface{}, error)
-	ReadTopic(name string, hierarchical bool) (map[string]interface{}, error)
-	DeleteTopic(name string) error
+	CreateTopic(body string) (map[string]interface{}, bool, error)
+	UpdateTopic(body string, partial bool) (map[string]interface{}, error)
+	ReadTopic(name string, hierarchical bool, selector string, liveness bool, expiringWithin int) (map[string]interface{}, error)
+	ReadTopicWithHops(name string, hierarchical bool, selector string, hops uint) (map[string]interface{}, error)
+	DeleteTopic(name string, endpoint string) error
+	SetDatamodelValidation(enabled bool)
 }
 
 // Executor implements the Command interface.
@@ -36,13 +44,104 @@ type Executor struct{}
 
 var topicDbExecutor topicDB.Command
 var keepaliveExecutor keepaliveController.Command
+var watchExecutor watchController.Command
+var federationExecutor federationController.Command
+
+// If true, topics can be registered only with the datamodels in the registry.
+var datamodelValidation bool
 
 func init() {
 	topicDbExecutor = topicDB.Executor{}
 	keepaliveExecutor = keepaliveController.Executor{}
+	watchExecutor = watchController.Executor{}
+	federationExecutor = federationController.Executor{}
 }
 
-func (Executor) CreateTopic(body string) (map[string]interface{}, error) {
+// CreateTopic registers the publisher of the topic in body.
+// It returns false if the publisher has already been registered, e.g., it is
+// restarted within the keep-alive interval, and then its keep-alive is refreshed.
+// If 'lease_id' is given, the publisher is kept alive by the lease instead.
+func (Executor) CreateTopic(body string) (map[string]interface{}, bool, error) {
+	logger.Logging(logger.DEBUG, "IN")
+	defer logger.Logging(logger.DEBUG, "OUT")
+
+	bodyMap, err := util.ConvertJsonToMap(body)
+	if err != nil {
+		logger.Logging(logger.ERROR, "ConvertJsonToMap failed: "+err.Error())
+		return nil, false, err
+	}
+
+	topic, exists := bodyMap["topic"].(map[string]interface{})
+	if !exists {
+		logger.Logging(logger.DEBUG, "'topic' does not present in body")
+		return nil, false, errors.InvalidParam{"'topic' field is required"}
+	}
+
+	name, exists := topic["name"].(string)
+	if !exists {
+		logger.Logging(logger.DEBUG, "'name' does not present in body")
+		return nil, false, errors.InvalidParam{"'name' field is required"}
+	}
+
+	err = checkDatamodel(topic)
+	if err != nil {
+		return nil, false, err
+	}
+
+	interval, err := requestedInterval(topic)
+	if err != nil {
+		return nil, false, err
+	}
+
+	leaseID, err := requestedLease(topic)
+	if err != nil {
+		return nil, false, err
+	}
+	if leaseID != "" && interval != 0 {
+		return nil, false, errors.InvalidParam{"'ka_interval' can not be given with 'lease_id'"}
+	}
+
+	created, err := topicDbExecutor.CreateTopic(topic)
+	if err != nil {
+		logger.Logging(logger.DEBUG, "CreateTopic failed: "+err.Error())
+		return nil, false, err
+	}
+
+	// endpoint is validated by CreateTopic
+	endpoint := topic["endpoint"].(string)
+	resp := make(map[string]interface{})
+
+	if leaseID != "" {
+		err = keepaliveExecutor.AddTopicWithLease(name, endpoint, leaseID)
+		if err != nil {
+			// The lease has expired after it was checked
+			logger.Logging(logger.DEBUG, "AddTopicWithLease failed: "+err.Error())
+			if created {
+				topicDbExecutor.DeletePublisher(name, endpoint)
+			} else {
+				keepaliveExecutor.AddTopic(name, endpoint, 0)
+			}
+			return nil, false, errors.InvalidParam{"unknown lease: " + leaseID}
+		}
+
+		resp["lease_id"] = leaseID
+		publishRegistration(topic, created)
+		return resp, created, nil
+	}
+
+	keepaliveExecutor.AddTopic(name, endpoint, interval)
+
+	// Granted interval, which may differ from the requested one
+	resp["ka_interval"] = keepaliveExecutor.GetInterval(name)
+	publishRegistration(topic, created)
+
+	return resp, created, nil
+}
+
+// UpdateTopic replaces the registered topic with the one in body.
+// If partial is true, only the given properties are changed.
+// The keep-alive state of the publishers is not affected.
+func (Executor) UpdateTopic(body string, partial bool) (map[string]interface{}, error) {
 	logger.Logging(logger.DEBUG, "IN")
 	defer logger.Logging(logger.DEBUG, "OUT")
 
@@ -64,53 +163,130 @@ func (Executor) CreateTopic(body string) (map[string]interface{}, error) {
 		return nil, errors.InvalidParam{"'name' field is required"}
 	}
 
-	err = topicDbExecutor.CreateTopic(topic)
+	err = checkDatamodel(topic)
 	if err != nil {
-		logger.Logging(logger.DEBUG, "CreateTopic failed: "+err.Error())
 		return nil, err
 	}
 
-	keepaliveExecutor.AddTopic(name)
+	updated, err := topicDbExecutor.UpdateTopic(topic, partial)
+	if err != nil {
+		logger.Logging(logger.DEBUG, "UpdateTopic failed: "+err.Error())
+		return nil, err
+	}
+
+	// endpoint of the single publisher may have been changed
+	keepaliveExecutor.SetEndpoints(name, updated["endpoints"].([]string))
+	delete(updated, "registered_at")
+	watchExecutor.Publish(watchController.EVENT_UPDATED, updated)
 
 	resp := make(map[string]interface{})
-	resp["ka_interval"] = keepaliveExecutor.GetInterval()
+	resp["topic"] = updated
 
 	return resp, nil
 }
 
-func (Executor) ReadTopic(name string, hierarchical bool) (map[string]interface{}, error) {
+// ReadTopic returns the topics matched by name, or all topics if name is empty.
+// If selector is given, only the topics whose labels match it are returned.
+// If liveness is true, the keep-alive state of the topics is returned together.
+// If expiringWithin is not negative, only the topics which expire within
+// expiringWithin seconds are returned.
+// If nothing is found locally, the name is looked up on the federated servers
+// unless the keep-alive state is requested.
+func (Executor) ReadTopic(name string, hierarchical bool, selector string, liveness bool, expiringWithin int) (map[string]interface{}, error) {
 	logger.Logging(logger.DEBUG, "IN")
 	defer logger.Logging(logger.DEBUG, "OUT")
 
+	return readTopic(name, hierarchical, selector, liveness, expiringWithin, 0)
+}
+
+// ReadTopicWithHops returns the topics matched by name for the federated server
+// which has forwarded the lookup, which has passed through hops servers so far.
+func (Executor) ReadTopicWithHops(name string, hierarchical bool, selector string, hops uint) (map[string]interface{}, error) {
+	logger.Logging(logger.DEBUG, "IN")
+	defer logger.Logging(logger.DEBUG, "OUT")
+
+	return readTopic(name, hierarchical, selector, false, -1, hops)
+}
+
+// readTopic looks up the topics in DB, or on the federated servers with hops
+// if nothing is found.
+func readTopic(name string, hierarchical bool, selector string, liveness bool, expiringWithin int, hops uint) (map[string]interface{}, error) {
 	var topics []map[string]interface{}
 	var err error
 
 	if name == "" {
-		topics, err = topicDbExecutor.ReadTopicAll()
+		topics, err = topicDbExecutor.ReadTopicAll(selector)
 	} else {
-		topics, err = topicDbExecutor.ReadTopic(name, hierarchical)
+		topics, err = topicDbExecutor.ReadTopic(name, hierarchical, selector)
 	}
 
 	if err != nil {
 		return nil, err
-	} else if len(topics) == 0 {
+	}
+
+	if liveness || expiringWithin >= 0 {
+		topics = addLiveness(topics, liveness, expiringWithin)
+	} else {
+		for _, topic := range topics {
+			delete(topic, "registered_at")
+		}
+	}
+
+	// Keep-alive state of the topics on the other servers is unknown
+	var federated []map[string]interface{}
+	if len(topics) == 0 && !liveness && expiringWithin < 0 {
+		federated = federationExecutor.Lookup(name, hierarchical, selector, hops)
+	}
+
+	if len(topics) == 0 && len(federated) == 0 {
 		logger.Logging(logger.DEBUG, "Nothing found")
 		if name == "" {
 			name = "topic is empty"
 		}
+		if selector != "" {
+			name += " with selector " + selector
+		}
+		if expiringWithin >= 0 {
+			name += " expiring within " + strconv.Itoa(expiringWithin) + " seconds"
+		}
 		return nil, errors.NotFound{name}
 	}
 
+	// Stale topics are returned until their grace period ends
+	for _, topic := range topics {
+		name, _ := topic["name"].(string)
+		topic["status"] = keepaliveExecutor.GetStatus(name)
+	}
+
+	// Topics on the other servers have their own status
+	topics = append(topics, federated...)
+
 	resp := make(map[string]interface{})
 	resp["topics"] = topics
 
 	return resp, nil
 }
 
-func (Executor) DeleteTopic(name string) error {
+// DeleteTopic removes the topic with all of its publishers.
+// If endpoint is given, only the publisher of it leaves the topic,
+// and the topic is removed when it is the last publisher.
+func (Executor) DeleteTopic(name string, endpoint string) error {
 	logger.Logging(logger.DEBUG, "IN")
 	defer logger.Logging(logger.DEBUG, "OUT")
 
+	if endpoint != "" {
+		err := topicDbExecutor.DeletePublisher(name, endpoint)
+		if err != nil {
+			logger.Logging(logger.DEBUG, "DeletePublisher failed")
+			return err
+		}
+
+		keepaliveExecutor.DeletePublisher(name, endpoint)
+		watchExecutor.Publish(watchController.EVENT_DELETED, map[string]interface{}{"name": name, "endpoint": endpoint})
+
+		return nil
+	}
+
 	err := topicDbExecutor.DeleteTopic(name)
 	if err != nil {
 		logger.Logging(logger.DEBUG, "DeleteTopic failed")
@@ -118,6 +294,159 @@ func (Executor) DeleteTopic(name string) error {
 	}
 
 	keepaliveExecutor.DeleteTopic(name)
+	watchExecutor.Publish(watchController.EVENT_DELETED, map[string]interface{}{"name": name})
+
+	return nil
+}
+
+// SetDatamodelValidation enables or disables the check of datamodels
+// on registration and update of topics.
+func (Executor) SetDatamodelValidation(enabled bool) {
+	datamodelValidation = enabled
+}
+
+// addLiveness returns the topics with the keep-alive state of their publishers,
+// which are merged from keep-alive. Topics which do not expire within expiringWithin
+// seconds are excluded unless it is negative. Times are in RFC3339.
+func addLiveness(topics []map[string]interface{}, liveness bool, expiringWithin int) []map[string]interface{} {
+	deadline := time.Now().Add(time.Duration(expiringWithin) * time.Second)
+
+	filtered := make([]map[string]interface{}, 0, len(topics))
+	for _, topic := range topics {
+		name, _ := topic["name"].(string)
+		endpoints, _ := topic["endpoints"].([]string)
+		registeredAt, _ := topic["registered_at"].(map[string]time.Time)
+		delete(topic, "registered_at")
+		states := keepaliveExecutor.GetLiveness(name)
+
+		// The topic is registered with its first publisher, and
+		// expires with its last publisher
+		var firstRegisteredAt, lastSeen, expiresAt time.Time
+		publishers := make([]map[string]interface{}, 0, len(endpoints))
+		for _, endpoint := range endpoints {
+			publisher := map[string]interface{}{"endpoint": endpoint}
+			if t := registeredAt[endpoint]; !t.IsZero() {
+				publisher["registered_at"] = formatTime(t)
+				if firstRegisteredAt.IsZero() || t.Before(firstRegisteredAt) {
+					firstRegisteredAt = t
+				}
+			}
+			if state, exists := states[endpoint]; exists {
+				publisher["last_seen"] = formatTime(state.LastSeen)
+				publisher["expires_at"] = formatTime(state.ExpiresAt)
+				if state.LastSeen.After(lastSeen) {
+					lastSeen = state.LastSeen
+				}
+				if state.ExpiresAt.After(expiresAt) {
+					expiresAt = state.ExpiresAt
+				}
+			}
+			publishers = append(publishers, publisher)
+		}
+
+		if expiringWithin >= 0 && (expiresAt.IsZero() || expiresAt.After(deadline)) {
+			continue
+		}
+
+		if liveness {
+			if !firstRegisteredAt.IsZero() {
+				topic["registered_at"] = formatTime(firstRegisteredAt)
+			}
+			if !expiresAt.IsZero() {
+				topic["last_seen"] = formatTime(lastSeen)
+				topic["expires_at"] = formatTime(expiresAt)
+			}
+			topic["ka_interval"] = keepaliveExecutor.GetInterval(name)
+			topic["publishers"] = publishers
+		}
+		filtered = append(filtered, topic)
+	}
+
+	return filtered
+}
+
+// publishRegistration notifies watchers of the registered publisher. The options
+// of keep-alive in the request are not a part of the topic.
+func publishRegistration(topic map[string]interface{}, created bool) {
+	event := make(map[string]interface{}, len(topic))
+	for key, value := range topic {
+		if key != "ka_interval" && key != "lease_id" {
+			event[key] = value
+		}
+	}
+
+	if created {
+		watchExecutor.Publish(watchController.EVENT_CREATED, event)
+	} else {
+		watchExecutor.Publish(watchController.EVENT_UPDATED, event)
+	}
+}
+
+func formatTime(t time.Time) string {
+	return t.UTC().Format(time.RFC3339)
+}
+
+// requestedInterval returns the period of keep-alive requested by the publisher
+// in 'ka_interval' field, 0 if not given.
+func requestedInterval(topic map[string]interface{}) (uint, error) {
+	value, exists := topic["ka_interval"]
+	if !exists {
+		return 0, nil
+	}
+
+	interval, ok := value.(float64)
+	if !ok || interval < 1 || interval > math.MaxUint32 || interval != math.Trunc(interval) {
+		return 0, errors.InvalidParam{"'ka_interval' field must be a positive integer"}
+	}
+	return uint(interval), nil
+}
+
+// requestedLease returns the ID of the lease in 'lease_id' field, "" if not given.
+// InvalidParam is returned if the lease does not exist.
+func requestedLease(topic map[string]interface{}) (string, error) {
+	value, exists := topic["lease_id"]
+	if !exists {
+		return "", nil
+	}
+
+	id, ok := value.(string)
+	if !ok || id == "" {
+		return "", errors.InvalidParam{"'lease_id' field must be a string"}
+	}
+
+	_, err := keepaliveExecutor.ReadLease(id)
+	if err != nil {
+		if _, notFound := err.(errors.NotFound); notFound {
+			logger.Logging(logger.DEBUG, "Unknown lease: "+id)
+			return "", errors.InvalidParam{"unknown lease: " + id}
+		}
+		return "", err
+	}
+
+	return id, nil
+}
+
+// checkDatamodel returns InvalidParam if the datamodel of the topic is not in the registry.
+// The type of the datamodel is not checked here, it is validated by DB.
+func checkDatamodel(topic map[string]interface{}) error {
+	if !datamodelValidation {
+		return nil
+	}
+
+	datamodel, exists := topic["datamodel"].(string)
+	if !exists {
+		return nil
+	}
+
+	_, err := topicDbExecutor.ReadDatamodel(datamodel)
+	if err != nil {
+		if _, notFound := err.(errors.NotFound); notFound {
+			logger.Logging(logger.DEBUG, "Unknown datamodel: "+datamodel)
+			return errors.InvalidParam{"unknown datamodel: " + datamodel}
+		}
+		logger.Logging(logger.ERROR, "ReadDatamodel failed: "+err.Error())
+		return err
+	}
 
 	return nil
 }
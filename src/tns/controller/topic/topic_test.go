@@ -20,9 +20,16 @@ package topic
 import (
 	"github.com/golang/mock/gomock"
 	"reflect"
+	"strings"
 	"testing"
+	"time"
 	"tns/commons/errors"
+	federationController "tns/controller/federation"
+	federationControllerMock "tns/controller/federation/mocks"
+	"tns/controller/keepalive"
 	kaControllerMock "tns/controller/keepalive/mocks"
+	watchController "tns/controller/watch"
+	watchControllerMock "tns/controller/watch/mocks"
 	topicDbMock "tns/db/topic/mocks"
 )
 
@@ -39,27 +46,207 @@ func TestCallCreateTopic(t *testing.T) {
 	topicDbMockObj := topicDbMock.NewMockCommand(ctrl)
 	kaControllerMockObj := kaControllerMock.NewMockCommand(ctrl)
 
+	watchControllerMockObj := watchControllerMock.NewMockCommand(ctrl)
+
 	// pass mockObj to a real object.
 	topicDbExecutor = topicDbMockObj
 	keepaliveExecutor = kaControllerMockObj
+	watchExecutor = watchControllerMockObj
+	defer func() { watchExecutor = watchController.Executor{} }()
 
 	dummyBodyString := `{"topic":{"name":"/a","endpoint":"0.0.0.0:1234","datamodel":"test_0.0.1"}}`
 	dummyTopic := map[string]interface{}{"name": "/a", "endpoint": "0.0.0.0:1234", "datamodel": "test_0.0.1"}
 	interval := uint(10)
 	expectedResp := map[string]interface{}{"ka_interval": interval}
 
-	gomock.InOrder(
-		topicDbMockObj.EXPECT().CreateTopic(dummyTopic).Return(nil),
-		kaControllerMockObj.EXPECT().AddTopic("/a"),
-		kaControllerMockObj.EXPECT().GetInterval().Return(interval),
-	)
+	testCases := []struct {
+		name          string
+		mockCreated   bool
+		expectedEvent string
+	}{
+		{"Created", true, watchController.EVENT_CREATED},
+		// keep-alive is refreshed for the publisher registered again
+		{"Reregistered", false, watchController.EVENT_UPDATED},
+	}
 
-	resp, err := Handler.CreateTopic(dummyBodyString)
-	if err != nil {
-		t.Errorf("CreateTopic returned an error: %s", err.Error())
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			gomock.InOrder(
+				topicDbMockObj.EXPECT().CreateTopic(dummyTopic).Return(tc.mockCreated, nil),
+				kaControllerMockObj.EXPECT().AddTopic("/a", "0.0.0.0:1234", uint(0)),
+				kaControllerMockObj.EXPECT().GetInterval("/a").Return(interval),
+				watchControllerMockObj.EXPECT().Publish(tc.expectedEvent, dummyTopic),
+			)
+
+			resp, created, err := Handler.CreateTopic(dummyBodyString)
+			if err != nil {
+				t.Errorf("CreateTopic returned an error: %s", err.Error())
+			}
+			if created != tc.mockCreated {
+				t.Errorf("Expected Created: %t, Actual: %t", tc.mockCreated, created)
+			}
+			if isEqual := reflect.DeepEqual(resp, expectedResp); !isEqual {
+				t.Errorf("Expected Resp: %s, Actual: %s", expectedResp, resp)
+			}
+		})
 	}
-	if isEqual := reflect.DeepEqual(resp, expectedResp); !isEqual {
-		t.Errorf("Expected Resp: %s, Actual: %s", expectedResp, resp)
+}
+
+func TestCallCreateTopicWithDatamodelValidation(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	topicDbMockObj := topicDbMock.NewMockCommand(ctrl)
+	kaControllerMockObj := kaControllerMock.NewMockCommand(ctrl)
+
+	// pass mockObj to a real object.
+	topicDbExecutor = topicDbMockObj
+	keepaliveExecutor = kaControllerMockObj
+
+	Handler.SetDatamodelValidation(true)
+	defer Handler.SetDatamodelValidation(false)
+
+	dummyBodyString := `{"topic":{"name":"/a","endpoint":"0.0.0.0:1234","datamodel":"test_0.0.1"}}`
+	dummyTopic := map[string]interface{}{"name": "/a", "endpoint": "0.0.0.0:1234", "datamodel": "test_0.0.1"}
+
+	testCases := []struct {
+		name          string
+		mockRetError  error
+		expectedError error
+	}{
+		{"Success", nil, nil},
+		{"UnknownDatamodel", errors.NotFound{}, errors.InvalidParam{}},
+		{"DbFailed", errors.InternalServerError{}, errors.InternalServerError{}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			topicDbMockObj.EXPECT().ReadDatamodel("test_0.0.1").Return(nil, tc.mockRetError)
+			if tc.mockRetError == nil {
+				gomock.InOrder(
+					topicDbMockObj.EXPECT().CreateTopic(dummyTopic).Return(true, nil),
+					kaControllerMockObj.EXPECT().AddTopic("/a", "0.0.0.0:1234", uint(0)),
+					kaControllerMockObj.EXPECT().GetInterval("/a").Return(uint(10)),
+				)
+			}
+
+			_, _, err := Handler.CreateTopic(dummyBodyString)
+			if reflect.TypeOf(err) != reflect.TypeOf(tc.expectedError) {
+				t.Errorf("Expected Error: %s, Actual: %s", tc.expectedError, err)
+			}
+		})
+	}
+}
+
+func TestCallCreateTopicWithInterval(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	topicDbMockObj := topicDbMock.NewMockCommand(ctrl)
+	kaControllerMockObj := kaControllerMock.NewMockCommand(ctrl)
+
+	// pass mockObj to a real object.
+	topicDbExecutor = topicDbMockObj
+	keepaliveExecutor = kaControllerMockObj
+
+	testCases := []struct {
+		name            string
+		dummyBodyString string
+		expectedError   error
+	}{
+		{"Success", `{"topic":{"name":"/a","endpoint":"0.0.0.0:1234","datamodel":"test_0.0.1","ka_interval":20}}`, nil},
+		{"InvalidParam_string", `{"topic":{"name":"/a","endpoint":"0.0.0.0:1234","datamodel":"test_0.0.1","ka_interval":"20"}}`, errors.InvalidParam{}},
+		{"InvalidParam_zero", `{"topic":{"name":"/a","endpoint":"0.0.0.0:1234","datamodel":"test_0.0.1","ka_interval":0}}`, errors.InvalidParam{}},
+		{"InvalidParam_fraction", `{"topic":{"name":"/a","endpoint":"0.0.0.0:1234","datamodel":"test_0.0.1","ka_interval":1.5}}`, errors.InvalidParam{}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// mock will be called only for the valid interval.
+			if tc.expectedError == nil {
+				gomock.InOrder(
+					topicDbMockObj.EXPECT().CreateTopic(gomock.Any()).Return(true, nil),
+					kaControllerMockObj.EXPECT().AddTopic("/a", "0.0.0.0:1234", uint(20)),
+					kaControllerMockObj.EXPECT().GetInterval("/a").Return(uint(20)),
+				)
+			}
+
+			resp, _, err := Handler.CreateTopic(tc.dummyBodyString)
+			if reflect.TypeOf(err) != reflect.TypeOf(tc.expectedError) {
+				t.Errorf("Expected Error: %s, Actual: %s", tc.expectedError, err)
+			}
+			if err == nil && resp["ka_interval"] != uint(20) {
+				t.Errorf("Unexpected Resp: %v", resp)
+			}
+		})
+	}
+}
+
+func TestCallCreateTopicWithLease(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	topicDbMockObj := topicDbMock.NewMockCommand(ctrl)
+	kaControllerMockObj := kaControllerMock.NewMockCommand(ctrl)
+
+	// pass mockObj to a real object.
+	topicDbExecutor = topicDbMockObj
+	keepaliveExecutor = kaControllerMockObj
+
+	dummyBodyString := `{"topic":{"name":"/a","endpoint":"0.0.0.0:1234","datamodel":"test_0.0.1","lease_id":"0123456789abcdef"}}`
+
+	testCases := []struct {
+		name            string
+		dummyBodyString string
+		expectedError   error
+	}{
+		{"Success", dummyBodyString, nil},
+		{"UnknownLease", dummyBodyString, errors.InvalidParam{}},
+		{"LeaseExpired", dummyBodyString, errors.InvalidParam{}},
+		{"LeaseExpired_Registered", dummyBodyString, errors.InvalidParam{}},
+		{"InvalidParam_number", strings.Replace(dummyBodyString, `"0123456789abcdef"`, "1", 1), errors.InvalidParam{}},
+		{"InvalidParam_interval", strings.Replace(dummyBodyString, `"lease_id"`, `"ka_interval":20,"lease_id"`, 1), errors.InvalidParam{}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			switch tc.name {
+			case "Success":
+				gomock.InOrder(
+					kaControllerMockObj.EXPECT().ReadLease("0123456789abcdef").Return(map[string]interface{}{}, nil),
+					topicDbMockObj.EXPECT().CreateTopic(gomock.Any()).Return(true, nil),
+					kaControllerMockObj.EXPECT().AddTopicWithLease("/a", "0.0.0.0:1234", "0123456789abcdef").Return(nil),
+				)
+			case "UnknownLease":
+				kaControllerMockObj.EXPECT().ReadLease("0123456789abcdef").Return(nil, errors.NotFound{})
+			case "LeaseExpired":
+				// the publisher is removed again
+				gomock.InOrder(
+					kaControllerMockObj.EXPECT().ReadLease("0123456789abcdef").Return(map[string]interface{}{}, nil),
+					topicDbMockObj.EXPECT().CreateTopic(gomock.Any()).Return(true, nil),
+					kaControllerMockObj.EXPECT().AddTopicWithLease("/a", "0.0.0.0:1234", "0123456789abcdef").Return(errors.NotFound{}),
+					topicDbMockObj.EXPECT().DeletePublisher("/a", "0.0.0.0:1234").Return(nil),
+				)
+			case "LeaseExpired_Registered":
+				// the publisher which has been registered is kept alive without the lease
+				gomock.InOrder(
+					kaControllerMockObj.EXPECT().ReadLease("0123456789abcdef").Return(map[string]interface{}{}, nil),
+					topicDbMockObj.EXPECT().CreateTopic(gomock.Any()).Return(false, nil),
+					kaControllerMockObj.EXPECT().AddTopicWithLease("/a", "0.0.0.0:1234", "0123456789abcdef").Return(errors.NotFound{}),
+					kaControllerMockObj.EXPECT().AddTopic("/a", "0.0.0.0:1234", uint(0)),
+				)
+			case "InvalidParam_interval":
+				kaControllerMockObj.EXPECT().ReadLease("0123456789abcdef").Return(map[string]interface{}{}, nil)
+			}
+
+			resp, _, err := Handler.CreateTopic(tc.dummyBodyString)
+			if reflect.TypeOf(err) != reflect.TypeOf(tc.expectedError) {
+				t.Errorf("Expected Error: %s, Actual: %s", tc.expectedError, err)
+			}
+			if err == nil && resp["lease_id"] != "0123456789abcdef" {
+				t.Errorf("Unexpected Resp: %v", resp)
+			}
+		})
 	}
 }
 
@@ -88,10 +275,81 @@ func TestCallCreateTopicWithInvalidBody(t *testing.T) {
 			// mock will be called only for the conflict error case.
 			if tc.name == "Conflict" {
 				dummyTopic := map[string]interface{}{"name": "/a", "endpoint": "0.0.0.0:1234", "datamodel": "test_0.0.1"}
-				topicDbMockObj.EXPECT().CreateTopic(dummyTopic).Return(errors.Conflict{})
+				topicDbMockObj.EXPECT().CreateTopic(dummyTopic).Return(false, errors.Conflict{})
 			}
 
-			_, err := Handler.CreateTopic(tc.dummyBodyString)
+			_, _, err := Handler.CreateTopic(tc.dummyBodyString)
+			if reflect.TypeOf(err) != reflect.TypeOf(tc.expectedError) {
+				t.Errorf("Expected Error: %s, Actual: %s", tc.expectedError, err)
+			}
+		})
+	}
+}
+
+func TestCallUpdateTopic(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	topicDbMockObj := topicDbMock.NewMockCommand(ctrl)
+	kaControllerMockObj := kaControllerMock.NewMockCommand(ctrl)
+
+	watchControllerMockObj := watchControllerMock.NewMockCommand(ctrl)
+
+	// pass mockObj to a real object.
+	topicDbExecutor = topicDbMockObj
+	keepaliveExecutor = kaControllerMockObj
+	watchExecutor = watchControllerMockObj
+	defer func() { watchExecutor = watchController.Executor{} }()
+
+	dummyBodyString := `{"topic":{"name":"/a","endpoint":"0.0.0.0:5678","revision":1}}`
+	dummyTopic := map[string]interface{}{"name": "/a", "endpoint": "0.0.0.0:5678", "revision": float64(1)}
+	dummyUpdated := map[string]interface{}{"name": "/a", "endpoint": "0.0.0.0:5678", "endpoints": []string{"0.0.0.0:5678"}, "datamodel": "test_0.0.1", "secured": false, "revision": int64(2)}
+	expectedResp := map[string]interface{}{"topic": dummyUpdated}
+
+	gomock.InOrder(
+		topicDbMockObj.EXPECT().UpdateTopic(dummyTopic, true).Return(dummyUpdated, nil),
+		kaControllerMockObj.EXPECT().SetEndpoints("/a", []string{"0.0.0.0:5678"}),
+		watchControllerMockObj.EXPECT().Publish(watchController.EVENT_UPDATED, dummyUpdated),
+	)
+
+	resp, err := Handler.UpdateTopic(dummyBodyString, true)
+	if err != nil {
+		t.Errorf("UpdateTopic returned an error: %s", err.Error())
+	}
+	if isEqual := reflect.DeepEqual(resp, expectedResp); !isEqual {
+		t.Errorf("Expected Resp: %s, Actual: %s", expectedResp, resp)
+	}
+}
+
+func TestCallUpdateTopicWithInvalidBody(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	topicDbMockObj := topicDbMock.NewMockCommand(ctrl)
+
+	// pass mockObj to a real object.
+	topicDbExecutor = topicDbMockObj
+
+	testCases := []struct {
+		name            string
+		dummyBodyString string
+		expectedError   error
+	}{
+		{"InvalidJson", `{invalidJson[}`, errors.InvalidJSON{}},
+		{"InvalidParam_topic", `{"invalid":{"name":"/a","endpoint":"0.0.0.0:1234"}}`, errors.InvalidParam{}},
+		{"InvalidParam_name", `{"topic":{"endpoint":"0.0.0.0:1234"}}`, errors.InvalidParam{}},
+		{"Conflict", `{"topic":{"name":"/a","endpoint":"0.0.0.0:1234","revision":1}}`, errors.Conflict{}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// mock will be called only for the conflict error case.
+			if tc.name == "Conflict" {
+				dummyTopic := map[string]interface{}{"name": "/a", "endpoint": "0.0.0.0:1234", "revision": float64(1)}
+				topicDbMockObj.EXPECT().UpdateTopic(dummyTopic, false).Return(nil, errors.Conflict{})
+			}
+
+			_, err := Handler.UpdateTopic(tc.dummyBodyString, false)
 			if reflect.TypeOf(err) != reflect.TypeOf(tc.expectedError) {
 				t.Errorf("Expected Error: %s, Actual: %s", tc.expectedError, err)
 			}
@@ -104,37 +362,45 @@ func TestCallReadTopic(t *testing.T) {
 	defer ctrl.Finish()
 
 	topicDbMockObj := topicDbMock.NewMockCommand(ctrl)
+	kaControllerMockObj := kaControllerMock.NewMockCommand(ctrl)
 
 	// pass mockObj to a real object.
 	topicDbExecutor = topicDbMockObj
+	keepaliveExecutor = kaControllerMockObj
 
 	topics := []map[string]interface{}{{"name": "/a"}}
-	successResp := map[string]interface{}{"topics": topics}
+	successResp := map[string]interface{}{"topics": []map[string]interface{}{{"name": "/a", "status": "stale"}}}
 	hierarchical := false
 
+	kaControllerMockObj.EXPECT().GetStatus("/a").Return("stale").AnyTimes()
+
 	testCases := []struct {
 		name          string
 		topicName     string
+		selector      string
 		mockRetTopics []map[string]interface{}
 		mockRetError  error
 		expectedResp  map[string]interface{}
 		expectedError error
 	}{
-		{"Success_Single", "/a", topics, nil, successResp, nil},
-		{"Success_All", "", topics, nil, successResp, nil},
-		{"DbFailed", "/a", nil, errors.InternalServerError{}, nil, errors.InternalServerError{}},
-		{"NotFound", "", nil, nil, nil, errors.NotFound{}},
+		{"Success_Single", "/a", "", topics, nil, successResp, nil},
+		{"Success_All", "", "", topics, nil, successResp, nil},
+		{"Success_Selector", "", "site=plant3", topics, nil, successResp, nil},
+		{"DbFailed", "/a", "", nil, errors.InternalServerError{}, nil, errors.InternalServerError{}},
+		{"InvalidSelector", "/a", "site=", nil, errors.InvalidQuery{}, nil, errors.InvalidQuery{}},
+		{"NotFound", "", "", nil, nil, nil, errors.NotFound{}},
+		{"NotFound_Selector", "/a", "site=plant3", []map[string]interface{}{}, nil, nil, errors.NotFound{}},
 	}
 
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
 			if tc.topicName == "" {
-				topicDbMockObj.EXPECT().ReadTopicAll().Return(tc.mockRetTopics, tc.mockRetError)
+				topicDbMockObj.EXPECT().ReadTopicAll(tc.selector).Return(tc.mockRetTopics, tc.mockRetError)
 			} else {
-				topicDbMockObj.EXPECT().ReadTopic(tc.topicName, hierarchical).Return(tc.mockRetTopics, tc.mockRetError)
+				topicDbMockObj.EXPECT().ReadTopic(tc.topicName, hierarchical, tc.selector).Return(tc.mockRetTopics, tc.mockRetError)
 			}
 
-			resp, err := Handler.ReadTopic(tc.topicName, hierarchical)
+			resp, err := Handler.ReadTopic(tc.topicName, hierarchical, tc.selector, false, -1)
 			if reflect.TypeOf(err) != reflect.TypeOf(tc.expectedError) {
 				t.Errorf("Expected Error: %s, Actual: %s", tc.expectedError, err)
 			}
@@ -145,6 +411,148 @@ func TestCallReadTopic(t *testing.T) {
 	}
 }
 
+func TestCallReadTopicWithLiveness(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	topicDbMockObj := topicDbMock.NewMockCommand(ctrl)
+	kaControllerMockObj := kaControllerMock.NewMockCommand(ctrl)
+
+	// pass mockObj to a real object.
+	topicDbExecutor = topicDbMockObj
+	keepaliveExecutor = kaControllerMockObj
+
+	currTime := time.Now().Truncate(time.Second)
+	dummyTopics := func() []map[string]interface{} {
+		return []map[string]interface{}{
+			{"name": "/a", "endpoints": []string{"0.0.0.0:1234", "0.0.0.0:5678"},
+				"registered_at": map[string]time.Time{"0.0.0.0:1234": currTime.Add(-time.Hour), "0.0.0.0:5678": currTime.Add(-time.Minute)}},
+			{"name": "/b", "endpoints": []string{"0.0.0.0:1234"}, "registered_at": map[string]time.Time{"0.0.0.0:1234": {}}},
+		}
+	}
+
+	kaControllerMockObj.EXPECT().GetLiveness("/a").Return(map[string]keepalive.Liveness{
+		"0.0.0.0:1234": {LastSeen: currTime.Add(-20 * time.Second), ExpiresAt: currTime.Add(10 * time.Second)},
+		"0.0.0.0:5678": {LastSeen: currTime.Add(-10 * time.Second), ExpiresAt: currTime.Add(20 * time.Second)},
+	}).AnyTimes()
+	kaControllerMockObj.EXPECT().GetLiveness("/b").Return(map[string]keepalive.Liveness{
+		"0.0.0.0:1234": {LastSeen: currTime, ExpiresAt: currTime.Add(time.Hour)},
+	}).AnyTimes()
+	kaControllerMockObj.EXPECT().GetInterval(gomock.Any()).Return(uint(10)).AnyTimes()
+	kaControllerMockObj.EXPECT().GetStatus(gomock.Any()).Return("alive").AnyTimes()
+
+	expectedA := map[string]interface{}{"name": "/a", "endpoints": []string{"0.0.0.0:1234", "0.0.0.0:5678"}, "status": "alive",
+		"registered_at": formatTime(currTime.Add(-time.Hour)),
+		"last_seen":     formatTime(currTime.Add(-10 * time.Second)),
+		"expires_at":    formatTime(currTime.Add(20 * time.Second)),
+		"ka_interval":   uint(10),
+		"publishers": []map[string]interface{}{
+			{"endpoint": "0.0.0.0:1234", "registered_at": formatTime(currTime.Add(-time.Hour)),
+				"last_seen": formatTime(currTime.Add(-20 * time.Second)), "expires_at": formatTime(currTime.Add(10 * time.Second))},
+			{"endpoint": "0.0.0.0:5678", "registered_at": formatTime(currTime.Add(-time.Minute)),
+				"last_seen": formatTime(currTime.Add(-10 * time.Second)), "expires_at": formatTime(currTime.Add(20 * time.Second))},
+		},
+	}
+	// registered by a former version
+	expectedB := map[string]interface{}{"name": "/b", "endpoints": []string{"0.0.0.0:1234"}, "status": "alive",
+		"last_seen":   formatTime(currTime),
+		"expires_at":  formatTime(currTime.Add(time.Hour)),
+		"ka_interval": uint(10),
+		"publishers": []map[string]interface{}{
+			{"endpoint": "0.0.0.0:1234", "last_seen": formatTime(currTime), "expires_at": formatTime(currTime.Add(time.Hour))},
+		},
+	}
+
+	testCases := []struct {
+		name           string
+		liveness       bool
+		expiringWithin int
+		expectedTopics []map[string]interface{}
+		expectedError  error
+	}{
+		{"Success", true, -1, []map[string]interface{}{expectedA, expectedB}, nil},
+		{"Success_ExpiringWithin", true, 60, []map[string]interface{}{expectedA}, nil},
+		{"Success_WithoutLiveness", false, -1, []map[string]interface{}{
+			{"name": "/a", "endpoints": []string{"0.0.0.0:1234", "0.0.0.0:5678"}, "status": "alive"},
+			{"name": "/b", "endpoints": []string{"0.0.0.0:1234"}, "status": "alive"}}, nil},
+		{"NotFound_ExpiringWithin", true, 5, nil, errors.NotFound{}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			topicDbMockObj.EXPECT().ReadTopicAll("").Return(dummyTopics(), nil)
+
+			resp, err := Handler.ReadTopic("", false, "", tc.liveness, tc.expiringWithin)
+			if reflect.TypeOf(err) != reflect.TypeOf(tc.expectedError) {
+				t.Errorf("Expected Error: %s, Actual: %s", tc.expectedError, err)
+			}
+			if err == nil && !reflect.DeepEqual(resp["topics"], tc.expectedTopics) {
+				t.Errorf("Expected Topics: %v, Actual: %v", tc.expectedTopics, resp["topics"])
+			}
+		})
+	}
+}
+
+func TestCallReadTopicWithFederation(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	topicDbMockObj := topicDbMock.NewMockCommand(ctrl)
+	kaControllerMockObj := kaControllerMock.NewMockCommand(ctrl)
+	federationControllerMockObj := federationControllerMock.NewMockCommand(ctrl)
+
+	// pass mockObj to a real object.
+	topicDbExecutor = topicDbMockObj
+	keepaliveExecutor = kaControllerMockObj
+	federationExecutor = federationControllerMockObj
+	defer func() { federationExecutor = federationController.Executor{} }()
+
+	kaControllerMockObj.EXPECT().GetStatus(gomock.Any()).Return("alive").AnyTimes()
+
+	remoteTopics := []map[string]interface{}{{"name": "/a", "status": "stale", "source": "cell1:48323"}}
+
+	testCases := []struct {
+		name           string
+		liveness       bool
+		hops           uint
+		mockRetLocal   []map[string]interface{}
+		mockRetRemote  []map[string]interface{}
+		lookedUp       bool
+		expectedTopics []map[string]interface{}
+		expectedError  error
+	}{
+		{"Local", false, 0, []map[string]interface{}{{"name": "/a"}}, nil, false,
+			[]map[string]interface{}{{"name": "/a", "status": "alive"}}, nil},
+		{"Remote", false, 0, nil, remoteTopics, true, remoteTopics, nil},
+		{"Remote_Hops", false, 2, nil, remoteTopics, true, remoteTopics, nil},
+		{"NotFound", false, 0, nil, nil, true, nil, errors.NotFound{}},
+		{"NotFound_Liveness", true, 0, nil, nil, false, nil, errors.NotFound{}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			topicDbMockObj.EXPECT().ReadTopic("/a", false, "").Return(tc.mockRetLocal, nil)
+			if tc.lookedUp {
+				federationControllerMockObj.EXPECT().Lookup("/a", false, "", tc.hops).Return(tc.mockRetRemote)
+			}
+
+			var resp map[string]interface{}
+			var err error
+			if tc.hops != 0 {
+				resp, err = Handler.ReadTopicWithHops("/a", false, "", tc.hops)
+			} else {
+				resp, err = Handler.ReadTopic("/a", false, "", tc.liveness, -1)
+			}
+			if reflect.TypeOf(err) != reflect.TypeOf(tc.expectedError) {
+				t.Errorf("Expected Error: %s, Actual: %s", tc.expectedError, err)
+			}
+			if err == nil && !reflect.DeepEqual(resp["topics"], tc.expectedTopics) {
+				t.Errorf("Expected Topics: %v, Actual: %v", tc.expectedTopics, resp["topics"])
+			}
+		})
+	}
+}
+
 func TestCallDeleteTopic(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -152,31 +560,48 @@ func TestCallDeleteTopic(t *testing.T) {
 	topicDbMockObj := topicDbMock.NewMockCommand(ctrl)
 	kaControllerMockObj := kaControllerMock.NewMockCommand(ctrl)
 
+	watchControllerMockObj := watchControllerMock.NewMockCommand(ctrl)
+
 	// pass mockObj to a real object.
 	topicDbExecutor = topicDbMockObj
 	keepaliveExecutor = kaControllerMockObj
+	watchExecutor = watchControllerMockObj
+	defer func() { watchExecutor = watchController.Executor{} }()
 
 	topicName := "/a"
 
 	testCases := []struct {
 		name          string
+		endpoint      string
 		mockRetError  error
 		expectedError error
 	}{
-		{"Success", nil, nil},
-		{"DbFailed", errors.NotFound{}, errors.NotFound{}},
+		{"Success", "", nil, nil},
+		{"DbFailed", "", errors.NotFound{}, errors.NotFound{}},
+		{"Success_Publisher", "0.0.0.0:1234", nil, nil},
+		{"DbFailed_Publisher", "0.0.0.0:1234", errors.NotFound{}, errors.NotFound{}},
 	}
 
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
-			topicDbMockObj.EXPECT().DeleteTopic(topicName).Return(tc.mockRetError)
+			if tc.endpoint == "" {
+				topicDbMockObj.EXPECT().DeleteTopic(topicName).Return(tc.mockRetError)
+			} else {
+				topicDbMockObj.EXPECT().DeletePublisher(topicName, tc.endpoint).Return(tc.mockRetError)
+			}
 
-			// kaMock will be called only for the success case.
-			if tc.name == "Success" {
-				kaControllerMockObj.EXPECT().DeleteTopic(topicName)
+			// kaMock and watchMock will be called only for the success case.
+			if tc.mockRetError == nil {
+				if tc.endpoint == "" {
+					kaControllerMockObj.EXPECT().DeleteTopic(topicName)
+					watchControllerMockObj.EXPECT().Publish(watchController.EVENT_DELETED, map[string]interface{}{"name": topicName})
+				} else {
+					kaControllerMockObj.EXPECT().DeletePublisher(topicName, tc.endpoint)
+					watchControllerMockObj.EXPECT().Publish(watchController.EVENT_DELETED, map[string]interface{}{"name": topicName, "endpoint": tc.endpoint})
+				}
 			}
 
-			err := Handler.DeleteTopic(topicName)
+			err := Handler.DeleteTopic(topicName, tc.endpoint)
 			if reflect.TypeOf(err) != reflect.TypeOf(tc.expectedError) {
 				t.Errorf("Expected Error: %s, Actual: %s", tc.expectedError, err)
 			}
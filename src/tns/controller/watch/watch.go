@@ -0,0 +1,202 @@
+/*******************************************************************************
+ * Copyright 2018 Samsung Electronics All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ *******************************************************************************/
+package watch
+
+import (
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"tns/commons/logger"
+	topicDB "tns/db/topic"
+)
+
+// Changes of the topic registry are published as events to the subscribers
+// whose name filters match the topics. Recent events are kept in memory, so
+// that a subscriber can resume from the ID of the last event it has received.
+// IDs consist of the start time of the server and a sequence number, thus
+// the events before a restart can not be resumed.
+
+type Command interface {
+	Publish(eventType string, topic map[string]interface{})
+	Subscribe(name string, hierarchical bool, lastEventID string) (*Subscription, error)
+	Unsubscribe(subscription *Subscription)
+}
+
+// Executor implements the Command interface.
+type Executor struct{}
+
+// Types of events.
+const (
+	EVENT_CREATED = "created" // A topic is registered, or a publisher joins it
+	EVENT_UPDATED = "updated" // A topic is updated, or a publisher registers it again
+	EVENT_DELETED = "deleted" // A topic is removed, or a publisher leaves it
+	EVENT_EXPIRED = "expired" // A publisher is removed without keep-alive
+	EVENT_RESET   = "reset"   // Events may have been missed, topics should be read again
+)
+
+const (
+	HISTORY_SIZE             = 1024 // Number of recent events kept for resumption
+	SUBSCRIPTION_BUFFER_SIZE = 256  // Events pending for a subscriber
+)
+
+// Event is a change of a topic. Topic has 'name' at least, and 'endpoint'
+// if the change is about a publisher of the topic. An updated topic has all of
+// its properties with 'endpoints', while a publisher registered again has the
+// properties in the request without 'endpoints'.
+type Event struct {
+	ID    string
+	Type  string
+	Topic map[string]interface{}
+	seq   uint64
+}
+
+// Subscription receives the matched events from Events. Events is closed when
+// the subscription is dropped because it can not keep up with the events,
+// then the subscriber should resume with the ID of the last received event.
+type Subscription struct {
+	Events <-chan Event
+	events chan Event
+	match  func(name string) bool
+}
+
+type watchInfo struct {
+	sync.Mutex
+	epoch         string // Start time of the server
+	seq           uint64 // Sequence number of the last event
+	history       []Event
+	subscriptions map[*Subscription]bool
+}
+
+var info watchInfo
+
+func init() {
+	info.epoch = strconv.FormatInt(time.Now().UnixNano(), 36)
+	info.subscriptions = make(map[*Subscription]bool)
+}
+
+// Publish sends the event of the topic to the matched subscribers.
+// Subscribers which have no room for it are dropped instead of being waited.
+func (Executor) Publish(eventType string, topic map[string]interface{}) {
+	name, _ := topic["name"].(string)
+
+	info.Lock()
+	defer info.Unlock()
+
+	info.seq++
+	event := Event{ID: eventID(info.seq), Type: eventType, Topic: topic, seq: info.seq}
+
+	info.history = append(info.history, event)
+	if len(info.history) > HISTORY_SIZE {
+		info.history = info.history[1:]
+	}
+
+	for subscription := range info.subscriptions {
+		if !subscription.match(name) {
+			continue
+		}
+		select {
+		case subscription.events <- event:
+		default:
+			logger.Logging(logger.DEBUG, "Subscriber dropped, too many pending events")
+			removeSubscription(subscription)
+		}
+	}
+}
+
+// Subscribe starts to receive the events of the topics matched by name,
+// in the same way as ReadTopic. If lastEventID is given, the events after it
+// are received first, or a reset event if they are no longer available.
+func (Executor) Subscribe(name string, hierarchical bool, lastEventID string) (*Subscription, error) {
+	match, err := topicDB.NameMatcher(name, hierarchical)
+	if err != nil {
+		logger.Logging(logger.DEBUG, "NameMatcher failed: "+err.Error())
+		return nil, err
+	}
+
+	info.Lock()
+	defer info.Unlock()
+
+	var pending []Event
+	if lastEventID != "" {
+		pending = eventsAfter(lastEventID, match)
+	}
+
+	events := make(chan Event, len(pending)+SUBSCRIPTION_BUFFER_SIZE)
+	for _, event := range pending {
+		events <- event
+	}
+
+	subscription := &Subscription{Events: events, events: events, match: match}
+	info.subscriptions[subscription] = true
+
+	return subscription, nil
+}
+
+// Unsubscribe stops the subscription. It is safe to call after it is dropped.
+func (Executor) Unsubscribe(subscription *Subscription) {
+	info.Lock()
+	defer info.Unlock()
+
+	removeSubscription(subscription)
+}
+
+// The followings should be called with info locked.
+
+// eventsAfter returns the matched events after the given ID. A reset event
+// is returned if some of them have been discarded, or the ID is unknown.
+func eventsAfter(lastEventID string, match func(name string) bool) []Event {
+	seq, ok := parseEventID(lastEventID)
+	oldest := info.seq - uint64(len(info.history)) // Sequence number before the history
+	if !ok || seq > info.seq || seq < oldest {
+		logger.Logging(logger.DEBUG, "Events after "+lastEventID+" are not available")
+		return []Event{{ID: eventID(info.seq), Type: EVENT_RESET, Topic: map[string]interface{}{}, seq: info.seq}}
+	}
+
+	var events []Event
+	for _, event := range info.history[seq-oldest:] {
+		name, _ := event.Topic["name"].(string)
+		if match(name) {
+			events = append(events, event)
+		}
+	}
+	return events
+}
+
+func removeSubscription(subscription *Subscription) {
+	if info.subscriptions[subscription] {
+		delete(info.subscriptions, subscription)
+		close(subscription.events)
+	}
+}
+
+func eventID(seq uint64) string {
+	return info.epoch + "-" + strconv.FormatUint(seq, 10)
+}
+
+// parseEventID returns the sequence number of the event ID issued by this server.
+func parseEventID(id string) (uint64, bool) {
+	i := strings.LastIndex(id, "-")
+	if i < 0 || id[:i] != info.epoch {
+		return 0, false
+	}
+	seq, err := strconv.ParseUint(id[i+1:], 10, 64)
+	if err != nil {
+		return 0, false
+	}
+	return seq, true
+}
@@ -0,0 +1,177 @@
+/*******************************************************************************
+ * Copyright 2018 Samsung Electronics All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ *******************************************************************************/
+package watch
+
+import (
+	"reflect"
+	"testing"
+	"tns/commons/errors"
+)
+
+var Handler Command
+
+func init() {
+	Handler = Executor{}
+}
+
+func TestCallSubscribe(t *testing.T) {
+	initWatchForTest()
+
+	testCases := []struct {
+		name           string
+		filter         string
+		hierarchical   bool
+		expectedTopics []string
+		expectedError  error
+	}{
+		{"All", "", false, []string{"/a", "/a/b", "/b"}, nil},
+		{"Exact", "/a", false, []string{"/a"}, nil},
+		{"Hierarchical", "/a", true, []string{"/a", "/a/b"}, nil},
+		{"Wildcard", "/+", false, []string{"/a", "/b"}, nil},
+		{"InvalidWildcard", "/#/a", false, nil, errors.InvalidQuery{}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			subscription, err := Handler.Subscribe(tc.filter, tc.hierarchical, "")
+			if reflect.TypeOf(err) != reflect.TypeOf(tc.expectedError) {
+				t.Fatalf("Expected Error: %s, Actual: %s", tc.expectedError, err)
+			}
+			if err != nil {
+				return
+			}
+			defer Handler.Unsubscribe(subscription)
+
+			for _, name := range []string{"/a", "/a/b", "/b"} {
+				Handler.Publish(EVENT_CREATED, map[string]interface{}{"name": name})
+			}
+
+			if names := receivedTopics(subscription); !reflect.DeepEqual(names, tc.expectedTopics) {
+				t.Errorf("Expected Topics: %v, Actual: %v", tc.expectedTopics, names)
+			}
+		})
+	}
+}
+
+func TestCallSubscribeWithLastEventID(t *testing.T) {
+	initWatchForTest()
+
+	for _, name := range []string{"/a", "/b", "/a/b", "/c"} {
+		Handler.Publish(EVENT_CREATED, map[string]interface{}{"name": name})
+	}
+
+	testCases := []struct {
+		name          string
+		lastEventID   string
+		expectedTypes []string
+		expectedIDs   []string
+	}{
+		{"Resume", eventID(1), []string{EVENT_CREATED}, []string{eventID(3)}},
+		{"Resume_FromOldest", eventID(0), []string{EVENT_CREATED, EVENT_CREATED}, []string{eventID(1), eventID(3)}},
+		{"Resume_Latest", eventID(4), nil, nil},
+		{"Reset_Future", eventID(5), []string{EVENT_RESET}, []string{eventID(4)}},
+		{"Reset_AnotherServer", "0-1", []string{EVENT_RESET}, []string{eventID(4)}},
+		{"Reset_Invalid", "invalid", []string{EVENT_RESET}, []string{eventID(4)}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			subscription, err := Handler.Subscribe("/a", true, tc.lastEventID)
+			if err != nil {
+				t.Fatalf("Subscribe returned an error: %s", err.Error())
+			}
+			Handler.Unsubscribe(subscription)
+
+			var types, ids []string
+			for event := range subscription.Events {
+				types = append(types, event.Type)
+				ids = append(ids, event.ID)
+			}
+			if !reflect.DeepEqual(types, tc.expectedTypes) || !reflect.DeepEqual(ids, tc.expectedIDs) {
+				t.Errorf("Expected Events: %v %v, Actual: %v %v", tc.expectedTypes, tc.expectedIDs, types, ids)
+			}
+		})
+	}
+}
+
+func TestSubscribeAfterHistoryDiscarded(t *testing.T) {
+	initWatchForTest()
+
+	for i := 0; i < HISTORY_SIZE+1; i++ {
+		Handler.Publish(EVENT_UPDATED, map[string]interface{}{"name": "/a"})
+	}
+
+	// The first event has been discarded
+	subscription, _ := Handler.Subscribe("", false, eventID(0))
+	if event := <-subscription.Events; event.Type != EVENT_RESET {
+		t.Errorf("Expected Event: %s, Actual: %s", EVENT_RESET, event.Type)
+	}
+	Handler.Unsubscribe(subscription)
+
+	subscription, _ = Handler.Subscribe("", false, eventID(1))
+	if len(subscription.Events) != HISTORY_SIZE {
+		t.Errorf("Expected Events: %d, Actual: %d", HISTORY_SIZE, len(subscription.Events))
+	}
+	Handler.Unsubscribe(subscription)
+}
+
+func TestPublishDropsSlowSubscriber(t *testing.T) {
+	initWatchForTest()
+
+	slow, _ := Handler.Subscribe("", false, "")
+	unmatched, _ := Handler.Subscribe("/b", false, "")
+	defer Handler.Unsubscribe(unmatched)
+
+	for i := 0; i < SUBSCRIPTION_BUFFER_SIZE+1; i++ {
+		Handler.Publish(EVENT_UPDATED, map[string]interface{}{"name": "/a"})
+	}
+
+	// Pending events are delivered before it is closed
+	count := 0
+	for range slow.Events {
+		count++
+	}
+	if count != SUBSCRIPTION_BUFFER_SIZE {
+		t.Errorf("Expected Events: %d, Actual: %d", SUBSCRIPTION_BUFFER_SIZE, count)
+	}
+	if _, exists := info.subscriptions[unmatched]; !exists {
+		t.Errorf("Expected the unmatched subscriber to be kept")
+	}
+
+	// Already dropped
+	Handler.Unsubscribe(slow)
+}
+
+// initWatchForTest clears the events and subscriptions.
+func initWatchForTest() {
+	info.Lock()
+	defer info.Unlock()
+
+	info.seq = 0
+	info.history = nil
+	info.subscriptions = make(map[*Subscription]bool)
+}
+
+// receivedTopics returns the names of the topics of the pending events.
+func receivedTopics(subscription *Subscription) []string {
+	var names []string
+	for len(subscription.Events) != 0 {
+		event := <-subscription.Events
+		names = append(names, event.Topic["name"].(string))
+	}
+	return names
+}
@@ -0,0 +1,343 @@
+/*******************************************************************************
+ * Copyright 2018 Samsung Electronics All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ *******************************************************************************/
+package webhook
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"time"
+	"tns/commons/logger"
+	watchController "tns/controller/watch"
+	topicDB "tns/db/topic"
+)
+
+// The dispatcher receives the events from the watch stream and passes them to
+// the sender of every matched webhook. Each sender posts the events to its URL
+// one by one in order, retrying failed ones with exponential backoff.
+// An event is signed with HMAC-SHA256 of the body keyed by the secret of the
+// webhook, which is sent in SIGNATURE_HEADER as "sha256=<hex digest>".
+
+const (
+	MAX_DELIVERY_ATTEMPTS = 5
+	DELIVERY_QUEUE_SIZE   = 256 // Events pending for a webhook
+	DELIVERY_LOG_SIZE     = 50  // Recent deliveries kept for a webhook
+
+	EVENT_HEADER     = "X-TNS-Event"
+	DELIVERY_HEADER  = "X-TNS-Delivery"
+	SIGNATURE_HEADER = "X-TNS-Signature"
+)
+
+// Status of deliveries.
+const (
+	DELIVERY_PENDING   = "pending"
+	DELIVERY_DELIVERED = "delivered"
+	DELIVERY_FAILED    = "failed"  // Gave up after retries, or rejected by the webhook
+	DELIVERY_DROPPED   = "dropped" // Too many events pending for the webhook
+)
+
+// Delay before the first retry of a failed delivery, doubled on each retry.
+var deliveryRetryDelay = time.Second
+
+var httpClient = &http.Client{Timeout: 10 * time.Second}
+
+// sender delivers the events to a webhook.
+type sender struct {
+	id     string
+	url    string
+	secret string
+	queue  chan *delivery
+	stop   chan struct{}
+	log    []*delivery // Recent deliveries, the oldest first
+}
+
+// delivery is an event to be delivered, which is updated with info locked.
+type delivery struct {
+	event      watchController.Event
+	timestamp  time.Time
+	status     string
+	attempts   int
+	statusCode int
+	err        string
+}
+
+// dispatcher passes the events of the subscription to the senders until stop is closed.
+// It resumes from the last received event when its subscription is dropped.
+func dispatcher(subscription *watchController.Subscription, stop <-chan struct{}) {
+	logger.Logging(logger.DEBUG, "Start Webhook dispatcher")
+	defer logger.Logging(logger.DEBUG, "Webhook dispatcher Finished")
+
+	lastEventID := ""
+	for {
+		dropped := false
+		for !dropped {
+			select {
+			case event, ok := <-subscription.Events:
+				if !ok {
+					dropped = true
+					break
+				}
+				lastEventID = event.ID
+				dispatch(event)
+			case <-stop:
+				watchExecutor.Unsubscribe(subscription)
+				return
+			}
+		}
+
+		var err error
+		subscription, err = watchExecutor.Subscribe("", false, lastEventID)
+		if err != nil {
+			logger.Logging(logger.ERROR, "Subscribe failed: "+err.Error())
+			return
+		}
+	}
+}
+
+// dispatch passes the event to the senders of the matched webhooks.
+func dispatch(event watchController.Event) {
+	if event.Type == watchController.EVENT_RESET {
+		logger.Logging(logger.ERROR, "Events are missed, webhooks are not notified of them")
+		return
+	}
+
+	webhooks, err := topicDbExecutor.ReadWebhookAll()
+	if err != nil {
+		logger.Logging(logger.ERROR, "ReadWebhookAll failed: "+err.Error())
+		return
+	}
+
+	name, _ := event.Topic["name"].(string)
+
+	info.Lock()
+	defer info.Unlock()
+
+	registered := make(map[string]bool, len(webhooks))
+	for _, webhook := range webhooks {
+		id, _ := webhook["id"].(string)
+		registered[id] = true
+		if matches(webhook, name, event.Type) {
+			getSender(webhook).enqueue(event)
+		}
+	}
+
+	// Removed by another server sharing DB
+	for id := range info.senders {
+		if !registered[id] {
+			removeSender(id)
+		}
+	}
+}
+
+// matches returns true if the webhook is interested in the event of the topic.
+func matches(webhook map[string]interface{}, name string, eventType string) bool {
+	if events, _ := webhook["events"].([]string); len(events) != 0 {
+		found := false
+		for _, event := range events {
+			found = found || event == eventType
+		}
+		if !found {
+			return false
+		}
+	}
+
+	filter, _ := webhook["name"].(string)
+	hierarchical, _ := webhook["hierarchical"].(bool)
+	match, err := topicDB.NameMatcher(filter, hierarchical)
+	return err == nil && match(name)
+}
+
+// readDeliveries returns the recent deliveries of the webhook.
+func readDeliveries(id string) []map[string]interface{} {
+	info.Lock()
+	defer info.Unlock()
+
+	deliveries := []map[string]interface{}{}
+	if sender, exists := info.senders[id]; exists {
+		for _, delivery := range sender.log {
+			deliveries = append(deliveries, delivery.convertToMap())
+		}
+	}
+	return deliveries
+}
+
+// The followings should be called with info locked.
+
+// getSender returns the sender of the webhook, which is started if not exists.
+func getSender(webhook map[string]interface{}) *sender {
+	id, _ := webhook["id"].(string)
+	if s, exists := info.senders[id]; exists {
+		return s
+	}
+
+	s := &sender{
+		id:    id,
+		queue: make(chan *delivery, DELIVERY_QUEUE_SIZE),
+		stop:  make(chan struct{}),
+	}
+	s.url, _ = webhook["url"].(string)
+	s.secret, _ = webhook["secret"].(string)
+	info.senders[id] = s
+
+	go s.run()
+
+	return s
+}
+
+func removeSender(id string) {
+	if s, exists := info.senders[id]; exists {
+		close(s.stop)
+		delete(info.senders, id)
+	}
+}
+
+// stopDispatcher stops the dispatcher and all senders.
+func stopDispatcher() {
+	if info.stop != nil {
+		close(info.stop)
+		info.stop = nil
+	}
+	for id := range info.senders {
+		removeSender(id)
+	}
+}
+
+// enqueue adds the event to the queue of the sender, and to its log.
+func (s *sender) enqueue(event watchController.Event) {
+	d := &delivery{event: event, timestamp: time.Now(), status: DELIVERY_PENDING}
+
+	s.log = append(s.log, d)
+	if len(s.log) > DELIVERY_LOG_SIZE {
+		s.log = s.log[1:]
+	}
+
+	select {
+	case s.queue <- d:
+	default:
+		logger.Logging(logger.ERROR, "Delivery dropped: "+s.id+" "+event.ID)
+		d.status = DELIVERY_DROPPED
+	}
+}
+
+// run delivers the events in the queue until the sender is stopped.
+func (s *sender) run() {
+	for {
+		select {
+		case d := <-s.queue:
+			s.deliver(d)
+		case <-s.stop:
+			return
+		}
+	}
+}
+
+// deliver posts the event to the webhook up to MAX_DELIVERY_ATTEMPTS times.
+// Responses of 4xx other than 429 (Too Many Requests) are not retried.
+func (s *sender) deliver(d *delivery) {
+	body, _ := json.Marshal(map[string]interface{}{
+		"id":        d.event.ID,
+		"event":     d.event.Type,
+		"topic":     d.event.Topic,
+		"timestamp": d.timestamp.UTC().Format(time.RFC3339),
+	})
+
+	delay := deliveryRetryDelay
+	for attempt := 1; attempt <= MAX_DELIVERY_ATTEMPTS; attempt++ {
+		if attempt != 1 {
+			select {
+			case <-time.After(delay):
+			case <-s.stop:
+				return
+			}
+			delay *= 2
+		}
+
+		statusCode, err := s.post(d.event, body)
+
+		info.Lock()
+		d.attempts = attempt
+		d.statusCode = statusCode
+		d.err = ""
+		if err != nil {
+			d.err = err.Error()
+		}
+		switch {
+		case err == nil && statusCode/100 == 2:
+			d.status = DELIVERY_DELIVERED
+		case err == nil && statusCode/100 == 4 && statusCode != http.StatusTooManyRequests:
+			d.status = DELIVERY_FAILED
+		case attempt == MAX_DELIVERY_ATTEMPTS:
+			d.status = DELIVERY_FAILED
+		}
+		status := d.status
+		info.Unlock()
+
+		if status != DELIVERY_PENDING {
+			logger.Logging(logger.DEBUG, "Delivery "+status+": "+s.id+" "+d.event.ID)
+			return
+		}
+	}
+}
+
+// post sends the signed body to the webhook and returns the status code of the response.
+func (s *sender) post(event watchController.Event, body []byte) (int, error) {
+	req, err := http.NewRequest(http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(EVENT_HEADER, event.Type)
+	req.Header.Set(DELIVERY_HEADER, event.ID)
+	req.Header.Set(SIGNATURE_HEADER, sign(s.secret, body))
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	resp.Body.Close()
+
+	return resp.StatusCode, nil
+}
+
+// sign returns the signature of the body with the secret.
+func sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func (d *delivery) convertToMap() map[string]interface{} {
+	name, _ := d.event.Topic["name"].(string)
+	delivery := map[string]interface{}{
+		"event_id":  d.event.ID,
+		"event":     d.event.Type,
+		"name":      name,
+		"timestamp": d.timestamp.UTC().Format(time.RFC3339),
+		"status":    d.status,
+		"attempts":  d.attempts,
+	}
+	if d.statusCode != 0 {
+		delivery["status_code"] = d.statusCode
+	}
+	if d.err != "" {
+		delivery["error"] = d.err
+	}
+	return delivery
+}
@@ -0,0 +1,174 @@
+/*******************************************************************************
+ * Copyright 2018 Samsung Electronics All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ *******************************************************************************/
+package webhook
+
+import (
+	"encoding/json"
+	"github.com/golang/mock/gomock"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+	watchController "tns/controller/watch"
+	topicDbMock "tns/db/topic/mocks"
+)
+
+func TestDispatch(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	topicDbMockObj := topicDbMock.NewMockCommand(ctrl)
+
+	// pass mockObj to a real object.
+	topicDbExecutor = topicDbMockObj
+
+	deliveryRetryDelay = time.Millisecond
+	defer func() { deliveryRetryDelay = time.Second }()
+	defer stopSendersForTest()
+
+	type request struct {
+		event     string
+		signature string
+		body      []byte
+	}
+	requests := make(chan request, 16)
+
+	testCases := []struct {
+		name               string
+		filter             map[string]interface{}
+		topic              string
+		eventType          string
+		responses          []int
+		expectedNotified   bool
+		expectedStatus     string
+		expectedAttempts   int
+		expectedStatusCode int
+	}{
+		{"Delivered", nil, "/a/b", watchController.EVENT_CREATED, []int{http.StatusOK}, true, DELIVERY_DELIVERED, 1, http.StatusOK},
+		{"Delivered_AfterRetry", nil, "/a/b", watchController.EVENT_UPDATED,
+			[]int{http.StatusInternalServerError, http.StatusTooManyRequests, http.StatusNoContent}, true, DELIVERY_DELIVERED, 3, http.StatusNoContent},
+		{"Failed_Rejected", nil, "/a/b", watchController.EVENT_DELETED, []int{http.StatusBadRequest}, true, DELIVERY_FAILED, 1, http.StatusBadRequest},
+		{"Failed_Retries", nil, "/a/b", watchController.EVENT_EXPIRED,
+			[]int{500, 500, 500, 500, 500}, true, DELIVERY_FAILED, MAX_DELIVERY_ATTEMPTS, http.StatusInternalServerError},
+		{"Unmatched_Name", map[string]interface{}{"name": "/b"}, "/a/b", watchController.EVENT_CREATED, nil, false, "", 0, 0},
+		{"Unmatched_Event", map[string]interface{}{"events": []string{watchController.EVENT_DELETED}}, "/a/b", watchController.EVENT_CREATED, nil, false, "", 0, 0},
+		{"Matched_Hierarchical", map[string]interface{}{"name": "/a", "hierarchical": true}, "/a/b", watchController.EVENT_CREATED, []int{http.StatusOK}, true, DELIVERY_DELIVERED, 1, http.StatusOK},
+	}
+
+	for i, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			responses := make(chan int, len(tc.responses))
+			for _, code := range tc.responses {
+				responses <- code
+			}
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				body, _ := ioutil.ReadAll(req.Body)
+				requests <- request{req.Header.Get(EVENT_HEADER), req.Header.Get(SIGNATURE_HEADER), body}
+				w.WriteHeader(<-responses)
+			}))
+			defer server.Close()
+
+			id := string('0' + rune(i))
+			webhook := map[string]interface{}{"id": id, "url": server.URL, "secret": "secret"}
+			for key, value := range tc.filter {
+				webhook[key] = value
+			}
+			topicDbMockObj.EXPECT().ReadWebhookAll().Return([]map[string]interface{}{webhook}, nil)
+
+			event := watchController.Event{ID: "x-" + id, Type: tc.eventType, Topic: map[string]interface{}{"name": tc.topic}}
+			dispatch(event)
+
+			for attempt := 0; attempt < len(tc.responses); attempt++ {
+				r := <-requests
+				if r.event != tc.eventType {
+					t.Errorf("Expected Event: %s, Actual: %s", tc.eventType, r.event)
+				}
+				if expected := sign("secret", r.body); r.signature != expected {
+					t.Errorf("Expected Signature: %s, Actual: %s", expected, r.signature)
+				}
+				var payload map[string]interface{}
+				if err := json.Unmarshal(r.body, &payload); err != nil || payload["id"] != event.ID {
+					t.Errorf("Unexpected payload: %s", r.body)
+				}
+			}
+
+			deliveries := waitDeliveriesForTest(id)
+			if !tc.expectedNotified {
+				if len(deliveries) != 0 {
+					t.Errorf("Expected no deliveries, Actual: %v", deliveries)
+				}
+				return
+			}
+			if len(deliveries) != 1 {
+				t.Fatalf("Expected a delivery, Actual: %v", deliveries)
+			}
+			d := deliveries[0]
+			if d["status"] != tc.expectedStatus || d["attempts"] != tc.expectedAttempts || d["status_code"] != tc.expectedStatusCode {
+				t.Errorf("Expected Delivery: %s %d %d, Actual: %v", tc.expectedStatus, tc.expectedAttempts, tc.expectedStatusCode, d)
+			}
+		})
+	}
+}
+
+func TestDispatchRemovesUnregisteredSender(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	topicDbMockObj := topicDbMock.NewMockCommand(ctrl)
+
+	// pass mockObj to a real object.
+	topicDbExecutor = topicDbMockObj
+	defer stopSendersForTest()
+
+	info.Lock()
+	getSender(map[string]interface{}{"id": "removed", "url": "http://127.0.0.1:0"})
+	info.Unlock()
+
+	topicDbMockObj.EXPECT().ReadWebhookAll().Return([]map[string]interface{}{}, nil)
+
+	dispatch(watchController.Event{ID: "x-1", Type: watchController.EVENT_CREATED, Topic: map[string]interface{}{"name": "/a"}})
+
+	info.Lock()
+	defer info.Unlock()
+	if _, exists := info.senders["removed"]; exists {
+		t.Errorf("Expected the sender of the unregistered webhook to be removed")
+	}
+}
+
+// waitDeliveriesForTest returns the deliveries of the webhook once none is pending.
+func waitDeliveriesForTest(id string) []map[string]interface{} {
+	for i := 0; ; i++ {
+		deliveries := readDeliveries(id)
+		pending := false
+		for _, d := range deliveries {
+			pending = pending || d["status"] == DELIVERY_PENDING
+		}
+		if !pending || i == 500 {
+			return deliveries
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// stopSendersForTest stops and removes all senders.
+func stopSendersForTest() {
+	info.Lock()
+	defer info.Unlock()
+
+	stopDispatcher()
+}
@@ -0,0 +1,221 @@
+/*******************************************************************************
+ * Copyright 2018 Samsung Electronics All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ *******************************************************************************/
+package webhook
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"sync"
+	"tns/commons/errors"
+	"tns/commons/logger"
+	"tns/commons/util"
+	watchController "tns/controller/watch"
+	topicDB "tns/db/topic"
+)
+
+// Webhooks are registered in DB, and notified of the events of the topics
+// published to the watch stream. Deliveries are kept in memory only.
+
+type Command interface {
+	InitWebhook() error
+	CreateWebhook(body string) (map[string]interface{}, error)
+	ReadWebhook(id string) (map[string]interface{}, error)
+	DeleteWebhook(id string) error
+}
+
+// Executor implements the Command interface.
+type Executor struct{}
+
+const (
+	WEBHOOK_ID_LENGTH = 8  // Bytes, hex encoded
+	SECRET_LENGTH     = 32 // Bytes of generated secrets, hex encoded
+)
+
+type webhookInfo struct {
+	sync.Mutex
+	senders map[string]*sender // Senders of webhooks by ID, created on the first delivery
+	stop    chan struct{}      // Closed to stop the dispatcher
+}
+
+var topicDbExecutor topicDB.Command
+var watchExecutor watchController.Command
+var info webhookInfo
+
+func init() {
+	topicDbExecutor = topicDB.Executor{}
+	watchExecutor = watchController.Executor{}
+	info.senders = make(map[string]*sender)
+}
+
+// InitWebhook starts to notify the registered webhooks of the changes of topics.
+func (Executor) InitWebhook() error {
+	logger.Logging(logger.DEBUG, "IN")
+	defer logger.Logging(logger.DEBUG, "OUT")
+
+	info.Lock()
+	defer info.Unlock()
+
+	stopDispatcher()
+
+	// Subscribed here not to miss the events published right after
+	subscription, err := watchExecutor.Subscribe("", false, "")
+	if err != nil {
+		logger.Logging(logger.ERROR, "Subscribe failed: "+err.Error())
+		return err
+	}
+
+	info.stop = make(chan struct{})
+	go dispatcher(subscription, info.stop)
+
+	return nil
+}
+
+// CreateWebhook registers the webhook in body. A secret is generated if not given,
+// and it is returned only in the response of the registration.
+func (Executor) CreateWebhook(body string) (map[string]interface{}, error) {
+	logger.Logging(logger.DEBUG, "IN")
+	defer logger.Logging(logger.DEBUG, "OUT")
+
+	bodyMap, err := util.ConvertJsonToMap(body)
+	if err != nil {
+		logger.Logging(logger.ERROR, "ConvertJsonToMap failed: "+err.Error())
+		return nil, err
+	}
+
+	webhook, exists := bodyMap["webhook"].(map[string]interface{})
+	if !exists {
+		logger.Logging(logger.DEBUG, "'webhook' does not present in body")
+		return nil, errors.InvalidParam{"'webhook' field is required"}
+	}
+
+	if events, exists := webhook["events"].([]interface{}); exists {
+		for _, event := range events {
+			if !isEventType(event) {
+				return nil, errors.InvalidParam{"unknown event in 'events' field"}
+			}
+		}
+	}
+
+	if value, exists := webhook["secret"]; exists {
+		if secret, ok := value.(string); !ok || secret == "" {
+			return nil, errors.InvalidParam{"'secret' field must be a non-empty string"}
+		}
+	} else {
+		secret, err := randomHex(SECRET_LENGTH)
+		if err != nil {
+			logger.Logging(logger.ERROR, "randomHex failed: "+err.Error())
+			return nil, errors.InternalServerError{"Failed to generate secret"}
+		}
+		webhook["secret"] = secret
+	}
+
+	id, err := randomHex(WEBHOOK_ID_LENGTH)
+	if err != nil {
+		logger.Logging(logger.ERROR, "randomHex failed: "+err.Error())
+		return nil, errors.InternalServerError{"Failed to generate webhook ID"}
+	}
+	webhook["id"] = id
+
+	err = topicDbExecutor.CreateWebhook(webhook)
+	if err != nil {
+		logger.Logging(logger.DEBUG, "CreateWebhook failed: "+err.Error())
+		return nil, err
+	}
+
+	created, err := topicDbExecutor.ReadWebhook(id)
+	if err != nil {
+		logger.Logging(logger.DEBUG, "ReadWebhook failed: "+err.Error())
+		return nil, err
+	}
+
+	resp := make(map[string]interface{})
+	resp["webhook"] = created
+
+	return resp, nil
+}
+
+// ReadWebhook returns the webhook of the given ID with its recent deliveries,
+// or all webhooks if id is empty. Secrets are not returned.
+func (Executor) ReadWebhook(id string) (map[string]interface{}, error) {
+	logger.Logging(logger.DEBUG, "IN")
+	defer logger.Logging(logger.DEBUG, "OUT")
+
+	var webhooks []map[string]interface{}
+
+	if id != "" {
+		webhook, err := topicDbExecutor.ReadWebhook(id)
+		if err != nil {
+			return nil, err
+		}
+		webhook["deliveries"] = readDeliveries(id)
+		webhooks = []map[string]interface{}{webhook}
+	} else {
+		var err error
+		webhooks, err = topicDbExecutor.ReadWebhookAll()
+		if err != nil {
+			return nil, err
+		} else if len(webhooks) == 0 {
+			logger.Logging(logger.DEBUG, "Nothing found")
+			return nil, errors.NotFound{"webhook is empty"}
+		}
+	}
+
+	for _, webhook := range webhooks {
+		delete(webhook, "secret")
+	}
+
+	resp := make(map[string]interface{})
+	resp["webhooks"] = webhooks
+
+	return resp, nil
+}
+
+// DeleteWebhook removes the webhook of the given ID.
+// Deliveries in progress are abandoned.
+func (Executor) DeleteWebhook(id string) error {
+	logger.Logging(logger.DEBUG, "IN")
+	defer logger.Logging(logger.DEBUG, "OUT")
+
+	err := topicDbExecutor.DeleteWebhook(id)
+	if err != nil {
+		logger.Logging(logger.DEBUG, "DeleteWebhook failed: "+err.Error())
+		return err
+	}
+
+	info.Lock()
+	removeSender(id)
+	info.Unlock()
+
+	return nil
+}
+
+func isEventType(event interface{}) bool {
+	switch event {
+	case watchController.EVENT_CREATED, watchController.EVENT_UPDATED,
+		watchController.EVENT_DELETED, watchController.EVENT_EXPIRED:
+		return true
+	}
+	return false
+}
+
+func randomHex(length int) (string, error) {
+	bytes := make([]byte, length)
+	if _, err := rand.Read(bytes); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(bytes), nil
+}
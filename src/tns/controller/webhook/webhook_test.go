@@ -0,0 +1,172 @@
+/*******************************************************************************
+ * Copyright 2018 Samsung Electronics All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ *******************************************************************************/
+package webhook
+
+import (
+	"github.com/golang/mock/gomock"
+	"reflect"
+	"testing"
+	"tns/commons/errors"
+	topicDbMock "tns/db/topic/mocks"
+)
+
+var Handler Command
+
+func init() {
+	Handler = Executor{}
+}
+
+func TestCallCreateWebhook(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	topicDbMockObj := topicDbMock.NewMockCommand(ctrl)
+
+	// pass mockObj to a real object.
+	topicDbExecutor = topicDbMockObj
+
+	dummyBodyString := `{"webhook":{"url":"http://127.0.0.1:8080/hook","name":"/a","events":["created"]}}`
+
+	testCases := []struct {
+		name            string
+		dummyBodyString string
+		callDb          bool
+		mockRetError    error
+		expectedSecret  string
+		expectedError   error
+	}{
+		{"Success", dummyBodyString, true, nil, "", nil},
+		{"Success_Secret", `{"webhook":{"url":"http://127.0.0.1:8080/hook","secret":"secret"}}`, true, nil, "secret", nil},
+		{"DbFailed", dummyBodyString, true, errors.InvalidParam{}, "", errors.InvalidParam{}},
+		{"InvalidJson", "{", false, nil, "", errors.InvalidJSON{}},
+		{"NoWebhook", `{"topic":{}}`, false, nil, "", errors.InvalidParam{}},
+		{"UnknownEvent", `{"webhook":{"url":"http://127.0.0.1:8080/hook","events":["reset"]}}`, false, nil, "", errors.InvalidParam{}},
+		{"EmptySecret", `{"webhook":{"url":"http://127.0.0.1:8080/hook","secret":""}}`, false, nil, "", errors.InvalidParam{}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Read back as registered
+			created := make(map[string]interface{})
+			if tc.callDb {
+				topicDbMockObj.EXPECT().CreateWebhook(gomock.Any()).Do(func(properties map[string]interface{}) {
+					for key, value := range properties {
+						created[key] = value
+					}
+				}).Return(tc.mockRetError)
+				if tc.mockRetError == nil {
+					topicDbMockObj.EXPECT().ReadWebhook(gomock.Any()).Return(created, nil)
+				}
+			}
+
+			resp, err := Handler.CreateWebhook(tc.dummyBodyString)
+			if reflect.TypeOf(err) != reflect.TypeOf(tc.expectedError) {
+				t.Fatalf("Expected Error: %s, Actual: %s", tc.expectedError, err)
+			}
+			if err != nil {
+				return
+			}
+
+			webhook, _ := resp["webhook"].(map[string]interface{})
+			if id, _ := webhook["id"].(string); len(id) != 2*WEBHOOK_ID_LENGTH {
+				t.Errorf("Expected ID of %d bytes, Actual: %s", WEBHOOK_ID_LENGTH, id)
+			}
+			secret, _ := webhook["secret"].(string)
+			if tc.expectedSecret != "" && secret != tc.expectedSecret {
+				t.Errorf("Expected Secret: %s, Actual: %s", tc.expectedSecret, secret)
+			} else if tc.expectedSecret == "" && len(secret) != 2*SECRET_LENGTH {
+				t.Errorf("Expected generated Secret of %d bytes, Actual: %s", SECRET_LENGTH, secret)
+			}
+		})
+	}
+}
+
+func TestCallReadWebhook(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	topicDbMockObj := topicDbMock.NewMockCommand(ctrl)
+
+	// pass mockObj to a real object.
+	topicDbExecutor = topicDbMockObj
+
+	testCases := []struct {
+		name          string
+		id            string
+		mockRetAll    []map[string]interface{}
+		mockRetError  error
+		expectedResp  map[string]interface{}
+		expectedError error
+	}{
+		{"Success_Id", "0123", nil, nil,
+			map[string]interface{}{"webhooks": []map[string]interface{}{{"id": "0123", "deliveries": []map[string]interface{}{}}}}, nil},
+		{"Success_All", "", []map[string]interface{}{{"id": "0123", "secret": "secret"}}, nil,
+			map[string]interface{}{"webhooks": []map[string]interface{}{{"id": "0123"}}}, nil},
+		{"NotFound_Id", "4567", nil, errors.NotFound{}, nil, errors.NotFound{}},
+		{"NotFound_All", "", []map[string]interface{}{}, nil, nil, errors.NotFound{}},
+		{"DbFailed", "", nil, errors.InternalServerError{}, nil, errors.InternalServerError{}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if tc.id != "" {
+				webhook := map[string]interface{}{"id": tc.id, "secret": "secret"}
+				topicDbMockObj.EXPECT().ReadWebhook(tc.id).Return(webhook, tc.mockRetError)
+			} else {
+				topicDbMockObj.EXPECT().ReadWebhookAll().Return(tc.mockRetAll, tc.mockRetError)
+			}
+
+			resp, err := Handler.ReadWebhook(tc.id)
+			if reflect.TypeOf(err) != reflect.TypeOf(tc.expectedError) {
+				t.Errorf("Expected Error: %s, Actual: %s", tc.expectedError, err)
+			}
+			if !reflect.DeepEqual(resp, tc.expectedResp) {
+				t.Errorf("Expected Resp: %v, Actual: %v", tc.expectedResp, resp)
+			}
+		})
+	}
+}
+
+func TestCallDeleteWebhook(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	topicDbMockObj := topicDbMock.NewMockCommand(ctrl)
+
+	// pass mockObj to a real object.
+	topicDbExecutor = topicDbMockObj
+
+	testCases := []struct {
+		name          string
+		mockRetError  error
+		expectedError error
+	}{
+		{"Success", nil, nil},
+		{"NotFound", errors.NotFound{}, errors.NotFound{}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			topicDbMockObj.EXPECT().DeleteWebhook("0123").Return(tc.mockRetError)
+
+			err := Handler.DeleteWebhook("0123")
+			if reflect.TypeOf(err) != reflect.TypeOf(tc.expectedError) {
+				t.Errorf("Expected Error: %s, Actual: %s", tc.expectedError, err)
+			}
+		})
+	}
+}
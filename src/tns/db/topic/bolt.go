@@ -0,0 +1,201 @@
+/*******************************************************************************
+ * Copyright 2018 Samsung Electronics All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ *******************************************************************************/
+
+package topic
+
+import (
+	"bytes"
+	"time"
+	"tns/commons/errors"
+	"tns/commons/logger"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+const (
+	TOPIC_BUCKET      = "TOPIC"
+	DATAMODEL_BUCKET  = "DATAMODEL"
+	WEBHOOK_BUCKET    = "WEBHOOK"
+	BOLT_FILE_EXT     = ".db"
+	BOLT_OPEN_TIMEOUT = 3 // Second
+)
+
+// BoltExecutor implements the Command interface on top of an embedded bbolt file.
+// Topics are stored in a single bucket keyed by name, so that
+// hierarchical queries can be served by a prefix scan.
+type BoltExecutor struct{}
+
+// boltStore implements the kvStore interface with a bucket of bbolt.
+type boltStore struct {
+	bucket string
+}
+
+type boltTx struct {
+	bucket *bolt.Bucket
+}
+
+var boltDB *bolt.DB
+
+func (b BoltExecutor) Connect(config Config) error {
+	path := config.Path
+	if path == "" {
+		path = config.Name + BOLT_FILE_EXT
+	}
+
+	db, err := bolt.Open(path, 0600, &bolt.Options{Timeout: BOLT_OPEN_TIMEOUT * time.Second})
+	if err != nil {
+		logger.Logging(logger.ERROR, err.Error())
+		return errors.InternalServerError{"Database Open Failed"}
+	}
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		for _, bucket := range []string{TOPIC_BUCKET, DATAMODEL_BUCKET, WEBHOOK_BUCKET} {
+			if _, err := tx.CreateBucketIfNotExists([]byte(bucket)); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		logger.Logging(logger.ERROR, err.Error())
+		db.Close()
+		return errors.InternalServerError{"Database Bucket Creation Failed"}
+	}
+
+	boltDB = db
+
+	logger.Logging(logger.DEBUG, "DB opened: "+path)
+
+	return nil
+}
+
+func (b BoltExecutor) Close() {
+	boltDB.Close()
+}
+
+func (b BoltExecutor) CreateTopic(properties map[string]interface{}) (bool, error) {
+	return kvCreateTopic(boltStore{TOPIC_BUCKET}, properties)
+}
+
+func (b BoltExecutor) UpdateTopic(properties map[string]interface{}, partial bool) (map[string]interface{}, error) {
+	return kvUpdateTopic(boltStore{TOPIC_BUCKET}, properties, partial)
+}
+
+func (b BoltExecutor) DeleteTopic(name string) error {
+	return kvDeleteTopic(boltStore{TOPIC_BUCKET}, name)
+}
+
+func (b BoltExecutor) DeletePublisher(name string, endpoint string) error {
+	return kvDeletePublisher(boltStore{TOPIC_BUCKET}, name, endpoint)
+}
+
+func (b BoltExecutor) DeletePublishers(publishers map[string][]string) error {
+	return kvDeletePublishers(boltStore{TOPIC_BUCKET}, publishers)
+}
+
+func (b BoltExecutor) UpdateLastSeen(names []string, endpoint string, lastSeen time.Time) error {
+	return kvUpdateLastSeen(boltStore{TOPIC_BUCKET}, names, endpoint, lastSeen)
+}
+
+func (b BoltExecutor) ReadLastSeenAll() (map[string]map[string]time.Time, error) {
+	return kvReadLastSeenAll(boltStore{TOPIC_BUCKET})
+}
+
+func (b BoltExecutor) UpdateInterval(name string, interval uint) error {
+	return kvUpdateInterval(boltStore{TOPIC_BUCKET}, name, interval)
+}
+
+func (b BoltExecutor) ReadIntervalAll() (map[string]uint, error) {
+	return kvReadIntervalAll(boltStore{TOPIC_BUCKET})
+}
+
+func (b BoltExecutor) ReadTopicAll(selector string) ([]map[string]interface{}, error) {
+	return kvReadTopicAll(boltStore{TOPIC_BUCKET}, selector)
+}
+
+func (b BoltExecutor) ReadTopic(name string, hierarchical bool, selector string) ([]map[string]interface{}, error) {
+	logger.Logging(logger.DEBUG, "IN")
+	defer logger.Logging(logger.DEBUG, "OUT")
+
+	return kvReadTopic(boltStore{TOPIC_BUCKET}, name, hierarchical, selector)
+}
+
+func (b BoltExecutor) CreateDatamodel(properties map[string]interface{}) error {
+	return kvCreateDatamodel(boltStore{DATAMODEL_BUCKET}, properties)
+}
+
+func (b BoltExecutor) ReadDatamodel(id string) (map[string]interface{}, error) {
+	return kvReadDatamodel(boltStore{DATAMODEL_BUCKET}, id)
+}
+
+func (b BoltExecutor) ReadDatamodelAll(name string) ([]map[string]interface{}, error) {
+	return kvReadDatamodelAll(boltStore{DATAMODEL_BUCKET}, name)
+}
+
+func (b BoltExecutor) DeleteDatamodel(id string) error {
+	return kvDeleteDatamodel(boltStore{DATAMODEL_BUCKET}, id)
+}
+
+func (b BoltExecutor) CreateWebhook(properties map[string]interface{}) error {
+	return kvCreateWebhook(boltStore{WEBHOOK_BUCKET}, properties)
+}
+
+func (b BoltExecutor) ReadWebhook(id string) (map[string]interface{}, error) {
+	return kvReadWebhook(boltStore{WEBHOOK_BUCKET}, id)
+}
+
+func (b BoltExecutor) ReadWebhookAll() ([]map[string]interface{}, error) {
+	return kvReadWebhookAll(boltStore{WEBHOOK_BUCKET})
+}
+
+func (b BoltExecutor) DeleteWebhook(id string) error {
+	return kvDeleteWebhook(boltStore{WEBHOOK_BUCKET}, id)
+}
+
+func (store boltStore) view(fn func(tx kvTx) error) error {
+	return boltDB.View(func(tx *bolt.Tx) error {
+		return fn(boltTx{bucket: tx.Bucket([]byte(store.bucket))})
+	})
+}
+
+func (store boltStore) update(fn func(tx kvTx) error) error {
+	return boltDB.Update(func(tx *bolt.Tx) error {
+		return fn(boltTx{bucket: tx.Bucket([]byte(store.bucket))})
+	})
+}
+
+func (tx boltTx) get(key string) []byte {
+	return tx.bucket.Get([]byte(key))
+}
+
+func (tx boltTx) put(key string, value []byte) error {
+	return tx.bucket.Put([]byte(key), value)
+}
+
+func (tx boltTx) remove(key string) error {
+	return tx.bucket.Delete([]byte(key))
+}
+
+func (tx boltTx) scan(prefix string, fn func(key string, value []byte) error) error {
+	cursor := tx.bucket.Cursor()
+	for key, value := cursor.Seek([]byte(prefix)); key != nil && bytes.HasPrefix(key, []byte(prefix)); key, value = cursor.Next() {
+		if err := fn(string(key), value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
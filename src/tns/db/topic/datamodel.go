@@ -0,0 +1,112 @@
+/*******************************************************************************
+ * Copyright 2018 Samsung Electronics All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ *******************************************************************************/
+
+package topic
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"regexp"
+	"tns/commons/errors"
+)
+
+// Supported formats of datamodel schema.
+const (
+	DATAMODEL_FORMAT_JSON_SCHEMA = "jsonschema" // JSON Schema document
+	DATAMODEL_FORMAT_PROTOBUF    = "protobuf"   // Base64 encoded FileDescriptorSet
+	DATAMODEL_ID_SEPARATOR       = "_"
+)
+
+var (
+	datamodelNameRegex    = regexp.MustCompile(`^[A-Za-z0-9][-A-Za-z0-9_.]*$`)
+	datamodelVersionRegex = regexp.MustCompile(`^[0-9A-Za-z][-0-9A-Za-z.+]*$`)
+)
+
+// Datamodel is a versioned definition of the data published on topics.
+// Topics refer to it by its ID, which is "<name>_<version>" (e.g., GTC_Robot_0.0.1).
+// A registered datamodel can not be changed, a new version should be registered instead.
+type Datamodel struct {
+	Id      string `bson:"_id" json:"id"`
+	Name    string `bson:"name" json:"name"`
+	Version string `bson:"version" json:"version"`
+	Format  string `bson:"format" json:"format"`
+	Schema  string `bson:"schema" json:"schema"`
+}
+
+func (datamodel Datamodel) convertToMap() map[string]interface{} {
+	return map[string]interface{}{
+		"id":      datamodel.Id,
+		"name":    datamodel.Name,
+		"version": datamodel.Version,
+		"format":  datamodel.Format,
+		"schema":  datamodel.Schema,
+	}
+}
+
+// convertToDatamodel validates the properties of a datamodel to be registered and
+// converts them into a Datamodel.
+func convertToDatamodel(properties map[string]interface{}) (Datamodel, error) {
+	name, exists := properties["name"].(string)
+	if !exists {
+		return Datamodel{}, errors.InvalidParam{"'name' field is required"}
+	}
+	if !datamodelNameRegex.MatchString(name) {
+		return Datamodel{}, errors.InvalidParam{"invalid name: " + name}
+	}
+
+	version, exists := properties["version"].(string)
+	if !exists {
+		return Datamodel{}, errors.InvalidParam{"'version' field is required"}
+	}
+	if !datamodelVersionRegex.MatchString(version) {
+		return Datamodel{}, errors.InvalidParam{"invalid version: " + version}
+	}
+
+	format, exists := properties["format"].(string)
+	if !exists {
+		return Datamodel{}, errors.InvalidParam{"'format' field is required"}
+	}
+
+	schema, exists := properties["schema"].(string)
+	if !exists {
+		return Datamodel{}, errors.InvalidParam{"'schema' field is required"}
+	}
+
+	switch format {
+	case DATAMODEL_FORMAT_JSON_SCHEMA:
+		document := make(map[string]interface{})
+		if err := json.Unmarshal([]byte(schema), &document); err != nil {
+			return Datamodel{}, errors.InvalidParam{"'schema' must be a JSON object: " + err.Error()}
+		}
+	case DATAMODEL_FORMAT_PROTOBUF:
+		if _, err := base64.StdEncoding.DecodeString(schema); err != nil || schema == "" {
+			return Datamodel{}, errors.InvalidParam{"'schema' must be a base64 encoded descriptor"}
+		}
+	default:
+		return Datamodel{}, errors.InvalidParam{"unsupported format: " + format}
+	}
+
+	datamodel := Datamodel{
+		Id:      name + DATAMODEL_ID_SEPARATOR + version,
+		Name:    name,
+		Version: version,
+		Format:  format,
+		Schema:  schema,
+	}
+
+	return datamodel, nil
+}
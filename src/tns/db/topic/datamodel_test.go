@@ -0,0 +1,58 @@
+/*******************************************************************************
+ * Copyright 2018 Samsung Electronics All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ *******************************************************************************/
+
+package topic
+
+import (
+	"reflect"
+	"testing"
+	"tns/commons/errors"
+)
+
+func TestConvertToDatamodel(t *testing.T) {
+	testCases := []struct {
+		name              string
+		dummyProperties   map[string]interface{}
+		expectedDatamodel Datamodel
+		expectedError     error
+	}{
+		{"Success_JsonSchema", map[string]interface{}{"name": "GTC_Robot", "version": "0.0.1", "format": "jsonschema", "schema": `{"type":"object"}`},
+			Datamodel{Id: "GTC_Robot_0.0.1", Name: "GTC_Robot", Version: "0.0.1", Format: "jsonschema", Schema: `{"type":"object"}`}, nil},
+		{"Success_Protobuf", map[string]interface{}{"name": "Robot", "version": "1.0.0-rc.1", "format": "protobuf", "schema": "CgR0ZXN0"},
+			Datamodel{Id: "Robot_1.0.0-rc.1", Name: "Robot", Version: "1.0.0-rc.1", Format: "protobuf", Schema: "CgR0ZXN0"}, nil},
+		{"InvalidParam_NoName", map[string]interface{}{"version": "0.0.1", "format": "jsonschema", "schema": `{}`}, Datamodel{}, errors.InvalidParam{}},
+		{"InvalidParam_Name", map[string]interface{}{"name": "GTC Robot", "version": "0.0.1", "format": "jsonschema", "schema": `{}`}, Datamodel{}, errors.InvalidParam{}},
+		{"InvalidParam_NoVersion", map[string]interface{}{"name": "Robot", "format": "jsonschema", "schema": `{}`}, Datamodel{}, errors.InvalidParam{}},
+		{"InvalidParam_Version", map[string]interface{}{"name": "Robot", "version": "_1", "format": "jsonschema", "schema": `{}`}, Datamodel{}, errors.InvalidParam{}},
+		{"InvalidParam_Format", map[string]interface{}{"name": "Robot", "version": "1", "format": "xml", "schema": `<a/>`}, Datamodel{}, errors.InvalidParam{}},
+		{"InvalidParam_NoSchema", map[string]interface{}{"name": "Robot", "version": "1", "format": "jsonschema"}, Datamodel{}, errors.InvalidParam{}},
+		{"InvalidParam_JsonSchema", map[string]interface{}{"name": "Robot", "version": "1", "format": "jsonschema", "schema": `[1]`}, Datamodel{}, errors.InvalidParam{}},
+		{"InvalidParam_Protobuf", map[string]interface{}{"name": "Robot", "version": "1", "format": "protobuf", "schema": "not base64"}, Datamodel{}, errors.InvalidParam{}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			datamodel, err := convertToDatamodel(tc.dummyProperties)
+			if reflect.TypeOf(err) != reflect.TypeOf(tc.expectedError) {
+				t.Errorf("Expected Error: %s, Actual: %s", tc.expectedError, err)
+			}
+			if !reflect.DeepEqual(datamodel, tc.expectedDatamodel) {
+				t.Errorf("Expected Datamodel: %v, Actual: %v", tc.expectedDatamodel, datamodel)
+			}
+		})
+	}
+}
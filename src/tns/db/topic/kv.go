@@ -0,0 +1,583 @@
+/*******************************************************************************
+ * Copyright 2018 Samsung Electronics All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ *******************************************************************************/
+
+package topic
+
+import (
+	"encoding/json"
+	"regexp"
+	"time"
+	"tns/commons/errors"
+	"tns/commons/logger"
+)
+
+// kvStore is an ordered key/value table of topics keyed by name.
+// It is implemented by the embedded storages (bolt, memory) so that
+// they share the same topic operations.
+type kvStore interface {
+	// view runs fn in a read-only transaction.
+	view(fn func(tx kvTx) error) error
+	// update runs fn in a read-write transaction.
+	// Changes are discarded if fn returns an error.
+	update(fn func(tx kvTx) error) error
+}
+
+type kvTx interface {
+	// get returns nil if the key does not exist.
+	get(key string) []byte
+	put(key string, value []byte) error
+	remove(key string) error
+	// scan calls fn for every key starting with prefix in key order.
+	scan(prefix string, fn func(key string, value []byte) error) error
+}
+
+func kvCreateTopic(store kvStore, properties map[string]interface{}) (bool, error) {
+	topic, err := convertToTopic(properties)
+	if err != nil {
+		return false, err
+	}
+
+	return kvRegisterTopic(store, topic)
+}
+
+// kvRegisterTopic registers the publisher of topic, which is converted by convertToTopic.
+func kvRegisterTopic(store kvStore, topic Topic) (bool, error) {
+	// Conflict check and insertion are done in a single transaction.
+	created := true
+	err := store.update(func(tx kvTx) error {
+		value := tx.get(topic.Name)
+		if value == nil {
+			return kvPutTopic(tx, topic)
+		}
+
+		// Join the topic as another publisher, or register again
+		current, err := kvDecodeTopic(value)
+		if err != nil {
+			return err
+		}
+		updated, joined, err := current.registerPublisher(topic)
+		if err != nil {
+			logger.Logging(logger.DEBUG, "Failed to register publisher: "+err.Error())
+			return err
+		}
+		created = joined
+		return kvPutTopic(tx, updated)
+	})
+	if err != nil {
+		if _, conflict := err.(errors.Conflict); conflict {
+			return false, err
+		}
+		logger.Logging(logger.ERROR, "Failed to Put: "+err.Error())
+		return false, errors.InternalServerError{"Database Insert Failed"}
+	}
+
+	return created, nil
+}
+
+func kvUpdateTopic(store kvStore, properties map[string]interface{}, partial bool) (map[string]interface{}, error) {
+	name, exists := properties["name"].(string)
+	if !exists {
+		return nil, errors.InvalidParam{"'name' field is required"}
+	}
+
+	topic, err := kvModifyTopic(store, name, func(current Topic) (Topic, error) {
+		return convertToUpdatedTopic(current, properties, partial)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return topic.convertToMap(), nil
+}
+
+func kvDeleteTopic(store kvStore, name string) error {
+	err := store.update(func(tx kvTx) error {
+		if tx.get(name) == nil {
+			logger.Logging(logger.DEBUG, "Not found: "+name)
+			return errors.NotFound{name}
+		}
+		return tx.remove(name)
+	})
+	if err != nil {
+		if _, notFound := err.(errors.NotFound); notFound {
+			return err
+		}
+		logger.Logging(logger.ERROR, "Failed to Remove: "+name)
+		return errors.InternalServerError{"Database Remove Failed"}
+	}
+
+	return nil
+}
+
+func kvDeletePublisher(store kvStore, name string, endpoint string) error {
+	_, err := kvModifyTopic(store, name, func(current Topic) (Topic, error) {
+		return current.removePublisher(endpoint)
+	})
+	return err
+}
+
+// kvDeletePublishers removes the publishers of all topics in a single transaction.
+func kvDeletePublishers(store kvStore, publishers map[string][]string) error {
+	err := store.update(func(tx kvTx) error {
+		for name, endpoints := range publishers {
+			value := tx.get(name)
+			if value == nil {
+				continue
+			}
+
+			current, err := kvDecodeTopic(value)
+			if err != nil {
+				return err
+			}
+			topic, removed := current.removePublishers(endpoints)
+			if !removed {
+				continue
+			}
+
+			if len(topic.Publishers) == 0 {
+				err = tx.remove(name)
+			} else {
+				err = kvPutTopic(tx, topic)
+			}
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		logger.Logging(logger.ERROR, "Failed to Update: "+err.Error())
+		return errors.InternalServerError{"Database Update Failed"}
+	}
+
+	return nil
+}
+
+func kvUpdateLastSeen(store kvStore, names []string, endpoint string, lastSeen time.Time) error {
+	err := store.update(func(tx kvTx) error {
+		for _, name := range names {
+			value := tx.get(name)
+			if value == nil {
+				continue
+			}
+
+			topic, err := kvDecodeTopic(value)
+			if err != nil {
+				return err
+			}
+			if !topic.touchPublishers(endpoint, lastSeen) {
+				continue
+			}
+			if err := kvPutTopic(tx, topic); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		logger.Logging(logger.ERROR, "Failed to Update: "+err.Error())
+		return errors.InternalServerError{"Database Update Failed"}
+	}
+
+	return nil
+}
+
+func kvReadLastSeenAll(store kvStore) (map[string]map[string]time.Time, error) {
+	lastSeen := make(map[string]map[string]time.Time)
+
+	err := store.view(func(tx kvTx) error {
+		return tx.scan("", func(key string, value []byte) error {
+			topic, err := kvDecodeTopic(value)
+			if err != nil {
+				return err
+			}
+			lastSeen[key] = make(map[string]time.Time, len(topic.Publishers))
+			for _, publisher := range topic.Publishers {
+				lastSeen[key][publisher.Endpoint] = publisher.LastSeen
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		logger.Logging(logger.ERROR, "Failed to Read: "+err.Error())
+		return nil, errors.InternalServerError{"Database Query Failed"}
+	}
+
+	return lastSeen, nil
+}
+
+func kvUpdateInterval(store kvStore, name string, interval uint) error {
+	err := store.update(func(tx kvTx) error {
+		value := tx.get(name)
+		if value == nil {
+			return errors.NotFound{name}
+		}
+
+		// The revision is not changed since it is not a change by users
+		topic, err := kvDecodeTopic(value)
+		if err != nil {
+			return err
+		}
+		topic.Interval = interval
+		return kvPutTopic(tx, topic)
+	})
+	if err != nil {
+		if _, notFound := err.(errors.NotFound); notFound {
+			return err
+		}
+		logger.Logging(logger.ERROR, "Failed to Update: "+err.Error())
+		return errors.InternalServerError{"Database Update Failed"}
+	}
+
+	return nil
+}
+
+func kvReadIntervalAll(store kvStore) (map[string]uint, error) {
+	intervals := make(map[string]uint)
+
+	err := store.view(func(tx kvTx) error {
+		return tx.scan("", func(key string, value []byte) error {
+			topic, err := kvDecodeTopic(value)
+			if err != nil {
+				return err
+			}
+			if topic.Interval != 0 {
+				intervals[key] = topic.Interval
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		logger.Logging(logger.ERROR, "Failed to Read: "+err.Error())
+		return nil, errors.InternalServerError{"Database Query Failed"}
+	}
+
+	return intervals, nil
+}
+
+// kvModifyTopic replaces the topic of the given name with the one returned by modify
+// in a single transaction, or removes it if the returned topic has no publisher.
+func kvModifyTopic(store kvStore, name string, modify func(current Topic) (Topic, error)) (Topic, error) {
+	topic := Topic{}
+	err := store.update(func(tx kvTx) error {
+		value := tx.get(name)
+		if value == nil {
+			logger.Logging(logger.DEBUG, "Not found: "+name)
+			return errors.NotFound{name}
+		}
+
+		current, err := kvDecodeTopic(value)
+		if err != nil {
+			return err
+		}
+
+		topic, err = modify(current)
+		if err != nil {
+			logger.Logging(logger.DEBUG, "Failed to modify topic: "+err.Error())
+			return err
+		}
+
+		if len(topic.Publishers) == 0 {
+			return tx.remove(name)
+		}
+		return kvPutTopic(tx, topic)
+	})
+	if err != nil {
+		switch err.(type) {
+		case errors.NotFound, errors.Conflict, errors.InvalidParam:
+			return Topic{}, err
+		}
+		logger.Logging(logger.ERROR, "Failed to Update: "+err.Error())
+		return Topic{}, errors.InternalServerError{"Database Update Failed"}
+	}
+
+	return topic, nil
+}
+
+func kvCreateDatamodel(store kvStore, properties map[string]interface{}) error {
+	datamodel, err := convertToDatamodel(properties)
+	if err != nil {
+		return err
+	}
+
+	err = store.update(func(tx kvTx) error {
+		if tx.get(datamodel.Id) != nil {
+			logger.Logging(logger.DEBUG, "Duplicated datamodel: "+datamodel.Id)
+			return errors.Conflict{datamodel.Id}
+		}
+
+		value, err := json.Marshal(datamodel)
+		if err != nil {
+			return err
+		}
+		return tx.put(datamodel.Id, value)
+	})
+	if err != nil {
+		if _, conflict := err.(errors.Conflict); conflict {
+			return err
+		}
+		logger.Logging(logger.ERROR, "Failed to Put: "+err.Error())
+		return errors.InternalServerError{"Database Insert Failed"}
+	}
+
+	return nil
+}
+
+func kvReadDatamodel(store kvStore, id string) (map[string]interface{}, error) {
+	datamodel := Datamodel{}
+	err := store.view(func(tx kvTx) error {
+		value := tx.get(id)
+		if value == nil {
+			logger.Logging(logger.DEBUG, "Not found: "+id)
+			return errors.NotFound{id}
+		}
+		return json.Unmarshal(value, &datamodel)
+	})
+	if err != nil {
+		if _, notFound := err.(errors.NotFound); notFound {
+			return nil, err
+		}
+		logger.Logging(logger.ERROR, "Failed to Read: "+err.Error())
+		return nil, errors.InternalServerError{"Database Query Failed"}
+	}
+
+	return datamodel.convertToMap(), nil
+}
+
+func kvReadDatamodelAll(store kvStore, name string) ([]map[string]interface{}, error) {
+	prefix := ""
+	if name != "" {
+		// IDs of all versions start with the name
+		prefix = name + DATAMODEL_ID_SEPARATOR
+	}
+
+	datamodels := []map[string]interface{}{}
+	err := store.view(func(tx kvTx) error {
+		return tx.scan(prefix, func(key string, value []byte) error {
+			datamodel := Datamodel{}
+			if err := json.Unmarshal(value, &datamodel); err != nil {
+				return err
+			}
+			// A name can be a prefix of another name (e.g., Robot and Robot_Arm)
+			if name != "" && datamodel.Name != name {
+				return nil
+			}
+			datamodels = append(datamodels, datamodel.convertToMap())
+			return nil
+		})
+	})
+	if err != nil {
+		logger.Logging(logger.ERROR, "Failed to Read: "+err.Error())
+		return nil, errors.InternalServerError{"Database Query Failed"}
+	}
+
+	return datamodels, nil
+}
+
+func kvDeleteDatamodel(store kvStore, id string) error {
+	err := store.update(func(tx kvTx) error {
+		if tx.get(id) == nil {
+			logger.Logging(logger.DEBUG, "Not found: "+id)
+			return errors.NotFound{id}
+		}
+		return tx.remove(id)
+	})
+	if err != nil {
+		if _, notFound := err.(errors.NotFound); notFound {
+			return err
+		}
+		logger.Logging(logger.ERROR, "Failed to Remove: "+id)
+		return errors.InternalServerError{"Database Remove Failed"}
+	}
+
+	return nil
+}
+
+func kvCreateWebhook(store kvStore, properties map[string]interface{}) error {
+	webhook, err := convertToWebhook(properties)
+	if err != nil {
+		return err
+	}
+
+	err = store.update(func(tx kvTx) error {
+		if tx.get(webhook.Id) != nil {
+			logger.Logging(logger.DEBUG, "Duplicated webhook: "+webhook.Id)
+			return errors.Conflict{webhook.Id}
+		}
+
+		value, err := json.Marshal(webhook)
+		if err != nil {
+			return err
+		}
+		return tx.put(webhook.Id, value)
+	})
+	if err != nil {
+		if _, conflict := err.(errors.Conflict); conflict {
+			return err
+		}
+		logger.Logging(logger.ERROR, "Failed to Put: "+err.Error())
+		return errors.InternalServerError{"Database Insert Failed"}
+	}
+
+	return nil
+}
+
+func kvReadWebhook(store kvStore, id string) (map[string]interface{}, error) {
+	webhook := Webhook{}
+	err := store.view(func(tx kvTx) error {
+		value := tx.get(id)
+		if value == nil {
+			logger.Logging(logger.DEBUG, "Not found: "+id)
+			return errors.NotFound{id}
+		}
+		return json.Unmarshal(value, &webhook)
+	})
+	if err != nil {
+		if _, notFound := err.(errors.NotFound); notFound {
+			return nil, err
+		}
+		logger.Logging(logger.ERROR, "Failed to Read: "+err.Error())
+		return nil, errors.InternalServerError{"Database Query Failed"}
+	}
+
+	return webhook.convertToMap(), nil
+}
+
+func kvReadWebhookAll(store kvStore) ([]map[string]interface{}, error) {
+	webhooks := []map[string]interface{}{}
+	err := store.view(func(tx kvTx) error {
+		return tx.scan("", func(key string, value []byte) error {
+			webhook := Webhook{}
+			if err := json.Unmarshal(value, &webhook); err != nil {
+				return err
+			}
+			webhooks = append(webhooks, webhook.convertToMap())
+			return nil
+		})
+	})
+	if err != nil {
+		logger.Logging(logger.ERROR, "Failed to Read: "+err.Error())
+		return nil, errors.InternalServerError{"Database Query Failed"}
+	}
+
+	return webhooks, nil
+}
+
+func kvDeleteWebhook(store kvStore, id string) error {
+	err := store.update(func(tx kvTx) error {
+		if tx.get(id) == nil {
+			logger.Logging(logger.DEBUG, "Not found: "+id)
+			return errors.NotFound{id}
+		}
+		return tx.remove(id)
+	})
+	if err != nil {
+		if _, notFound := err.(errors.NotFound); notFound {
+			return err
+		}
+		logger.Logging(logger.ERROR, "Failed to Remove: "+id)
+		return errors.InternalServerError{"Database Remove Failed"}
+	}
+
+	return nil
+}
+
+func kvDecodeTopic(value []byte) (Topic, error) {
+	topic := Topic{}
+	err := json.Unmarshal(value, &topic)
+	return topic, err
+}
+
+func kvPutTopic(tx kvTx, topic Topic) error {
+	value, err := json.Marshal(topic)
+	if err != nil {
+		return err
+	}
+	return tx.put(topic.Name, value)
+}
+
+func kvReadTopicAll(store kvStore, selector string) ([]map[string]interface{}, error) {
+	return kvReadTopicFromDB(store, selector, func(tx kvTx, fn func(key string, value []byte) error) error {
+		return tx.scan("", fn)
+	})
+}
+
+func kvReadTopic(store kvStore, name string, hierarchical bool, selector string) ([]map[string]interface{}, error) {
+	if isWildcard(name) {
+		pattern, err := convertWildcardToRegex(name, hierarchical)
+		if err != nil {
+			logger.Logging(logger.DEBUG, "convertWildcardToRegex failed: "+err.Error())
+			return nil, err
+		}
+
+		regex := regexp.MustCompile(pattern)
+		return kvReadTopicFromDB(store, selector, func(tx kvTx, fn func(key string, value []byte) error) error {
+			return tx.scan(literalPrefix(name), func(key string, value []byte) error {
+				if !regex.MatchString(key) {
+					return nil
+				}
+				return fn(key, value)
+			})
+		})
+	}
+
+	return kvReadTopicFromDB(store, selector, func(tx kvTx, fn func(key string, value []byte) error) error {
+		// One exactly matched
+		if value := tx.get(name); value != nil {
+			if err := fn(name, value); err != nil {
+				return err
+			}
+		}
+		if !hierarchical {
+			return nil
+		}
+		// All started with 'name/'
+		return tx.scan(name+LEVEL_SEPARATOR, fn)
+	})
+}
+
+// kvReadTopicFromDB decodes all the topics visited by iterate in a read-only transaction,
+// skipping the ones whose labels do not match selector.
+func kvReadTopicFromDB(store kvStore, selector string, iterate func(tx kvTx, fn func(key string, value []byte) error) error) ([]map[string]interface{}, error) {
+	sel, err := parseSelector(selector)
+	if err != nil {
+		logger.Logging(logger.DEBUG, "parseSelector failed: "+err.Error())
+		return nil, err
+	}
+
+	topics := []map[string]interface{}{}
+
+	err = store.view(func(tx kvTx) error {
+		return iterate(tx, func(key string, value []byte) error {
+			topic, err := kvDecodeTopic(value)
+			if err != nil {
+				return err
+			}
+			if !sel.matches(topic.Labels) {
+				return nil
+			}
+			topics = append(topics, topic.convertToMap())
+			return nil
+		})
+	})
+	if err != nil {
+		logger.Logging(logger.ERROR, "Failed to Read: "+err.Error())
+		return nil, errors.InternalServerError{"Database Query Failed"}
+	}
+
+	return topics, nil
+}
@@ -0,0 +1,602 @@
+/*******************************************************************************
+ * Copyright 2018 Samsung Electronics All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ *******************************************************************************/
+
+package topic
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+	"tns/commons/errors"
+)
+
+// kvStorages are the storages built on the kvStore interface.
+// Every test in this file is run against all of them.
+var kvStorages = []struct {
+	name    string
+	handler Command
+	config  func(dir string) Config
+}{
+	{"Bolt", BoltExecutor{}, func(dir string) Config { return Config{Type: BOLT_DB, Path: filepath.Join(dir, "test.db")} }},
+	{"Memory", MemoryExecutor{}, func(dir string) Config { return Config{Type: MEMORY_DB} }},
+	{"Raft", raftTestExecutor{}, func(dir string) Config {
+		return Config{Type: RAFT_DB, Cluster: newClusterConfigForTest(dir, 1)[0]}
+	}},
+}
+
+func openKvForTest(t *testing.T, handler Command, config func(dir string) Config, names ...string) func() {
+	dir, err := ioutil.TempDir("", "tns")
+	if err != nil {
+		t.Fatal("TempDir failed")
+	}
+
+	err = handler.Connect(config(dir))
+	if err != nil {
+		os.RemoveAll(dir)
+		t.Fatalf("Connect returned an error: %s", err.Error())
+	}
+
+	for _, name := range names {
+		properties := map[string]interface{}{"name": name, "endpoint": "0.0.0.0:1234", "datamodel": "test_0.0.1"}
+		if _, err := handler.CreateTopic(properties); err != nil {
+			t.Fatalf("CreateTopic returned an error: %s", err.Error())
+		}
+	}
+
+	return func() {
+		handler.Close()
+		os.RemoveAll(dir)
+	}
+}
+
+func TestCallBoltConnectWithInvalidPath(t *testing.T) {
+	err := BoltExecutor{}.Connect(Config{Type: BOLT_DB, Path: "/nonExistsDir/test.db"})
+	if reflect.TypeOf(err) != reflect.TypeOf(errors.InternalServerError{}) {
+		t.Errorf("Expected Error: %s, Actual: %s", errors.InternalServerError{}, err)
+	}
+}
+
+func TestCallKvCreateTopic(t *testing.T) {
+	for _, kv := range kvStorages {
+		closeKv := openKvForTest(t, kv.handler, kv.config, "/a")
+
+		testCases := []struct {
+			name            string
+			dummyProperties map[string]interface{}
+			expectedCreated bool
+			expectedError   error
+		}{
+			{"Success", map[string]interface{}{"name": "/b", "endpoint": "0.0.0.0:1234", "datamodel": "test_0.0.1", "secured": true}, true, nil},
+			{"InvalidParam_name", map[string]interface{}{"endpoint": "0.0.0.0:1234", "datamodel": "test_0.0.1"}, false, errors.InvalidParam{}},
+			{"InvalidParam_name_Wildcard", map[string]interface{}{"name": "/c/+", "endpoint": "0.0.0.0:1234", "datamodel": "test_0.0.1"}, false, errors.InvalidParam{}},
+			{"InvalidParam_name_MultiLevelWildcard", map[string]interface{}{"name": "/c/#", "endpoint": "0.0.0.0:1234", "datamodel": "test_0.0.1"}, false, errors.InvalidParam{}},
+			{"InvalidParam_name_Asterisk", map[string]interface{}{"name": "/c*", "endpoint": "0.0.0.0:1234", "datamodel": "test_0.0.1"}, false, errors.InvalidParam{}},
+			{"InvalidParam_endpoint", map[string]interface{}{"name": "/c", "datamodel": "test_0.0.1"}, false, errors.InvalidParam{}},
+			{"InvalidParam_datamodel", map[string]interface{}{"name": "/c", "endpoint": "0.0.0.0:1234"}, false, errors.InvalidParam{}},
+			{"Success_Join", map[string]interface{}{"name": "/a", "endpoint": "0.0.0.0:5678", "datamodel": "test_0.0.1"}, true, nil},
+			{"Success_Reregister", map[string]interface{}{"name": "/a", "endpoint": "0.0.0.0:1234", "datamodel": "test_0.0.1"}, false, nil},
+			{"Success_ReregisterUpdated", map[string]interface{}{"name": "/b", "endpoint": "0.0.0.0:1234", "datamodel": "test_0.0.2"}, false, nil},
+			{"ReregisterMismatch", map[string]interface{}{"name": "/a", "endpoint": "0.0.0.0:1234", "datamodel": "test_0.0.2"}, false, errors.Conflict{}},
+			{"DatamodelMismatch", map[string]interface{}{"name": "/a", "endpoint": "0.0.0.0:9999", "datamodel": "test_0.0.2"}, false, errors.Conflict{}},
+			{"SecuredMismatch", map[string]interface{}{"name": "/a", "endpoint": "0.0.0.0:9999", "datamodel": "test_0.0.1", "secured": true}, false, errors.Conflict{}},
+		}
+
+		for _, tc := range testCases {
+			t.Run(kv.name+"_"+tc.name, func(t *testing.T) {
+				created, err := kv.handler.CreateTopic(tc.dummyProperties)
+				if reflect.TypeOf(err) != reflect.TypeOf(tc.expectedError) {
+					t.Errorf("Expected Error: %s, Actual: %s", tc.expectedError, err)
+				}
+				if created != tc.expectedCreated {
+					t.Errorf("Expected Created: %t, Actual: %t", tc.expectedCreated, created)
+				}
+			})
+		}
+
+		expectedTopics := []map[string]interface{}{
+			{"name": "/a", "endpoint": "0.0.0.0:1234", "endpoints": []string{"0.0.0.0:1234", "0.0.0.0:5678"}, "datamodel": "test_0.0.1", "secured": false, "labels": map[string]string{}, "revision": int64(2)},
+			{"name": "/b", "endpoint": "0.0.0.0:1234", "endpoints": []string{"0.0.0.0:1234"}, "datamodel": "test_0.0.2", "secured": false, "labels": map[string]string{}, "revision": int64(2)},
+		}
+		topics, _ := kv.handler.ReadTopicAll("")
+		for _, topic := range topics {
+			// Publishers keep the time of their first registration
+			for endpoint, registeredAt := range topic["registered_at"].(map[string]time.Time) {
+				if registeredAt.IsZero() || registeredAt.After(time.Now()) {
+					t.Errorf("Unexpected registered_at of %s: %v", endpoint, registeredAt)
+				}
+			}
+			delete(topic, "registered_at")
+		}
+		if !reflect.DeepEqual(topics, expectedTopics) {
+			t.Errorf("Expected Topics: %v, Actual: %v", expectedTopics, topics)
+		}
+
+		closeKv()
+	}
+}
+
+func TestCallKvDeleteTopic(t *testing.T) {
+	for _, kv := range kvStorages {
+		closeKv := openKvForTest(t, kv.handler, kv.config, "/a")
+
+		testCases := []struct {
+			name          string
+			expectedError error
+		}{
+			{"Success", nil},
+			{"TopicNotFound", errors.NotFound{}},
+		}
+
+		for _, tc := range testCases {
+			t.Run(kv.name+"_"+tc.name, func(t *testing.T) {
+				err := kv.handler.DeleteTopic("/a")
+				if reflect.TypeOf(err) != reflect.TypeOf(tc.expectedError) {
+					t.Errorf("Expected Error: %s, Actual: %s", tc.expectedError, err)
+				}
+			})
+		}
+
+		closeKv()
+	}
+}
+
+func TestCallKvReadTopic(t *testing.T) {
+	for _, kv := range kvStorages {
+		closeKv := openKvForTest(t, kv.handler, kv.config, "/a", "/a/b", "/a/b/c", "/a-b", "/ab", "/x/b/c")
+
+		testCases := []struct {
+			name          string
+			topicName     string
+			hierarchical  bool
+			expectedNames []string
+			expectedError error
+		}{
+			{"Success", "/a", false, []string{"/a"}, nil},
+			{"Success_NotFound", "/a/c", false, []string{}, nil},
+			{"Success_Hierarchical", "/a", true, []string{"/a", "/a/b", "/a/b/c"}, nil},
+			{"Success_Wildcard", "/+/b/#", false, []string{"/a/b", "/a/b/c", "/x/b/c"}, nil},
+			{"Success_MultiLevelWildcard", "/a/#", false, []string{"/a", "/a/b", "/a/b/c"}, nil},
+			{"Success_HierarchicalAndWildcard", "/a*", true, []string{"/a", "/a-b", "/a/b", "/a/b/c", "/ab"}, nil},
+			{"InvalidQuery_Wildcard", "/a/b#", false, nil, errors.InvalidQuery{}},
+		}
+
+		for _, tc := range testCases {
+			t.Run(kv.name+"_"+tc.name, func(t *testing.T) {
+				topics, err := kv.handler.ReadTopic(tc.topicName, tc.hierarchical, "")
+				if reflect.TypeOf(err) != reflect.TypeOf(tc.expectedError) {
+					t.Errorf("Expected Error: %s, Actual: %s", tc.expectedError, err)
+				}
+				if err != nil {
+					return
+				}
+
+				names := []string{}
+				for _, topic := range topics {
+					names = append(names, topic["name"].(string))
+				}
+				if !reflect.DeepEqual(names, tc.expectedNames) {
+					t.Errorf("Expected Names: %v, Actual: %v", tc.expectedNames, names)
+				}
+			})
+		}
+
+		closeKv()
+	}
+}
+
+func TestCallKvReadTopicAll(t *testing.T) {
+	for _, kv := range kvStorages {
+		closeKv := openKvForTest(t, kv.handler, kv.config, "/b", "/a")
+
+		topics, err := kv.handler.ReadTopicAll("")
+		if err != nil {
+			t.Errorf("ReadTopicAll returned an error: %s", err.Error())
+		}
+		if len(topics) != 2 || topics[0]["name"] != "/a" || topics[1]["name"] != "/b" {
+			t.Errorf("Unexpected Topics: %v", topics)
+		}
+
+		closeKv()
+	}
+}
+
+func TestCallKvReadTopicWithSelector(t *testing.T) {
+	for _, kv := range kvStorages {
+		closeKv := openKvForTest(t, kv.handler, kv.config, "/a/none")
+
+		labels := map[string]map[string]interface{}{
+			"/a/1": {"site": "plant3", "line": "1"},
+			"/a/2": {"site": "plant3", "line": "2"},
+			"/a/3": {"site": "plant4", "line": "1", "deprecated": ""},
+		}
+		for name, label := range labels {
+			properties := map[string]interface{}{"name": name, "endpoint": "0.0.0.0:1234", "datamodel": "test_0.0.1", "labels": label}
+			if _, err := kv.handler.CreateTopic(properties); err != nil {
+				t.Fatalf("CreateTopic returned an error: %s", err.Error())
+			}
+		}
+
+		testCases := []struct {
+			name          string
+			selector      string
+			expectedNames []string
+			expectedError error
+		}{
+			{"Equals", "site=plant3", []string{"/a/1", "/a/2"}, nil},
+			{"NotEquals", "site!=plant3", []string{"/a/3", "/a/none"}, nil},
+			{"In", "line in (2, 3)", []string{"/a/2"}, nil},
+			{"NotIn", "site notin (plant3)", []string{"/a/3", "/a/none"}, nil},
+			{"Exists", "deprecated", []string{"/a/3"}, nil},
+			{"NotExists", "!site", []string{"/a/none"}, nil},
+			{"Multiple", "site in (plant3,plant4),line==1,!deprecated", []string{"/a/1"}, nil},
+			{"InvalidQuery", "site in (plant3", nil, errors.InvalidQuery{}},
+		}
+
+		for _, tc := range testCases {
+			t.Run(kv.name+"_"+tc.name, func(t *testing.T) {
+				topics, err := kv.handler.ReadTopic("/a", true, tc.selector)
+				if reflect.TypeOf(err) != reflect.TypeOf(tc.expectedError) {
+					t.Errorf("Expected Error: %s, Actual: %s", tc.expectedError, err)
+				}
+				if err != nil {
+					return
+				}
+
+				names := []string{}
+				for _, topic := range topics {
+					names = append(names, topic["name"].(string))
+				}
+				if !reflect.DeepEqual(names, tc.expectedNames) {
+					t.Errorf("Expected Names: %v, Actual: %v", tc.expectedNames, names)
+				}
+			})
+		}
+
+		topics, _ := kv.handler.ReadTopicAll("line=2")
+		if len(topics) != 1 || !reflect.DeepEqual(topics[0]["labels"], map[string]string{"site": "plant3", "line": "2"}) {
+			t.Errorf("Unexpected Topics: %v", topics)
+		}
+
+		closeKv()
+	}
+}
+
+func TestCallKvDeletePublisher(t *testing.T) {
+	for _, kv := range kvStorages {
+		closeKv := openKvForTest(t, kv.handler, kv.config, "/a")
+
+		properties := map[string]interface{}{"name": "/a", "endpoint": "0.0.0.0:5678", "datamodel": "test_0.0.1"}
+		if _, err := kv.handler.CreateTopic(properties); err != nil {
+			t.Fatalf("CreateTopic returned an error: %s", err.Error())
+		}
+
+		testCases := []struct {
+			name              string
+			endpoint          string
+			expectedEndpoints []string
+			expectedError     error
+		}{
+			{"Success", "0.0.0.0:1234", []string{"0.0.0.0:5678"}, nil},
+			{"PublisherNotFound", "0.0.0.0:1234", []string{"0.0.0.0:5678"}, errors.NotFound{}},
+			{"Success_LastPublisher", "0.0.0.0:5678", nil, nil},
+			{"TopicNotFound", "0.0.0.0:5678", nil, errors.NotFound{}},
+		}
+
+		for _, tc := range testCases {
+			t.Run(kv.name+"_"+tc.name, func(t *testing.T) {
+				err := kv.handler.DeletePublisher("/a", tc.endpoint)
+				if reflect.TypeOf(err) != reflect.TypeOf(tc.expectedError) {
+					t.Errorf("Expected Error: %s, Actual: %s", tc.expectedError, err)
+				}
+
+				topics, _ := kv.handler.ReadTopic("/a", false, "")
+				if tc.expectedEndpoints == nil {
+					if len(topics) != 0 {
+						t.Errorf("Unexpected Topics: %v", topics)
+					}
+				} else if len(topics) != 1 || !reflect.DeepEqual(topics[0]["endpoints"], tc.expectedEndpoints) {
+					t.Errorf("Unexpected Topics: %v", topics)
+				}
+			})
+		}
+
+		closeKv()
+	}
+}
+
+func TestCallKvDeletePublishers(t *testing.T) {
+	for _, kv := range kvStorages {
+		closeKv := openKvForTest(t, kv.handler, kv.config, "/a", "/b", "/c")
+
+		properties := map[string]interface{}{"name": "/a", "endpoint": "0.0.0.0:5678", "datamodel": "test_0.0.1"}
+		if _, err := kv.handler.CreateTopic(properties); err != nil {
+			t.Fatalf("CreateTopic returned an error: %s", err.Error())
+		}
+
+		// Unknown names and endpoints are ignored
+		err := kv.handler.DeletePublishers(map[string][]string{
+			"/a": {"0.0.0.0:1234"},
+			"/b": {"0.0.0.0:1234", "0.0.0.0:5678"},
+			"/c": {"0.0.0.0:9999"},
+			"/d": {"0.0.0.0:1234"},
+		})
+		if err != nil {
+			t.Errorf("DeletePublishers returned an error: %s", err.Error())
+		}
+
+		topics, _ := kv.handler.ReadTopicAll("")
+		endpoints := make(map[string]interface{})
+		for _, topic := range topics {
+			endpoints[topic["name"].(string)] = topic["endpoints"]
+		}
+		expectedEndpoints := map[string]interface{}{"/a": []string{"0.0.0.0:5678"}, "/c": []string{"0.0.0.0:1234"}}
+		if !reflect.DeepEqual(endpoints, expectedEndpoints) {
+			t.Errorf("Expected Endpoints: %v, Actual: %v", expectedEndpoints, endpoints)
+		}
+
+		closeKv()
+	}
+}
+
+func TestCallKvUpdateLastSeen(t *testing.T) {
+	for _, kv := range kvStorages {
+		closeKv := openKvForTest(t, kv.handler, kv.config, "/a", "/b")
+
+		properties := map[string]interface{}{"name": "/a", "endpoint": "0.0.0.0:5678", "datamodel": "test_0.0.1"}
+		if _, err := kv.handler.CreateTopic(properties); err != nil {
+			t.Fatalf("CreateTopic returned an error: %s", err.Error())
+		}
+
+		dummyLastSeen := time.Unix(1500000000, 0)
+		dummyLastSeenAll := time.Unix(1600000000, 0)
+
+		// Unknown names and endpoints are ignored
+		for _, endpoint := range []string{"0.0.0.0:1234", "0.0.0.0:9999"} {
+			err := kv.handler.UpdateLastSeen([]string{"/a", "/c"}, endpoint, dummyLastSeen)
+			if err != nil {
+				t.Errorf("UpdateLastSeen returned an error: %s", err.Error())
+			}
+		}
+		// All publishers of the topic
+		err := kv.handler.UpdateLastSeen([]string{"/b"}, "", dummyLastSeenAll)
+		if err != nil {
+			t.Errorf("UpdateLastSeen returned an error: %s", err.Error())
+		}
+
+		lastSeen, err := kv.handler.ReadLastSeenAll()
+		if err != nil {
+			t.Errorf("ReadLastSeenAll returned an error: %s", err.Error())
+		}
+
+		expectedLastSeen := map[string]map[string]time.Time{
+			"/a": {"0.0.0.0:1234": dummyLastSeen, "0.0.0.0:5678": time.Time{}},
+			"/b": {"0.0.0.0:1234": dummyLastSeenAll},
+		}
+		if len(lastSeen) != len(expectedLastSeen) {
+			t.Errorf("Expected LastSeen: %v, Actual: %v", expectedLastSeen, lastSeen)
+		}
+		for name, publishers := range expectedLastSeen {
+			if len(lastSeen[name]) != len(publishers) {
+				t.Errorf("%s: Expected LastSeen: %v, Actual: %v", kv.name, publishers, lastSeen[name])
+			}
+			for endpoint, expected := range publishers {
+				if !lastSeen[name][endpoint].Equal(expected) {
+					t.Errorf("%s: Expected LastSeen: %v, Actual: %v", kv.name, expected, lastSeen[name][endpoint])
+				}
+			}
+		}
+
+		closeKv()
+	}
+}
+
+func TestCallKvUpdateInterval(t *testing.T) {
+	for _, kv := range kvStorages {
+		closeKv := openKvForTest(t, kv.handler, kv.config, "/a", "/b")
+
+		if err := kv.handler.UpdateInterval("/a", 30); err != nil {
+			t.Errorf("%s: UpdateInterval returned an error: %s", kv.name, err.Error())
+		}
+		if err := kv.handler.UpdateInterval("/c", 30); reflect.TypeOf(err) != reflect.TypeOf(errors.NotFound{}) {
+			t.Errorf("%s: Expected Error: %s, Actual: %s", kv.name, errors.NotFound{}, err)
+		}
+
+		// The interval is kept on update by users
+		properties := map[string]interface{}{"name": "/a", "datamodel": "test_0.0.2"}
+		if _, err := kv.handler.UpdateTopic(properties, true); err != nil {
+			t.Errorf("%s: UpdateTopic returned an error: %s", kv.name, err.Error())
+		}
+
+		intervals, err := kv.handler.ReadIntervalAll()
+		if err != nil {
+			t.Errorf("%s: ReadIntervalAll returned an error: %s", kv.name, err.Error())
+		}
+		expectedIntervals := map[string]uint{"/a": 30}
+		if !reflect.DeepEqual(intervals, expectedIntervals) {
+			t.Errorf("%s: Expected Intervals: %v, Actual: %v", kv.name, expectedIntervals, intervals)
+		}
+
+		closeKv()
+	}
+}
+
+func TestCallKvUpdateTopic(t *testing.T) {
+	for _, kv := range kvStorages {
+		closeKv := openKvForTest(t, kv.handler, kv.config, "/a")
+
+		testCases := []struct {
+			name             string
+			dummyProperties  map[string]interface{}
+			partial          bool
+			expectedEndpoint string
+			expectedRevision int64
+			expectedError    error
+		}{
+			{"Success_Patch", map[string]interface{}{"name": "/a", "endpoint": "0.0.0.0:5678"}, true, "0.0.0.0:5678", 2, nil},
+			{"Success_PutWithRevision", map[string]interface{}{"name": "/a", "endpoint": "0.0.0.0:9999", "datamodel": "test_0.0.2", "revision": float64(2)}, false, "0.0.0.0:9999", 3, nil},
+			{"RevisionMismatch", map[string]interface{}{"name": "/a", "endpoint": "0.0.0.0:1111", "revision": float64(2)}, true, "0.0.0.0:9999", 3, errors.Conflict{}},
+			{"InvalidParam_Put", map[string]interface{}{"name": "/a", "endpoint": "0.0.0.0:1111"}, false, "0.0.0.0:9999", 3, errors.InvalidParam{}},
+			{"InvalidParam_name", map[string]interface{}{"endpoint": "0.0.0.0:1111"}, true, "0.0.0.0:9999", 3, errors.InvalidParam{}},
+			{"TopicNotFound", map[string]interface{}{"name": "/b", "endpoint": "0.0.0.0:1111"}, true, "0.0.0.0:9999", 3, errors.NotFound{}},
+		}
+
+		for _, tc := range testCases {
+			t.Run(kv.name+"_"+tc.name, func(t *testing.T) {
+				_, err := kv.handler.UpdateTopic(tc.dummyProperties, tc.partial)
+				if reflect.TypeOf(err) != reflect.TypeOf(tc.expectedError) {
+					t.Errorf("Expected Error: %s, Actual: %s", tc.expectedError, err)
+				}
+
+				topics, _ := kv.handler.ReadTopic("/a", false, "")
+				if len(topics) != 1 || topics[0]["endpoint"] != tc.expectedEndpoint || topics[0]["revision"] != tc.expectedRevision {
+					t.Errorf("Unexpected Topics: %v", topics)
+				}
+			})
+		}
+
+		// endpoint can not be changed for a topic with multiple publishers
+		properties := map[string]interface{}{"name": "/a", "endpoint": "0.0.0.0:1234", "datamodel": "test_0.0.2"}
+		if _, err := kv.handler.CreateTopic(properties); err != nil {
+			t.Fatalf("CreateTopic returned an error: %s", err.Error())
+		}
+		_, err := kv.handler.UpdateTopic(map[string]interface{}{"name": "/a", "endpoint": "0.0.0.0:1111"}, true)
+		if reflect.TypeOf(err) != reflect.TypeOf(errors.InvalidParam{}) {
+			t.Errorf("Expected Error: %s, Actual: %s", errors.InvalidParam{}, err)
+		}
+
+		closeKv()
+	}
+}
+
+func TestCallKvDatamodel(t *testing.T) {
+	for _, kv := range kvStorages {
+		closeKv := openKvForTest(t, kv.handler, kv.config)
+
+		datamodels := []map[string]interface{}{
+			{"name": "Robot", "version": "0.0.1", "format": "jsonschema", "schema": `{"type":"object"}`},
+			{"name": "Robot", "version": "0.0.2", "format": "jsonschema", "schema": `{"type":"object"}`},
+			{"name": "Robot_Arm", "version": "1.0", "format": "protobuf", "schema": "CgR0ZXN0"},
+		}
+		for _, datamodel := range datamodels {
+			if err := kv.handler.CreateDatamodel(datamodel); err != nil {
+				t.Fatalf("CreateDatamodel returned an error: %s", err.Error())
+			}
+		}
+
+		t.Run(kv.name+"_Conflict", func(t *testing.T) {
+			err := kv.handler.CreateDatamodel(datamodels[0])
+			if reflect.TypeOf(err) != reflect.TypeOf(errors.Conflict{}) {
+				t.Errorf("Expected Error: %s, Actual: %s", errors.Conflict{}, err)
+			}
+		})
+
+		t.Run(kv.name+"_Read", func(t *testing.T) {
+			datamodel, err := kv.handler.ReadDatamodel("Robot_0.0.2")
+			if err != nil || datamodel["name"] != "Robot" || datamodel["version"] != "0.0.2" {
+				t.Errorf("Unexpected Datamodel: %v, Error: %v", datamodel, err)
+			}
+		})
+
+		t.Run(kv.name+"_ReadByName", func(t *testing.T) {
+			all, _ := kv.handler.ReadDatamodelAll("")
+			byName, _ := kv.handler.ReadDatamodelAll("Robot")
+			if len(all) != 3 || len(byName) != 2 {
+				t.Errorf("Unexpected Datamodels: %v, %v", all, byName)
+			}
+		})
+
+		t.Run(kv.name+"_Delete", func(t *testing.T) {
+			if err := kv.handler.DeleteDatamodel("Robot_0.0.1"); err != nil {
+				t.Errorf("DeleteDatamodel returned an error: %s", err.Error())
+			}
+			_, err := kv.handler.ReadDatamodel("Robot_0.0.1")
+			if reflect.TypeOf(err) != reflect.TypeOf(errors.NotFound{}) {
+				t.Errorf("Expected Error: %s, Actual: %s", errors.NotFound{}, err)
+			}
+			err = kv.handler.DeleteDatamodel("Robot_0.0.1")
+			if reflect.TypeOf(err) != reflect.TypeOf(errors.NotFound{}) {
+				t.Errorf("Expected Error: %s, Actual: %s", errors.NotFound{}, err)
+			}
+		})
+
+		// Topics are not affected by datamodels
+		topics, _ := kv.handler.ReadTopicAll("")
+		if len(topics) != 0 {
+			t.Errorf("Unexpected Topics: %v", topics)
+		}
+
+		closeKv()
+	}
+}
+
+func TestCallKvWebhook(t *testing.T) {
+	for _, kv := range kvStorages {
+		closeKv := openKvForTest(t, kv.handler, kv.config)
+
+		webhooks := []map[string]interface{}{
+			{"id": "0001", "secret": "s3cr3t", "url": "http://10.0.0.1:8080/hook"},
+			{"id": "0002", "secret": "s3cr3t", "url": "http://10.0.0.2:8080/hook", "name": "/robot", "hierarchical": true, "events": []interface{}{"expired"}},
+		}
+		for _, webhook := range webhooks {
+			if err := kv.handler.CreateWebhook(webhook); err != nil {
+				t.Fatalf("CreateWebhook returned an error: %s", err.Error())
+			}
+		}
+
+		t.Run(kv.name+"_Conflict", func(t *testing.T) {
+			err := kv.handler.CreateWebhook(webhooks[0])
+			if reflect.TypeOf(err) != reflect.TypeOf(errors.Conflict{}) {
+				t.Errorf("Expected Error: %s, Actual: %s", errors.Conflict{}, err)
+			}
+		})
+
+		t.Run(kv.name+"_Read", func(t *testing.T) {
+			expected := map[string]interface{}{"id": "0002", "secret": "s3cr3t", "url": "http://10.0.0.2:8080/hook",
+				"name": "/robot", "hierarchical": true, "events": []string{"expired"}}
+			webhook, err := kv.handler.ReadWebhook("0002")
+			if err != nil || !reflect.DeepEqual(webhook, expected) {
+				t.Errorf("Unexpected Webhook: %v, Error: %v", webhook, err)
+			}
+		})
+
+		t.Run(kv.name+"_ReadAll", func(t *testing.T) {
+			all, err := kv.handler.ReadWebhookAll()
+			if err != nil || len(all) != 2 {
+				t.Errorf("Unexpected Webhooks: %v, Error: %v", all, err)
+			}
+		})
+
+		t.Run(kv.name+"_Delete", func(t *testing.T) {
+			if err := kv.handler.DeleteWebhook("0001"); err != nil {
+				t.Errorf("DeleteWebhook returned an error: %s", err.Error())
+			}
+			_, err := kv.handler.ReadWebhook("0001")
+			if reflect.TypeOf(err) != reflect.TypeOf(errors.NotFound{}) {
+				t.Errorf("Expected Error: %s, Actual: %s", errors.NotFound{}, err)
+			}
+			err = kv.handler.DeleteWebhook("0001")
+			if reflect.TypeOf(err) != reflect.TypeOf(errors.NotFound{}) {
+				t.Errorf("Expected Error: %s, Actual: %s", errors.NotFound{}, err)
+			}
+		})
+
+		closeKv()
+	}
+}
@@ -0,0 +1,246 @@
+/*******************************************************************************
+ * Copyright 2018 Samsung Electronics All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ *******************************************************************************/
+
+package topic
+
+import (
+	goerrors "errors"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"tns/commons/logger"
+)
+
+// MemoryExecutor implements the Command interface on top of an in-memory table.
+// Topics are lost when the process exits, so it is intended for development
+// and integration tests only.
+type MemoryExecutor struct{}
+
+// Tables of the in-memory DB.
+const (
+	TOPIC_TABLE     = "TOPIC"
+	DATAMODEL_TABLE = "DATAMODEL"
+	WEBHOOK_TABLE   = "WEBHOOK"
+)
+
+// memoryStore implements the kvStore interface with a table guarded by a RWMutex.
+type memoryStore struct {
+	table string
+}
+
+type memoryTx struct {
+	table   map[string][]byte
+	pending map[string][]byte // written values of the transaction, nil for removed keys
+}
+
+type memoryTables struct {
+	sync.RWMutex
+	tables map[string]map[string][]byte
+}
+
+var memoryDB memoryTables
+
+var errReadOnlyTx = goerrors.New("read-only transaction")
+
+func (m MemoryExecutor) Connect(config Config) error {
+	memoryDB.Lock()
+	memoryDB.tables = newTables()
+	memoryDB.Unlock()
+
+	logger.Logging(logger.DEBUG, "In-memory DB created")
+
+	return nil
+}
+
+func (m MemoryExecutor) Close() {
+	memoryDB.Lock()
+	memoryDB.tables = nil
+	memoryDB.Unlock()
+}
+
+func (m MemoryExecutor) CreateTopic(properties map[string]interface{}) (bool, error) {
+	return kvCreateTopic(memoryStore{TOPIC_TABLE}, properties)
+}
+
+func (m MemoryExecutor) UpdateTopic(properties map[string]interface{}, partial bool) (map[string]interface{}, error) {
+	return kvUpdateTopic(memoryStore{TOPIC_TABLE}, properties, partial)
+}
+
+func (m MemoryExecutor) DeleteTopic(name string) error {
+	return kvDeleteTopic(memoryStore{TOPIC_TABLE}, name)
+}
+
+func (m MemoryExecutor) DeletePublisher(name string, endpoint string) error {
+	return kvDeletePublisher(memoryStore{TOPIC_TABLE}, name, endpoint)
+}
+
+func (m MemoryExecutor) DeletePublishers(publishers map[string][]string) error {
+	return kvDeletePublishers(memoryStore{TOPIC_TABLE}, publishers)
+}
+
+func (m MemoryExecutor) UpdateLastSeen(names []string, endpoint string, lastSeen time.Time) error {
+	return kvUpdateLastSeen(memoryStore{TOPIC_TABLE}, names, endpoint, lastSeen)
+}
+
+func (m MemoryExecutor) ReadLastSeenAll() (map[string]map[string]time.Time, error) {
+	return kvReadLastSeenAll(memoryStore{TOPIC_TABLE})
+}
+
+func (m MemoryExecutor) UpdateInterval(name string, interval uint) error {
+	return kvUpdateInterval(memoryStore{TOPIC_TABLE}, name, interval)
+}
+
+func (m MemoryExecutor) ReadIntervalAll() (map[string]uint, error) {
+	return kvReadIntervalAll(memoryStore{TOPIC_TABLE})
+}
+
+func (m MemoryExecutor) ReadTopicAll(selector string) ([]map[string]interface{}, error) {
+	return kvReadTopicAll(memoryStore{TOPIC_TABLE}, selector)
+}
+
+func (m MemoryExecutor) ReadTopic(name string, hierarchical bool, selector string) ([]map[string]interface{}, error) {
+	logger.Logging(logger.DEBUG, "IN")
+	defer logger.Logging(logger.DEBUG, "OUT")
+
+	return kvReadTopic(memoryStore{TOPIC_TABLE}, name, hierarchical, selector)
+}
+
+func (m MemoryExecutor) CreateDatamodel(properties map[string]interface{}) error {
+	return kvCreateDatamodel(memoryStore{DATAMODEL_TABLE}, properties)
+}
+
+func (m MemoryExecutor) ReadDatamodel(id string) (map[string]interface{}, error) {
+	return kvReadDatamodel(memoryStore{DATAMODEL_TABLE}, id)
+}
+
+func (m MemoryExecutor) ReadDatamodelAll(name string) ([]map[string]interface{}, error) {
+	return kvReadDatamodelAll(memoryStore{DATAMODEL_TABLE}, name)
+}
+
+func (m MemoryExecutor) DeleteDatamodel(id string) error {
+	return kvDeleteDatamodel(memoryStore{DATAMODEL_TABLE}, id)
+}
+
+func (m MemoryExecutor) CreateWebhook(properties map[string]interface{}) error {
+	return kvCreateWebhook(memoryStore{WEBHOOK_TABLE}, properties)
+}
+
+func (m MemoryExecutor) ReadWebhook(id string) (map[string]interface{}, error) {
+	return kvReadWebhook(memoryStore{WEBHOOK_TABLE}, id)
+}
+
+func (m MemoryExecutor) ReadWebhookAll() ([]map[string]interface{}, error) {
+	return kvReadWebhookAll(memoryStore{WEBHOOK_TABLE})
+}
+
+func (m MemoryExecutor) DeleteWebhook(id string) error {
+	return kvDeleteWebhook(memoryStore{WEBHOOK_TABLE}, id)
+}
+
+// newTables returns the empty tables of the in-memory DB.
+func newTables() map[string]map[string][]byte {
+	return map[string]map[string][]byte{
+		TOPIC_TABLE:     make(map[string][]byte),
+		DATAMODEL_TABLE: make(map[string][]byte),
+		WEBHOOK_TABLE:   make(map[string][]byte),
+	}
+}
+
+func (store memoryStore) view(fn func(tx kvTx) error) error {
+	return memoryDB.view(store.table, fn)
+}
+
+func (store memoryStore) update(fn func(tx kvTx) error) error {
+	return memoryDB.update(store.table, fn)
+}
+
+// view runs fn in a read-only transaction on the table.
+func (db *memoryTables) view(table string, fn func(tx kvTx) error) error {
+	db.RLock()
+	defer db.RUnlock()
+
+	return fn(memoryTx{table: db.tables[table]})
+}
+
+// update runs fn in a read-write transaction on the table.
+func (db *memoryTables) update(table string, fn func(tx kvTx) error) error {
+	db.Lock()
+	defer db.Unlock()
+
+	rows := db.tables[table]
+	tx := memoryTx{table: rows, pending: make(map[string][]byte)}
+	if err := fn(tx); err != nil {
+		return err
+	}
+
+	// Commit
+	for key, value := range tx.pending {
+		if value == nil {
+			delete(rows, key)
+		} else {
+			rows[key] = value
+		}
+	}
+
+	return nil
+}
+
+func (tx memoryTx) get(key string) []byte {
+	if value, exists := tx.pending[key]; exists {
+		return value
+	}
+	return tx.table[key]
+}
+
+func (tx memoryTx) put(key string, value []byte) error {
+	if tx.pending == nil {
+		return errReadOnlyTx
+	}
+	tx.pending[key] = append([]byte{}, value...)
+	return nil
+}
+
+func (tx memoryTx) remove(key string) error {
+	if tx.pending == nil {
+		return errReadOnlyTx
+	}
+	tx.pending[key] = nil
+	return nil
+}
+
+func (tx memoryTx) scan(prefix string, fn func(key string, value []byte) error) error {
+	keys := []string{}
+	for key := range tx.table {
+		if _, exists := tx.pending[key]; !exists && strings.HasPrefix(key, prefix) {
+			keys = append(keys, key)
+		}
+	}
+	for key, value := range tx.pending {
+		if value != nil && strings.HasPrefix(key, prefix) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		if err := fn(key, tx.get(key)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
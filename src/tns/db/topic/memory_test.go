@@ -0,0 +1,87 @@
+/*******************************************************************************
+ * Copyright 2018 Samsung Electronics All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ *******************************************************************************/
+
+package topic
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+)
+
+func TestCallMemoryCreateTopicConcurrently(t *testing.T) {
+	memoryHandler := MemoryExecutor{}
+	memoryHandler.Connect(Config{Type: MEMORY_DB})
+	defer memoryHandler.Close()
+
+	const publishers = 50
+
+	var wg sync.WaitGroup
+	type result struct {
+		created bool
+		err     error
+	}
+	results := make(chan result, publishers)
+	for i := 0; i < publishers; i++ {
+		wg.Add(3)
+		// Same name for all publishers, they join the topic
+		go func(i int) {
+			defer wg.Done()
+			_, err := memoryHandler.CreateTopic(map[string]interface{}{"name": "/a", "endpoint": fmt.Sprintf("0.0.0.0:%d", i), "datamodel": "test_0.0.1"})
+			if err != nil {
+				t.Errorf("Unexpected Error: %s", err)
+			}
+		}(i)
+		// Same name and endpoint for all publishers, they register again
+		go func(i int) {
+			defer wg.Done()
+			created, err := memoryHandler.CreateTopic(map[string]interface{}{"name": "/c", "endpoint": "0.0.0.0:1234", "datamodel": "test_0.0.1"})
+			results <- result{created, err}
+		}(i)
+		// Different name for each publisher
+		go func(i int) {
+			defer wg.Done()
+			memoryHandler.CreateTopic(map[string]interface{}{"name": fmt.Sprintf("/b/%d", i), "endpoint": "0.0.0.0:1234", "datamodel": "test_0.0.1"})
+			memoryHandler.ReadTopic("/b", true, "")
+		}(i)
+	}
+	wg.Wait()
+	close(results)
+
+	created := 0
+	for result := range results {
+		if result.err != nil {
+			t.Errorf("Unexpected Error: %s", result.err)
+		}
+		if result.created {
+			created++
+		}
+	}
+	if created != 1 {
+		t.Errorf("Expected one creation of /c, Actual: %d", created)
+	}
+
+	topics, _ := memoryHandler.ReadTopic("/a", false, "")
+	if len(topics) != 1 || len(topics[0]["endpoints"].([]string)) != publishers {
+		t.Errorf("Expected Publishers of /a: %d, Actual: %v", publishers, topics)
+	}
+
+	topics, _ = memoryHandler.ReadTopic("/b", true, "")
+	if len(topics) != publishers {
+		t.Errorf("Expected Topics: %d, Actual: %d", publishers, len(topics))
+	}
+}
@@ -24,6 +24,8 @@ package mock_topic
 import (
 	gomock "github.com/golang/mock/gomock"
 	reflect "reflect"
+	time "time"
+	topic "tns/db/topic"
 )
 
 // MockCommand is a mock of Command interface
@@ -50,15 +52,15 @@ func (m *MockCommand) EXPECT() *MockCommandMockRecorder {
 }
 
 // Connect mocks base method
-func (m *MockCommand) Connect(name string) error {
-	ret := m.ctrl.Call(m, "Connect", name)
+func (m *MockCommand) Connect(config topic.Config) error {
+	ret := m.ctrl.Call(m, "Connect", config)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // Connect indicates an expected call of Connect
-func (mr *MockCommandMockRecorder) Connect(name interface{}) *gomock.Call {
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Connect", reflect.TypeOf((*MockCommand)(nil).Connect), name)
+func (mr *MockCommandMockRecorder) Connect(config interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Connect", reflect.TypeOf((*MockCommand)(nil).Connect), config)
 }
 
 // Close mocks base method
@@ -72,10 +74,11 @@ func (mr *MockCommandMockRecorder) Close() *gomock.Call {
 }
 
 // CreateTopic mocks base method
-func (m *MockCommand) CreateTopic(arg0 map[string]interface{}) error {
+func (m *MockCommand) CreateTopic(arg0 map[string]interface{}) (bool, error) {
 	ret := m.ctrl.Call(m, "CreateTopic", arg0)
-	ret0, _ := ret[0].(error)
-	return ret0
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
 }
 
 // CreateTopic indicates an expected call of CreateTopic
@@ -83,30 +86,43 @@ func (mr *MockCommandMockRecorder) CreateTopic(arg0 interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateTopic", reflect.TypeOf((*MockCommand)(nil).CreateTopic), arg0)
 }
 
+// UpdateTopic mocks base method
+func (m *MockCommand) UpdateTopic(properties map[string]interface{}, partial bool) (map[string]interface{}, error) {
+	ret := m.ctrl.Call(m, "UpdateTopic", properties, partial)
+	ret0, _ := ret[0].(map[string]interface{})
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateTopic indicates an expected call of UpdateTopic
+func (mr *MockCommandMockRecorder) UpdateTopic(properties, partial interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateTopic", reflect.TypeOf((*MockCommand)(nil).UpdateTopic), properties, partial)
+}
+
 // ReadTopicAll mocks base method
-func (m *MockCommand) ReadTopicAll() ([]map[string]interface{}, error) {
-	ret := m.ctrl.Call(m, "ReadTopicAll")
+func (m *MockCommand) ReadTopicAll(selector string) ([]map[string]interface{}, error) {
+	ret := m.ctrl.Call(m, "ReadTopicAll", selector)
 	ret0, _ := ret[0].([]map[string]interface{})
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // ReadTopicAll indicates an expected call of ReadTopicAll
-func (mr *MockCommandMockRecorder) ReadTopicAll() *gomock.Call {
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadTopicAll", reflect.TypeOf((*MockCommand)(nil).ReadTopicAll))
+func (mr *MockCommandMockRecorder) ReadTopicAll(selector interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadTopicAll", reflect.TypeOf((*MockCommand)(nil).ReadTopicAll), selector)
 }
 
 // ReadTopic mocks base method
-func (m *MockCommand) ReadTopic(name string, hierarchical bool) ([]map[string]interface{}, error) {
-	ret := m.ctrl.Call(m, "ReadTopic", name, hierarchical)
+func (m *MockCommand) ReadTopic(name string, hierarchical bool, selector string) ([]map[string]interface{}, error) {
+	ret := m.ctrl.Call(m, "ReadTopic", name, hierarchical, selector)
 	ret0, _ := ret[0].([]map[string]interface{})
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // ReadTopic indicates an expected call of ReadTopic
-func (mr *MockCommandMockRecorder) ReadTopic(name, hierarchical interface{}) *gomock.Call {
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadTopic", reflect.TypeOf((*MockCommand)(nil).ReadTopic), name, hierarchical)
+func (mr *MockCommandMockRecorder) ReadTopic(name, hierarchical, selector interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadTopic", reflect.TypeOf((*MockCommand)(nil).ReadTopic), name, hierarchical, selector)
 }
 
 // DeleteTopic mocks base method
@@ -120,3 +136,248 @@ func (m *MockCommand) DeleteTopic(name string) error {
 func (mr *MockCommandMockRecorder) DeleteTopic(name interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteTopic", reflect.TypeOf((*MockCommand)(nil).DeleteTopic), name)
 }
+
+// DeletePublisher mocks base method
+func (m *MockCommand) DeletePublisher(name, endpoint string) error {
+	ret := m.ctrl.Call(m, "DeletePublisher", name, endpoint)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeletePublisher indicates an expected call of DeletePublisher
+func (mr *MockCommandMockRecorder) DeletePublisher(name, endpoint interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeletePublisher", reflect.TypeOf((*MockCommand)(nil).DeletePublisher), name, endpoint)
+}
+
+// DeletePublishers mocks base method
+func (m *MockCommand) DeletePublishers(publishers map[string][]string) error {
+	ret := m.ctrl.Call(m, "DeletePublishers", publishers)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeletePublishers indicates an expected call of DeletePublishers
+func (mr *MockCommandMockRecorder) DeletePublishers(publishers interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeletePublishers", reflect.TypeOf((*MockCommand)(nil).DeletePublishers), publishers)
+}
+
+// UpdateLastSeen mocks base method
+func (m *MockCommand) UpdateLastSeen(names []string, endpoint string, lastSeen time.Time) error {
+	ret := m.ctrl.Call(m, "UpdateLastSeen", names, endpoint, lastSeen)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateLastSeen indicates an expected call of UpdateLastSeen
+func (mr *MockCommandMockRecorder) UpdateLastSeen(names, endpoint, lastSeen interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateLastSeen", reflect.TypeOf((*MockCommand)(nil).UpdateLastSeen), names, endpoint, lastSeen)
+}
+
+// ReadLastSeenAll mocks base method
+func (m *MockCommand) ReadLastSeenAll() (map[string]map[string]time.Time, error) {
+	ret := m.ctrl.Call(m, "ReadLastSeenAll")
+	ret0, _ := ret[0].(map[string]map[string]time.Time)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReadLastSeenAll indicates an expected call of ReadLastSeenAll
+func (mr *MockCommandMockRecorder) ReadLastSeenAll() *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadLastSeenAll", reflect.TypeOf((*MockCommand)(nil).ReadLastSeenAll))
+}
+
+// UpdateInterval mocks base method
+func (m *MockCommand) UpdateInterval(name string, interval uint) error {
+	ret := m.ctrl.Call(m, "UpdateInterval", name, interval)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateInterval indicates an expected call of UpdateInterval
+func (mr *MockCommandMockRecorder) UpdateInterval(name, interval interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateInterval", reflect.TypeOf((*MockCommand)(nil).UpdateInterval), name, interval)
+}
+
+// ReadIntervalAll mocks base method
+func (m *MockCommand) ReadIntervalAll() (map[string]uint, error) {
+	ret := m.ctrl.Call(m, "ReadIntervalAll")
+	ret0, _ := ret[0].(map[string]uint)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReadIntervalAll indicates an expected call of ReadIntervalAll
+func (mr *MockCommandMockRecorder) ReadIntervalAll() *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadIntervalAll", reflect.TypeOf((*MockCommand)(nil).ReadIntervalAll))
+}
+
+// CreateDatamodel mocks base method
+func (m *MockCommand) CreateDatamodel(properties map[string]interface{}) error {
+	ret := m.ctrl.Call(m, "CreateDatamodel", properties)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateDatamodel indicates an expected call of CreateDatamodel
+func (mr *MockCommandMockRecorder) CreateDatamodel(properties interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateDatamodel", reflect.TypeOf((*MockCommand)(nil).CreateDatamodel), properties)
+}
+
+// ReadDatamodel mocks base method
+func (m *MockCommand) ReadDatamodel(id string) (map[string]interface{}, error) {
+	ret := m.ctrl.Call(m, "ReadDatamodel", id)
+	ret0, _ := ret[0].(map[string]interface{})
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReadDatamodel indicates an expected call of ReadDatamodel
+func (mr *MockCommandMockRecorder) ReadDatamodel(id interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadDatamodel", reflect.TypeOf((*MockCommand)(nil).ReadDatamodel), id)
+}
+
+// ReadDatamodelAll mocks base method
+func (m *MockCommand) ReadDatamodelAll(name string) ([]map[string]interface{}, error) {
+	ret := m.ctrl.Call(m, "ReadDatamodelAll", name)
+	ret0, _ := ret[0].([]map[string]interface{})
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReadDatamodelAll indicates an expected call of ReadDatamodelAll
+func (mr *MockCommandMockRecorder) ReadDatamodelAll(name interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadDatamodelAll", reflect.TypeOf((*MockCommand)(nil).ReadDatamodelAll), name)
+}
+
+// DeleteDatamodel mocks base method
+func (m *MockCommand) DeleteDatamodel(id string) error {
+	ret := m.ctrl.Call(m, "DeleteDatamodel", id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteDatamodel indicates an expected call of DeleteDatamodel
+func (mr *MockCommandMockRecorder) DeleteDatamodel(id interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteDatamodel", reflect.TypeOf((*MockCommand)(nil).DeleteDatamodel), id)
+}
+
+// CreateWebhook mocks base method
+func (m *MockCommand) CreateWebhook(properties map[string]interface{}) error {
+	ret := m.ctrl.Call(m, "CreateWebhook", properties)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateWebhook indicates an expected call of CreateWebhook
+func (mr *MockCommandMockRecorder) CreateWebhook(properties interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateWebhook", reflect.TypeOf((*MockCommand)(nil).CreateWebhook), properties)
+}
+
+// ReadWebhook mocks base method
+func (m *MockCommand) ReadWebhook(id string) (map[string]interface{}, error) {
+	ret := m.ctrl.Call(m, "ReadWebhook", id)
+	ret0, _ := ret[0].(map[string]interface{})
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReadWebhook indicates an expected call of ReadWebhook
+func (mr *MockCommandMockRecorder) ReadWebhook(id interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadWebhook", reflect.TypeOf((*MockCommand)(nil).ReadWebhook), id)
+}
+
+// ReadWebhookAll mocks base method
+func (m *MockCommand) ReadWebhookAll() ([]map[string]interface{}, error) {
+	ret := m.ctrl.Call(m, "ReadWebhookAll")
+	ret0, _ := ret[0].([]map[string]interface{})
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReadWebhookAll indicates an expected call of ReadWebhookAll
+func (mr *MockCommandMockRecorder) ReadWebhookAll() *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadWebhookAll", reflect.TypeOf((*MockCommand)(nil).ReadWebhookAll))
+}
+
+// DeleteWebhook mocks base method
+func (m *MockCommand) DeleteWebhook(id string) error {
+	ret := m.ctrl.Call(m, "DeleteWebhook", id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteWebhook indicates an expected call of DeleteWebhook
+func (mr *MockCommandMockRecorder) DeleteWebhook(id interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteWebhook", reflect.TypeOf((*MockCommand)(nil).DeleteWebhook), id)
+}
+
+// MockCluster is a mock of Cluster interface
+type MockCluster struct {
+	ctrl     *gomock.Controller
+	recorder *MockClusterMockRecorder
+}
+
+// MockClusterMockRecorder is the mock recorder for MockCluster
+type MockClusterMockRecorder struct {
+	mock *MockCluster
+}
+
+// NewMockCluster creates a new mock instance
+func NewMockCluster(ctrl *gomock.Controller) *MockCluster {
+	mock := &MockCluster{ctrl: ctrl}
+	mock.recorder = &MockClusterMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockCluster) EXPECT() *MockClusterMockRecorder {
+	return m.recorder
+}
+
+// IsLeader mocks base method
+func (m *MockCluster) IsLeader() bool {
+	ret := m.ctrl.Call(m, "IsLeader")
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// IsLeader indicates an expected call of IsLeader
+func (mr *MockClusterMockRecorder) IsLeader() *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsLeader", reflect.TypeOf((*MockCluster)(nil).IsLeader))
+}
+
+// Leader mocks base method
+func (m *MockCluster) Leader() (topic.ClusterNode, bool) {
+	ret := m.ctrl.Call(m, "Leader")
+	ret0, _ := ret[0].(topic.ClusterNode)
+	ret1, _ := ret[1].(bool)
+	return ret0, ret1
+}
+
+// Leader indicates an expected call of Leader
+func (mr *MockClusterMockRecorder) Leader() *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Leader", reflect.TypeOf((*MockCluster)(nil).Leader))
+}
+
+// ReadCluster mocks base method
+func (m *MockCluster) ReadCluster() (map[string]interface{}, error) {
+	ret := m.ctrl.Call(m, "ReadCluster")
+	ret0, _ := ret[0].(map[string]interface{})
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReadCluster indicates an expected call of ReadCluster
+func (mr *MockClusterMockRecorder) ReadCluster() *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadCluster", reflect.TypeOf((*MockCluster)(nil).ReadCluster))
+}
+
+// WatchLeadership mocks base method
+func (m *MockCluster) WatchLeadership(fn func(bool)) {
+	m.ctrl.Call(m, "WatchLeadership", fn)
+}
+
+// WatchLeadership indicates an expected call of WatchLeadership
+func (mr *MockClusterMockRecorder) WatchLeadership(fn interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WatchLeadership", reflect.TypeOf((*MockCluster)(nil).WatchLeadership), fn)
+}
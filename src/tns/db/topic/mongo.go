@@ -0,0 +1,678 @@
+/*******************************************************************************
+ * Copyright 2018 Samsung Electronics All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ *******************************************************************************/
+
+package topic
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"io/ioutil"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"tns/commons/errors"
+	"tns/commons/logger"
+	mgo "tns/db/wrapper"
+
+	"gopkg.in/mgo.v2/bson"
+)
+
+const (
+	DB_URL                  = "127.0.0.1:27017"
+	TOPIC_COLLECTION        = "TOPIC"
+	DATAMODEL_COLLECTION    = "DATAMODEL"
+	WEBHOOK_COLLECTION      = "WEBHOOK"
+	DEFAULT_CONNECT_TIMEOUT = 10 // Second
+	MAX_MODIFY_RETRY        = 3
+)
+
+// MongoExecutor implements the Command interface on top of MongoDB.
+type MongoExecutor struct{}
+
+var (
+	mgoDial            mgo.Connection
+	mgoSession         mgo.Session
+	mgoTopicCollection mgo.Collection
+
+	// Datamodels are keyed by their IDs (_id), so they are unique without an extra index.
+	mgoDatamodelCollection mgo.Collection
+	mgoWebhookCollection   mgo.Collection
+)
+
+func init() {
+	mgoDial = mgo.MongoDial{}
+}
+
+func (m MongoExecutor) Connect(config Config) error {
+	dialInfo, err := convertToDialInfo(config)
+	if err != nil {
+		logger.Logging(logger.ERROR, err.Error())
+		return err
+	}
+
+	session, err := mgoDial.Dial(dialInfo)
+	if err != nil {
+		logger.Logging(logger.ERROR, err.Error())
+		return err
+	}
+
+	mgoSession = session
+	database := mgoSession.DB(config.Name)
+	mgoTopicCollection = database.C(TOPIC_COLLECTION)
+	mgoDatamodelCollection = database.C(DATAMODEL_COLLECTION)
+	mgoWebhookCollection = database.C(WEBHOOK_COLLECTION)
+
+	logger.Logging(logger.DEBUG, "DB connected: "+hideCredentials(dialInfo.Url))
+
+	if err := m.ensureUniqueName(); err != nil {
+		logger.Logging(logger.ERROR, "ensureUniqueName failed")
+		return err
+	}
+
+	if err := m.migrateTopics(); err != nil {
+		logger.Logging(logger.ERROR, "migrateTopics failed")
+		return err
+	}
+
+	return nil
+}
+
+func (m MongoExecutor) Close() {
+	mgoSession.Close()
+}
+
+func (m MongoExecutor) CreateTopic(properties map[string]interface{}) (bool, error) {
+	topic, err := convertToTopic(properties)
+	if err != nil {
+		return false, err
+	}
+
+	for retry := 0; retry < MAX_MODIFY_RETRY; retry++ {
+		// Duplicates are rejected by the unique index on name
+		err = mgoTopicCollection.Insert(topic)
+		if err == nil {
+			return true, nil
+		}
+		if !mgo.IsDup(err) {
+			logger.Logging(logger.ERROR, "Failed to Insert on mongoDb: "+err.Error())
+			return false, errors.InternalServerError{"Database Insert Failed"}
+		}
+
+		// Join the topic as another publisher, or register again
+		created := false
+		_, err = m.modifyTopic(topic.Name, func(current Topic) (Topic, error) {
+			updated, joined, err := current.registerPublisher(topic)
+			created = joined
+			return updated, err
+		})
+		if err == nil {
+			return created, nil
+		}
+		if _, notFound := err.(errors.NotFound); !notFound {
+			logger.Logging(logger.DEBUG, "Failed to register publisher: "+err.Error())
+			return false, err
+		}
+
+		// Removed in the meantime
+	}
+
+	return false, errors.Conflict{"topic modified concurrently: " + topic.Name}
+}
+
+func (m MongoExecutor) UpdateTopic(properties map[string]interface{}, partial bool) (map[string]interface{}, error) {
+	name, exists := properties["name"].(string)
+	if !exists {
+		return nil, errors.InvalidParam{"'name' field is required"}
+	}
+
+	topic, err := m.modifyTopic(name, func(current Topic) (Topic, error) {
+		return convertToUpdatedTopic(current, properties, partial)
+	})
+	if err != nil {
+		logger.Logging(logger.DEBUG, "Failed to update topic: "+err.Error())
+		return nil, err
+	}
+
+	return topic.convertToMap(), nil
+}
+
+func (m MongoExecutor) DeleteTopic(name string) error {
+	query := bson.M{"name": name}
+	err := mgoTopicCollection.Remove(query)
+	if err != nil {
+		if err == mgo.ErrNotFound {
+			logger.Logging(logger.DEBUG, "Not found on mongoDb: "+name)
+			return errors.NotFound{name}
+		}
+		logger.Logging(logger.ERROR, "Failed to Remove on mongoDb: "+name)
+		return errors.InternalServerError{"Database Remove Failed"}
+	}
+
+	return nil
+}
+
+func (m MongoExecutor) DeletePublisher(name string, endpoint string) error {
+	_, err := m.modifyTopic(name, func(current Topic) (Topic, error) {
+		return current.removePublisher(endpoint)
+	})
+	if err != nil {
+		logger.Logging(logger.DEBUG, "Failed to remove publisher: "+err.Error())
+		return err
+	}
+
+	return nil
+}
+
+// DeletePublishers removes the publishers topic by topic, since a write to
+// multiple documents is not atomic in mongoDB.
+func (m MongoExecutor) DeletePublishers(publishers map[string][]string) error {
+	for name, endpoints := range publishers {
+		_, err := m.modifyTopic(name, func(current Topic) (Topic, error) {
+			topic, removed := current.removePublishers(endpoints)
+			if !removed {
+				return Topic{}, errors.NotFound{name}
+			}
+			return topic, nil
+		})
+		if err != nil {
+			if _, notFound := err.(errors.NotFound); notFound {
+				continue
+			}
+			logger.Logging(logger.DEBUG, "Failed to remove publishers: "+err.Error())
+			return err
+		}
+	}
+
+	return nil
+}
+
+// UpdateLastSeen updates the keep-alive time of the publisher at endpoint,
+// or of all the publishers if endpoint is empty, with a single UpdateAll.
+// The all positional operator "$[]" requires mongoDB 3.6 or later.
+func (m MongoExecutor) UpdateLastSeen(names []string, endpoint string, lastSeen time.Time) error {
+	// The revision is not changed since it is not a change by users
+	query := bson.M{"name": bson.M{"$in": names}}
+	update := bson.M{"$set": bson.M{"publishers.$[].last_seen": lastSeen}}
+	if endpoint != "" {
+		query["publishers.endpoint"] = endpoint
+		update = bson.M{"$set": bson.M{"publishers.$.last_seen": lastSeen}}
+	}
+
+	err := mgoTopicCollection.UpdateAll(query, update)
+	if err != nil {
+		logger.Logging(logger.ERROR, "Failed to UpdateAll on mongoDb: "+err.Error())
+		return errors.InternalServerError{"Database Update Failed"}
+	}
+
+	return nil
+}
+
+func (m MongoExecutor) ReadLastSeenAll() (map[string]map[string]time.Time, error) {
+	topics := []Topic{}
+	err := mgoTopicCollection.Find(nil).All(&topics)
+	if err != nil {
+		logger.Logging(logger.ERROR, "Failed to Find All on mongoDB: "+err.Error())
+		return nil, errors.InternalServerError{"Database Query Failed"}
+	}
+
+	lastSeen := make(map[string]map[string]time.Time, len(topics))
+	for _, topic := range topics {
+		lastSeen[topic.Name] = make(map[string]time.Time, len(topic.Publishers))
+		for _, publisher := range topic.Publishers {
+			lastSeen[topic.Name][publisher.Endpoint] = publisher.LastSeen
+		}
+	}
+
+	return lastSeen, nil
+}
+
+func (m MongoExecutor) UpdateInterval(name string, interval uint) error {
+	// The revision is not changed since it is not a change by users
+	err := mgoTopicCollection.Update(bson.M{"name": name}, bson.M{"$set": bson.M{"interval": interval}})
+	if err != nil {
+		if err == mgo.ErrNotFound {
+			logger.Logging(logger.DEBUG, "Not found on mongoDb: "+name)
+			return errors.NotFound{name}
+		}
+		logger.Logging(logger.ERROR, "Failed to Update on mongoDb: "+err.Error())
+		return errors.InternalServerError{"Database Update Failed"}
+	}
+
+	return nil
+}
+
+func (m MongoExecutor) ReadIntervalAll() (map[string]uint, error) {
+	topics := []Topic{}
+	err := mgoTopicCollection.Find(bson.M{"interval": bson.M{"$gt": 0}}).All(&topics)
+	if err != nil {
+		logger.Logging(logger.ERROR, "Failed to Find All on mongoDB: "+err.Error())
+		return nil, errors.InternalServerError{"Database Query Failed"}
+	}
+
+	intervals := make(map[string]uint, len(topics))
+	for _, topic := range topics {
+		intervals[topic.Name] = topic.Interval
+	}
+
+	return intervals, nil
+}
+
+func (m MongoExecutor) ReadTopicAll(selector string) ([]map[string]interface{}, error) {
+	topics, err := m.readTopicFromDB(nil, selector)
+	if err != nil {
+		logger.Logging(logger.ERROR, "readTopicFromDB failed")
+		return nil, err
+	}
+
+	return topics, nil
+}
+
+func (m MongoExecutor) ReadTopic(name string, hierarchical bool, selector string) ([]map[string]interface{}, error) {
+	logger.Logging(logger.DEBUG, "IN")
+	defer logger.Logging(logger.DEBUG, "OUT")
+
+	if isWildcard(name) {
+		return m.readTopicWildcard(name, hierarchical, selector)
+	}
+
+	query := bson.M{}
+	if hierarchical {
+		// add escapes characters before special characters for regular expression
+		len := len(name)
+		for i := 0; i < len; i++ {
+			matched, _ := regexp.MatchString("[^a-zA-Z0-9]", string(name[i]))
+			if matched {
+				name = name[:i] + "\\" + name[i:]
+				len++
+				i++
+			}
+		}
+
+		pattern := "^" + name + "$|^" + name + "/" // All started with 'name'
+		query = bson.M{"name": bson.RegEx{Pattern: pattern}}
+	} else {
+		query = bson.M{"name": name} // One exactly matched
+	}
+
+	topics, err := m.readTopicFromDB(query, selector)
+	if err != nil {
+		logger.Logging(logger.ERROR, "readTopicFromDB failed")
+		return nil, err
+	}
+
+	return topics, nil
+}
+
+func (m MongoExecutor) CreateDatamodel(properties map[string]interface{}) error {
+	datamodel, err := convertToDatamodel(properties)
+	if err != nil {
+		return err
+	}
+
+	err = mgoDatamodelCollection.Insert(datamodel)
+	if err != nil {
+		if mgo.IsDup(err) {
+			logger.Logging(logger.DEBUG, "Duplicated datamodel: "+datamodel.Id)
+			return errors.Conflict{datamodel.Id}
+		}
+		logger.Logging(logger.ERROR, "Failed to Insert on mongoDb: "+err.Error())
+		return errors.InternalServerError{"Database Insert Failed"}
+	}
+
+	return nil
+}
+
+func (m MongoExecutor) ReadDatamodel(id string) (map[string]interface{}, error) {
+	datamodel := Datamodel{}
+	err := mgoDatamodelCollection.Find(bson.M{"_id": id}).One(&datamodel)
+	if err != nil {
+		if err == mgo.ErrNotFound {
+			logger.Logging(logger.DEBUG, "Not found on mongoDb: "+id)
+			return nil, errors.NotFound{id}
+		}
+		logger.Logging(logger.ERROR, "Failed to Find One on mongoDb: "+err.Error())
+		return nil, errors.InternalServerError{"Database Query Failed"}
+	}
+
+	return datamodel.convertToMap(), nil
+}
+
+func (m MongoExecutor) ReadDatamodelAll(name string) ([]map[string]interface{}, error) {
+	var query bson.M
+	if name != "" {
+		query = bson.M{"name": name}
+	}
+
+	datamodels := []Datamodel{}
+	err := mgoDatamodelCollection.Find(query).All(&datamodels)
+	if err != nil {
+		logger.Logging(logger.ERROR, "Failed to Find All on mongoDB: "+err.Error())
+		return nil, errors.InternalServerError{"Database Query Failed"}
+	}
+
+	datamodelsInterface := make([]map[string]interface{}, len(datamodels))
+	for i, datamodel := range datamodels {
+		datamodelsInterface[i] = datamodel.convertToMap()
+	}
+
+	return datamodelsInterface, nil
+}
+
+func (m MongoExecutor) DeleteDatamodel(id string) error {
+	err := mgoDatamodelCollection.Remove(bson.M{"_id": id})
+	if err != nil {
+		if err == mgo.ErrNotFound {
+			logger.Logging(logger.DEBUG, "Not found on mongoDb: "+id)
+			return errors.NotFound{id}
+		}
+		logger.Logging(logger.ERROR, "Failed to Remove on mongoDb: "+err.Error())
+		return errors.InternalServerError{"Database Remove Failed"}
+	}
+
+	return nil
+}
+
+func (m MongoExecutor) CreateWebhook(properties map[string]interface{}) error {
+	webhook, err := convertToWebhook(properties)
+	if err != nil {
+		return err
+	}
+
+	err = mgoWebhookCollection.Insert(webhook)
+	if err != nil {
+		if mgo.IsDup(err) {
+			logger.Logging(logger.DEBUG, "Duplicated webhook: "+webhook.Id)
+			return errors.Conflict{webhook.Id}
+		}
+		logger.Logging(logger.ERROR, "Failed to Insert on mongoDb: "+err.Error())
+		return errors.InternalServerError{"Database Insert Failed"}
+	}
+
+	return nil
+}
+
+func (m MongoExecutor) ReadWebhook(id string) (map[string]interface{}, error) {
+	webhook := Webhook{}
+	err := mgoWebhookCollection.Find(bson.M{"_id": id}).One(&webhook)
+	if err != nil {
+		if err == mgo.ErrNotFound {
+			logger.Logging(logger.DEBUG, "Not found on mongoDb: "+id)
+			return nil, errors.NotFound{id}
+		}
+		logger.Logging(logger.ERROR, "Failed to Find One on mongoDb: "+err.Error())
+		return nil, errors.InternalServerError{"Database Query Failed"}
+	}
+
+	return webhook.convertToMap(), nil
+}
+
+func (m MongoExecutor) ReadWebhookAll() ([]map[string]interface{}, error) {
+	webhooks := []Webhook{}
+	err := mgoWebhookCollection.Find(nil).All(&webhooks)
+	if err != nil {
+		logger.Logging(logger.ERROR, "Failed to Find All on mongoDB: "+err.Error())
+		return nil, errors.InternalServerError{"Database Query Failed"}
+	}
+
+	webhooksInterface := make([]map[string]interface{}, len(webhooks))
+	for i, webhook := range webhooks {
+		webhooksInterface[i] = webhook.convertToMap()
+	}
+
+	return webhooksInterface, nil
+}
+
+func (m MongoExecutor) DeleteWebhook(id string) error {
+	err := mgoWebhookCollection.Remove(bson.M{"_id": id})
+	if err != nil {
+		if err == mgo.ErrNotFound {
+			logger.Logging(logger.DEBUG, "Not found on mongoDb: "+id)
+			return errors.NotFound{id}
+		}
+		logger.Logging(logger.ERROR, "Failed to Remove on mongoDb: "+err.Error())
+		return errors.InternalServerError{"Database Remove Failed"}
+	}
+
+	return nil
+}
+
+// readTopicFromDB finds the topics matched by query whose labels match selector.
+func (m MongoExecutor) readTopicFromDB(query bson.M, selector string) ([]map[string]interface{}, error) {
+	sel, err := parseSelector(selector)
+	if err != nil {
+		logger.Logging(logger.DEBUG, "parseSelector failed: "+err.Error())
+		return nil, err
+	}
+	if labelQueries := sel.convertToBson(); labelQueries != nil {
+		if query != nil {
+			labelQueries = append([]bson.M{query}, labelQueries...)
+		}
+		query = bson.M{"$and": labelQueries}
+	}
+
+	topics := []Topic{}
+	err = mgoTopicCollection.Find(query).All(&topics)
+	if err != nil {
+		logger.Logging(logger.ERROR, "Failed to Find All on mongoDB: "+err.Error())
+		return nil, errors.InternalServerError{"Database Query Failed"}
+	}
+
+	topicsInterface := make([]map[string]interface{}, len(topics))
+	for i, topic := range topics {
+		topicsInterface[i] = topic.convertToMap()
+	}
+
+	return topicsInterface, nil
+}
+
+func (m MongoExecutor) readTopicWildcard(name string, hierarchical bool, selector string) ([]map[string]interface{}, error) {
+	logger.Logging(logger.DEBUG, "IN")
+	defer logger.Logging(logger.DEBUG, "OUT")
+
+	pattern, err := convertWildcardToRegex(name, hierarchical)
+	if err != nil {
+		logger.Logging(logger.DEBUG, "convertWildcardToRegex failed: "+err.Error())
+		return nil, err
+	}
+
+	query := bson.M{"name": bson.RegEx{Pattern: pattern}}
+	topics, err := m.readTopicFromDB(query, selector)
+	if err != nil {
+		logger.Logging(logger.ERROR, "readTopicFromDB failed")
+		return nil, err
+	}
+
+	return topics, nil
+}
+
+// modifyTopic replaces the topic of the given name with the one returned by modify,
+// or removes it if the returned topic has no publisher.
+// The topic is written only if it has not been changed since it was read,
+// and it is read again up to MAX_MODIFY_RETRY times if it has been changed.
+func (m MongoExecutor) modifyTopic(name string, modify func(current Topic) (Topic, error)) (Topic, error) {
+	for retry := 0; retry < MAX_MODIFY_RETRY; retry++ {
+		current := Topic{}
+		err := mgoTopicCollection.Find(bson.M{"name": name}).One(&current)
+		if err != nil {
+			if err == mgo.ErrNotFound {
+				logger.Logging(logger.DEBUG, "Not found on mongoDb: "+name)
+				return Topic{}, errors.NotFound{name}
+			}
+			logger.Logging(logger.ERROR, "Failed to Find One on mongoDb: "+err.Error())
+			return Topic{}, errors.InternalServerError{"Database Query Failed"}
+		}
+
+		topic, err := modify(current)
+		if err != nil {
+			return Topic{}, err
+		}
+
+		query := bson.M{"name": name, "revision": current.Revision}
+		if current.Revision == 0 {
+			// Topics registered by former versions do not have a revision
+			query["revision"] = bson.M{"$in": []interface{}{0, nil}}
+		}
+
+		if len(topic.Publishers) == 0 {
+			err = mgoTopicCollection.Remove(query)
+		} else {
+			err = mgoTopicCollection.Update(query, topic)
+		}
+		if err == nil {
+			return topic, nil
+		}
+		if err != mgo.ErrNotFound {
+			logger.Logging(logger.ERROR, "Failed to Update on mongoDb: "+err.Error())
+			return Topic{}, errors.InternalServerError{"Database Update Failed"}
+		}
+
+		logger.Logging(logger.DEBUG, "Topic modified concurrently: "+name)
+	}
+
+	return Topic{}, errors.Conflict{"topic modified concurrently: " + name}
+}
+
+// migrateTopics converts the topics stored by former versions, which have
+// a single endpoint, into the topics with a list of publishers.
+func (m MongoExecutor) migrateTopics() error {
+	legacies := []legacyTopic{}
+	err := mgoTopicCollection.Find(bson.M{"publishers": bson.M{"$exists": false}}).All(&legacies)
+	if err != nil {
+		logger.Logging(logger.ERROR, "Failed to Find All on mongoDB: "+err.Error())
+		return errors.InternalServerError{"Database Query Failed"}
+	}
+
+	for _, legacy := range legacies {
+		logger.Logging(logger.DEBUG, "Migrate topic: "+legacy.Name)
+		query := bson.M{"name": legacy.Name}
+		update := bson.M{
+			"$set":   bson.M{"publishers": legacy.convertToPublishers()},
+			"$unset": bson.M{"endpoint": "", "last_seen": ""},
+		}
+		if err := mgoTopicCollection.Update(query, update); err != nil {
+			logger.Logging(logger.ERROR, "Failed to Update on mongoDB: "+err.Error())
+			return errors.InternalServerError{"Database Update Failed"}
+		}
+	}
+
+	return nil
+}
+
+// ensureUniqueName ensures the unique index on name of topic collection.
+// If there are topics registered with the same name before the index, they are reported
+// with an error, since the index cannot be built until they are removed.
+func (m MongoExecutor) ensureUniqueName() error {
+	pipeline := []bson.M{
+		{"$group": bson.M{"_id": "$name", "count": bson.M{"$sum": 1}}},
+		{"$match": bson.M{"count": bson.M{"$gt": 1}}},
+	}
+
+	duplicates := []struct {
+		Name  string `bson:"_id"`
+		Count int    `bson:"count"`
+	}{}
+	if err := mgoTopicCollection.Pipe(pipeline).All(&duplicates); err != nil {
+		logger.Logging(logger.ERROR, "Failed to Pipe on mongoDB: "+err.Error())
+		return errors.InternalServerError{"Database Query Failed"}
+	}
+
+	if len(duplicates) != 0 {
+		names := make([]string, len(duplicates))
+		for i, duplicate := range duplicates {
+			logger.Logging(logger.ERROR, "Duplicated topic: "+duplicate.Name+" ("+strconv.Itoa(duplicate.Count)+" documents)")
+			names[i] = duplicate.Name
+		}
+		return errors.Conflict{"duplicated topics must be removed: " + strings.Join(names, ", ")}
+	}
+
+	index := mgo.Index{Key: []string{"name"}, Unique: true}
+	if err := mgoTopicCollection.EnsureIndex(index); err != nil {
+		logger.Logging(logger.ERROR, "Failed to EnsureIndex on mongoDB: "+err.Error())
+		return errors.InternalServerError{"Database Index Failed"}
+	}
+
+	return nil
+}
+
+// convertToDialInfo converts the database settings into the options for dialing MongoDB.
+func convertToDialInfo(config Config) (mgo.DialInfo, error) {
+	dialInfo := mgo.DialInfo{
+		Url:            config.Url,
+		Username:       config.Username,
+		Password:       config.Password,
+		Source:         config.AuthSource,
+		ReplicaSetName: config.ReplicaSet,
+		Timeout:        time.Duration(config.ConnectTimeout) * time.Second,
+		SocketTimeout:  time.Duration(config.SocketTimeout) * time.Second,
+		SyncTimeout:    time.Duration(config.OperationTimeout) * time.Second,
+	}
+
+	if dialInfo.Url == "" {
+		dialInfo.Url = DB_URL
+	}
+	if dialInfo.Timeout == 0 {
+		dialInfo.Timeout = DEFAULT_CONNECT_TIMEOUT * time.Second
+	}
+
+	if dialInfo.Password == "" && config.PasswordFile != "" {
+		password, err := ioutil.ReadFile(config.PasswordFile)
+		if err != nil {
+			return mgo.DialInfo{}, errors.InvalidParam{"cannot read password file: " + err.Error()}
+		}
+		dialInfo.Password = strings.TrimSpace(string(password))
+	}
+
+	if config.Tls || config.TlsCAFile != "" || config.TlsCertFile != "" {
+		tlsConfig := &tls.Config{}
+
+		if config.TlsCAFile != "" {
+			pem, err := ioutil.ReadFile(config.TlsCAFile)
+			if err != nil {
+				return mgo.DialInfo{}, errors.InvalidParam{"cannot read CA file: " + err.Error()}
+			}
+			tlsConfig.RootCAs = x509.NewCertPool()
+			if !tlsConfig.RootCAs.AppendCertsFromPEM(pem) {
+				return mgo.DialInfo{}, errors.InvalidParam{"no certificate in CA file: " + config.TlsCAFile}
+			}
+		}
+
+		if config.TlsCertFile != "" {
+			cert, err := tls.LoadX509KeyPair(config.TlsCertFile, config.TlsKeyFile)
+			if err != nil {
+				return mgo.DialInfo{}, errors.InvalidParam{"cannot load client certificate: " + err.Error()}
+			}
+			tlsConfig.Certificates = []tls.Certificate{cert}
+		}
+
+		dialInfo.TLSConfig = tlsConfig
+	}
+
+	return dialInfo, nil
+}
+
+// hideCredentials masks the user information of a connection URI for logging.
+func hideCredentials(url string) string {
+	if i := strings.LastIndex(url, "@"); i >= 0 {
+		scheme := ""
+		if j := strings.Index(url, "://"); j >= 0 && j < i {
+			scheme = url[:j+3]
+		}
+		return scheme + "****" + url[i:]
+	}
+	return url
+}
@@ -0,0 +1,643 @@
+/*******************************************************************************
+ * Copyright 2018 Samsung Electronics All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ *******************************************************************************/
+package topic
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+	"tns/commons/errors"
+	"tns/commons/logger"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/raft"
+	raftboltdb "github.com/hashicorp/raft-boltdb/v2"
+)
+
+// RaftExecutor implements the Command and Cluster interfaces on top of in-memory
+// tables replicated among the nodes of the cluster through Raft.
+// Writes are appended to the Raft log by the leader and applied to the tables of
+// all nodes in the same order, while reads are served by the tables of this node.
+// Writes on followers fail with Unavailable, so requests should be passed to the
+// leader. The log and snapshots are kept in DataDir, and the tables are restored
+// from them on a restart.
+type RaftExecutor struct{}
+
+const (
+	RAFT_LOG_FILE        = "raft.db"
+	RAFT_APPLY_TIMEOUT   = 10 * time.Second
+	RAFT_MAX_POOL        = 3 // Connections kept to each node
+	RAFT_SNAPSHOT_RETAIN = 2
+)
+
+// Heartbeat and election timeout, shortened in tests.
+var raftTimeout = time.Second
+
+// Operations of raftCommand.
+const (
+	opCreateTopic      = "create_topic"
+	opUpdateTopic      = "update_topic"
+	opDeleteTopic      = "delete_topic"
+	opDeletePublisher  = "delete_publisher"
+	opDeletePublishers = "delete_publishers"
+	opUpdateLastSeen   = "update_last_seen"
+	opUpdateInterval   = "update_interval"
+	opCreateDatamodel  = "create_datamodel"
+	opDeleteDatamodel  = "delete_datamodel"
+	opCreateWebhook    = "create_webhook"
+	opDeleteWebhook    = "delete_webhook"
+)
+
+// raftCommand is a write in the Raft log.
+// Time is given by the leader so that all nodes apply the same values.
+type raftCommand struct {
+	Op         string                 `json:"op"`
+	Properties map[string]interface{} `json:"properties,omitempty"`
+	Partial    bool                   `json:"partial,omitempty"`
+	Name       string                 `json:"name,omitempty"` // Name of a topic, or ID of a datamodel or webhook
+	Names      []string               `json:"names,omitempty"`
+	Endpoint   string                 `json:"endpoint,omitempty"`
+	Publishers map[string][]string    `json:"publishers,omitempty"`
+	Interval   uint                   `json:"interval,omitempty"`
+	Time       time.Time              `json:"time"` // Time of registration or keep-alive
+}
+
+// raftResult is the result of applying a raftCommand.
+type raftResult struct {
+	value interface{}
+	err   error
+}
+
+// raftFSM applies the Raft log to the tables of a node.
+type raftFSM struct {
+	db memoryTables
+}
+
+// raftStore implements the kvStore interface with a table of raftFSM.
+// It should be updated only by raftFSM.Apply.
+type raftStore struct {
+	fsm   *raftFSM
+	table string
+}
+
+// raftSnapshot is the tables of a node at a point of the Raft log.
+type raftSnapshot struct {
+	tables map[string]map[string][]byte
+}
+
+// raftNode is a member of the cluster.
+type raftNode struct {
+	config    ClusterConfig
+	raft      *raft.Raft
+	fsm       *raftFSM
+	transport *raft.NetworkTransport
+	logStore  *raftboltdb.BoltStore
+	stop      chan struct{}
+
+	sync.Mutex // Guards leader and observers
+	leader     bool
+	observers  []func(leader bool)
+}
+
+var raftDB *raftNode
+
+func (RaftExecutor) Connect(config Config) error {
+	node, err := openRaftNode(config.Cluster)
+	if err != nil {
+		return err
+	}
+	raftDB = node
+
+	return nil
+}
+
+func (RaftExecutor) Close() {
+	if raftDB != nil {
+		raftDB.close()
+		raftDB = nil
+	}
+}
+
+func (RaftExecutor) CreateTopic(properties map[string]interface{}) (bool, error) {
+	return raftDB.CreateTopic(properties)
+}
+
+func (RaftExecutor) UpdateTopic(properties map[string]interface{}, partial bool) (map[string]interface{}, error) {
+	return raftDB.UpdateTopic(properties, partial)
+}
+
+func (RaftExecutor) ReadTopicAll(selector string) ([]map[string]interface{}, error) {
+	return raftDB.ReadTopicAll(selector)
+}
+
+func (RaftExecutor) ReadTopic(name string, hierarchical bool, selector string) ([]map[string]interface{}, error) {
+	return raftDB.ReadTopic(name, hierarchical, selector)
+}
+
+func (RaftExecutor) DeleteTopic(name string) error {
+	return raftDB.DeleteTopic(name)
+}
+
+func (RaftExecutor) DeletePublisher(name string, endpoint string) error {
+	return raftDB.DeletePublisher(name, endpoint)
+}
+
+func (RaftExecutor) DeletePublishers(publishers map[string][]string) error {
+	return raftDB.DeletePublishers(publishers)
+}
+
+func (RaftExecutor) UpdateLastSeen(names []string, endpoint string, lastSeen time.Time) error {
+	return raftDB.UpdateLastSeen(names, endpoint, lastSeen)
+}
+
+func (RaftExecutor) ReadLastSeenAll() (map[string]map[string]time.Time, error) {
+	return raftDB.ReadLastSeenAll()
+}
+
+func (RaftExecutor) UpdateInterval(name string, interval uint) error {
+	return raftDB.UpdateInterval(name, interval)
+}
+
+func (RaftExecutor) ReadIntervalAll() (map[string]uint, error) {
+	return raftDB.ReadIntervalAll()
+}
+
+func (RaftExecutor) CreateDatamodel(properties map[string]interface{}) error {
+	return raftDB.CreateDatamodel(properties)
+}
+
+func (RaftExecutor) ReadDatamodel(id string) (map[string]interface{}, error) {
+	return raftDB.ReadDatamodel(id)
+}
+
+func (RaftExecutor) ReadDatamodelAll(name string) ([]map[string]interface{}, error) {
+	return raftDB.ReadDatamodelAll(name)
+}
+
+func (RaftExecutor) DeleteDatamodel(id string) error {
+	return raftDB.DeleteDatamodel(id)
+}
+
+func (RaftExecutor) CreateWebhook(properties map[string]interface{}) error {
+	return raftDB.CreateWebhook(properties)
+}
+
+func (RaftExecutor) ReadWebhook(id string) (map[string]interface{}, error) {
+	return raftDB.ReadWebhook(id)
+}
+
+func (RaftExecutor) ReadWebhookAll() ([]map[string]interface{}, error) {
+	return raftDB.ReadWebhookAll()
+}
+
+func (RaftExecutor) DeleteWebhook(id string) error {
+	return raftDB.DeleteWebhook(id)
+}
+
+func (RaftExecutor) IsLeader() bool {
+	return raftDB.IsLeader()
+}
+
+func (RaftExecutor) Leader() (ClusterNode, bool) {
+	return raftDB.Leader()
+}
+
+func (RaftExecutor) ReadCluster() (map[string]interface{}, error) {
+	return raftDB.ReadCluster()
+}
+
+func (RaftExecutor) WatchLeadership(fn func(leader bool)) {
+	raftDB.WatchLeadership(fn)
+}
+
+// openRaftNode starts the node of config.NodeId, and bootstraps the cluster
+// with config.Nodes if DataDir has no state.
+func openRaftNode(config ClusterConfig) (*raftNode, error) {
+	var servers []raft.Server
+	var self *ClusterNode
+	for i, member := range config.Nodes {
+		if member.Id == "" || member.Address == "" {
+			return nil, errors.InvalidParam{"'id' and 'address' of nodes are required"}
+		}
+		if member.Id == config.NodeId {
+			self = &config.Nodes[i]
+		}
+		servers = append(servers, raft.Server{ID: raft.ServerID(member.Id), Address: raft.ServerAddress(member.Address)})
+	}
+	if self == nil {
+		return nil, errors.InvalidParam{"nodeId is not one of nodes: " + config.NodeId}
+	}
+	if config.DataDir == "" {
+		return nil, errors.InvalidParam{"dataDir is required"}
+	}
+
+	if err := os.MkdirAll(config.DataDir, 0700); err != nil {
+		logger.Logging(logger.ERROR, "MkdirAll failed: "+err.Error())
+		return nil, errors.InternalServerError{"Failed to create dataDir"}
+	}
+
+	raftLogger := hclog.New(&hclog.LoggerOptions{Name: "raft", Level: hclog.Warn})
+
+	raftConfig := raft.DefaultConfig()
+	raftConfig.LocalID = raft.ServerID(config.NodeId)
+	raftConfig.HeartbeatTimeout = raftTimeout
+	raftConfig.ElectionTimeout = raftTimeout
+	raftConfig.LeaderLeaseTimeout = raftTimeout / 2
+	raftConfig.Logger = raftLogger
+
+	logStore, err := raftboltdb.NewBoltStore(filepath.Join(config.DataDir, RAFT_LOG_FILE))
+	if err != nil {
+		logger.Logging(logger.ERROR, "NewBoltStore failed: "+err.Error())
+		return nil, errors.InternalServerError{"Failed to open Raft log"}
+	}
+
+	snapshots, err := raft.NewFileSnapshotStoreWithLogger(config.DataDir, RAFT_SNAPSHOT_RETAIN, raftLogger)
+	if err != nil {
+		logger.Logging(logger.ERROR, "NewFileSnapshotStore failed: "+err.Error())
+		logStore.Close()
+		return nil, errors.InternalServerError{"Failed to open Raft snapshots"}
+	}
+
+	transport, err := raft.NewTCPTransportWithLogger(self.Address, nil, RAFT_MAX_POOL, RAFT_APPLY_TIMEOUT, raftLogger)
+	if err != nil {
+		logger.Logging(logger.ERROR, "NewTCPTransport failed: "+err.Error())
+		logStore.Close()
+		return nil, errors.InternalServerError{"Failed to listen on " + self.Address}
+	}
+
+	existing, err := raft.HasExistingState(logStore, logStore, snapshots)
+	if err != nil {
+		logger.Logging(logger.ERROR, "HasExistingState failed: "+err.Error())
+		transport.Close()
+		logStore.Close()
+		return nil, errors.InternalServerError{"Failed to read Raft log"}
+	}
+
+	node := &raftNode{
+		config:    config,
+		fsm:       &raftFSM{db: memoryTables{tables: newTables()}},
+		transport: transport,
+		logStore:  logStore,
+		stop:      make(chan struct{}),
+	}
+
+	node.raft, err = raft.NewRaft(raftConfig, node.fsm, logStore, logStore, snapshots, transport)
+	if err != nil {
+		logger.Logging(logger.ERROR, "NewRaft failed: "+err.Error())
+		transport.Close()
+		logStore.Close()
+		return nil, errors.InternalServerError{"Failed to start Raft"}
+	}
+
+	if !existing {
+		// All nodes bootstrap with the same configuration, so that any of them can be elected.
+		err = node.raft.BootstrapCluster(raft.Configuration{Servers: servers}).Error()
+		if err != nil && err != raft.ErrCantBootstrap {
+			logger.Logging(logger.ERROR, "BootstrapCluster failed: "+err.Error())
+			node.close()
+			return nil, errors.InternalServerError{"Failed to bootstrap the cluster"}
+		}
+	}
+
+	go node.watchLeadership(node.raft.LeaderCh())
+
+	logger.Logging(logger.DEBUG, "Raft node started: "+config.NodeId+" "+self.Address)
+
+	return node, nil
+}
+
+func (node *raftNode) close() {
+	close(node.stop)
+	if err := node.raft.Shutdown().Error(); err != nil {
+		logger.Logging(logger.ERROR, "Shutdown failed: "+err.Error())
+	}
+	node.transport.Close()
+	node.logStore.Close()
+}
+
+func (node *raftNode) CreateTopic(properties map[string]interface{}) (bool, error) {
+	value, err := node.apply(raftCommand{Op: opCreateTopic, Properties: properties, Time: now()})
+	created, _ := value.(bool)
+	return created, err
+}
+
+func (node *raftNode) UpdateTopic(properties map[string]interface{}, partial bool) (map[string]interface{}, error) {
+	value, err := node.apply(raftCommand{Op: opUpdateTopic, Properties: properties, Partial: partial})
+	topic, _ := value.(map[string]interface{})
+	return topic, err
+}
+
+func (node *raftNode) ReadTopicAll(selector string) ([]map[string]interface{}, error) {
+	return kvReadTopicAll(node.fsm.store(TOPIC_TABLE), selector)
+}
+
+func (node *raftNode) ReadTopic(name string, hierarchical bool, selector string) ([]map[string]interface{}, error) {
+	return kvReadTopic(node.fsm.store(TOPIC_TABLE), name, hierarchical, selector)
+}
+
+func (node *raftNode) DeleteTopic(name string) error {
+	_, err := node.apply(raftCommand{Op: opDeleteTopic, Name: name})
+	return err
+}
+
+func (node *raftNode) DeletePublisher(name string, endpoint string) error {
+	_, err := node.apply(raftCommand{Op: opDeletePublisher, Name: name, Endpoint: endpoint})
+	return err
+}
+
+func (node *raftNode) DeletePublishers(publishers map[string][]string) error {
+	_, err := node.apply(raftCommand{Op: opDeletePublishers, Publishers: publishers})
+	return err
+}
+
+func (node *raftNode) UpdateLastSeen(names []string, endpoint string, lastSeen time.Time) error {
+	_, err := node.apply(raftCommand{Op: opUpdateLastSeen, Names: names, Endpoint: endpoint, Time: lastSeen})
+	return err
+}
+
+func (node *raftNode) ReadLastSeenAll() (map[string]map[string]time.Time, error) {
+	return kvReadLastSeenAll(node.fsm.store(TOPIC_TABLE))
+}
+
+func (node *raftNode) UpdateInterval(name string, interval uint) error {
+	_, err := node.apply(raftCommand{Op: opUpdateInterval, Name: name, Interval: interval})
+	return err
+}
+
+func (node *raftNode) ReadIntervalAll() (map[string]uint, error) {
+	return kvReadIntervalAll(node.fsm.store(TOPIC_TABLE))
+}
+
+func (node *raftNode) CreateDatamodel(properties map[string]interface{}) error {
+	_, err := node.apply(raftCommand{Op: opCreateDatamodel, Properties: properties})
+	return err
+}
+
+func (node *raftNode) ReadDatamodel(id string) (map[string]interface{}, error) {
+	return kvReadDatamodel(node.fsm.store(DATAMODEL_TABLE), id)
+}
+
+func (node *raftNode) ReadDatamodelAll(name string) ([]map[string]interface{}, error) {
+	return kvReadDatamodelAll(node.fsm.store(DATAMODEL_TABLE), name)
+}
+
+func (node *raftNode) DeleteDatamodel(id string) error {
+	_, err := node.apply(raftCommand{Op: opDeleteDatamodel, Name: id})
+	return err
+}
+
+func (node *raftNode) CreateWebhook(properties map[string]interface{}) error {
+	_, err := node.apply(raftCommand{Op: opCreateWebhook, Properties: properties})
+	return err
+}
+
+func (node *raftNode) ReadWebhook(id string) (map[string]interface{}, error) {
+	return kvReadWebhook(node.fsm.store(WEBHOOK_TABLE), id)
+}
+
+func (node *raftNode) ReadWebhookAll() ([]map[string]interface{}, error) {
+	return kvReadWebhookAll(node.fsm.store(WEBHOOK_TABLE))
+}
+
+func (node *raftNode) DeleteWebhook(id string) error {
+	_, err := node.apply(raftCommand{Op: opDeleteWebhook, Name: id})
+	return err
+}
+
+// IsLeader returns true if this node is the leader.
+func (node *raftNode) IsLeader() bool {
+	return node.raft.State() == raft.Leader
+}
+
+// Leader returns the member of the cluster which is the leader.
+func (node *raftNode) Leader() (ClusterNode, bool) {
+	_, id := node.raft.LeaderWithID()
+	if id == "" {
+		return ClusterNode{}, false
+	}
+	for _, member := range node.config.Nodes {
+		if member.Id == string(id) {
+			return member, true
+		}
+	}
+	return ClusterNode{Id: string(id)}, true
+}
+
+// ReadCluster returns the state of this node and the members of the cluster.
+func (node *raftNode) ReadCluster() (map[string]interface{}, error) {
+	nodes := make([]map[string]interface{}, len(node.config.Nodes))
+	for i, member := range node.config.Nodes {
+		nodes[i] = map[string]interface{}{
+			"id":          member.Id,
+			"address":     member.Address,
+			"api_address": member.ApiAddress,
+		}
+	}
+
+	leader, _ := node.Leader()
+
+	return map[string]interface{}{
+		"node_id":       node.config.NodeId,
+		"state":         strings.ToLower(node.raft.State().String()),
+		"leader_id":     leader.Id,
+		"term":          node.raft.CurrentTerm(),
+		"applied_index": node.raft.AppliedIndex(),
+		"nodes":         nodes,
+	}, nil
+}
+
+// WatchLeadership calls fn with the current leadership, and whenever it changes.
+func (node *raftNode) WatchLeadership(fn func(leader bool)) {
+	node.Lock()
+	defer node.Unlock()
+
+	node.observers = append(node.observers, fn)
+	fn(node.leader)
+}
+
+// watchLeadership notifies the observers of the changes of leadership until the node is closed.
+func (node *raftNode) watchLeadership(leaderCh <-chan bool) {
+	for {
+		select {
+		case leader := <-leaderCh:
+			// The entries committed by former leaders are applied before serving as the leader
+			for leader {
+				err := node.raft.Barrier(RAFT_APPLY_TIMEOUT).Error()
+				if err == nil {
+					break
+				}
+				logger.Logging(logger.ERROR, "Barrier failed: "+err.Error())
+				leader = node.raft.State() == raft.Leader
+			}
+
+			logger.Logging(logger.DEBUG, "Leadership changed: "+node.config.NodeId)
+
+			node.Lock()
+			node.leader = leader
+			for _, fn := range node.observers {
+				fn(leader)
+			}
+			node.Unlock()
+		case <-node.stop:
+			return
+		}
+	}
+}
+
+// apply appends cmd to the Raft log, and returns the result of applying it.
+// Unavailable is returned if this node is not the leader.
+func (node *raftNode) apply(cmd raftCommand) (interface{}, error) {
+	data, err := json.Marshal(cmd)
+	if err != nil {
+		logger.Logging(logger.ERROR, "Marshal failed: "+err.Error())
+		return nil, errors.InvalidParam{err.Error()}
+	}
+
+	future := node.raft.Apply(data, RAFT_APPLY_TIMEOUT)
+	if err := future.Error(); err != nil {
+		switch err {
+		case raft.ErrNotLeader, raft.ErrLeadershipLost, raft.ErrLeadershipTransferInProgress:
+			leader, exists := node.Leader()
+			if !exists {
+				return nil, errors.Unavailable{"no leader is elected"}
+			}
+			return nil, errors.Unavailable{"not the leader, the leader is " + leader.Id}
+		}
+		logger.Logging(logger.ERROR, "Apply failed: "+err.Error())
+		return nil, errors.InternalServerError{"Raft Apply Failed"}
+	}
+
+	result := future.Response().(raftResult)
+	return result.value, result.err
+}
+
+func (fsm *raftFSM) store(table string) raftStore {
+	return raftStore{fsm: fsm, table: table}
+}
+
+// Apply applies a raftCommand of the Raft log, and returns its raftResult.
+func (fsm *raftFSM) Apply(log *raft.Log) interface{} {
+	var cmd raftCommand
+	if err := json.Unmarshal(log.Data, &cmd); err != nil {
+		logger.Logging(logger.ERROR, "Unmarshal failed: "+err.Error())
+		return raftResult{err: errors.InternalServerError{"Invalid Raft log"}}
+	}
+
+	topics := fsm.store(TOPIC_TABLE)
+
+	var result raftResult
+	switch cmd.Op {
+	case opCreateTopic:
+		topic, err := convertToTopic(cmd.Properties)
+		if err != nil {
+			return raftResult{err: err}
+		}
+		// Registered at the time of the leader, not of this node
+		topic.Publishers[0].RegisteredAt = cmd.Time
+		result.value, result.err = kvRegisterTopic(topics, topic)
+	case opUpdateTopic:
+		result.value, result.err = kvUpdateTopic(topics, cmd.Properties, cmd.Partial)
+	case opDeleteTopic:
+		result.err = kvDeleteTopic(topics, cmd.Name)
+	case opDeletePublisher:
+		result.err = kvDeletePublisher(topics, cmd.Name, cmd.Endpoint)
+	case opDeletePublishers:
+		result.err = kvDeletePublishers(topics, cmd.Publishers)
+	case opUpdateLastSeen:
+		result.err = kvUpdateLastSeen(topics, cmd.Names, cmd.Endpoint, cmd.Time)
+	case opUpdateInterval:
+		result.err = kvUpdateInterval(topics, cmd.Name, cmd.Interval)
+	case opCreateDatamodel:
+		result.err = kvCreateDatamodel(fsm.store(DATAMODEL_TABLE), cmd.Properties)
+	case opDeleteDatamodel:
+		result.err = kvDeleteDatamodel(fsm.store(DATAMODEL_TABLE), cmd.Name)
+	case opCreateWebhook:
+		result.err = kvCreateWebhook(fsm.store(WEBHOOK_TABLE), cmd.Properties)
+	case opDeleteWebhook:
+		result.err = kvDeleteWebhook(fsm.store(WEBHOOK_TABLE), cmd.Name)
+	default:
+		// Written by a newer version
+		logger.Logging(logger.ERROR, "Unknown operation: "+cmd.Op)
+		result.err = errors.InternalServerError{"Unknown Raft operation: " + cmd.Op}
+	}
+
+	return result
+}
+
+// Snapshot returns the current tables. Values are not copied since they are
+// replaced, not modified, by transactions.
+func (fsm *raftFSM) Snapshot() (raft.FSMSnapshot, error) {
+	fsm.db.RLock()
+	defer fsm.db.RUnlock()
+
+	tables := make(map[string]map[string][]byte, len(fsm.db.tables))
+	for name, table := range fsm.db.tables {
+		tables[name] = make(map[string][]byte, len(table))
+		for key, value := range table {
+			tables[name][key] = value
+		}
+	}
+
+	return raftSnapshot{tables: tables}, nil
+}
+
+// Restore replaces the tables with a snapshot.
+func (fsm *raftFSM) Restore(snapshot io.ReadCloser) error {
+	defer snapshot.Close()
+
+	var tables map[string]map[string][]byte
+	if err := json.NewDecoder(snapshot).Decode(&tables); err != nil {
+		logger.Logging(logger.ERROR, "Decode failed: "+err.Error())
+		return err
+	}
+
+	restored := newTables()
+	for name, table := range tables {
+		if table != nil {
+			restored[name] = table
+		}
+	}
+
+	fsm.db.Lock()
+	fsm.db.tables = restored
+	fsm.db.Unlock()
+
+	return nil
+}
+
+func (snapshot raftSnapshot) Persist(sink raft.SnapshotSink) error {
+	if err := json.NewEncoder(sink).Encode(snapshot.tables); err != nil {
+		logger.Logging(logger.ERROR, "Encode failed: "+err.Error())
+		sink.Cancel()
+		return err
+	}
+	return sink.Close()
+}
+
+func (snapshot raftSnapshot) Release() {}
+
+func (store raftStore) view(fn func(tx kvTx) error) error {
+	return store.fsm.db.view(store.table, fn)
+}
+
+func (store raftStore) update(fn func(tx kvTx) error) error {
+	return store.fsm.db.update(store.table, fn)
+}
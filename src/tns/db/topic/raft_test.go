@@ -0,0 +1,387 @@
+/*******************************************************************************
+ * Copyright 2018 Samsung Electronics All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ *******************************************************************************/
+package topic
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+	"tns/commons/errors"
+
+	"github.com/hashicorp/raft"
+)
+
+const (
+	testRaftTimeout = 50 * time.Millisecond
+	testWaitTimeout = 5 * time.Second
+)
+
+var testRaftTopic = map[string]interface{}{"name": "/a", "endpoint": "0.0.0.0:1234", "datamodel": "test_0.0.1"}
+
+// raftTestExecutor is a single node cluster, which is ready for writes on Connect.
+type raftTestExecutor struct {
+	RaftExecutor
+}
+
+func (e raftTestExecutor) Connect(config Config) error {
+	raftTimeout = testRaftTimeout
+	if err := e.RaftExecutor.Connect(config); err != nil {
+		return err
+	}
+	if _, err := waitForLeader([]*raftNode{raftDB}); err != nil {
+		e.Close()
+		return err
+	}
+	return nil
+}
+
+// newClusterConfigForTest returns the configurations of n nodes on localhost ports.
+func newClusterConfigForTest(dir string, n int) []ClusterConfig {
+	var nodes []ClusterNode
+	for i := 0; i < n; i++ {
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			panic(err)
+		}
+		address := listener.Addr().String()
+		listener.Close()
+
+		id := fmt.Sprintf("tns%d", i+1)
+		nodes = append(nodes, ClusterNode{Id: id, Address: address, ApiAddress: "127.0.0.1:4832" + fmt.Sprint(i)})
+	}
+
+	configs := make([]ClusterConfig, n)
+	for i, node := range nodes {
+		configs[i] = ClusterConfig{Enabled: true, NodeId: node.Id, DataDir: filepath.Join(dir, node.Id), Nodes: nodes}
+	}
+	return configs
+}
+
+// openClusterForTest starts the nodes of configs. Closed nodes should be set to nil.
+func openClusterForTest(t *testing.T, configs []ClusterConfig) []*raftNode {
+	raftTimeout = testRaftTimeout
+
+	nodes := make([]*raftNode, len(configs))
+	for i, config := range configs {
+		node, err := openRaftNode(config)
+		if err != nil {
+			closeClusterForTest(nodes)
+			t.Fatalf("openRaftNode returned an error: %s", err.Error())
+		}
+		nodes[i] = node
+	}
+	return nodes
+}
+
+func closeClusterForTest(nodes []*raftNode) {
+	for _, node := range nodes {
+		if node != nil {
+			node.close()
+		}
+	}
+}
+
+// waitForLeader returns the index of the leader among nodes, skipping nil ones.
+func waitForLeader(nodes []*raftNode) (int, error) {
+	deadline := time.Now().Add(testWaitTimeout)
+	for time.Now().Before(deadline) {
+		for i, node := range nodes {
+			if node != nil && node.raft.State() == raft.Leader {
+				return i, nil
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	return -1, errors.Unavailable{"no leader is elected"}
+}
+
+// waitFor fails the test if condition does not become true in testWaitTimeout.
+func waitFor(t *testing.T, description string, condition func() bool) {
+	deadline := time.Now().Add(testWaitTimeout)
+	for !condition() {
+		if time.Now().After(deadline) {
+			t.Fatalf("Timed out waiting for %s", description)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestCallRaftConnectWithInvalidConfig(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tns")
+	if err != nil {
+		t.Fatal("TempDir failed")
+	}
+	defer os.RemoveAll(dir)
+
+	nodes := []ClusterNode{{Id: "tns1", Address: "127.0.0.1:0"}}
+
+	testCases := []struct {
+		name   string
+		config ClusterConfig
+	}{
+		{"UnknownNodeId", ClusterConfig{NodeId: "tns2", DataDir: dir, Nodes: nodes}},
+		{"NoNodes", ClusterConfig{NodeId: "tns1", DataDir: dir}},
+		{"NoAddress", ClusterConfig{NodeId: "tns1", DataDir: dir, Nodes: []ClusterNode{{Id: "tns1"}}}},
+		{"NoDataDir", ClusterConfig{NodeId: "tns1", Nodes: nodes}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := RaftExecutor{}.Connect(Config{Type: RAFT_DB, Cluster: tc.config})
+			if reflect.TypeOf(err) != reflect.TypeOf(errors.InvalidParam{}) {
+				t.Errorf("Expected Error: %T, Actual: %v", errors.InvalidParam{}, err)
+			}
+		})
+	}
+}
+
+func TestCallRaftReplicatesWrites(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tns")
+	if err != nil {
+		t.Fatal("TempDir failed")
+	}
+	defer os.RemoveAll(dir)
+
+	nodes := openClusterForTest(t, newClusterConfigForTest(dir, 3))
+	defer closeClusterForTest(nodes)
+
+	index, err := waitForLeader(nodes)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	leader, follower := nodes[index], nodes[(index+1)%len(nodes)]
+
+	created, err := leader.CreateTopic(testRaftTopic)
+	if err != nil || !created {
+		t.Fatalf("CreateTopic returned %t, %v", created, err)
+	}
+	expected, _ := leader.ReadTopic("/a", false, "")
+
+	for _, node := range nodes {
+		waitFor(t, "the topic on "+node.config.NodeId, func() bool {
+			topics, err := node.ReadTopic("/a", false, "")
+			return err == nil && reflect.DeepEqual(topics, expected)
+		})
+	}
+
+	t.Run("ConflictOnLeader", func(t *testing.T) {
+		properties := map[string]interface{}{"name": "/a", "endpoint": "0.0.0.0:5678", "datamodel": "test_0.0.2"}
+		_, err := leader.CreateTopic(properties)
+		if reflect.TypeOf(err) != reflect.TypeOf(errors.Conflict{}) {
+			t.Errorf("Expected Error: %T, Actual: %v", errors.Conflict{}, err)
+		}
+	})
+
+	t.Run("WriteOnFollower", func(t *testing.T) {
+		_, err := follower.CreateTopic(map[string]interface{}{"name": "/b", "endpoint": "0.0.0.0:1234", "datamodel": "test_0.0.1"})
+		if reflect.TypeOf(err) != reflect.TypeOf(errors.Unavailable{}) {
+			t.Fatalf("Expected Error: %T, Actual: %v", errors.Unavailable{}, err)
+		}
+		if !strings.Contains(err.Error(), leader.config.NodeId) {
+			t.Errorf("Leader is not given: %s", err.Error())
+		}
+
+		if member, exists := follower.Leader(); !exists || member.Id != leader.config.NodeId || member.ApiAddress == "" {
+			t.Errorf("Expected leader: %s, Actual: %v", leader.config.NodeId, member)
+		}
+	})
+
+	t.Run("KeepAlive", func(t *testing.T) {
+		lastSeen := time.Unix(1500000000, 0)
+		if err := leader.UpdateLastSeen([]string{"/a"}, "0.0.0.0:1234", lastSeen); err != nil {
+			t.Fatalf("UpdateLastSeen returned an error: %s", err.Error())
+		}
+		if err := leader.UpdateInterval("/a", 30); err != nil {
+			t.Fatalf("UpdateInterval returned an error: %s", err.Error())
+		}
+
+		for _, node := range nodes {
+			waitFor(t, "the keep-alive on "+node.config.NodeId, func() bool {
+				all, _ := node.ReadLastSeenAll()
+				intervals, _ := node.ReadIntervalAll()
+				return all["/a"]["0.0.0.0:1234"].Equal(lastSeen) && intervals["/a"] == 30
+			})
+		}
+	})
+}
+
+func TestCallRaftFailover(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tns")
+	if err != nil {
+		t.Fatal("TempDir failed")
+	}
+	defer os.RemoveAll(dir)
+
+	nodes := openClusterForTest(t, newClusterConfigForTest(dir, 3))
+	defer closeClusterForTest(nodes)
+
+	index, err := waitForLeader(nodes)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if _, err := nodes[index].CreateTopic(testRaftTopic); err != nil {
+		t.Fatalf("CreateTopic returned an error: %s", err.Error())
+	}
+
+	elected := make(chan string, len(nodes))
+	for _, node := range nodes {
+		id := node.config.NodeId
+		node.WatchLeadership(func(leader bool) {
+			if leader {
+				elected <- id
+			}
+		})
+	}
+	select {
+	case id := <-elected:
+		if id != nodes[index].config.NodeId {
+			t.Errorf("Expected leader: %s, Actual: %s", nodes[index].config.NodeId, id)
+		}
+	case <-time.After(testWaitTimeout):
+		t.Fatal("Leadership is not notified")
+	}
+
+	// Stop the leader
+	nodes[index].close()
+	nodes[index] = nil
+
+	index, err = waitForLeader(nodes)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	leader := nodes[index]
+
+	select {
+	case id := <-elected:
+		if id != leader.config.NodeId {
+			t.Errorf("Expected leader: %s, Actual: %s", leader.config.NodeId, id)
+		}
+	case <-time.After(testWaitTimeout):
+		t.Fatal("Leadership is not notified")
+	}
+
+	// Entries of the former leader are applied before notified
+	if topics, err := leader.ReadTopic("/a", false, ""); err != nil || len(topics) != 1 {
+		t.Fatalf("Topic is lost: %v, %v", topics, err)
+	}
+
+	if err := leader.DeleteTopic("/a"); err != nil {
+		t.Fatalf("DeleteTopic returned an error: %s", err.Error())
+	}
+	for _, node := range nodes {
+		if node == nil {
+			continue
+		}
+		waitFor(t, "the deletion on "+node.config.NodeId, func() bool {
+			topics, err := node.ReadTopic("/a", false, "")
+			return err == nil && len(topics) == 0
+		})
+	}
+}
+
+func TestCallRaftRestoresOnRestart(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tns")
+	if err != nil {
+		t.Fatal("TempDir failed")
+	}
+	defer os.RemoveAll(dir)
+
+	configs := newClusterConfigForTest(dir, 3)
+	nodes := openClusterForTest(t, configs)
+
+	index, err := waitForLeader(nodes)
+	if err != nil {
+		closeClusterForTest(nodes)
+		t.Fatal(err.Error())
+	}
+	leader := nodes[index]
+
+	datamodel := map[string]interface{}{"name": "test", "version": "0.0.1", "format": DATAMODEL_FORMAT_JSON_SCHEMA, "schema": "{}"}
+	if err := leader.CreateDatamodel(datamodel); err != nil {
+		closeClusterForTest(nodes)
+		t.Fatalf("CreateDatamodel returned an error: %s", err.Error())
+	}
+	if _, err := leader.CreateTopic(testRaftTopic); err != nil {
+		closeClusterForTest(nodes)
+		t.Fatalf("CreateTopic returned an error: %s", err.Error())
+	}
+
+	// Restored from the snapshot and the log after it
+	if err := leader.raft.Snapshot().Error(); err != nil {
+		closeClusterForTest(nodes)
+		t.Fatalf("Snapshot returned an error: %s", err.Error())
+	}
+	if _, err := leader.CreateTopic(map[string]interface{}{"name": "/b", "endpoint": "0.0.0.0:1234", "datamodel": "test_0.0.1"}); err != nil {
+		closeClusterForTest(nodes)
+		t.Fatalf("CreateTopic returned an error: %s", err.Error())
+	}
+	closeClusterForTest(nodes)
+
+	nodes = openClusterForTest(t, configs)
+	defer closeClusterForTest(nodes)
+
+	if _, err := waitForLeader(nodes); err != nil {
+		t.Fatal(err.Error())
+	}
+	for _, node := range nodes {
+		waitFor(t, "the restored tables on "+node.config.NodeId, func() bool {
+			topics, _ := node.ReadTopicAll("")
+			datamodels, _ := node.ReadDatamodelAll("test")
+			return len(topics) == 2 && len(datamodels) == 1
+		})
+	}
+}
+
+func TestCallRaftReadCluster(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tns")
+	if err != nil {
+		t.Fatal("TempDir failed")
+	}
+	defer os.RemoveAll(dir)
+
+	config := newClusterConfigForTest(dir, 1)[0]
+	handler := raftTestExecutor{}
+	if err := handler.Connect(Config{Type: RAFT_DB, Cluster: config}); err != nil {
+		t.Fatalf("Connect returned an error: %s", err.Error())
+	}
+	defer handler.Close()
+
+	cluster, err := handler.ReadCluster()
+	if err != nil {
+		t.Fatalf("ReadCluster returned an error: %s", err.Error())
+	}
+	if cluster["node_id"] != "tns1" || cluster["state"] != "leader" || cluster["leader_id"] != "tns1" {
+		t.Errorf("Unexpected cluster: %v", cluster)
+	}
+	nodes, _ := cluster["nodes"].([]map[string]interface{})
+	if len(nodes) != 1 || nodes[0]["address"] != config.Nodes[0].Address {
+		t.Errorf("Unexpected nodes: %v", cluster["nodes"])
+	}
+
+	if !handler.IsLeader() {
+		t.Error("Single node is not the leader")
+	}
+	if leader, exists := handler.Leader(); !exists || leader.Id != "tns1" {
+		t.Errorf("Expected leader: tns1, Actual: %v", leader)
+	}
+}
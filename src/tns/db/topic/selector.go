@@ -0,0 +1,226 @@
+/*******************************************************************************
+ * Copyright 2018 Samsung Electronics All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ *******************************************************************************/
+
+package topic
+
+import (
+	"regexp"
+	"strings"
+	"tns/commons/errors"
+
+	"gopkg.in/mgo.v2/bson"
+)
+
+// Operators of label selector.
+const (
+	SELECTOR_EQUALS        = "="
+	SELECTOR_DOUBLE_EQUALS = "=="
+	SELECTOR_NOT_EQUALS    = "!="
+	SELECTOR_IN            = "in"
+	SELECTOR_NOT_IN        = "notin"
+	SELECTOR_EXISTS        = "exists"
+	SELECTOR_NOT_EXISTS    = "!"
+)
+
+// Label keys are used as field names of MongoDB, so '.' and '$' are not allowed.
+var (
+	labelKeyRegex   = regexp.MustCompile(`^[A-Za-z0-9]([-A-Za-z0-9_/]*[A-Za-z0-9])?$`)
+	labelValueRegex = regexp.MustCompile(`^([A-Za-z0-9]([-A-Za-z0-9_.]*[A-Za-z0-9])?)?$`)
+
+	setRequirementRegex       = regexp.MustCompile(`^(\S+)\s+(in|notin)\s*\((.*)\)$`)
+	equalityRequirementRegex  = regexp.MustCompile(`^([^=!\s]+)\s*(==|!=|=)\s*(\S*)$`)
+	notExistsRequirementRegex = regexp.MustCompile(`^!\s*(\S+)$`)
+)
+
+// selector is a list of requirements which must be satisfied together.
+// The syntax is the same as the label selector of Kubernetes, e.g.,
+//
+//	site=plant3,line!=2,vendor in (acme,globex),!deprecated
+type selector []requirement
+
+type requirement struct {
+	key      string
+	operator string
+	values   []string
+}
+
+// parseSelector parses a label selector. An empty string matches all topics.
+func parseSelector(str string) (selector, error) {
+	terms, err := splitSelector(str)
+	if err != nil {
+		return nil, err
+	}
+
+	sel := selector{}
+	for _, term := range terms {
+		req, err := parseRequirement(term)
+		if err != nil {
+			return nil, err
+		}
+		sel = append(sel, req)
+	}
+
+	return sel, nil
+}
+
+// splitSelector splits a selector into requirements by the commas
+// which are not enclosed in parentheses.
+func splitSelector(str string) ([]string, error) {
+	terms := []string{}
+	if strings.TrimSpace(str) == "" {
+		return terms, nil
+	}
+
+	depth, start := 0, 0
+	for i, c := range str {
+		switch c {
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth < 0 {
+				return nil, errors.InvalidQuery{"unbalanced parentheses in selector: " + str}
+			}
+		case ',':
+			if depth == 0 {
+				terms = append(terms, strings.TrimSpace(str[start:i]))
+				start = i + 1
+			}
+		}
+	}
+	if depth != 0 {
+		return nil, errors.InvalidQuery{"unbalanced parentheses in selector: " + str}
+	}
+
+	return append(terms, strings.TrimSpace(str[start:])), nil
+}
+
+func parseRequirement(term string) (requirement, error) {
+	var req requirement
+
+	if match := setRequirementRegex.FindStringSubmatch(term); match != nil {
+		req = requirement{key: match[1], operator: match[2]}
+		for _, value := range strings.Split(match[3], ",") {
+			req.values = append(req.values, strings.TrimSpace(value))
+		}
+	} else if match := equalityRequirementRegex.FindStringSubmatch(term); match != nil {
+		operator := match[2]
+		if operator == SELECTOR_DOUBLE_EQUALS {
+			operator = SELECTOR_EQUALS
+		}
+		req = requirement{key: match[1], operator: operator, values: []string{match[3]}}
+	} else if match := notExistsRequirementRegex.FindStringSubmatch(term); match != nil {
+		req = requirement{key: match[1], operator: SELECTOR_NOT_EXISTS}
+	} else {
+		req = requirement{key: term, operator: SELECTOR_EXISTS}
+	}
+
+	if !labelKeyRegex.MatchString(req.key) {
+		return requirement{}, errors.InvalidQuery{"invalid requirement in selector: " + term}
+	}
+	for _, value := range req.values {
+		if !labelValueRegex.MatchString(value) {
+			return requirement{}, errors.InvalidQuery{"invalid value in selector: " + term}
+		}
+	}
+
+	return req, nil
+}
+
+// matches returns true if the labels satisfy all the requirements.
+func (sel selector) matches(labels map[string]string) bool {
+	for _, req := range sel {
+		value, exists := labels[req.key]
+
+		var matched bool
+		switch req.operator {
+		case SELECTOR_EQUALS:
+			matched = exists && value == req.values[0]
+		case SELECTOR_NOT_EQUALS:
+			matched = !exists || value != req.values[0]
+		case SELECTOR_IN:
+			matched = exists && contains(req.values, value)
+		case SELECTOR_NOT_IN:
+			matched = !exists || !contains(req.values, value)
+		case SELECTOR_EXISTS:
+			matched = exists
+		case SELECTOR_NOT_EXISTS:
+			matched = !exists
+		}
+
+		if !matched {
+			return false
+		}
+	}
+	return true
+}
+
+// convertToBson converts the selector into a query of MongoDB.
+// It returns nil for an empty selector.
+func (sel selector) convertToBson() []bson.M {
+	if len(sel) == 0 {
+		return nil
+	}
+
+	queries := make([]bson.M, len(sel))
+	for i, req := range sel {
+		field := "labels." + req.key
+		switch req.operator {
+		case SELECTOR_EQUALS:
+			queries[i] = bson.M{field: req.values[0]}
+		case SELECTOR_NOT_EQUALS:
+			queries[i] = bson.M{field: bson.M{"$ne": req.values[0]}}
+		case SELECTOR_IN:
+			queries[i] = bson.M{field: bson.M{"$in": req.values}}
+		case SELECTOR_NOT_IN:
+			queries[i] = bson.M{field: bson.M{"$nin": req.values}}
+		case SELECTOR_EXISTS:
+			queries[i] = bson.M{field: bson.M{"$exists": true}}
+		case SELECTOR_NOT_EXISTS:
+			queries[i] = bson.M{field: bson.M{"$exists": false}}
+		}
+	}
+	return queries
+}
+
+// convertToLabels validates the labels of a topic.
+func convertToLabels(value interface{}) (map[string]string, error) {
+	labelsMap, ok := value.(map[string]interface{})
+	if !ok {
+		return nil, errors.InvalidParam{"'labels' field must be an object"}
+	}
+
+	labels := make(map[string]string, len(labelsMap))
+	for key, v := range labelsMap {
+		str, ok := v.(string)
+		if !ok || !labelKeyRegex.MatchString(key) || !labelValueRegex.MatchString(str) {
+			return nil, errors.InvalidParam{"invalid label: " + key}
+		}
+		labels[key] = str
+	}
+
+	return labels, nil
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
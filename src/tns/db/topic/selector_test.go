@@ -0,0 +1,98 @@
+/*******************************************************************************
+ * Copyright 2018 Samsung Electronics All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ *******************************************************************************/
+
+package topic
+
+import (
+	"reflect"
+	"testing"
+	"tns/commons/errors"
+)
+
+func TestParseSelector(t *testing.T) {
+	testCases := []struct {
+		name             string
+		selector         string
+		expectedSelector selector
+		expectedError    error
+	}{
+		{"Empty", " ", selector{}, nil},
+		{"Equality", "a=1, b==2 ,c != 3", selector{
+			{"a", SELECTOR_EQUALS, []string{"1"}},
+			{"b", SELECTOR_EQUALS, []string{"2"}},
+			{"c", SELECTOR_NOT_EQUALS, []string{"3"}}}, nil},
+		{"EmptyValue", "a=", selector{{"a", SELECTOR_EQUALS, []string{""}}}, nil},
+		{"Set", "a in (1, 2),b notin(3)", selector{
+			{"a", SELECTOR_IN, []string{"1", "2"}},
+			{"b", SELECTOR_NOT_IN, []string{"3"}}}, nil},
+		{"Existence", "a,!b", selector{
+			{"a", SELECTOR_EXISTS, nil},
+			{"b", SELECTOR_NOT_EXISTS, nil}}, nil},
+		{"InvalidQuery_Parentheses", "a in (1,2", nil, errors.InvalidQuery{}},
+		{"InvalidQuery_ClosingParenthesis", "a in 1,2)", nil, errors.InvalidQuery{}},
+		{"InvalidQuery_EmptyRequirement", "a=1,", nil, errors.InvalidQuery{}},
+		{"InvalidQuery_Key", "a.b=1", nil, errors.InvalidQuery{}},
+		{"InvalidQuery_Value", "a in (1,$2)", nil, errors.InvalidQuery{}},
+		{"InvalidQuery_Operator", "a>1", nil, errors.InvalidQuery{}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			sel, err := parseSelector(tc.selector)
+			if reflect.TypeOf(err) != reflect.TypeOf(tc.expectedError) {
+				t.Errorf("Expected Error: %s, Actual: %s", tc.expectedError, err)
+			}
+			if !reflect.DeepEqual(sel, tc.expectedSelector) {
+				t.Errorf("Expected Selector: %v, Actual: %v", tc.expectedSelector, sel)
+			}
+		})
+	}
+}
+
+func TestSelectorMatches(t *testing.T) {
+	labels := map[string]string{"site": "plant3", "line": "1"}
+
+	testCases := []struct {
+		selector string
+		expected bool
+	}{
+		{"", true},
+		{"site=plant3", true},
+		{"site=plant4", false},
+		{"site!=plant4", true},
+		{"vendor!=acme", true},
+		{"line in (1,2)", true},
+		{"vendor in (acme)", false},
+		{"line notin (1,2)", false},
+		{"vendor notin (acme)", true},
+		{"site", true},
+		{"!site", false},
+		{"site=plant3,line=2", false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.selector, func(t *testing.T) {
+			sel, err := parseSelector(tc.selector)
+			if err != nil {
+				t.Fatalf("parseSelector returned an error: %s", err.Error())
+			}
+			if sel.matches(labels) != tc.expected {
+				t.Errorf("Expected %s to match %v: %t", tc.selector, labels, tc.expected)
+			}
+		})
+	}
+}
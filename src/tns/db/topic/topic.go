@@ -18,92 +18,332 @@
 package topic
 
 import (
+	"reflect"
 	"regexp"
 	"strings"
+	"time"
 	"tns/commons/errors"
 	"tns/commons/logger"
-	mgo "tns/db/wrapper"
+)
 
-	"gopkg.in/mgo.v2/bson"
+const (
+	WILDCARD              = "*"
+	SINGLE_LEVEL_WILDCARD = "+"
+	MULTI_LEVEL_WILDCARD  = "#"
+	LEVEL_SEPARATOR       = "/"
 )
 
+// Supported database types.
 const (
-	WILDCARD         = "*"
-	DB_URL           = "127.0.0.1:27017"
-	TOPIC_COLLECTION = "TOPIC"
+	MONGO_DB  = "mongo"
+	BOLT_DB   = "bolt"
+	MEMORY_DB = "memory"
+	RAFT_DB   = "raft" // Used in the cluster mode, see raft.go
 )
 
 type Command interface {
-	Connect(name string) error
+	Connect(config Config) error
 	Close()
-	CreateTopic(map[string]interface{}) error
-	ReadTopicAll() ([]map[string]interface{}, error)
-	ReadTopic(name string, hierarchical bool) ([]map[string]interface{}, error)
+	CreateTopic(map[string]interface{}) (bool, error)
+	UpdateTopic(properties map[string]interface{}, partial bool) (map[string]interface{}, error)
+	ReadTopicAll(selector string) ([]map[string]interface{}, error)
+	ReadTopic(name string, hierarchical bool, selector string) ([]map[string]interface{}, error)
 	DeleteTopic(name string) error
+	DeletePublisher(name string, endpoint string) error
+	DeletePublishers(publishers map[string][]string) error
+	UpdateLastSeen(names []string, endpoint string, lastSeen time.Time) error
+	ReadLastSeenAll() (map[string]map[string]time.Time, error)
+	UpdateInterval(name string, interval uint) error
+	ReadIntervalAll() (map[string]uint, error)
+	CreateDatamodel(properties map[string]interface{}) error
+	ReadDatamodel(id string) (map[string]interface{}, error)
+	ReadDatamodelAll(name string) ([]map[string]interface{}, error)
+	DeleteDatamodel(id string) error
+	CreateWebhook(properties map[string]interface{}) error
+	ReadWebhook(id string) (map[string]interface{}, error)
+	ReadWebhookAll() ([]map[string]interface{}, error)
+	DeleteWebhook(id string) error
 }
 
+// Cluster is the state of the Raft cluster, implemented by RaftExecutor.
+type Cluster interface {
+	IsLeader() bool
+	// Leader returns the current leader, false if it is not known.
+	Leader() (ClusterNode, bool)
+	ReadCluster() (map[string]interface{}, error)
+	// WatchLeadership calls fn with whether this node is the leader, at first
+	// and then whenever it changes. fn is called one at a time.
+	WatchLeadership(fn func(leader bool))
+}
+
+// Config holds the settings of the [database] section in the configuration file.
+type Config struct {
+	Type    string        // MONGO_DB(default), BOLT_DB, MEMORY_DB or RAFT_DB
+	Name    string        // Name of database
+	Path    string        // File path of database, used by BOLT_DB only
+	Cluster ClusterConfig // Used by RAFT_DB only, given by the [cluster] section
+
+	// The followings are used by MONGO_DB only
+	Url              string // Connection URI (e.g., mongodb://host1:27017,host2:27017)
+	Username         string
+	Password         string
+	PasswordFile     string // File containing the password, used if Password is empty
+	AuthSource       string // Database to authenticate against
+	ReplicaSet       string // Name of replica set
+	Tls              bool   // Use TLS with system CA, implied by TlsCAFile or TlsCertFile
+	TlsCAFile        string // PEM file of CA certificates to verify the server
+	TlsCertFile      string // PEM file of client certificate
+	TlsKeyFile       string // PEM file of client private key
+	ConnectTimeout   uint   // Second
+	SocketTimeout    uint   // Second
+	OperationTimeout uint   // Second
+}
+
+// ClusterConfig holds the settings of the [cluster] section in the configuration file.
+// All nodes should be given the same Nodes, each with its own NodeId.
+type ClusterConfig struct {
+	Enabled  bool
+	NodeId   string        // ID of this node in Nodes
+	DataDir  string        // Directory of the Raft log and snapshots
+	Redirect bool          // Redirect requests on followers to the leader instead of forwarding
+	Nodes    []ClusterNode // Members of the cluster, three or more to tolerate a failure
+}
+
+// ClusterNode is a member of the cluster.
+type ClusterNode struct {
+	Id         string
+	Address    string // Host and port of Raft, e.g., "10.0.0.1:48330"
+	ApiAddress string // Host and port of REST APIs, e.g., "10.0.0.1:48323"
+}
+
+// Executor implements the Command interface.
+// All operations are forwarded to the storage selected by Connect.
 type Executor struct{}
 
 type Topic struct {
 	//ID            bson.ObjectId    `bson:"_id,omitempty"`
-	Name      string `bson:"name"`
-	Endpoint  string `bson:"endpoint"`
-	Datamodel string `bson:"datamodel"`
-	Secured   bool   `bson:"secured"`
+	Name       string            `bson:"name" json:"name"`
+	Publishers []Publisher       `bson:"publishers" json:"publishers"` // In order of registration
+	Datamodel  string            `bson:"datamodel" json:"datamodel"`
+	Secured    bool              `bson:"secured" json:"secured"`
+	Labels     map[string]string `bson:"labels,omitempty" json:"labels,omitempty"`     // e.g., {"site":"plant3"}
+	Revision   int64             `bson:"revision" json:"revision"`                     // Incremented on every update
+	Interval   uint              `bson:"interval,omitempty" json:"interval,omitempty"` // Keep-alive interval in seconds, 0 for the default
 }
 
-var (
-	mgoDial            mgo.Connection
-	mgoSession         mgo.Session
-	mgoTopicCollection mgo.Collection
-)
+// Publisher is an endpoint which publishes a topic.
+// A topic is removed when its last publisher leaves or expires.
+type Publisher struct {
+	Endpoint string `bson:"endpoint" json:"endpoint"`
+
+	// Time of the last keep-alive, zero if never recorded.
+	LastSeen time.Time `bson:"last_seen" json:"last_seen"`
+
+	// Time of the first registration, zero for the ones registered by former versions.
+	RegisteredAt time.Time `bson:"registered_at,omitempty" json:"registered_at,omitempty"`
+}
+
+// legacyTopic is the format of topics stored by former versions,
+// which allowed only a single publisher per topic.
+type legacyTopic struct {
+	Name     string    `bson:"name"`
+	Endpoint string    `bson:"endpoint"`
+	LastSeen time.Time `bson:"last_seen"`
+}
+
+var storage Command
+
+// now returns the current time, replaced in tests.
+var now = time.Now
 
 func init() {
-	mgoDial = mgo.MongoDial{}
+	storage = MongoExecutor{}
 }
 
-func (topic Topic) convertToMap() map[string]interface{} {
-	return map[string]interface{}{
-		"name":      topic.Name,
-		"endpoint":  topic.Endpoint,
-		"datamodel": topic.Datamodel,
-		"secured":   topic.Secured,
+func (Executor) Connect(config Config) error {
+	switch config.Type {
+	case "", MONGO_DB:
+		storage = MongoExecutor{}
+	case BOLT_DB:
+		storage = BoltExecutor{}
+	case MEMORY_DB:
+		storage = MemoryExecutor{}
+	case RAFT_DB:
+		storage = RaftExecutor{}
+	default:
+		logger.Logging(logger.ERROR, "Unsupported database type: "+config.Type)
+		return errors.InvalidParam{"unsupported database type: " + config.Type}
 	}
+
+	return storage.Connect(config)
 }
 
-func (m Executor) Connect(name string) error {
-	session, err := mgoDial.Dial(DB_URL)
-	if err != nil {
-		logger.Logging(logger.ERROR, err.Error())
-		return err
-	}
+func (Executor) Close() {
+	storage.Close()
+}
 
-	mgoSession = session
-	mgoTopicCollection = mgoSession.DB(name).C(TOPIC_COLLECTION)
+// CreateTopic registers the publisher of the topic given by properties.
+// It returns true if the topic is created or the publisher joins the topic,
+// false if the publisher has already been registered.
+func (Executor) CreateTopic(properties map[string]interface{}) (bool, error) {
+	return storage.CreateTopic(properties)
+}
 
-	logger.Logging(logger.DEBUG, "DB connected: "+DB_URL)
+// UpdateTopic replaces the topic of the given name with properties.
+// If partial is true, only the given properties are changed.
+// If 'revision' is given, the topic is updated only if its revision is the same,
+// otherwise Conflict is returned.
+func (Executor) UpdateTopic(properties map[string]interface{}, partial bool) (map[string]interface{}, error) {
+	return storage.UpdateTopic(properties, partial)
+}
 
-	return nil
+// ReadTopicAll returns all the topics whose labels match selector.
+// An empty selector matches all topics.
+func (Executor) ReadTopicAll(selector string) ([]map[string]interface{}, error) {
+	return storage.ReadTopicAll(selector)
+}
+
+// ReadTopic returns the topics matched by name whose labels match selector.
+// The syntax of selector is described in selector.go, InvalidQuery is returned
+// if it can not be parsed.
+func (Executor) ReadTopic(name string, hierarchical bool, selector string) ([]map[string]interface{}, error) {
+	return storage.ReadTopic(name, hierarchical, selector)
+}
+
+func (Executor) DeleteTopic(name string) error {
+	return storage.DeleteTopic(name)
+}
+
+// DeletePublisher removes the publisher of the given endpoint from the topic.
+// The topic itself is deleted when its last publisher is removed.
+func (Executor) DeletePublisher(name string, endpoint string) error {
+	return storage.DeletePublisher(name, endpoint)
+}
+
+// DeletePublishers removes the given publishers by topic name and endpoints,
+// e.g., all publishers of an expired lease. Topics without publishers are deleted.
+// Names and endpoints which do not exist are ignored.
+func (Executor) DeletePublishers(publishers map[string][]string) error {
+	return storage.DeletePublishers(publishers)
 }
 
-func (m Executor) Close() {
-	mgoSession.Close()
+// UpdateLastSeen records the time of the last keep-alive of the given topics.
+// If endpoint is empty, all publishers of the topics are updated.
+// Names and endpoints which do not exist are ignored.
+func (Executor) UpdateLastSeen(names []string, endpoint string, lastSeen time.Time) error {
+	return storage.UpdateLastSeen(names, endpoint, lastSeen)
+}
+
+// ReadLastSeenAll returns the time of the last keep-alive of all publishers
+// by topic name and endpoint.
+func (Executor) ReadLastSeenAll() (map[string]map[string]time.Time, error) {
+	return storage.ReadLastSeenAll()
+}
+
+// UpdateInterval records the keep-alive interval negotiated for the topic.
+// NotFound is returned if the topic does not exist.
+func (Executor) UpdateInterval(name string, interval uint) error {
+	return storage.UpdateInterval(name, interval)
+}
+
+// ReadIntervalAll returns the keep-alive intervals of the topics which have
+// negotiated one, by topic name.
+func (Executor) ReadIntervalAll() (map[string]uint, error) {
+	return storage.ReadIntervalAll()
+}
+
+// CreateDatamodel registers a datamodel. Conflict is returned if the same
+// version of the datamodel has already been registered.
+func (Executor) CreateDatamodel(properties map[string]interface{}) error {
+	return storage.CreateDatamodel(properties)
+}
+
+// ReadDatamodel returns the datamodel of the given ID, NotFound if not registered.
+func (Executor) ReadDatamodel(id string) (map[string]interface{}, error) {
+	return storage.ReadDatamodel(id)
+}
+
+// ReadDatamodelAll returns all versions of the datamodel of the given name,
+// or all datamodels if name is empty.
+func (Executor) ReadDatamodelAll(name string) ([]map[string]interface{}, error) {
+	return storage.ReadDatamodelAll(name)
+}
+
+func (Executor) DeleteDatamodel(id string) error {
+	return storage.DeleteDatamodel(id)
+}
+
+// CreateWebhook registers a webhook. Conflict is returned if the ID is in use.
+func (Executor) CreateWebhook(properties map[string]interface{}) error {
+	return storage.CreateWebhook(properties)
+}
+
+// ReadWebhook returns the webhook of the given ID, NotFound if not registered.
+func (Executor) ReadWebhook(id string) (map[string]interface{}, error) {
+	return storage.ReadWebhook(id)
+}
+
+func (Executor) ReadWebhookAll() ([]map[string]interface{}, error) {
+	return storage.ReadWebhookAll()
+}
+
+func (Executor) DeleteWebhook(id string) error {
+	return storage.DeleteWebhook(id)
+}
+
+// convertToMap returns the properties of the topic. 'registered_at' is the time of
+// registration by endpoint, which is not a part of the topic in responses.
+func (topic Topic) convertToMap() map[string]interface{} {
+	endpoints := make([]string, len(topic.Publishers))
+	registeredAt := make(map[string]time.Time, len(topic.Publishers))
+	for i, publisher := range topic.Publishers {
+		endpoints[i] = publisher.Endpoint
+		registeredAt[publisher.Endpoint] = publisher.RegisteredAt
+	}
+
+	// 'endpoint' is the first publisher, kept for the clients of former versions
+	endpoint := ""
+	if len(endpoints) != 0 {
+		endpoint = endpoints[0]
+	}
+
+	labels := make(map[string]string, len(topic.Labels))
+	for key, value := range topic.Labels {
+		labels[key] = value
+	}
+
+	return map[string]interface{}{
+		"name":          topic.Name,
+		"endpoint":      endpoint,
+		"endpoints":     endpoints,
+		"datamodel":     topic.Datamodel,
+		"secured":       topic.Secured,
+		"labels":        labels,
+		"revision":      topic.Revision,
+		"registered_at": registeredAt,
+	}
 }
 
-func (m Executor) CreateTopic(properties map[string]interface{}) error {
+// convertToTopic validates the properties of a topic to be registered and
+// converts them into a Topic.
+func convertToTopic(properties map[string]interface{}) (Topic, error) {
 	name, exists := properties["name"].(string)
 	if !exists {
-		return errors.InvalidParam{"'name' field is required"}
+		return Topic{}, errors.InvalidParam{"'name' field is required"}
+	}
+	if isWildcard(name) {
+		// Such a topic could not be looked up exactly
+		return Topic{}, errors.InvalidParam{"'name' can not contain wildcards: " + name}
 	}
 
 	endpoint, exists := properties["endpoint"].(string)
 	if !exists {
-		return errors.InvalidParam{"'endpoint' field is required"}
+		return Topic{}, errors.InvalidParam{"'endpoint' field is required"}
 	}
 
 	datamodel, exists := properties["datamodel"].(string)
 	if !exists {
-		return errors.InvalidParam{"'datamodel' field is required"}
+		return Topic{}, errors.InvalidParam{"'datamodel' field is required"}
 	}
 
 	secured, exists := properties["secured"].(bool)
@@ -111,126 +351,331 @@ func (m Executor) CreateTopic(properties map[string]interface{}) error {
 		secured = false
 	}
 
-	exists, err := m.isTopicNameExists(name)
-	if err != nil {
-		logger.Logging(logger.ERROR, "isTopicNameExists failed")
-		return err
-	}
-	if exists {
-		logger.Logging(logger.DEBUG, "Topic already exists: "+name)
-		return errors.Conflict{name}
+	var labels map[string]string
+	if value, exists := properties["labels"]; exists {
+		var err error
+		if labels, err = convertToLabels(value); err != nil {
+			return Topic{}, err
+		}
 	}
 
 	topic := Topic{
 		//ID:            bson.NewObjectId(),
-		Name:      name,
-		Endpoint:  endpoint,
-		Datamodel: datamodel,
-		Secured:   secured,
+		Name:       name,
+		Publishers: []Publisher{{Endpoint: endpoint, RegisteredAt: now()}},
+		Datamodel:  datamodel,
+		Secured:    secured,
+		Labels:     labels,
+		Revision:   1,
 	}
 
-	if err := mgoTopicCollection.Insert(topic); err != nil {
-		return errors.InternalServerError{"Database Insert Failed"}
+	return topic, nil
+}
+
+// registerPublisher returns the next revision of the topic with the publisher of
+// candidate, which is a topic converted by convertToTopic, and true if the publisher is new.
+// A new publisher can join only if it publishes the same datamodel in the same way,
+// and labels of the candidate, if any, must be the same as the ones of the topic.
+// A publisher which has already been registered (e.g., restarted within the keep-alive
+// interval) is registered again without a conflict. If it is the only publisher,
+// the topic is updated with candidate, otherwise candidate must be compatible with it.
+func (topic Topic) registerPublisher(candidate Topic) (Topic, bool, error) {
+	endpoint := candidate.Publishers[0].Endpoint
+	registered := topic.findPublisher(endpoint) >= 0
+
+	if registered && len(topic.Publishers) == 1 {
+		updated := topic
+		updated.Datamodel = candidate.Datamodel
+		updated.Secured = candidate.Secured
+		if candidate.Labels != nil {
+			updated.Labels = candidate.Labels
+		}
+		if !reflect.DeepEqual(updated, topic) {
+			updated.Revision++
+		}
+		return updated, false, nil
 	}
 
-	return nil
+	if topic.Datamodel != candidate.Datamodel || topic.Secured != candidate.Secured {
+		return Topic{}, false, errors.Conflict{"topic has different datamodel or secured: " + topic.Name}
+	}
+	if len(candidate.Labels) != 0 && !reflect.DeepEqual(topic.Labels, candidate.Labels) {
+		return Topic{}, false, errors.Conflict{"topic has different labels: " + topic.Name}
+	}
+
+	if registered {
+		return topic, false, nil
+	}
+
+	updated := topic
+	updated.Publishers = append(append([]Publisher{}, topic.Publishers...), candidate.Publishers[0])
+	updated.Revision++
+
+	return updated, true, nil
+}
+
+// removePublisher returns the next revision of the topic without the publisher
+// of the given endpoint. The returned topic has no publisher if it was the last one.
+func (topic Topic) removePublisher(endpoint string) (Topic, error) {
+	i := topic.findPublisher(endpoint)
+	if i < 0 {
+		return Topic{}, errors.NotFound{topic.Name + " " + endpoint}
+	}
+
+	updated := topic
+	updated.Publishers = append(append([]Publisher{}, topic.Publishers[:i]...), topic.Publishers[i+1:]...)
+	updated.Revision++
+
+	return updated, nil
 }
 
-func (m Executor) DeleteTopic(name string) error {
-	query := bson.M{"name": name}
-	err := mgoTopicCollection.Remove(query)
-	if err != nil {
-		if err == mgo.ErrNotFound {
-			logger.Logging(logger.DEBUG, "Not found on mongoDb: "+name)
-			return errors.NotFound{name}
+// removePublishers returns the next revision of the topic without the publishers
+// of the given endpoints, and false if none of them is a publisher of the topic.
+func (topic Topic) removePublishers(endpoints []string) (Topic, bool) {
+	updated := topic
+	updated.Publishers = nil
+	for _, publisher := range topic.Publishers {
+		removed := false
+		for _, endpoint := range endpoints {
+			if publisher.Endpoint == endpoint {
+				removed = true
+				break
+			}
+		}
+		if !removed {
+			updated.Publishers = append(updated.Publishers, publisher)
 		}
-		logger.Logging(logger.ERROR, "Failed to Remove on mongoDb: "+name)
-		return errors.InternalServerError{"Database Remove Failed"}
 	}
 
-	return nil
+	if len(updated.Publishers) == len(topic.Publishers) {
+		return topic, false
+	}
+	updated.Revision++
+
+	return updated, true
 }
 
-func (m Executor) ReadTopicAll() ([]map[string]interface{}, error) {
-	topics, err := m.readTopicFromDB(nil)
-	if err != nil {
-		logger.Logging(logger.ERROR, "readTopicFromDB failed")
-		return nil, err
+// findPublisher returns the index of the publisher of the given endpoint, -1 if not found.
+func (topic Topic) findPublisher(endpoint string) int {
+	for i, publisher := range topic.Publishers {
+		if publisher.Endpoint == endpoint {
+			return i
+		}
 	}
+	return -1
+}
 
-	return topics, nil
+// touchPublishers sets the time of the last keep-alive of the publisher of the
+// given endpoint, or of all publishers if endpoint is empty.
+// It returns false if no publisher is changed.
+func (topic *Topic) touchPublishers(endpoint string, lastSeen time.Time) bool {
+	touched := false
+	for i := range topic.Publishers {
+		if endpoint == "" || topic.Publishers[i].Endpoint == endpoint {
+			topic.Publishers[i].LastSeen = lastSeen
+			touched = true
+		}
+	}
+	return touched
 }
 
-func (m Executor) ReadTopic(name string, hierarchical bool) ([]map[string]interface{}, error) {
-	logger.Logging(logger.DEBUG, "IN")
-	defer logger.Logging(logger.DEBUG, "OUT")
+// convertToPublishers returns the publishers of a topic stored by former versions.
+func (legacy legacyTopic) convertToPublishers() []Publisher {
+	if legacy.Endpoint == "" {
+		return []Publisher{}
+	}
+	return []Publisher{{Endpoint: legacy.Endpoint, LastSeen: legacy.LastSeen}}
+}
 
-	if strings.Contains(name, WILDCARD) {
-		if hierarchical {
-			return nil, errors.InvalidQuery{"wildcard is not available while hierarchical is yes"}
+// convertToUpdatedTopic applies properties to the current topic and
+// returns the next revision of it.
+// Name and the keep-alive time are kept from the current topic.
+// The endpoint can be changed only if the topic has a single publisher,
+// other publishers should join and leave instead.
+func convertToUpdatedTopic(current Topic, properties map[string]interface{}, partial bool) (Topic, error) {
+	if value, exists := properties["revision"]; exists {
+		revision, ok := value.(float64)
+		if !ok || revision != float64(int64(revision)) {
+			return Topic{}, errors.InvalidParam{"'revision' field must be an integer"}
+		}
+		if int64(revision) != current.Revision {
+			return Topic{}, errors.Conflict{"revision mismatch: " + current.Name}
 		}
-		return m.readTopicWildcard(name)
 	}
 
-	query := bson.M{}
-	if hierarchical {
-		// add escapes characters before special characters for regular expression
-		len := len(name)
-		for i := 0; i < len; i++ {
-			matched, _ := regexp.MatchString("[^a-zA-Z0-9]", string(name[i]))
-			if matched {
-				name = name[:i] + "\\" + name[i:]
-				len++
-				i++
+	updated := current
+	if partial {
+		if value, exists := properties["endpoint"]; exists {
+			endpoint, ok := value.(string)
+			if !ok {
+				return Topic{}, errors.InvalidParam{"'endpoint' field must be a string"}
+			}
+			if err := updated.replaceEndpoint(endpoint); err != nil {
+				return Topic{}, err
 			}
 		}
-
-		pattern := "^" + name + "$|^" + name + "/" // All started with 'name'
-		query = bson.M{"name": bson.RegEx{Pattern: pattern}}
+		if value, exists := properties["datamodel"]; exists {
+			datamodel, ok := value.(string)
+			if !ok {
+				return Topic{}, errors.InvalidParam{"'datamodel' field must be a string"}
+			}
+			updated.Datamodel = datamodel
+		}
+		if value, exists := properties["secured"]; exists {
+			secured, ok := value.(bool)
+			if !ok {
+				return Topic{}, errors.InvalidParam{"'secured' field must be a boolean"}
+			}
+			updated.Secured = secured
+		}
+		if value, exists := properties["labels"]; exists {
+			labels, err := mergeLabels(current.Labels, value)
+			if err != nil {
+				return Topic{}, err
+			}
+			updated.Labels = labels
+		}
 	} else {
-		query = bson.M{"name": name} // One exactly matched
+		replaced, err := convertToTopic(properties)
+		if err != nil {
+			return Topic{}, err
+		}
+		if err := updated.replaceEndpoint(replaced.Publishers[0].Endpoint); err != nil {
+			return Topic{}, err
+		}
+		updated.Datamodel = replaced.Datamodel
+		updated.Secured = replaced.Secured
+		updated.Labels = replaced.Labels
 	}
 
-	topics, err := m.readTopicFromDB(query)
-	if err != nil {
-		logger.Logging(logger.ERROR, "readTopicFromDB failed")
-		return nil, err
-	}
+	updated.Revision = current.Revision + 1
 
-	return topics, nil
+	return updated, nil
 }
 
-func (m Executor) readTopicFromDB(query bson.M) ([]map[string]interface{}, error) {
-	topics := []Topic{}
-	err := mgoTopicCollection.Find(query).All(&topics)
-	if err != nil {
-		logger.Logging(logger.ERROR, "Failed to Find All on mongoDB: "+err.Error())
-		return nil, errors.InternalServerError{"Database Query Failed"}
+// mergeLabels applies a partial update of labels to the current ones.
+// A label with a null value is removed, as in JSON merge patch.
+func mergeLabels(current map[string]string, value interface{}) (map[string]string, error) {
+	patch, ok := value.(map[string]interface{})
+	if !ok {
+		return nil, errors.InvalidParam{"'labels' field must be an object"}
 	}
 
-	topicsInterface := make([]map[string]interface{}, len(topics))
-	for i, topic := range topics {
-		topicsInterface[i] = topic.convertToMap()
+	merged := make(map[string]interface{}, len(current)+len(patch))
+	for key, v := range current {
+		merged[key] = v
+	}
+	for key, v := range patch {
+		if v == nil {
+			delete(merged, key)
+			continue
+		}
+		merged[key] = v
 	}
 
-	return topicsInterface, nil
+	labels, err := convertToLabels(merged)
+	if err != nil || len(labels) == 0 {
+		return nil, err
+	}
+	return labels, nil
 }
 
-func (m Executor) readTopicWildcard(name string) ([]map[string]interface{}, error) {
-	logger.Logging(logger.DEBUG, "IN")
-	defer logger.Logging(logger.DEBUG, "OUT")
+// replaceEndpoint changes the endpoint of the single publisher of the topic.
+func (topic *Topic) replaceEndpoint(endpoint string) error {
+	if len(topic.Publishers) == 1 && topic.Publishers[0].Endpoint == endpoint {
+		return nil
+	}
+	if len(topic.Publishers) != 1 {
+		return errors.InvalidParam{"'endpoint' can not be changed for a topic with multiple publishers"}
+	}
 
-	// @TODO not supported yet
-	return nil, errors.InvalidQuery{"wildcard is not supported yet"}
+	publisher := topic.Publishers[0]
+	publisher.Endpoint = endpoint
+	topic.Publishers = []Publisher{publisher}
+	return nil
 }
 
-func (m Executor) isTopicNameExists(name string) (bool, error) {
-	query := bson.M{"name": name}
+func isWildcard(name string) bool {
+	return strings.ContainsAny(name, WILDCARD+SINGLE_LEVEL_WILDCARD+MULTI_LEVEL_WILDCARD)
+}
+
+// literalPrefix returns the leading part of a topic name filter that has no wildcard.
+// Every name matched by the filter starts with the returned prefix.
+// The separator before a '#' level is trimmed, since '#' also matches the parent level,
+// e.g., "/a" for "/a/#", which matches "/a" itself.
+func literalPrefix(name string) string {
+	i := strings.IndexAny(name, WILDCARD+SINGLE_LEVEL_WILDCARD+MULTI_LEVEL_WILDCARD)
+	if i < 0 {
+		return name
+	}
+	if name[i:] == MULTI_LEVEL_WILDCARD {
+		return strings.TrimSuffix(name[:i], LEVEL_SEPARATOR)
+	}
+	return name[:i]
+}
+
+// NameMatcher returns a function which reports whether a topic name is matched by
+// the name filter in the same way as ReadTopic. All names are matched if name is empty.
+func NameMatcher(name string, hierarchical bool) (func(string) bool, error) {
+	switch {
+	case name == "":
+		return func(string) bool { return true }, nil
+	case isWildcard(name):
+		pattern, err := convertWildcardToRegex(name, hierarchical)
+		if err != nil {
+			return nil, err
+		}
+		return regexp.MustCompile(pattern).MatchString, nil
+	}
 
-	hit, err := mgoTopicCollection.Find(query).Count()
-	if err != nil {
-		logger.Logging(logger.ERROR, err.Error())
-		return true, errors.InternalServerError{"Database Query Failed"}
+	return func(topicName string) bool {
+		return topicName == name || (hierarchical && strings.HasPrefix(topicName, name+LEVEL_SEPARATOR))
+	}, nil
+}
+
+// convertWildcardToRegex translates a topic name filter into an anchored regular expression.
+// The following wildcards are supported:
+//
+//	'+' matches exactly one level and must occupy the whole level (e.g., /a/+/c)
+//	'#' matches the parent level and any number of sub levels,
+//	    it must occupy the whole last level (e.g., /a/#)
+//	'*' matches any characters within a level (e.g., /a/robot*/c)
+//
+// All other characters are escaped so that they are matched literally.
+// If hierarchical is true, sub levels of every matched name are matched as well.
+func convertWildcardToRegex(name string, hierarchical bool) (string, error) {
+	levels := strings.Split(name, LEVEL_SEPARATOR)
+	last := len(levels) - 1
+
+	patterns := make([]string, 0, len(levels))
+	multiLevel := false
+	for i, level := range levels {
+		switch {
+		case level == MULTI_LEVEL_WILDCARD:
+			if i != last {
+				return "", errors.InvalidQuery{"'#' must be the last level: " + name}
+			}
+			multiLevel = true
+		case level == SINGLE_LEVEL_WILDCARD:
+			patterns = append(patterns, "[^/]+")
+		case strings.ContainsAny(level, SINGLE_LEVEL_WILDCARD+MULTI_LEVEL_WILDCARD):
+			return "", errors.InvalidQuery{"'+' and '#' must occupy an entire level: " + name}
+		default:
+			parts := strings.Split(level, WILDCARD)
+			for j, part := range parts {
+				parts[j] = regexp.QuoteMeta(part)
+			}
+			patterns = append(patterns, strings.Join(parts, "[^/]*"))
+		}
+	}
+
+	pattern := strings.Join(patterns, LEVEL_SEPARATOR)
+	switch {
+	case multiLevel && len(patterns) == 0:
+		pattern = ".*" // '#' only
+	case multiLevel || hierarchical:
+		pattern += "(/.*)?"
 	}
-	return (hit != 0), nil
+
+	return "^" + pattern + "$", nil
 }
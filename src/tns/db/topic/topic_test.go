@@ -18,292 +18,270 @@
 package topic
 
 import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"reflect"
 	"regexp"
 	"testing"
 	"tns/commons/errors"
-	mgo "tns/db/wrapper"
-	mgoMock "tns/db/wrapper/mocks"
-
-	"github.com/golang/mock/gomock"
-	"gopkg.in/mgo.v2/bson"
 )
 
-var Handler Command
-
-func init() {
-	Handler = Executor{}
-}
-
-func TestCallConnect(t *testing.T) {
-	ctrl := gomock.NewController(t)
-	defer ctrl.Finish()
-
-	mgoConnectionMockObj := mgoMock.NewMockConnection(ctrl)
-	mgoSessionMockObj := mgoMock.NewMockSession(ctrl)
-	mgoDatabaseMockObj := mgoMock.NewMockDatabase(ctrl)
-
-	// pass mockObj to a real object.
-	mgoDial = mgoConnectionMockObj
-
-	name := "topic"
+func TestCallConnectWithDatabaseType(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tns")
+	if err != nil {
+		t.Fatal("TempDir failed")
+	}
+	defer os.RemoveAll(dir)
 
 	testCases := []struct {
-		name           string
-		mockRetSession mgoMock.MockSession
-		mockRetError   error
-		expectedError  error
+		name            string
+		config          Config
+		expectedStorage Command
+		expectedError   error
 	}{
-		{"Success", *mgoSessionMockObj, nil, nil},
-		{"DialFailed", *mgoSessionMockObj, errors.Unknown{}, errors.Unknown{}},
+		{"Bolt", Config{Type: BOLT_DB, Path: filepath.Join(dir, "test.db")}, BoltExecutor{}, nil},
+		{"Memory", Config{Type: MEMORY_DB}, MemoryExecutor{}, nil},
+		{"UnsupportedType", Config{Type: "invalid"}, MemoryExecutor{}, errors.InvalidParam{}},
 	}
 
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
-			callFist := mgoConnectionMockObj.EXPECT().Dial(DB_URL).Return(mgoSessionMockObj, tc.mockRetError)
-
-			if tc.name == "Success" {
-				callSecond := mgoSessionMockObj.EXPECT().DB(name).Return(mgoDatabaseMockObj).After(callFist)
-				mgoDatabaseMockObj.EXPECT().C(TOPIC_COLLECTION).After(callSecond)
-			}
-
-			err := Handler.Connect(name)
+			err := Executor{}.Connect(tc.config)
 			if reflect.TypeOf(err) != reflect.TypeOf(tc.expectedError) {
 				t.Errorf("Expected Error: %s, Actual: %s", tc.expectedError, err)
 			}
+			if reflect.TypeOf(storage) != reflect.TypeOf(tc.expectedStorage) {
+				t.Errorf("Expected Storage: %T, Actual: %T", tc.expectedStorage, storage)
+			}
+			if err == nil {
+				Executor{}.Close()
+			}
 		})
 	}
 }
 
-func TestCallClose(t *testing.T) {
-	ctrl := gomock.NewController(t)
-	defer ctrl.Finish()
-
-	mgoSessionMockObj := mgoMock.NewMockSession(ctrl)
-
-	// pass mockObj to a real object.
-	mgoSession = mgoSessionMockObj
-
-	gomock.InOrder(
-		mgoSessionMockObj.EXPECT().Close(),
-	)
+func TestConvertWildcardToRegex(t *testing.T) {
+	testCases := []struct {
+		name          string
+		filter        string
+		hierarchical  bool
+		matched       []string
+		unmatched     []string
+		expectedError error
+	}{
+		{"SingleLevel", "/factory/+/robot", false, []string{"/factory/a/robot"}, []string{"/factory/robot", "/factory/a/b/robot", "/factory/a/robot/arm"}, nil},
+		{"MultiLevel", "/factory/+/robot/#", false, []string{"/factory/a/robot", "/factory/a/robot/arm/1"}, []string{"/factory/a/robotX", "/factory/robot/arm"}, nil},
+		{"MultiLevelOnly", "#", false, []string{"/a", "/a/b"}, nil, nil},
+		{"Glob", "/a/robot*/c", false, []string{"/a/robot/c", "/a/robot01/c"}, []string{"/a/robot/x/c", "/a/rob/c"}, nil},
+		{"Escaped", "/a.b/*", false, []string{"/a.b/c"}, []string{"/aXb/c"}, nil},
+		{"Hierarchical", "/a/+", true, []string{"/a/b", "/a/b/c/d"}, []string{"/a", "/ab/c"}, nil},
+		{"InvalidMultiLevel_NotLast", "/a/#/c", false, nil, nil, errors.InvalidQuery{}},
+		{"InvalidMultiLevel_PartOfLevel", "/a/b#", false, nil, nil, errors.InvalidQuery{}},
+		{"InvalidSingleLevel_PartOfLevel", "/a/b+/c", false, nil, nil, errors.InvalidQuery{}},
+	}
 
-	Handler.Close()
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			pattern, err := convertWildcardToRegex(tc.filter, tc.hierarchical)
+			if reflect.TypeOf(err) != reflect.TypeOf(tc.expectedError) {
+				t.Errorf("Expected Error: %s, Actual: %s", tc.expectedError, err)
+			}
+			for _, name := range tc.matched {
+				if matched, _ := regexp.MatchString(pattern, name); !matched {
+					t.Errorf("Expected %s to match %s (%s)", name, tc.filter, pattern)
+				}
+			}
+			for _, name := range tc.unmatched {
+				if matched, _ := regexp.MatchString(pattern, name); matched {
+					t.Errorf("Expected %s not to match %s (%s)", name, tc.filter, pattern)
+				}
+			}
+		})
+	}
 }
 
-func TestCallCreateTopic(t *testing.T) {
-	ctrl := gomock.NewController(t)
-	defer ctrl.Finish()
-
-	mgoCollectionMockObj := mgoMock.NewMockCollection(ctrl)
-	mgoQueryMockObj := mgoMock.NewMockQuery(ctrl)
-
-	// pass mockObj to a real object.
-	mgoTopicCollection = mgoCollectionMockObj
-
-	dummyProperties := map[string]interface{}{"name": "/a", "endpoint": "0.0.0.0:1234", "datamodel": "test_0.0.1"}
-	dummyQuery := bson.M{"name": "/a"}
-	dummpyTopic := Topic{
-		Name:      "/a",
-		Endpoint:  "0.0.0.0:1234",
-		Datamodel: "test_0.0.1",
+func TestNameMatcher(t *testing.T) {
+	testCases := []struct {
+		name          string
+		filter        string
+		hierarchical  bool
+		matched       []string
+		unmatched     []string
+		expectedError error
+	}{
+		{"All", "", false, []string{"/a", "/a/b"}, nil, nil},
+		{"Exact", "/a/b", false, []string{"/a/b"}, []string{"/a", "/a/b/c", "/a/bc"}, nil},
+		{"Hierarchical", "/a/b", true, []string{"/a/b", "/a/b/c"}, []string{"/a", "/a/bc"}, nil},
+		{"Wildcard", "/a/+", false, []string{"/a/b"}, []string{"/a", "/a/b/c"}, nil},
+		{"InvalidWildcard", "/a/#/c", false, nil, nil, errors.InvalidQuery{}},
 	}
 
-	gomock.InOrder(
-		mgoCollectionMockObj.EXPECT().Find(dummyQuery).Return(mgoQueryMockObj),
-		mgoQueryMockObj.EXPECT().Count().Return(0, nil),
-		mgoCollectionMockObj.EXPECT().Insert(dummpyTopic).Return(nil),
-	)
-
-	err := Handler.CreateTopic(dummyProperties)
-	if err != nil {
-		t.Errorf("CreateTopic returned an error: %s", err.Error())
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			match, err := NameMatcher(tc.filter, tc.hierarchical)
+			if reflect.TypeOf(err) != reflect.TypeOf(tc.expectedError) {
+				t.Fatalf("Expected Error: %s, Actual: %s", tc.expectedError, err)
+			}
+			for _, name := range tc.matched {
+				if !match(name) {
+					t.Errorf("Expected %s to match %s", name, tc.filter)
+				}
+			}
+			for _, name := range tc.unmatched {
+				if match(name) {
+					t.Errorf("Expected %s not to match %s", name, tc.filter)
+				}
+			}
+		})
 	}
 }
 
-func TestCallCreateTopicWithInvalidRequest(t *testing.T) {
-	ctrl := gomock.NewController(t)
-	defer ctrl.Finish()
-
-	mgoCollectionMockObj := mgoMock.NewMockCollection(ctrl)
-	mgoQueryMockObj := mgoMock.NewMockQuery(ctrl)
-
-	// pass mockObj to a real object.
-	mgoTopicCollection = mgoCollectionMockObj
+func TestConvertToUpdatedTopic(t *testing.T) {
+	current := Topic{Name: "/a", Publishers: []Publisher{{Endpoint: "0.0.0.0:1234"}}, Datamodel: "test_0.0.1", Secured: true, Labels: map[string]string{"site": "plant3", "line": "1"}, Revision: 2}
 
 	testCases := []struct {
 		name            string
 		dummyProperties map[string]interface{}
-		dummyQuery      bson.M
-		dummpyTopic     Topic
+		partial         bool
+		expectedTopic   Topic
 		expectedError   error
 	}{
-		{"InvalidParam_name", map[string]interface{}{"endpoint": "0.0.0.0:1234", "datamodel": "test_0.0.1"}, nil, Topic{}, errors.InvalidParam{}},
-		{"InvalidParam_endpoint", map[string]interface{}{"name": "/a", "datamodel": "test_0.0.1"}, nil, Topic{}, errors.InvalidParam{}},
-		{"InvalidParam_datamodel", map[string]interface{}{"name": "/a", "endpoint": "0.0.0.0:1234"}, nil, Topic{}, errors.InvalidParam{}},
-		{"DbFailed_Find", map[string]interface{}{"name": "/a", "endpoint": "0.0.0.0:1234", "datamodel": "test_0.0.1"}, bson.M{"name": "/a"}, Topic{}, errors.InternalServerError{}},
-		{"TopicAlreadyExists", map[string]interface{}{"name": "/a", "endpoint": "0.0.0.0:1234", "datamodel": "test_0.0.1"}, bson.M{"name": "/a"}, Topic{}, errors.Conflict{}},
-		{"DbFailed_Insert", map[string]interface{}{"name": "/a", "endpoint": "0.0.0.0:1234", "datamodel": "test_0.0.1"}, bson.M{"name": "/a"}, Topic{Name: "/a", Endpoint: "0.0.0.0:1234", Datamodel: "test_0.0.1"}, errors.InternalServerError{}},
+		{"Patch", map[string]interface{}{"name": "/a", "secured": false},
+			true, Topic{Name: "/a", Publishers: []Publisher{{Endpoint: "0.0.0.0:1234"}}, Datamodel: "test_0.0.1", Secured: false, Labels: map[string]string{"site": "plant3", "line": "1"}, Revision: 3}, nil},
+		{"Patch_labels", map[string]interface{}{"name": "/a", "labels": map[string]interface{}{"line": nil, "vendor": "acme"}},
+			true, Topic{Name: "/a", Publishers: []Publisher{{Endpoint: "0.0.0.0:1234"}}, Datamodel: "test_0.0.1", Secured: true, Labels: map[string]string{"site": "plant3", "vendor": "acme"}, Revision: 3}, nil},
+		{"Put", map[string]interface{}{"name": "/a", "endpoint": "0.0.0.0:5678", "datamodel": "test_0.0.2"},
+			false, Topic{Name: "/a", Publishers: []Publisher{{Endpoint: "0.0.0.0:5678"}}, Datamodel: "test_0.0.2", Secured: false, Revision: 3}, nil},
+		{"SameRevision", map[string]interface{}{"name": "/a", "revision": float64(2)},
+			true, Topic{Name: "/a", Publishers: []Publisher{{Endpoint: "0.0.0.0:1234"}}, Datamodel: "test_0.0.1", Secured: true, Labels: map[string]string{"site": "plant3", "line": "1"}, Revision: 3}, nil},
+		{"RevisionMismatch", map[string]interface{}{"name": "/a", "revision": float64(1)}, true, Topic{}, errors.Conflict{}},
+		{"InvalidParam_revision", map[string]interface{}{"name": "/a", "revision": 1.5}, true, Topic{}, errors.InvalidParam{}},
+		{"InvalidParam_endpoint", map[string]interface{}{"name": "/a", "endpoint": 1}, true, Topic{}, errors.InvalidParam{}},
+		{"InvalidParam_secured", map[string]interface{}{"name": "/a", "secured": "yes"}, true, Topic{}, errors.InvalidParam{}},
+		{"InvalidParam_labels", map[string]interface{}{"name": "/a", "labels": map[string]interface{}{"site": 3}}, true, Topic{}, errors.InvalidParam{}},
+		{"InvalidParam_Put", map[string]interface{}{"name": "/a", "endpoint": "0.0.0.0:5678"}, false, Topic{}, errors.InvalidParam{}},
+		{"InvalidParam_MultiplePublishers", map[string]interface{}{"name": "/a", "endpoint": "0.0.0.0:5678"}, true, Topic{}, errors.InvalidParam{}},
 	}
 
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
-
-			if tc.name == "DbFailed_Find" {
-				gomock.InOrder(
-					mgoCollectionMockObj.EXPECT().Find(tc.dummyQuery).Return(mgoQueryMockObj),
-					mgoQueryMockObj.EXPECT().Count().Return(0, errors.Unknown{}),
-				)
-			} else if tc.name == "TopicAlreadyExists" {
-				gomock.InOrder(
-					mgoCollectionMockObj.EXPECT().Find(tc.dummyQuery).Return(mgoQueryMockObj),
-					mgoQueryMockObj.EXPECT().Count().Return(1, nil),
-				)
-			} else if tc.name == "DbFailed_Insert" {
-				gomock.InOrder(
-					mgoCollectionMockObj.EXPECT().Find(tc.dummyQuery).Return(mgoQueryMockObj),
-					mgoQueryMockObj.EXPECT().Count().Return(0, nil),
-					mgoCollectionMockObj.EXPECT().Insert(tc.dummpyTopic).Return(errors.Unknown{}),
-				)
+			current := current
+			if tc.name == "InvalidParam_MultiplePublishers" {
+				current.Publishers = []Publisher{{Endpoint: "0.0.0.0:1234"}, {Endpoint: "0.0.0.0:9999"}}
 			}
 
-			err := Handler.CreateTopic(tc.dummyProperties)
+			topic, err := convertToUpdatedTopic(current, tc.dummyProperties, tc.partial)
 			if reflect.TypeOf(err) != reflect.TypeOf(tc.expectedError) {
 				t.Errorf("Expected Error: %s, Actual: %s", tc.expectedError, err)
 			}
+			if !reflect.DeepEqual(topic, tc.expectedTopic) {
+				t.Errorf("Expected Topic: %v, Actual: %v", tc.expectedTopic, topic)
+			}
 		})
 	}
 }
 
-func TestCallDeleteTopic(t *testing.T) {
-	ctrl := gomock.NewController(t)
-	defer ctrl.Finish()
-
-	mgoCollectionMockObj := mgoMock.NewMockCollection(ctrl)
-
-	// pass mockObj to a real object.
-	mgoTopicCollection = mgoCollectionMockObj
-
-	dummyName := "/a"
-	dummyQuery := bson.M{"name": dummyName}
-
+func TestConvertToTopicWithLabels(t *testing.T) {
 	testCases := []struct {
-		name          string
-		mockRetError  error
-		expectedError error
+		name           string
+		labels         interface{}
+		expectedLabels map[string]string
+		expectedError  error
 	}{
-		{"Success", nil, nil},
-		{"TopicNotFound", mgo.ErrNotFound, errors.NotFound{}},
-		{"DbFailed", errors.Unknown{}, errors.InternalServerError{}},
+		{"Success", map[string]interface{}{"site": "plant3", "app/tier": "edge-1"}, map[string]string{"site": "plant3", "app/tier": "edge-1"}, nil},
+		{"Success_EmptyValue", map[string]interface{}{"site": ""}, map[string]string{"site": ""}, nil},
+		{"InvalidParam_NotObject", "site=plant3", nil, errors.InvalidParam{}},
+		{"InvalidParam_NotString", map[string]interface{}{"line": float64(1)}, nil, errors.InvalidParam{}},
+		{"InvalidParam_Key", map[string]interface{}{"site.name": "plant3"}, nil, errors.InvalidParam{}},
+		{"InvalidParam_Value", map[string]interface{}{"site": "plant 3"}, nil, errors.InvalidParam{}},
 	}
 
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
-			gomock.InOrder(
-				mgoCollectionMockObj.EXPECT().Remove(dummyQuery).Return(tc.mockRetError),
-			)
+			properties := map[string]interface{}{"name": "/a", "endpoint": "0.0.0.0:1234", "datamodel": "test_0.0.1", "labels": tc.labels}
 
-			err := Handler.DeleteTopic(dummyName)
+			topic, err := convertToTopic(properties)
 			if reflect.TypeOf(err) != reflect.TypeOf(tc.expectedError) {
 				t.Errorf("Expected Error: %s, Actual: %s", tc.expectedError, err)
 			}
+			if !reflect.DeepEqual(topic.Labels, tc.expectedLabels) {
+				t.Errorf("Expected Labels: %v, Actual: %v", tc.expectedLabels, topic.Labels)
+			}
 		})
 	}
 }
 
-func TestCallReadTopicAll(t *testing.T) {
-	ctrl := gomock.NewController(t)
-	defer ctrl.Finish()
-
-	mgoCollectionMockObj := mgoMock.NewMockCollection(ctrl)
-	mgoQueryMockObj := mgoMock.NewMockQuery(ctrl)
-
-	// pass mockObj to a real object.
-	mgoTopicCollection = mgoCollectionMockObj
+func TestRegisterPublisherWithLabels(t *testing.T) {
+	current := Topic{Name: "/a", Publishers: []Publisher{{Endpoint: "0.0.0.0:1234"}}, Datamodel: "test_0.0.1", Labels: map[string]string{"site": "plant3"}, Revision: 1}
 
 	testCases := []struct {
 		name          string
-		mockRetError  error
+		labels        map[string]string
 		expectedError error
 	}{
-		{"Success", nil, nil},
-		{"DbFailed", errors.Unknown{}, errors.InternalServerError{}},
+		{"Success_WithoutLabels", nil, nil},
+		{"Success_SameLabels", map[string]string{"site": "plant3"}, nil},
+		{"Conflict_DifferentLabels", map[string]string{"site": "plant4"}, errors.Conflict{}},
 	}
 
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
-			outTopics := []Topic{{Name: "/a", Endpoint: "0.0.0.0:1234", Datamodel: "test_0.0.1"}}
+			candidate := Topic{Name: "/a", Publishers: []Publisher{{Endpoint: "0.0.0.0:5678"}}, Datamodel: "test_0.0.1", Labels: tc.labels, Revision: 1}
 
-			gomock.InOrder(
-				mgoCollectionMockObj.EXPECT().Find(nil).Return(mgoQueryMockObj), // nil query to read all
-				mgoQueryMockObj.EXPECT().All(gomock.Any()).SetArg(0, outTopics).Return(tc.mockRetError),
-			)
-
-			_, err := Handler.ReadTopicAll()
+			topic, _, err := current.registerPublisher(candidate)
 			if reflect.TypeOf(err) != reflect.TypeOf(tc.expectedError) {
 				t.Errorf("Expected Error: %s, Actual: %s", tc.expectedError, err)
 			}
+			if err == nil && !reflect.DeepEqual(topic.Labels, current.Labels) {
+				t.Errorf("Expected Labels: %v, Actual: %v", current.Labels, topic.Labels)
+			}
 		})
 	}
 }
 
-func TestCallReadTopic(t *testing.T) {
-	ctrl := gomock.NewController(t)
-	defer ctrl.Finish()
-
-	mgoCollectionMockObj := mgoMock.NewMockCollection(ctrl)
-	mgoQueryMockObj := mgoMock.NewMockQuery(ctrl)
-
-	// pass mockObj to a real object.
-	mgoTopicCollection = mgoCollectionMockObj
+func TestRegisterPublisherAgain(t *testing.T) {
+	single := Topic{Name: "/a", Publishers: []Publisher{{Endpoint: "0.0.0.0:1234"}}, Datamodel: "test_0.0.1", Labels: map[string]string{"site": "plant3"}, Revision: 1}
+	multiple := Topic{Name: "/a", Publishers: []Publisher{{Endpoint: "0.0.0.0:1234"}, {Endpoint: "0.0.0.0:5678"}}, Datamodel: "test_0.0.1", Revision: 1}
 
 	testCases := []struct {
 		name          string
-		topicName     string
-		hierarchical  bool
-		mockRetError  error
+		current       Topic
+		candidate     Topic
+		expectedTopic Topic
 		expectedError error
 	}{
-		{"Success", "/a", false, nil, nil},
-		{"Success_Hierarchical", "/a", true, nil, nil},
-		{"Success_Wildcard", "/a/*", false, nil, errors.InvalidQuery{}}, // @TODO: Since Wildcard feature is not implemented yet, the error is expteced.
-		{"InvalidQuery_HierarchicalAndWildcard", "/a/*", true, nil, errors.InvalidQuery{}},
-		{"DbFailed", "/a", false, errors.Unknown{}, errors.InternalServerError{}},
+		{"Success_Same",
+			single,
+			Topic{Name: "/a", Publishers: []Publisher{{Endpoint: "0.0.0.0:1234"}}, Datamodel: "test_0.0.1"},
+			single, nil},
+		{"Success_Updated",
+			single,
+			Topic{Name: "/a", Publishers: []Publisher{{Endpoint: "0.0.0.0:1234"}}, Datamodel: "test_0.0.2", Secured: true, Labels: map[string]string{"site": "plant4"}},
+			Topic{Name: "/a", Publishers: []Publisher{{Endpoint: "0.0.0.0:1234"}}, Datamodel: "test_0.0.2", Secured: true, Labels: map[string]string{"site": "plant4"}, Revision: 2},
+			nil},
+		{"Success_OneOfPublishers",
+			multiple,
+			Topic{Name: "/a", Publishers: []Publisher{{Endpoint: "0.0.0.0:5678"}}, Datamodel: "test_0.0.1"},
+			multiple, nil},
+		{"Conflict_OneOfPublishers",
+			multiple,
+			Topic{Name: "/a", Publishers: []Publisher{{Endpoint: "0.0.0.0:5678"}}, Datamodel: "test_0.0.2"},
+			Topic{}, errors.Conflict{}},
 	}
 
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
-			if tc.name != "InvalidQuery_HierarchicalAndWildcard" && tc.name != "Success_Wildcard" {
-				dummyQuery := bson.M{}
-				if tc.hierarchical {
-					name := tc.topicName
-					len := len(name)
-					for i := 0; i < len; i++ {
-						matched, _ := regexp.MatchString("[^a-zA-Z0-9]", string(name[i]))
-						if matched {
-							name = name[:i] + "\\" + name[i:]
-							len++
-							i++
-						}
-					}
-
-					pattern := "^" + name + "$|^" + name + "/"
-					dummyQuery = bson.M{"name": bson.RegEx{Pattern: pattern}}
-				} else {
-					dummyQuery = bson.M{"name": tc.topicName}
-				}
-
-				outTopics := []Topic{{Name: "/a", Endpoint: "0.0.0.0:1234", Datamodel: "test_0.0.1"}}
-
-				gomock.InOrder(
-					mgoCollectionMockObj.EXPECT().Find(dummyQuery).Return(mgoQueryMockObj),
-					mgoQueryMockObj.EXPECT().All(gomock.Any()).SetArg(0, outTopics).Return(tc.mockRetError),
-				)
-			}
-			_, err := Handler.ReadTopic(tc.topicName, tc.hierarchical)
+			topic, created, err := tc.current.registerPublisher(tc.candidate)
 			if reflect.TypeOf(err) != reflect.TypeOf(tc.expectedError) {
 				t.Errorf("Expected Error: %s, Actual: %s", tc.expectedError, err)
 			}
+			if created {
+				t.Error("registerPublisher returned created for a registered publisher")
+			}
+			if !reflect.DeepEqual(topic, tc.expectedTopic) {
+				t.Errorf("Expected Topic: %v, Actual: %v", tc.expectedTopic, topic)
+			}
 		})
 	}
 }
@@ -0,0 +1,118 @@
+/*******************************************************************************
+ * Copyright 2018 Samsung Electronics All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ *******************************************************************************/
+package topic
+
+import (
+	"net/url"
+	"tns/commons/errors"
+)
+
+// Webhook is an HTTP callback which is notified of the changes of the topics
+// matched by its name filter, in the same way as ReadTopic. Events are the types
+// of changes to be notified, all types if empty. Secret is the key to sign the
+// notifications with, which is not returned to clients once registered.
+type Webhook struct {
+	Id           string   `bson:"_id" json:"id"`
+	Url          string   `bson:"url" json:"url"`
+	Name         string   `bson:"name" json:"name"`
+	Hierarchical bool     `bson:"hierarchical" json:"hierarchical"`
+	Events       []string `bson:"events" json:"events"`
+	Secret       string   `bson:"secret" json:"secret"`
+}
+
+func (webhook Webhook) convertToMap() map[string]interface{} {
+	events := webhook.Events
+	if events == nil {
+		events = []string{}
+	}
+
+	return map[string]interface{}{
+		"id":           webhook.Id,
+		"url":          webhook.Url,
+		"name":         webhook.Name,
+		"hierarchical": webhook.Hierarchical,
+		"events":       events,
+		"secret":       webhook.Secret,
+	}
+}
+
+// convertToWebhook validates the properties of a webhook to be registered and
+// converts them into a Webhook. 'id' and 'secret' are given by the controller.
+func convertToWebhook(properties map[string]interface{}) (Webhook, error) {
+	id, _ := properties["id"].(string)
+	if id == "" {
+		return Webhook{}, errors.InvalidParam{"'id' field is required"}
+	}
+
+	secret, _ := properties["secret"].(string)
+	if secret == "" {
+		return Webhook{}, errors.InvalidParam{"'secret' field is required"}
+	}
+
+	callback, exists := properties["url"].(string)
+	if !exists {
+		return Webhook{}, errors.InvalidParam{"'url' field is required"}
+	}
+	parsed, err := url.Parse(callback)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return Webhook{}, errors.InvalidParam{"invalid url: " + callback}
+	}
+
+	name := ""
+	if value, exists := properties["name"]; exists {
+		if name, exists = value.(string); !exists {
+			return Webhook{}, errors.InvalidParam{"'name' field must be a string"}
+		}
+	}
+
+	hierarchical := false
+	if value, exists := properties["hierarchical"]; exists {
+		if hierarchical, exists = value.(bool); !exists {
+			return Webhook{}, errors.InvalidParam{"'hierarchical' field must be a boolean"}
+		}
+	}
+
+	if _, err := NameMatcher(name, hierarchical); err != nil {
+		return Webhook{}, errors.InvalidParam{"invalid name: " + name}
+	}
+
+	var events []string
+	if value, exists := properties["events"]; exists {
+		array, ok := value.([]interface{})
+		if !ok {
+			return Webhook{}, errors.InvalidParam{"'events' field must be an array of strings"}
+		}
+		for _, element := range array {
+			event, ok := element.(string)
+			if !ok {
+				return Webhook{}, errors.InvalidParam{"'events' field must be an array of strings"}
+			}
+			events = append(events, event)
+		}
+	}
+
+	webhook := Webhook{
+		Id:           id,
+		Url:          callback,
+		Name:         name,
+		Hierarchical: hierarchical,
+		Events:       events,
+		Secret:       secret,
+	}
+
+	return webhook, nil
+}
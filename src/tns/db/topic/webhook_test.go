@@ -0,0 +1,72 @@
+/*******************************************************************************
+ * Copyright 2018 Samsung Electronics All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ *******************************************************************************/
+package topic
+
+import (
+	"reflect"
+	"testing"
+	"tns/commons/errors"
+)
+
+func TestConvertToWebhook(t *testing.T) {
+	properties := func(key string, value interface{}) map[string]interface{} {
+		properties := map[string]interface{}{"id": "0123", "secret": "s3cr3t", "url": "http://10.0.0.1:8080/hook"}
+		if key != "" {
+			if value == nil {
+				delete(properties, key)
+			} else {
+				properties[key] = value
+			}
+		}
+		return properties
+	}
+
+	testCases := []struct {
+		name            string
+		dummyProperties map[string]interface{}
+		expectedWebhook Webhook
+		expectedError   error
+	}{
+		{"Success", properties("", nil),
+			Webhook{Id: "0123", Url: "http://10.0.0.1:8080/hook", Secret: "s3cr3t"}, nil},
+		{"Success_Filters", map[string]interface{}{"id": "0123", "secret": "s3cr3t", "url": "https://orchestrator/hook",
+			"name": "/robot/+", "hierarchical": true, "events": []interface{}{"deleted", "expired"}},
+			Webhook{Id: "0123", Url: "https://orchestrator/hook", Name: "/robot/+", Hierarchical: true, Events: []string{"deleted", "expired"}, Secret: "s3cr3t"}, nil},
+		{"InvalidParam_NoId", properties("id", nil), Webhook{}, errors.InvalidParam{}},
+		{"InvalidParam_NoSecret", properties("secret", nil), Webhook{}, errors.InvalidParam{}},
+		{"InvalidParam_NoUrl", properties("url", nil), Webhook{}, errors.InvalidParam{}},
+		{"InvalidParam_Url", properties("url", "10.0.0.1:8080/hook"), Webhook{}, errors.InvalidParam{}},
+		{"InvalidParam_UrlScheme", properties("url", "ftp://10.0.0.1/hook"), Webhook{}, errors.InvalidParam{}},
+		{"InvalidParam_Name", properties("name", 1.0), Webhook{}, errors.InvalidParam{}},
+		{"InvalidParam_NameWildcard", properties("name", "/a/#/b"), Webhook{}, errors.InvalidParam{}},
+		{"InvalidParam_Hierarchical", properties("hierarchical", "yes"), Webhook{}, errors.InvalidParam{}},
+		{"InvalidParam_Events", properties("events", "deleted"), Webhook{}, errors.InvalidParam{}},
+		{"InvalidParam_EventsElement", properties("events", []interface{}{1.0}), Webhook{}, errors.InvalidParam{}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			webhook, err := convertToWebhook(tc.dummyProperties)
+			if reflect.TypeOf(err) != reflect.TypeOf(tc.expectedError) {
+				t.Errorf("Expected Error: %s, Actual: %s", tc.expectedError, err)
+			}
+			if !reflect.DeepEqual(webhook, tc.expectedWebhook) {
+				t.Errorf("Expected Webhook: %v, Actual: %v", tc.expectedWebhook, webhook)
+			}
+		})
+	}
+}
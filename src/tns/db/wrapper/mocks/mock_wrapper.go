@@ -79,16 +79,16 @@ func (m *MockConnection) EXPECT() *MockConnectionMockRecorder {
 }
 
 // Dial mocks base method
-func (m *MockConnection) Dial(url string) (Session, error) {
-	ret := m.ctrl.Call(m, "Dial", url)
+func (m *MockConnection) Dial(info DialInfo) (Session, error) {
+	ret := m.ctrl.Call(m, "Dial", info)
 	ret0, _ := ret[0].(Session)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // Dial indicates an expected call of Dial
-func (mr *MockConnectionMockRecorder) Dial(url interface{}) *gomock.Call {
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Dial", reflect.TypeOf((*MockConnection)(nil).Dial), url)
+func (mr *MockConnectionMockRecorder) Dial(info interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Dial", reflect.TypeOf((*MockConnection)(nil).Dial), info)
 }
 
 // MockDatabase is a mock of Database interface
@@ -201,6 +201,42 @@ func (mr *MockCollectionMockRecorder) Update(selector, update interface{}) *gomo
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockCollection)(nil).Update), selector, update)
 }
 
+// UpdateAll mocks base method
+func (m *MockCollection) UpdateAll(selector, update interface{}) error {
+	ret := m.ctrl.Call(m, "UpdateAll", selector, update)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateAll indicates an expected call of UpdateAll
+func (mr *MockCollectionMockRecorder) UpdateAll(selector, update interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateAll", reflect.TypeOf((*MockCollection)(nil).UpdateAll), selector, update)
+}
+
+// EnsureIndex mocks base method
+func (m *MockCollection) EnsureIndex(index Index) error {
+	ret := m.ctrl.Call(m, "EnsureIndex", index)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// EnsureIndex indicates an expected call of EnsureIndex
+func (mr *MockCollectionMockRecorder) EnsureIndex(index interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EnsureIndex", reflect.TypeOf((*MockCollection)(nil).EnsureIndex), index)
+}
+
+// Pipe mocks base method
+func (m *MockCollection) Pipe(pipeline interface{}) Pipe {
+	ret := m.ctrl.Call(m, "Pipe", pipeline)
+	ret0, _ := ret[0].(Pipe)
+	return ret0
+}
+
+// Pipe indicates an expected call of Pipe
+func (mr *MockCollectionMockRecorder) Pipe(pipeline interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Pipe", reflect.TypeOf((*MockCollection)(nil).Pipe), pipeline)
+}
+
 // MockQuery is a mock of Query interface
 type MockQuery struct {
 	ctrl     *gomock.Controller
@@ -260,3 +296,38 @@ func (m *MockQuery) Count() (int, error) {
 func (mr *MockQueryMockRecorder) Count() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Count", reflect.TypeOf((*MockQuery)(nil).Count))
 }
+
+// MockPipe is a mock of Pipe interface
+type MockPipe struct {
+	ctrl     *gomock.Controller
+	recorder *MockPipeMockRecorder
+}
+
+// MockPipeMockRecorder is the mock recorder for MockPipe
+type MockPipeMockRecorder struct {
+	mock *MockPipe
+}
+
+// NewMockPipe creates a new mock instance
+func NewMockPipe(ctrl *gomock.Controller) *MockPipe {
+	mock := &MockPipe{ctrl: ctrl}
+	mock.recorder = &MockPipeMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockPipe) EXPECT() *MockPipeMockRecorder {
+	return m.recorder
+}
+
+// All mocks base method
+func (m *MockPipe) All(result interface{}) error {
+	ret := m.ctrl.Call(m, "All", result)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// All indicates an expected call of All
+func (mr *MockPipeMockRecorder) All(result interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "All", reflect.TypeOf((*MockPipe)(nil).All), result)
+}
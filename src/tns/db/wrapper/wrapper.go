@@ -18,7 +18,10 @@
 package wrapper
 
 import (
+	"crypto/tls"
 	"gopkg.in/mgo.v2"
+	"net"
+	"time"
 )
 
 type (
@@ -32,11 +35,25 @@ type (
 	}
 
 	Connection interface {
-		Dial(url string) (Session, error)
+		Dial(info DialInfo) (Session, error)
 	}
 
 	MongoDial struct{}
 
+	// DialInfo holds options for establishing a session with MongoDB.
+	// Non-empty fields take precedence over the options given by Url.
+	DialInfo struct {
+		Url            string
+		Username       string
+		Password       string
+		Source         string
+		ReplicaSetName string
+		TLSConfig      *tls.Config // nil if TLS is not used
+		Timeout        time.Duration
+		SocketTimeout  time.Duration
+		SyncTimeout    time.Duration
+	}
+
 	Database interface {
 		C(name string) Collection
 	}
@@ -50,6 +67,15 @@ type (
 		Insert(docs ...interface{}) error
 		Remove(selector interface{}) error
 		Update(selector interface{}, update interface{}) error
+		UpdateAll(selector interface{}, update interface{}) error
+		EnsureIndex(index Index) error
+		Pipe(pipeline interface{}) Pipe
+	}
+
+	// Index describes an index of a collection.
+	Index struct {
+		Key    []string
+		Unique bool
 	}
 
 	MongoCollection struct {
@@ -65,10 +91,21 @@ type (
 	MongoQuery struct {
 		Query *mgo.Query
 	}
+
+	Pipe interface {
+		All(result interface{}) error
+	}
+
+	MongoPipe struct {
+		Pipe *mgo.Pipe
+	}
 )
 
 var ErrNotFound = mgo.ErrNotFound
 
+// IsDup returns whether err informs of a duplicate key error.
+var IsDup = mgo.IsDup
+
 func (s MongoSession) DB(name string) Database {
 	return &MongoDatabase{Database: s.Session.DB(name)}
 }
@@ -77,10 +114,45 @@ func (s MongoSession) Close() {
 	s.Session.Close()
 }
 
-// Dial is a wrapper function used to abstract mgo Dial function.
-func (MongoDial) Dial(url string) (Session, error) {
-	session, err := mgo.Dial(url)
-	return MongoSession{Session: session}, err
+// Dial is a wrapper function used to abstract mgo DialWithInfo function.
+func (MongoDial) Dial(info DialInfo) (Session, error) {
+	dialInfo, err := mgo.ParseURL(info.Url)
+	if err != nil {
+		return MongoSession{}, err
+	}
+
+	if info.Username != "" {
+		dialInfo.Username = info.Username
+	}
+	if info.Password != "" {
+		dialInfo.Password = info.Password
+	}
+	if info.Source != "" {
+		dialInfo.Source = info.Source
+	}
+	if info.ReplicaSetName != "" {
+		dialInfo.ReplicaSetName = info.ReplicaSetName
+	}
+	if info.TLSConfig != nil {
+		dialInfo.DialServer = func(addr *mgo.ServerAddr) (net.Conn, error) {
+			return tls.DialWithDialer(&net.Dialer{Timeout: info.Timeout}, "tcp", addr.String(), info.TLSConfig)
+		}
+	}
+	dialInfo.Timeout = info.Timeout
+
+	session, err := mgo.DialWithInfo(dialInfo)
+	if err != nil {
+		return MongoSession{Session: session}, err
+	}
+
+	if info.SocketTimeout != 0 {
+		session.SetSocketTimeout(info.SocketTimeout)
+	}
+	if info.SyncTimeout != 0 {
+		session.SetSyncTimeout(info.SyncTimeout)
+	}
+
+	return MongoSession{Session: session}, nil
 }
 
 // C is a wrapper function used to abstract mgo C function.
@@ -108,6 +180,22 @@ func (c MongoCollection) Update(selector interface{}, update interface{}) error
 	return c.Collection.Update(selector, update)
 }
 
+// UpdateAll is a wrapper function used to abstract mgo UpdateAll function.
+func (c MongoCollection) UpdateAll(selector interface{}, update interface{}) error {
+	_, err := c.Collection.UpdateAll(selector, update)
+	return err
+}
+
+// EnsureIndex is a wrapper function used to abstract mgo EnsureIndex function.
+func (c MongoCollection) EnsureIndex(index Index) error {
+	return c.Collection.EnsureIndex(mgo.Index{Key: index.Key, Unique: index.Unique})
+}
+
+// Pipe is a wrapper function used to abstract mgo Pipe function.
+func (c MongoCollection) Pipe(pipeline interface{}) Pipe {
+	return MongoPipe{Pipe: c.Collection.Pipe(pipeline)}
+}
+
 // All is a wrapper function used to abstract mgo All function.
 func (q MongoQuery) All(result interface{}) error {
 	return q.Query.All(result)
@@ -122,3 +210,8 @@ func (q MongoQuery) One(result interface{}) error {
 func (q MongoQuery) Count() (int, error) {
 	return q.Query.Count()
 }
+
+// All is a wrapper function used to abstract mgo Pipe.All function.
+func (p MongoPipe) All(result interface{}) error {
+	return p.Pipe.All(result)
+}
@@ -22,11 +22,25 @@ go get github.com/golang/mock/gomock
 
 pkg_list=("tns/api" \
           "tns/api/topic" \
+          "tns/api/cluster" \
           "tns/api/keepalive" \
+          "tns/api/datamodel" \
+          "tns/api/coap" \
+          "tns/api/dns" \
+          "tns/api/grpc" \
+          "tns/api/lease" \
+          "tns/api/mdns" \
+          "tns/api/watch" \
+          "tns/api/webhook" \
           "tns/commons/errors" \
           "tns/commons/logger" \
           "tns/controller/topic" \
           "tns/controller/keepalive" \
+          "tns/controller/datamodel" \
+          "tns/controller/federation" \
+          "tns/controller/relay" \
+          "tns/controller/watch" \
+          "tns/controller/webhook" \
           "tns/db/topic")
 
 function func_cleanup(){